@@ -1,10 +1,17 @@
 package imagefy
 
-import "strings"
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
 
 // LicenseSignal represents a single evidence point about an image's license status.
 type LicenseSignal struct {
-	Source  string       // signal source: "domain", "extra_domain", "metadata_stock", "metadata_cc", "url_pattern"
+	Source  string       // signal source: "domain", "extra_domain", "metadata_stock", "metadata_cc", "page_cc", "url_pattern", "provider_license", "trusted_creator"
 	Detail  string       // human-readable detail
 	License ImageLicense // what this signal indicates
 }
@@ -15,15 +22,20 @@ type LicenseAssessment struct {
 	Signals []LicenseSignal // contributing evidence (never nil, may be empty)
 }
 
-// AssessLicense combines domain classification, extended domain checks, and
-// metadata signals (stock detection, CC detection) into a single transparent
-// license verdict. Blocked signals always take precedence over Safe.
+// AssessLicense combines domain classification, extended domain checks,
+// metadata signals (stock detection, CC detection), and the provider-reported
+// license name (ImageCandidate.LicenseName) into a single transparent license
+// verdict. Blocked signals take precedence over Safe, except that an
+// image whose metadata credits one of Config.TrustedCreators is Safe
+// whatever its domain says; stock agency metadata and a blocked provider
+// license still block it.
 func (cfg *Config) AssessLicense(cand ImageCandidate, meta *ImageMetadata) LicenseAssessment {
+	cfg = cfg.orZero()
 	signals := make([]LicenseSignal, 0, 4) //nolint:mnd // pre-allocate for up to 4 signal types
 
 	// Signal 1: search-time domain classification (already set by provider).
-	// Guard: only emit when candidate has URL data (LicenseSafe is iota zero
-	// value, so a zero-value ImageCandidate would falsely match without this).
+	// Guard: only emit when candidate has URL data — a bare License without
+	// any URL is not evidence about an image.
 	if cand.ImgURL != "" || cand.Source != "" {
 		switch cand.License {
 		case LicenseBlocked:
@@ -42,8 +54,8 @@ func (cfg *Config) AssessLicense(cand ImageCandidate, meta *ImageMetadata) Licen
 	}
 
 	// Signal 2: extended domain check — only when extra lists are configured.
-	if len(cfg.ExtraBlockedDomains) > 0 || len(cfg.ExtraSafeDomains) > 0 {
-		extLicense := CheckLicenseWith(cand.ImgURL, cand.Source, cfg.ExtraBlockedDomains, cfg.ExtraSafeDomains)
+	if extraBlocked, extraSafe := cfg.extraBlocked(), cfg.extraSafe(); len(extraBlocked) > 0 || len(extraSafe) > 0 {
+		extLicense := checkLicense(cand.ImgURL, cand.Source, extraBlocked, extraSafe, cfg.BlockPolicy)
 		// Only add a signal if it changes the classification from the search-time check.
 		if extLicense != cand.License && extLicense != LicenseUnknown {
 			signals = append(signals, LicenseSignal{
@@ -54,11 +66,12 @@ func (cfg *Config) AssessLicense(cand ImageCandidate, meta *ImageMetadata) Licen
 		}
 	}
 
-	// Signal 3: metadata stock detection.
-	if IsStockByMetadata(meta) {
+	// Signal 3: metadata stock detection, skipping keywords of categories
+	// that Config.BlockPolicy allows.
+	if field := stockMetadataField(meta, cfg.BlockPolicy); field != "" {
 		signals = append(signals, LicenseSignal{
 			Source:  "metadata_stock",
-			Detail:  "stock agency detected in metadata: " + metadataStockDetail(meta),
+			Detail:  "stock agency detected in metadata: " + field,
 			License: LicenseBlocked,
 		})
 	}
@@ -72,46 +85,140 @@ func (cfg *Config) AssessLicense(cand ImageCandidate, meta *ImageMetadata) Licen
 		})
 	}
 
-	// Resolution: Blocked > Safe > Unknown.
+	// Signal 5: license reported by the provider (e.g. Wikimedia Commons).
+	if cand.LicenseName != "" {
+		if l := licenseFromName(cand.LicenseName); l != LicenseUnknown {
+			signals = append(signals, LicenseSignal{
+				Source:  "provider_license",
+				Detail:  "license reported by provider: " + cand.LicenseName,
+				License: l,
+			})
+		}
+	}
+
+	// Signal 6: creator allow list — overrides the domain signals.
+	if creator := cfg.trustedCreator(meta); creator != "" {
+		signals = append(signals, LicenseSignal{
+			Source:  "trusted_creator",
+			Detail:  "credited to trusted creator: " + creator,
+			License: LicenseSafe,
+		})
+	}
+
+	return LicenseAssessment{
+		License: resolveLicense(signals),
+		Signals: signals,
+	}
+}
+
+// AssessLicenseURL audits a single live image without running a search. It
+// downloads imageURL, extracts its embedded metadata, and — when sourceURL is
+// non-empty — fetches the source page and scans it for a Creative Commons
+// license (signal source "page_cc"). The domain signals come from
+// CheckLicense on both URLs, as a search provider would set them.
+// Failed fetches are skipped, so the result degrades to the signals that
+// could be gathered.
+func (cfg *Config) AssessLicenseURL(ctx context.Context, imageURL, sourceURL string) LicenseAssessment {
+	cfg = cfg.orZero()
+	cfg.defaults()
+
+	cand := ImageCandidate{ImgURL: imageURL, Source: sourceURL, License: CheckLicense(imageURL, sourceURL)}
+
+	var meta *ImageMetadata
+	if r, err := cfg.Download(ctx, imageURL, DownloadOpts{}); err == nil && r != nil {
+		meta = cfg.imageMetadata(ctx, r.Data)
+	}
+	assessment := cfg.AssessLicense(cand, meta)
+
+	if sourceURL == "" {
+		return assessment
+	}
+	if ccURL := ExtractCCLicense(cfg.fetchSourcePage(ctx, sourceURL)); ccURL != "" {
+		assessment.Signals = append(assessment.Signals, LicenseSignal{
+			Source:  "page_cc",
+			Detail:  "Creative Commons license on source page: " + ccURL,
+			License: LicenseSafe,
+		})
+		assessment.License = resolveLicense(assessment.Signals)
+	}
+	return assessment
+}
+
+// resolveLicense combines signals into a verdict: Blocked > Safe > Unknown.
+// With a trusted_creator signal, only metadata_stock and provider_license
+// signals can block.
+func resolveLicense(signals []LicenseSignal) ImageLicense {
+	trusted := slices.ContainsFunc(signals, func(s LicenseSignal) bool { return s.Source == "trusted_creator" })
 	final := LicenseUnknown
 	for _, sig := range signals {
-		if sig.License == LicenseBlocked {
-			final = LicenseBlocked
-			break
+		if sig.License == LicenseBlocked && (!trusted || sig.Source == "metadata_stock" || sig.Source == "provider_license") {
+			return LicenseBlocked
 		}
 		if sig.License == LicenseSafe {
 			final = LicenseSafe
 		}
 	}
+	return final
+}
 
-	return LicenseAssessment{
-		License: final,
-		Signals: signals,
+// fetchSourcePage returns up to maxHTMLScanBytes of the page at pageURL, or ""
+// on any failure. It goes through the same clients and host profiles as
+// image probes.
+func (cfg *Config) fetchSourcePage(ctx context.Context, pageURL string) string {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header = cfg.requestHeader(pageURL, "")
+
+	resp, err := cfg.downloadClient(cfg.probeClient(pageURL)).Do(req) //nolint:gosec // G704: URL is caller-supplied by design — SSRF is caller's responsibility
+	if err != nil {
+		slog.Debug("imagefy: source page fetch failed", "url", pageURL, "error", err.Error())
+		return ""
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ""
 	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTMLScanBytes))
+	if err != nil {
+		return ""
+	}
+	return string(body)
 }
 
-// metadataStockDetail returns the metadata field that triggered the stock
-// detection (the first field containing a matching keyword).
-func metadataStockDetail(meta *ImageMetadata) string {
-	if meta == nil {
+// rejectReason maps a blocked assessment to its RejectReason: embedded stock
+// metadata wins over domain/URL-pattern evidence. Returns "" unless blocked.
+func (a LicenseAssessment) rejectReason() RejectReason {
+	if a.License != LicenseBlocked {
 		return ""
 	}
-	for _, f := range []string{
-		meta.EXIFCopyright,
-		meta.EXIFArtist,
-		meta.IPTCCopyright,
-		meta.IPTCCredit,
-		meta.IPTCSource,
-		meta.IPTCByline,
-		meta.DCRights,
-		meta.DCCreator,
-	} {
+	for _, sig := range a.Signals {
+		if sig.Source == "metadata_stock" {
+			return ReasonStockMetadata
+		}
+	}
+	return ReasonBlockedDomain
+}
+
+// trustedCreator returns the first of the EXIF Artist, IPTC By-line, and
+// XMP/DC Creator fields of meta that names one of Config.TrustedCreators
+// (case-insensitive word-boundary match), or "" if none does.
+func (cfg *Config) trustedCreator(meta *ImageMetadata) string {
+	if meta == nil || len(cfg.TrustedCreators) == 0 {
+		return ""
+	}
+	for _, f := range []string{meta.EXIFArtist, meta.IPTCByline, meta.DCCreator} {
 		if f == "" {
 			continue
 		}
 		lower := strings.ToLower(f)
-		for _, kw := range stockMetadataKeywords {
-			if containsWord(lower, kw) {
+		for _, name := range cfg.TrustedCreators {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" && containsWord(lower, name) {
 				return f
 			}
 		}
@@ -1,6 +1,9 @@
 package imagefy
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -236,3 +239,144 @@ func TestAssessLicense_SignalsPopulated(t *testing.T) {
 		}
 	}
 }
+
+// newAuditServer serves a JPEG at /photo.jpg (with stock metadata when stock
+// is set) and an HTML page at /page that links a CC license when cc is set.
+func newAuditServer(t *testing.T, stock, cc bool) *httptest.Server {
+	t.Helper()
+	img := makeJPEG(1000, 600)
+	if stock {
+		img = withXMPRights(img, "Getty Images")
+	}
+	page := "<html><body>no license</body></html>"
+	if cc {
+		page = `<html><body><a rel="license" href="https://creativecommons.org/licenses/by/4.0/">CC BY</a></body></html>`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page" {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(page))
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(img)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAssessLicenseURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		stock, cc   bool
+		withSource  bool
+		wantLicense ImageLicense
+		wantSignal  string
+	}{
+		{name: "no signals", withSource: true, wantLicense: LicenseUnknown},
+		{name: "cc on source page", cc: true, withSource: true, wantLicense: LicenseSafe, wantSignal: "page_cc"},
+		{name: "source page not fetched without sourceURL", cc: true, wantLicense: LicenseUnknown},
+		{name: "stock metadata beats page cc", stock: true, cc: true, withSource: true, wantLicense: LicenseBlocked, wantSignal: "metadata_stock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := newAuditServer(t, tt.stock, tt.cc)
+			sourceURL := ""
+			if tt.withSource {
+				sourceURL = srv.URL + "/page"
+			}
+			cfg := &Config{HTTPClient: srv.Client()}
+
+			got := cfg.AssessLicenseURL(context.Background(), srv.URL+"/photo.jpg", sourceURL)
+			if got.License != tt.wantLicense {
+				t.Errorf("License = %v, want %v (signals %+v)", got.License, tt.wantLicense, got.Signals)
+			}
+			if tt.wantSignal != "" && !hasSignal(got.Signals, tt.wantSignal) {
+				t.Errorf("signals %+v missing %q", got.Signals, tt.wantSignal)
+			}
+		})
+	}
+}
+
+func TestAssessLicenseURL_BlockedDomainWithoutFetch(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{HTTPClient: &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, context.DeadlineExceeded
+	})}}
+	got := cfg.AssessLicenseURL(context.Background(), "https://www.shutterstock.com/x.jpg", "https://www.shutterstock.com/photo/1")
+	if got.License != LicenseBlocked || !hasSignal(got.Signals, "domain") {
+		t.Errorf("AssessLicenseURL = %+v, want blocked by domain", got)
+	}
+}
+
+func TestAssessLicenseURL_SourcePageViaStealthHost(t *testing.T) {
+	t.Parallel()
+
+	srv := newAuditServer(t, false, true)
+	cfg := &Config{
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			return nil, context.DeadlineExceeded
+		})},
+		StealthClient: srv.Client(),
+		StealthHosts:  []string{"127.0.0.1"},
+	}
+	got := cfg.AssessLicenseURL(context.Background(), srv.URL+"/photo.jpg", srv.URL+"/page")
+	if got.License != LicenseSafe || !hasSignal(got.Signals, "page_cc") {
+		t.Errorf("AssessLicenseURL = %+v, want safe by page_cc through StealthClient", got)
+	}
+}
+
+func hasSignal(signals []LicenseSignal, source string) bool {
+	for _, s := range signals {
+		if s.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAssessLicense_TrustedCreator(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{TrustedCreators: []string{"Jane Doe", "Reuters"}, ExtraBlockedDomains: []string{"agency.example"}}
+	blocked := ImageCandidate{ImgURL: "https://agency.example/photo.jpg", Source: "https://agency.example/story", License: LicenseBlocked}
+
+	tests := []struct {
+		name        string
+		licenseName string
+		meta        *ImageMetadata
+		wantLicense ImageLicense
+		wantTrusted bool
+	}{
+		{"artist on blocked domain", "", &ImageMetadata{EXIFArtist: "JANE DOE"}, LicenseSafe, true},
+		{"xmp creator", "", &ImageMetadata{DCCreator: "Photo: Jane Doe / Staff"}, LicenseSafe, true},
+		{"agency byline with stock credit", "", &ImageMetadata{IPTCByline: "Reuters", IPTCCredit: "Getty Images"}, LicenseBlocked, true},
+		{"blocked provider license", "Non-free content", &ImageMetadata{EXIFArtist: "Jane Doe"}, LicenseBlocked, true},
+		{"partial word", "", &ImageMetadata{EXIFArtist: "Jane Doell"}, LicenseBlocked, false},
+		{"credit only", "", &ImageMetadata{IPTCCredit: "Jane Doe"}, LicenseBlocked, false},
+		{"no metadata", "", nil, LicenseBlocked, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cand := blocked
+			cand.LicenseName = tc.licenseName
+			got := cfg.AssessLicense(cand, tc.meta)
+			if got.License != tc.wantLicense {
+				t.Errorf("AssessLicense().License = %v, want %v (signals %+v)", got.License, tc.wantLicense, got.Signals)
+			}
+			if trusted := got.Signals[len(got.Signals)-1].Source == "trusted_creator"; trusted != tc.wantTrusted {
+				t.Errorf("last signal = %+v", got.Signals[len(got.Signals)-1])
+			}
+		})
+	}
+
+	if cfg.isBlockedByExtraDomains(blocked) {
+		t.Error("extra domain pre-check ran with TrustedCreators set")
+	}
+}
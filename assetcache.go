@@ -0,0 +1,81 @@
+package imagefy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+
+	"github.com/corona10/goimagehash"
+)
+
+// assetCachePrefix namespaces asset entries in Config.Cache. Bump the
+// version when ImageMetadata or the hash algorithm changes.
+const assetCachePrefix = "imagefy_asset_v1"
+
+// assetInfo is what the pipeline derives from an image's bytes alone:
+// parsed metadata and the perceptual hash. It is cached under the SHA-256
+// of the bytes, so the same asset met again — under another URL or in a
+// later search — skips metadata decoding (large XMP packets are costly)
+// and hashing.
+type assetInfo struct {
+	Meta  *ImageMetadata `json:"meta,omitempty"`
+	DHash string         `json:"dhash,omitempty"` // goimagehash string form; "" if not hashed
+}
+
+// inspectAsset returns the metadata and dHash of data, using Config.Cache
+// when set. img is the decoded data; when nil, no hash is computed and
+// the returned hash is nil. Hashing failures degrade to a nil hash.
+func (cfg *Config) inspectAsset(ctx context.Context, data []byte, img image.Image) (*ImageMetadata, *goimagehash.ImageHash) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if cfg.Cache == nil {
+		return ExtractImageMetadata(data), hashImage(img)
+	}
+
+	sum := sha256.Sum256(data)
+	key := cfg.Cache.Key(assetCachePrefix, hex.EncodeToString(sum[:]))
+	var info assetInfo
+	if cfg.Cache.Get(ctx, key, &info) {
+		if img == nil {
+			return info.Meta, nil
+		}
+		if hash, err := parsePlaceholderHash(info.DHash); err == nil {
+			return info.Meta, hash
+		}
+		// Cached by a caller without a decoded image: add the hash.
+		hash := hashImage(img)
+		if hash != nil {
+			info.DHash = hash.ToString()
+			cfg.Cache.Set(ctx, key, info)
+		}
+		return info.Meta, hash
+	}
+
+	info.Meta = ExtractImageMetadata(data)
+	hash := hashImage(img)
+	if hash != nil {
+		info.DHash = hash.ToString()
+	}
+	cfg.Cache.Set(ctx, key, info)
+	return info.Meta, hash
+}
+
+// imageMetadata is ExtractImageMetadata through the asset cache.
+func (cfg *Config) imageMetadata(ctx context.Context, data []byte) *ImageMetadata {
+	meta, _ := cfg.inspectAsset(ctx, data, nil)
+	return meta
+}
+
+// hashImage returns the dHash of img, or nil if img is nil or cannot be hashed.
+func hashImage(img image.Image) *goimagehash.ImageHash {
+	if img == nil {
+		return nil
+	}
+	hash, err := goimagehash.DifferenceHash(img)
+	if err != nil {
+		return nil
+	}
+	return hash
+}
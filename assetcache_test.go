@@ -0,0 +1,119 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"sync"
+	"testing"
+)
+
+// jsonCache is a Cache that round-trips values through JSON, like a
+// Redis-backed cache would.
+type jsonCache struct {
+	mu    sync.Mutex
+	store map[string][]byte
+	sets  int
+}
+
+func (c *jsonCache) Key(prefix, value string) string { return prefix + ":" + value }
+
+func (c *jsonCache) Get(_ context.Context, key string, dest any) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.store[key]
+	return ok && json.Unmarshal(b, dest) == nil
+}
+
+func (c *jsonCache) Set(_ context.Context, key string, value any) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		c.store = make(map[string][]byte)
+	}
+	c.store[key] = b
+	c.sets++
+}
+
+func assetCacheKey(data []byte) string {
+	sum := sha256.Sum256(data)
+	return assetCachePrefix + ":" + hex.EncodeToString(sum[:])
+}
+
+func TestInspectAsset_CachesByContent(t *testing.T) {
+	t.Parallel()
+
+	data := withXMPRights(makeJPEG(1000, 600), "© Example Stock")
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &jsonCache{}
+	cfg := &Config{Cache: cache}
+
+	meta, hash := cfg.inspectAsset(context.Background(), data, img)
+	if meta == nil || meta.DCRights != "© Example Stock" {
+		t.Fatalf("meta = %+v, want the embedded dc:rights", meta)
+	}
+	if hash == nil {
+		t.Fatal("hash = nil, want the image's dHash")
+	}
+
+	// Replace the stored metadata: a second lookup of the same bytes must
+	// come from the cache rather than reparse them.
+	cache.Set(context.Background(), assetCacheKey(data), assetInfo{Meta: &ImageMetadata{DCRights: "cached"}, DHash: hash.ToString()})
+	meta2, hash2 := cfg.inspectAsset(context.Background(), data, img)
+	if meta2 == nil || meta2.DCRights != "cached" {
+		t.Errorf("second lookup meta = %+v, want the cached entry", meta2)
+	}
+	if hash2 == nil || hash2.ToString() != hash.ToString() {
+		t.Errorf("second lookup hash = %v, want %v", hash2, hash)
+	}
+	if cache.sets != 2 {
+		t.Errorf("cache sets = %d, want 2 (no write on a full hit)", cache.sets)
+	}
+}
+
+func TestInspectAsset_AddsHashToMetadataOnlyEntry(t *testing.T) {
+	t.Parallel()
+
+	data := makeJPEG(1000, 600)
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &jsonCache{}
+	cfg := &Config{Cache: cache}
+
+	if meta := cfg.imageMetadata(context.Background(), data); meta != nil {
+		t.Fatalf("meta = %+v, want nil for a JPEG without metadata", meta)
+	}
+	if _, hash := cfg.inspectAsset(context.Background(), data, img); hash == nil {
+		t.Fatal("hash = nil after a metadata-only entry")
+	}
+	var info assetInfo
+	if !cache.Get(context.Background(), assetCacheKey(data), &info) || info.DHash == "" {
+		t.Errorf("cached entry = %+v, want the hash added", info)
+	}
+}
+
+func TestInspectAsset_NoCache(t *testing.T) {
+	t.Parallel()
+
+	var cfg *Config
+	cfg = cfg.orZero()
+	if meta, hash := cfg.inspectAsset(context.Background(), nil, nil); meta != nil || hash != nil {
+		t.Errorf("inspectAsset(nil) = %v, %v; want nil, nil", meta, hash)
+	}
+	data := withXMPRights(makeJPEG(1000, 600), "CC0")
+	if meta := cfg.imageMetadata(context.Background(), data); meta == nil || meta.DCRights != "CC0" {
+		t.Errorf("meta = %+v, want dc:rights without a cache", meta)
+	}
+}
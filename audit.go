@@ -0,0 +1,229 @@
+package imagefy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Audit record types.
+const (
+	AuditClassification = "classification" // a ClassificationEvent, including license assessments
+	AuditAccepted       = "accepted"       // an accepted CandidateEvent
+	AuditRejected       = "rejected"       // a rejected CandidateEvent
+)
+
+// AuditRecord is one JSON line written by AuditLog.
+type AuditRecord struct {
+	Time       time.Time       `json:"time"`
+	Type       string          `json:"type"` // AuditClassification, AuditAccepted, or AuditRejected
+	URL        string          `json:"url"`
+	Source     string          `json:"source,omitempty"` // page URL for candidates; decision source for classifications
+	Stage      Stage           `json:"stage,omitempty"`
+	Reason     RejectReason    `json:"reason,omitempty"`
+	Class      string          `json:"class,omitempty"`
+	Confidence float64         `json:"confidence,omitempty"`
+	License    ImageLicense    `json:"license,omitempty"`
+	Provider   string          `json:"provider,omitempty"`
+	DurationMS int64           `json:"duration_ms,omitempty"`
+	Degraded   []Stage         `json:"degraded,omitempty"`
+	Variant    string          `json:"variant,omitempty"`
+	Rationale  string          `json:"rationale,omitempty"` // the model's stated reason, for classifications
+	Raw        json.RawMessage `json:"raw,omitempty"`       // the provider's result JSON, for candidates searched with SearchOpts.KeepRaw
+}
+
+// AuditFileOpts configures OpenAuditLog.
+type AuditFileOpts struct {
+	// MaxBytes rotates the file once it reaches this size (0 = never): it is
+	// renamed to "<path>.<UTC timestamp>" and a new file is started.
+	MaxBytes int64
+
+	// OnRotate is called with the rotated file's path after each rotation,
+	// e.g. to compress or upload it for retention. It runs synchronously,
+	// with further records blocked until it returns.
+	OnRotate func(rotatedPath string)
+}
+
+// AuditLog appends JSON Lines audit records of every classification,
+// license assessment, acceptance, and rejection. Wire it into a Config with
+// Attach. It is safe for concurrent use.
+//
+// A write error is logged once and reported by Err; later records are
+// dropped rather than failing the search.
+type AuditLog struct {
+	mu   sync.Mutex
+	w    io.Writer
+	err  error
+	now  func() time.Time
+	file *os.File // nil for NewAuditLog
+	path string
+	size int64
+	opts AuditFileOpts
+}
+
+// NewAuditLog returns an AuditLog writing to w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w, now: time.Now}
+}
+
+// OpenAuditLog returns an AuditLog appending to the file at path, creating
+// it if needed.
+func OpenAuditLog(path string, opts AuditFileOpts) (*AuditLog, error) {
+	a := &AuditLog{now: time.Now, path: path, opts: opts}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Attach makes cfg write its OnClassification, OnCandidateAccepted, and
+// OnCandidateRejected events to the log. Callbacks already set keep firing
+// after the record is written.
+func (a *AuditLog) Attach(cfg *Config) {
+	onClass, onAccepted, onRejected := cfg.OnClassification, cfg.OnCandidateAccepted, cfg.OnCandidateRejected
+	cfg.OnClassification = func(e ClassificationEvent) {
+		a.LogClassification(e)
+		if onClass != nil {
+			onClass(e)
+		}
+	}
+	cfg.OnCandidateAccepted = func(e CandidateEvent) {
+		a.LogCandidate(e)
+		if onAccepted != nil {
+			onAccepted(e)
+		}
+	}
+	cfg.OnCandidateRejected = func(e CandidateEvent) {
+		a.LogCandidate(e)
+		if onRejected != nil {
+			onRejected(e)
+		}
+	}
+}
+
+// LogClassification writes e; it can be used directly as
+// Config.OnClassification.
+func (a *AuditLog) LogClassification(e ClassificationEvent) {
+	a.write(AuditRecord{
+		Type:       AuditClassification,
+		URL:        e.URL,
+		Source:     e.Source,
+		Reason:     e.Reason,
+		Class:      e.Class,
+		Confidence: e.Confidence,
+		Variant:    e.Variant,
+		Rationale:  e.Rationale,
+	})
+}
+
+// LogCandidate writes e as an accepted or rejected record; it can be used
+// directly as Config.OnCandidateAccepted and OnCandidateRejected.
+func (a *AuditLog) LogCandidate(e CandidateEvent) {
+	typ := AuditAccepted
+	if e.Reason != "" {
+		typ = AuditRejected
+	}
+	a.write(AuditRecord{
+		Type:       typ,
+		URL:        e.Candidate.ImgURL,
+		Source:     e.Candidate.Source,
+		Stage:      e.Stage,
+		Reason:     e.Reason,
+		License:    e.Candidate.License,
+		Provider:   e.Candidate.Provider,
+		DurationMS: e.Duration.Milliseconds(),
+		Degraded:   e.Degraded,
+		Variant:    e.Variant,
+		Raw:        e.Raw,
+	})
+}
+
+// Err returns the first write or rotation error, if any.
+func (a *AuditLog) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// Close closes the file opened by OpenAuditLog; it is a no-op for
+// NewAuditLog.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file, a.w = nil, nil
+	return err
+}
+
+func (a *AuditLog) write(rec AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil || a.w == nil {
+		return
+	}
+	rec.Time = a.now().UTC()
+	line, err := json.Marshal(rec)
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	line = append(line, '\n')
+	if a.file != nil && a.opts.MaxBytes > 0 && a.size > 0 && a.size+int64(len(line)) > a.opts.MaxBytes {
+		if err := a.rotate(); err != nil {
+			a.fail(err)
+			return
+		}
+	}
+	n, err := a.w.Write(line)
+	a.size += int64(n)
+	if err != nil {
+		a.fail(err)
+	}
+}
+
+// fail records the first error. Callers hold a.mu.
+func (a *AuditLog) fail(err error) {
+	a.err = fmt.Errorf("imagefy: audit log: %w", err)
+	slog.Warn("imagefy: audit log write failed, dropping further records", "error", err)
+}
+
+// open opens a.path for appending. Callers hold a.mu or own a.
+func (a *AuditLog) open() error {
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) //nolint:mnd,gosec // rw-r--r--, path is caller-supplied
+	if err != nil {
+		return fmt.Errorf("imagefy: open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("imagefy: open audit log: %w", err)
+	}
+	a.file, a.w, a.size = f, f, info.Size()
+	return nil
+}
+
+// rotate renames the current file aside and starts a new one. Callers hold
+// a.mu.
+func (a *AuditLog) rotate() error {
+	if err := a.file.Close(); err != nil {
+		return err
+	}
+	rotated := a.path + "." + a.now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(a.path, rotated); err != nil {
+		return err
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+	if a.opts.OnRotate != nil {
+		a.opts.OnRotate(rotated)
+	}
+	return nil
+}
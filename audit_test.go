@@ -0,0 +1,145 @@
+package imagefy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func decodeAudit(t *testing.T, data []byte) []AuditRecord {
+	t.Helper()
+	var recs []AuditRecord
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		var r AuditRecord
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("decode %q: %v", sc.Text(), err)
+		}
+		recs = append(recs, r)
+	}
+	return recs
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func TestAuditLog_Attach(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultiImageServer(t, map[string][]byte{"/a.jpg": makeJPEG(1000, 600)})
+	var buf syncBuffer
+	accepted := make(chan CandidateEvent, 1)
+	cfg := &Config{
+		HTTPClient:          srv.Client(),
+		Classifier:          &mockClassifier{response: "PHOTO 0.9"},
+		OnCandidateAccepted: func(e CandidateEvent) { accepted <- e },
+		Providers: []SearchProvider{&mockProvider{name: "p", candidates: []ImageCandidate{
+			{ImgURL: srv.URL + "/a.jpg", Source: srv.URL + "/a", License: LicenseUnknown},
+			{ImgURL: srv.URL + "/missing.jpg", Source: srv.URL + "/b", License: LicenseUnknown},
+		}}},
+	}
+	NewAuditLog(&buf).Attach(cfg)
+
+	if got := cfg.SearchImages(context.Background(), "kremlin", 5); len(got) != 1 {
+		t.Fatalf("results = %d, want 1", len(got))
+	}
+	select {
+	case <-accepted:
+	default:
+		t.Error("existing OnCandidateAccepted not chained")
+	}
+
+	types := map[string]int{}
+	for _, r := range decodeAudit(t, buf.b.Bytes()) {
+		types[r.Type]++
+		if r.Time.IsZero() || r.URL == "" {
+			t.Errorf("incomplete record %+v", r)
+		}
+		if r.Type == AuditRejected && r.Reason == "" {
+			t.Errorf("rejection without reason: %+v", r)
+		}
+		if r.Type == AuditClassification && r.Class != ClassPhoto {
+			t.Errorf("classification = %+v", r)
+		}
+	}
+	if types[AuditAccepted] != 1 || types[AuditRejected] != 1 || types[AuditClassification] == 0 {
+		t.Errorf("record types = %v", types)
+	}
+}
+
+func TestAuditLog_Rotation(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	var rotated []string
+	a, err := OpenAuditLog(path, AuditFileOpts{MaxBytes: 300, OnRotate: func(p string) { rotated = append(rotated, p) }})
+	if err != nil {
+		t.Fatalf("OpenAuditLog: %v", err)
+	}
+	tick := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	a.now = func() time.Time { tick = tick.Add(time.Second); return tick }
+
+	for i := 0; i < 6; i++ {
+		a.LogClassification(ClassificationEvent{URL: "https://example.com/" + strings.Repeat("x", 60), Class: ClassPhoto, Source: "llm"})
+	}
+	if err := a.Close(); err != nil || a.Err() != nil {
+		t.Fatalf("Close = %v, Err = %v", err, a.Err())
+	}
+
+	if len(rotated) == 0 {
+		t.Fatal("no rotation at MaxBytes")
+	}
+	total := 0
+	for _, p := range append(rotated, path) {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("read %s: %v", p, err)
+		}
+		if len(data) > 300 {
+			t.Errorf("%s is %d bytes, over MaxBytes", p, len(data))
+		}
+		total += len(decodeAudit(t, data))
+	}
+	if total != 6 {
+		t.Errorf("records across files = %d, want 6", total)
+	}
+}
+
+type failingWriter struct{ calls int }
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	w.calls++
+	return 0, errors.New("disk full")
+}
+
+func TestAuditLog_WriteError(t *testing.T) {
+	t.Parallel()
+
+	w := &failingWriter{}
+	a := NewAuditLog(w)
+	a.LogCandidate(CandidateEvent{Candidate: ImageCandidate{ImgURL: "u"}})
+	a.LogCandidate(CandidateEvent{Candidate: ImageCandidate{ImgURL: "u"}})
+	if a.Err() == nil || w.calls != 1 {
+		t.Errorf("Err = %v, writes = %d; want an error and no writes after it", a.Err(), w.calls)
+	}
+	if err := a.Close(); err != nil {
+		t.Errorf("Close on writer log = %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package imagefy
+
+import (
+	"errors"
+	"maps"
+	"sync"
+)
+
+// errByteBudget fails image requests once SearchOpts.MaxTotalBytes is spent.
+var errByteBudget = errors.New("imagefy: search byte budget exhausted")
+
+// ByteStats is the image traffic of one search: bytes read from probe and
+// download response bodies, in total and per image host.
+type ByteStats struct {
+	Total     int64
+	PerHost   map[string]int64
+	Exhausted bool // SearchOpts.MaxTotalBytes was reached
+}
+
+// byteMeter counts the image bytes of one search and enforces its budget.
+// A nil *byteMeter counts nothing.
+type byteMeter struct {
+	max int64 // 0 = unlimited
+
+	mu      sync.Mutex
+	total   int64
+	perHost map[string]int64
+}
+
+// withByteMeter returns a derived copy of cfg that counts the image bytes of
+// one search, failing requests beyond maxBytes (0 = unlimited).
+func (cfg *Config) withByteMeter(maxBytes int64) *Config {
+	c := cfg.derive()
+	c.meter = &byteMeter{max: max(maxBytes, 0)}
+	return c
+}
+
+// reportBytes passes the search's ByteStats to Config.OnSearchBytes.
+func (cfg *Config) reportBytes() {
+	if cfg.OnSearchBytes != nil && cfg.meter != nil {
+		cfg.OnSearchBytes(cfg.meter.stats())
+	}
+}
+
+// add records n bytes from host and reports whether the budget is now
+// exceeded.
+func (m *byteMeter) add(host string, n int) bool {
+	if m == nil || n <= 0 {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.perHost == nil {
+		m.perHost = make(map[string]int64)
+	}
+	m.total += int64(n)
+	m.perHost[host] += int64(n)
+	return m.max > 0 && m.total > m.max
+}
+
+// spent reports whether the budget is used up.
+func (m *byteMeter) spent() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.max > 0 && m.total >= m.max
+}
+
+func (m *byteMeter) stats() ByteStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return ByteStats{
+		Total:     m.total,
+		PerHost:   maps.Clone(m.perHost),
+		Exhausted: m.max > 0 && m.total >= m.max,
+	}
+}
@@ -0,0 +1,94 @@
+package imagefy
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestSearchImages_ByteAccounting(t *testing.T) {
+	t.Parallel()
+
+	imgs := map[string][]byte{
+		"/a.jpg": encodeJPEG(t, makeGradientImage(1000, 600, 0)),
+		"/b.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 25)),
+		"/c.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 90)),
+	}
+	srv := newMultiImageServer(t, imgs)
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/a.jpg", Source: srv.URL + "/a", License: LicenseUnknown},
+		{ImgURL: srv.URL + "/b.jpg", Source: srv.URL + "/b", License: LicenseUnknown},
+		{ImgURL: srv.URL + "/c.jpg", Source: srv.URL + "/c", License: LicenseUnknown},
+	}
+
+	search := func(maxBytes int64) ([]ImageCandidate, ByteStats, []CandidateEvent) {
+		var (
+			mu       sync.Mutex
+			stats    ByteStats
+			rejected []CandidateEvent
+		)
+		cfg := &Config{
+			HTTPClient:          srv.Client(),
+			Providers:           []SearchProvider{&mockProvider{name: "p", candidates: cands}},
+			OnSearchBytes:       func(s ByteStats) { stats = s },
+			OnCandidateRejected: func(e CandidateEvent) { mu.Lock(); rejected = append(rejected, e); mu.Unlock() },
+		}
+		got := cfg.SearchImagesWithOpts(context.Background(), "q", 5, SearchOpts{MaxTotalBytes: maxBytes})
+		return got, stats, rejected
+	}
+
+	got, stats, _ := search(0)
+	if len(got) != 3 {
+		t.Fatalf("unlimited search accepted %d, want 3", len(got))
+	}
+	var sizes int64
+	for _, b := range imgs {
+		sizes += int64(len(b))
+	}
+	if stats.Total < sizes || stats.Exhausted {
+		t.Errorf("stats = %+v, want at least the %d bytes of the three downloads", stats, sizes)
+	}
+	if stats.PerHost["127.0.0.1"] != stats.Total {
+		t.Errorf("per-host = %v, want all bytes on 127.0.0.1 (total %d)", stats.PerHost, stats.Total)
+	}
+
+	got, stats, rejected := search(int64(len(imgs["/a.jpg"])))
+	if len(got) == 3 || !stats.Exhausted {
+		t.Fatalf("budgeted search accepted %d and stats = %+v, want the budget to run out", len(got), stats)
+	}
+	for _, c := range got {
+		if c.Degraded != "" {
+			t.Errorf("accepted %s degraded at %s after the budget ran out", c.ImgURL, c.Degraded)
+		}
+	}
+	budget := 0
+	for _, e := range rejected {
+		if e.Reason == ReasonByteBudget {
+			budget++
+		}
+	}
+	if budget == 0 {
+		t.Errorf("rejections = %+v, want byte_budget", rejected)
+	}
+}
+
+func TestByteMeter(t *testing.T) {
+	t.Parallel()
+
+	var nilMeter *byteMeter
+	if nilMeter.add("h", 10) || nilMeter.spent() {
+		t.Error("nil meter counted or enforced")
+	}
+
+	m := &byteMeter{max: 10}
+	if m.add("a", 6) || m.spent() {
+		t.Error("budget enforced below max")
+	}
+	if !m.add("b", 5) || !m.spent() {
+		t.Error("budget not enforced above max")
+	}
+	s := m.stats()
+	if s.Total != 11 || s.PerHost["a"] != 6 || s.PerHost["b"] != 5 || !s.Exhausted {
+		t.Errorf("stats = %+v", s)
+	}
+}
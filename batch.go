@@ -0,0 +1,71 @@
+package imagefy
+
+import (
+	"context"
+	"sync"
+)
+
+// batchConcurrency is the number of queries SearchImagesBatch runs at once.
+// Each query additionally validates up to validationSemaphore candidates in parallel.
+const batchConcurrency = 4
+
+// QuerySpec describes one query of a batch search.
+type QuerySpec struct {
+	Query      string
+	MaxResults int        // default: 3
+	Opts       SearchOpts // per-query search options
+}
+
+// SearchImagesBatch runs many queries with shared state: one HTTP client, one
+// dedup store and used-image history (an image is returned for at most one
+// query), and the provider-health tracking of a Session. Results are keyed by
+// QuerySpec.Query; duplicate queries are searched once, with the first spec's
+// settings, and queries with no results map to nil.
+//
+// To also share a vision budget or per-host rate limiting, create a Session
+// with the desired SessionOpts and call Session.SearchImagesBatch.
+func (cfg *Config) SearchImagesBatch(ctx context.Context, queries []QuerySpec) map[string][]ImageCandidate {
+	return cfg.NewSession(SessionOpts{}).SearchImagesBatch(ctx, queries)
+}
+
+// SearchImagesBatch is like Config.SearchImagesBatch, sharing the session's state.
+func (s *Session) SearchImagesBatch(ctx context.Context, queries []QuerySpec) map[string][]ImageCandidate {
+	out := make(map[string][]ImageCandidate, len(queries))
+	unique := make([]QuerySpec, 0, len(queries))
+	for _, q := range queries {
+		if _, seen := out[q.Query]; seen || q.Query == "" {
+			continue
+		}
+		out[q.Query] = nil
+		unique = append(unique, q)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, batchConcurrency)
+
+	for _, q := range unique {
+		maxResults := q.MaxResults
+		if maxResults <= 0 {
+			maxResults = findDefaultMaxResults
+		}
+
+		wg.Add(1)
+		go func(q QuerySpec, maxResults int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			results := s.SearchImagesWithOpts(ctx, q.Query, maxResults, q.Opts)
+			mu.Lock()
+			out[q.Query] = results
+			mu.Unlock()
+		}(q, maxResults)
+	}
+	wg.Wait()
+	return out
+}
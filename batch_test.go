@@ -0,0 +1,134 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// queryFuncProvider returns candidates computed from the query.
+type queryFuncProvider func(query string) []ImageCandidate
+
+func (queryFuncProvider) Name() string { return "queryfunc" }
+
+func (f queryFuncProvider) Search(_ context.Context, query string, _ SearchOpts) ([]ImageCandidate, error) {
+	return f(query), nil
+}
+
+func TestSearchImagesBatch(t *testing.T) {
+	t.Parallel()
+
+	srv := newJPEGServer(t)
+	cfg := &Config{
+		HTTPClient: srv.Client(),
+		Providers: []SearchProvider{queryFuncProvider(func(q string) []ImageCandidate {
+			if q == "empty" {
+				return nil
+			}
+			return []ImageCandidate{
+				{ImgURL: srv.URL + "/" + q + ".jpg", Source: srv.URL + "/page", License: LicenseUnknown},
+				{ImgURL: srv.URL + "/shared.jpg", Source: srv.URL + "/page", License: LicenseUnknown},
+			}
+		})},
+	}
+
+	got := cfg.SearchImagesBatch(context.Background(), []QuerySpec{
+		{Query: "a", MaxResults: 2},
+		{Query: "b", MaxResults: 2},
+		{Query: "a", MaxResults: 1}, // duplicate: ignored
+		{Query: "empty"},
+		{Query: ""},
+	})
+
+	if len(got) != 3 {
+		t.Fatalf("got %d keys, want 3 (a, b, empty): %v", len(got), got)
+	}
+	if res, ok := got["empty"]; !ok || res != nil {
+		t.Errorf(`got["empty"] = %v, %v; want nil, true`, res, ok)
+	}
+
+	shared := 0
+	for _, q := range []string{"a", "b"} {
+		own := false
+		for _, c := range got[q] {
+			switch c.ImgURL {
+			case srv.URL + "/shared.jpg":
+				shared++
+			case srv.URL + "/" + q + ".jpg":
+				own = true
+			}
+		}
+		if !own {
+			t.Errorf("query %q results %v lack its own image", q, got[q])
+		}
+	}
+	if shared != 1 {
+		t.Errorf("shared image returned %d times across the batch, want 1", shared)
+	}
+}
+
+func TestSessionSearchImagesBatch_VisionBudget(t *testing.T) {
+	t.Parallel()
+
+	bodies := map[string][]byte{
+		"/a.jpg": encodeJPEG(t, makeGradientImage(1000, 600, 0)),
+		"/b.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 100)),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(bodies[r.URL.Path])
+	}))
+	t.Cleanup(srv.Close)
+
+	// Every classified image is rejected, so an accepted image proves the
+	// budget ran out and classification was skipped.
+	cls := &lockedClassifier{response: "STOCK"}
+	cfg := &Config{
+		HTTPClient: srv.Client(),
+		Classifier: cls,
+		Providers: []SearchProvider{queryFuncProvider(func(q string) []ImageCandidate {
+			return []ImageCandidate{{ImgURL: srv.URL + "/" + q + ".jpg", Source: "https://" + q + ".example/page", License: LicenseUnknown}}
+		})},
+	}
+	s := cfg.NewSession(SessionOpts{VisionBudget: 1})
+	if left := s.VisionBudgetLeft(); left != 1 {
+		t.Fatalf("VisionBudgetLeft() = %d, want 1", left)
+	}
+
+	got := s.SearchImagesBatch(context.Background(), []QuerySpec{{Query: "a"}, {Query: "b"}})
+	if total := len(got["a"]) + len(got["b"]); total != 1 {
+		t.Errorf("accepted %d images, want 1 (one classified STOCK, one unclassified): %v", total, got)
+	}
+	if n := cls.count(); n != 1 {
+		t.Errorf("classifier calls = %d, want 1", n)
+	}
+	if left := s.VisionBudgetLeft(); left != 0 {
+		t.Errorf("VisionBudgetLeft() = %d, want 0", left)
+	}
+	if left := cfg.NewSession(SessionOpts{}).VisionBudgetLeft(); left != -1 {
+		t.Errorf("unlimited VisionBudgetLeft() = %d, want -1", left)
+	}
+}
+
+// lockedClassifier is a concurrency-safe classifier returning a fixed response.
+type lockedClassifier struct {
+	response string
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *lockedClassifier) Classify(_ context.Context, _ string, _ []ImageInput) (string, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	return c.response, nil
+}
+
+func (c *lockedClassifier) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
@@ -0,0 +1,215 @@
+package imagefy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	bingDefaultURL = "https://api.bing.microsoft.com/v7.0/images/search"
+	bingBodyLimit  = 2 * 1024 * 1024
+	bingPerPage    = 50
+)
+
+// BingLicense is a Bing Image Search license filter: the usage rights
+// Bing reports the returned images to carry.
+type BingLicense string
+
+// Bing license filters. Bing infers usage rights from the page an image is
+// on and does not verify them, so no filter makes candidates LicenseSafe.
+const (
+	BingLicenseAll                BingLicense = "All"                // no filter (the default)
+	BingLicenseAny                BingLicense = "Any"                // any Creative Commons or public domain license
+	BingLicensePublic             BingLicense = "Public"             // public domain
+	BingLicenseShare              BingLicense = "Share"              // free to share, non-commercially
+	BingLicenseShareCommercially  BingLicense = "ShareCommercially"  // free to share, commercially too
+	BingLicenseModify             BingLicense = "Modify"             // free to modify and share, non-commercially
+	BingLicenseModifyCommercially BingLicense = "ModifyCommercially" // free to modify and share, commercially too
+)
+
+// licenseName returns the ImageCandidate.LicenseName of images returned
+// under filter l, "" without a filter.
+func (l BingLicense) licenseName() string {
+	if l == "" || l == BingLicenseAll {
+		return ""
+	}
+	return "Bing license filter " + string(l)
+}
+
+// bingImage is one result of a Bing Image Search v7 response.
+type bingImage struct {
+	Name         string          `json:"name"`
+	ContentURL   string          `json:"contentUrl"`
+	ThumbnailURL string          `json:"thumbnailUrl"`
+	HostPageURL  string          `json:"hostPageUrl"`
+	Width        int             `json:"width"`
+	Height       int             `json:"height"`
+	Raw          json.RawMessage `json:"-"` // the result as received, with SearchOpts.KeepRaw
+}
+
+// BingImageProvider searches images through the Azure Bing Image Search v7
+// API, an alternative to SearXNG for consumers with an Azure key. License
+// filters the results by usage rights and is recorded as
+// ImageCandidate.LicenseName for attribution, but candidates stay
+// LicenseUnknown: Bing's rights are a best guess from the host page, not a
+// license grant. Results from blocked domains are dropped and safe domains
+// are LicenseSafe whatever the filter. Engines from SearchOpts are ignored.
+type BingImageProvider struct {
+	APIKey     string       // required: Ocp-Apim-Subscription-Key
+	BaseURL    string       // default: "https://api.bing.microsoft.com/v7.0/images/search"
+	HTTPClient *http.Client // optional (nil = the default imagefy client)
+	UserAgent  string       // optional
+
+	// License is the usage-rights filter (default: BingLicenseAll, none).
+	License BingLicense
+
+	// Market is the market code of the search, e.g. "ru-RU" (default: chosen
+	// by Bing from the request).
+	Market string
+}
+
+// Name returns the provider name.
+func (p *BingImageProvider) Name() string { return "bing" }
+
+// Search queries Bing for photos matching query and returns their
+// candidates in Bing's order.
+func (p *BingImageProvider) Search(ctx context.Context, query string, opts SearchOpts) ([]ImageCandidate, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("bing: no API key configured")
+	}
+	images, err := p.fetch(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	return p.filter(images, blockPolicyFrom(ctx)), nil
+}
+
+func (p *BingImageProvider) fetch(ctx context.Context, query string, opts SearchOpts) ([]bingImage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.buildURL(query, opts), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", p.APIKey)
+	req.Header.Set("Accept", "application/json")
+	if p.UserAgent != "" {
+		req.Header.Set("User-Agent", p.UserAgent)
+	}
+
+	client := p.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	resp, err := client.Do(req) //nolint:gosec // G107: URL is cfg-supplied by design — SSRF is caller's responsibility
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, bingBodyLimit))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if msg := bingErrorMessage(body); msg != "" {
+			return nil, fmt.Errorf("bing: unexpected status %d: %s", resp.StatusCode, msg)
+		}
+		return nil, fmt.Errorf("bing: unexpected status %d", resp.StatusCode)
+	}
+
+	var searchResp struct {
+		Value []bingImage `json:"value"`
+	}
+	if err := json.Unmarshal(body, &searchResp); err != nil {
+		return nil, err
+	}
+	if opts.KeepRaw {
+		if raws := rawElements(body, "value"); len(raws) == len(searchResp.Value) {
+			for i := range searchResp.Value {
+				searchResp.Value[i].Raw = raws[i]
+			}
+		}
+	}
+	return searchResp.Value, nil
+}
+
+// bingErrorMessage returns the first error message of a Bing error
+// response — {"errors": [...]} from the API, {"error": {...}} from the
+// Azure gateway — or "" if body is neither.
+func bingErrorMessage(body []byte) string {
+	type apiError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	var e struct {
+		Errors []apiError `json:"errors"`
+		Error  *apiError  `json:"error"`
+	}
+	if json.Unmarshal(body, &e) != nil {
+		return ""
+	}
+	if e.Error != nil {
+		e.Errors = append(e.Errors, *e.Error)
+	}
+	for _, ae := range e.Errors {
+		if ae.Message != "" {
+			return ae.Message
+		}
+	}
+	return ""
+}
+
+func (p *BingImageProvider) buildURL(query string, opts SearchOpts) string {
+	base := p.BaseURL
+	if base == "" {
+		base = bingDefaultURL
+	}
+	page := opts.PageNumber
+	if page < 1 {
+		page = 1
+	}
+
+	v := url.Values{}
+	v.Set("q", query)
+	v.Set("count", strconv.Itoa(bingPerPage))
+	v.Set("offset", strconv.Itoa((page-1)*bingPerPage))
+	v.Set("imageType", "Photo")
+	if p.License != "" && p.License != BingLicenseAll {
+		v.Set("license", string(p.License))
+	}
+	if p.Market != "" {
+		v.Set("mkt", p.Market)
+	}
+	return base + "?" + v.Encode()
+}
+
+func (p *BingImageProvider) filter(images []bingImage, policy blockPolicy) []ImageCandidate {
+	var candidates []ImageCandidate
+	for _, img := range images {
+		if img.ContentURL == "" || IsLogoOrBanner(strings.ToLower(img.ContentURL)) {
+			continue
+		}
+		license := policy.checkLicense(img.ContentURL, img.HostPageURL)
+		if license == LicenseBlocked {
+			continue
+		}
+		candidates = append(candidates, ImageCandidate{
+			ImgURL:      img.ContentURL,
+			Thumbnail:   img.ThumbnailURL,
+			Source:      img.HostPageURL,
+			Title:       img.Name,
+			License:     license,
+			LicenseName: p.License.licenseName(),
+			Width:       img.Width,
+			Height:      img.Height,
+			raw:         string(img.Raw),
+		})
+	}
+	return candidates
+}
@@ -0,0 +1,137 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const bingFixture = `{"_type":"Images","nextOffset":53,"value":[
+ {"name":"Kul Sharif mosque, Kazan","contentUrl":"https://travel.example/kul-sharif.jpg",
+  "thumbnailUrl":"https://tse1.mm.bing.net/th?id=OIP.1","hostPageUrl":"https://travel.example/kazan","width":1600,"height":1067},
+ {"name":"Kazan Kremlin stock photo","contentUrl":"https://image.shutterstock.com/kremlin.jpg","hostPageUrl":"https://www.shutterstock.com/image-photo/1"},
+ {"name":"Kazan on Wikimedia","contentUrl":"https://upload.wikimedia.org/kazan.jpg","hostPageUrl":"https://commons.wikimedia.org/wiki/File:Kazan.jpg"},
+ {"name":"no content URL","thumbnailUrl":"https://tse1.mm.bing.net/th?id=OIP.4"}
+]}`
+
+type bingRequest struct {
+	query url.Values
+	key   string
+}
+
+func newBingServer(t *testing.T, status int, body string, got *bingRequest) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got != nil {
+			got.query, got.key = r.URL.Query(), r.Header.Get("Ocp-Apim-Subscription-Key")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestBingImageProviderSearch_LicenseMapping(t *testing.T) {
+	t.Parallel()
+
+	srv := newBingServer(t, http.StatusOK, bingFixture, nil)
+	for _, tt := range []struct {
+		filter BingLicense
+		name   string
+	}{
+		{"", ""},
+		{BingLicenseAll, ""},
+		{BingLicenseShare, "Bing license filter Share"},
+		{BingLicensePublic, "Bing license filter Public"},
+		{BingLicenseModifyCommercially, "Bing license filter ModifyCommercially"},
+	} {
+		p := &BingImageProvider{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client(), License: tt.filter}
+		candidates, err := p.Search(context.Background(), "Kazan", SearchOpts{})
+		if err != nil {
+			t.Fatalf("%s: Search returned error: %v", tt.filter, err)
+		}
+		if len(candidates) != 2 {
+			t.Fatalf("%s: got %d candidates, want 2 (stock and content-less results dropped)", tt.filter, len(candidates))
+		}
+		if c := candidates[0]; c.License != LicenseUnknown || c.LicenseName != tt.name {
+			t.Errorf("%s: License = %v / %q, want unknown / %q", tt.filter, c.License, c.LicenseName, tt.name)
+		}
+		if a := (&Config{}).AssessLicense(candidates[0], nil); a.License != LicenseUnknown {
+			t.Errorf("%s: AssessLicense = %v, want the filter not to count as a license", tt.filter, a.License)
+		}
+		if c := candidates[1]; c.License != LicenseSafe {
+			t.Errorf("%s: safe-domain result License = %v, want safe", tt.filter, c.License)
+		}
+	}
+}
+
+func TestBingImageProviderSearch_Fields(t *testing.T) {
+	t.Parallel()
+
+	srv := newBingServer(t, http.StatusOK, bingFixture, nil)
+	p := &BingImageProvider{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client()}
+	candidates, err := p.Search(context.Background(), "Kazan", SearchOpts{KeepRaw: true})
+	if err != nil || len(candidates) == 0 {
+		t.Fatalf("Search = %d candidates, %v", len(candidates), err)
+	}
+	c := candidates[0]
+	if c.ImgURL != "https://travel.example/kul-sharif.jpg" || c.Thumbnail != "https://tse1.mm.bing.net/th?id=OIP.1" ||
+		c.Source != "https://travel.example/kazan" || c.Title != "Kul Sharif mosque, Kazan" || c.Width != 1600 || c.Height != 1067 {
+		t.Errorf("candidate = %+v", c)
+	}
+	if !strings.Contains(c.raw, `"contentUrl"`) {
+		t.Errorf("raw = %q, want the result's JSON with KeepRaw", c.raw)
+	}
+}
+
+func TestBingImageProviderSearch_Request(t *testing.T) {
+	t.Parallel()
+
+	var got bingRequest
+	srv := newBingServer(t, http.StatusOK, `{"value":[]}`, &got)
+	p := &BingImageProvider{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client(), License: BingLicenseShareCommercially, Market: "ru-RU"}
+	if _, err := p.Search(context.Background(), "Казань", SearchOpts{PageNumber: 3}); err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if got.key != "key" {
+		t.Errorf("subscription key header = %q, want key", got.key)
+	}
+	for param, want := range map[string]string{
+		"q": "Казань", "license": "ShareCommercially", "mkt": "ru-RU", "offset": "100", "count": "50", "imageType": "Photo",
+	} {
+		if v := got.query.Get(param); v != want {
+			t.Errorf("%s = %q, want %q", param, v, want)
+		}
+	}
+
+	p.License = BingLicenseAll
+	_, _ = p.Search(context.Background(), "Kazan", SearchOpts{})
+	if got.query.Has("license") {
+		t.Errorf("license = %q with BingLicenseAll, want no filter", got.query.Get("license"))
+	}
+}
+
+func TestBingImageProviderSearch_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (&BingImageProvider{}).Search(context.Background(), "Kazan", SearchOpts{}); err == nil {
+		t.Error("Search without APIKey returned nil error")
+	}
+
+	for name, body := range map[string]string{
+		"api":     `{"_type":"ErrorResponse","errors":[{"code":"InvalidAuthorization","message":"Authorization is required."}]}`,
+		"gateway": `{"error":{"code":"401","message":"Authorization is required."}}`,
+	} {
+		srv := newBingServer(t, http.StatusUnauthorized, body, nil)
+		p := &BingImageProvider{APIKey: "bad", BaseURL: srv.URL, HTTPClient: srv.Client()}
+		_, err := p.Search(context.Background(), "Kazan", SearchOpts{})
+		if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "Authorization is required") {
+			t.Errorf("%s: Search error = %v, want status and message", name, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package imagefy
+
+import (
+	"context"
+	"fmt"
+)
+
+// BlockCategory groups the built-in BlockedDomains and stock metadata
+// keywords by how their images may be licensed, so Config.BlockPolicy can
+// treat them differently.
+type BlockCategory string
+
+// Block categories. Each data/domains.json category maps to one of them:
+// "editorial" and "freemium" to their namesakes, every other category to
+// BlockStock.
+const (
+	// BlockStock is stock photo agencies and marketplaces that enforce
+	// copyright and send invoices.
+	BlockStock BlockCategory = "stock"
+	// BlockEditorial is editorial-only agencies and wires (e.g. WireImage),
+	// licensed for news use under a subscription.
+	BlockEditorial BlockCategory = "editorial"
+	// BlockFreemium is freemium asset sites (Freepik, Canva) whose assets
+	// are free to use only under the site's plan or with attribution.
+	BlockFreemium BlockCategory = "freemium"
+)
+
+// blockCategoryOf maps a data/domains.json category to its BlockCategory.
+func blockCategoryOf(category string) BlockCategory {
+	switch category {
+	case "editorial":
+		return BlockEditorial
+	case "freemium":
+		return BlockFreemium
+	default:
+		return BlockStock
+	}
+}
+
+// blockCategories indexes the BlockCategory of each entry.
+func blockCategories(entries []DomainEntry) map[string]BlockCategory {
+	m := make(map[string]BlockCategory, len(entries))
+	for _, e := range entries {
+		m[e.Entry] = blockCategoryOf(e.Category)
+	}
+	return m
+}
+
+var (
+	blockedDomainCategories = blockCategories(append(append([]DomainEntry(nil), builtinDomains.BlockedDomains...), builtinDomains.AgencyCDNs...))
+	stockKeywordCategories  = blockCategories(builtinDomains.StockMetadataKeywords)
+)
+
+// blockPolicy is the license given to images matching a built-in entry of
+// each BlockCategory; categories without an entry are LicenseBlocked.
+type blockPolicy map[BlockCategory]ImageLicense
+
+// license returns the license for a match in category c.
+func (p blockPolicy) license(c BlockCategory) ImageLicense {
+	switch l := p[c]; l {
+	case LicenseSafe, LicenseUnknown:
+		return l
+	default:
+		return LicenseBlocked
+	}
+}
+
+// domainCategory returns the BlockCategory of BlockedDomains or AgencyCDNs
+// entry d; entries appended at run time are BlockStock.
+func domainCategory(d string) BlockCategory {
+	if c, ok := blockedDomainCategories[d]; ok {
+		return c
+	}
+	return BlockStock
+}
+
+// domainLicense returns the license for a host matching BlockedDomains or
+// AgencyCDNs entry d.
+func (p blockPolicy) domainLicense(d string) ImageLicense {
+	if len(p) == 0 {
+		return LicenseBlocked
+	}
+	return p.license(domainCategory(d))
+}
+
+// keywordBlocked reports whether stock metadata keyword kw rejects an image.
+func (p blockPolicy) keywordBlocked(kw string) bool {
+	if len(p) == 0 {
+		return true
+	}
+	c, ok := stockKeywordCategories[kw]
+	if !ok {
+		c = BlockStock
+	}
+	return p.license(c) == LicenseBlocked
+}
+
+// blockPolicyKey is the context key of the Config.BlockPolicy a search runs
+// under.
+type blockPolicyKey struct{}
+
+// withBlockPolicy returns ctx carrying p for the providers of a search, so
+// built-in providers drop blocked results under the Config's policy rather
+// than the default one. Wrapping providers pass it on with ctx.
+func withBlockPolicy(ctx context.Context, p map[BlockCategory]ImageLicense) context.Context {
+	if len(p) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, blockPolicyKey{}, blockPolicy(p))
+}
+
+// blockPolicyFrom returns the policy withBlockPolicy attached to ctx, or nil
+// (everything blocked) when a provider is called outside a search.
+func blockPolicyFrom(ctx context.Context) blockPolicy {
+	p, _ := ctx.Value(blockPolicyKey{}).(blockPolicy)
+	return p
+}
+
+// checkLicense is CheckLicense under p, for built-in providers.
+func (p blockPolicy) checkLicense(imageURL, sourceURL string) ImageLicense {
+	return checkLicense(imageURL, sourceURL, nil, nil, p)
+}
+
+// checkBlockPolicy reports BlockPolicy keys that are not a BlockCategory
+// and values other than LicenseBlocked, LicenseUnknown, and LicenseSafe.
+func (cfg *Config) checkBlockPolicy() error {
+	for c, l := range cfg.BlockPolicy {
+		switch c {
+		case BlockStock, BlockEditorial, BlockFreemium:
+		default:
+			return fmt.Errorf("imagefy: BlockPolicy: unknown category %q", c)
+		}
+		switch l {
+		case LicenseBlocked, LicenseUnknown, LicenseSafe:
+		default:
+			return fmt.Errorf("imagefy: BlockPolicy[%s]: license must be safe, unknown, or blocked, not %s", c, l)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinBlockCategories(t *testing.T) {
+	t.Parallel()
+
+	for entry, want := range map[string]BlockCategory{
+		"shutterstock": BlockStock,
+		"gettyimages":  BlockStock,
+		"pixta":        BlockStock,
+		"wireimage":    BlockEditorial,
+		"filmmagic":    BlockEditorial,
+		"freepik":      BlockFreemium,
+		"canva.":       BlockFreemium,
+	} {
+		if got := domainCategory(entry); got != want {
+			t.Errorf("domainCategory(%q) = %q, want %q", entry, got, want)
+		}
+	}
+}
+
+func TestCheckLicense_BlockPolicy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		freepik   = "https://img.freepik.com/free-photo/kazan.jpg"
+		wire      = "https://www.wireimage.com/photos/123"
+		stock     = "https://www.shutterstock.com/image-photo/kazan-123"
+		stockPath = "https://img.freepik.com/stock-photo/kazan.jpg"
+	)
+	freemiumSafe := blockPolicy{BlockFreemium: LicenseSafe}
+	everything := blockPolicy{BlockStock: LicenseUnknown, BlockEditorial: LicenseUnknown, BlockFreemium: LicenseUnknown}
+	tests := []struct {
+		name             string
+		imageURL, source string
+		extraBlocked     []string
+		policy           blockPolicy
+		want             ImageLicense
+	}{
+		{"default policy blocks freemium", freepik, "", nil, nil, LicenseBlocked},
+		{"freemium allowed as unknown", freepik, "", nil, blockPolicy{BlockFreemium: LicenseUnknown}, LicenseUnknown},
+		{"freemium allowed as safe", freepik, "https://example.org/post", nil, freemiumSafe, LicenseSafe},
+		{"explicit blocked", freepik, "", nil, blockPolicy{BlockFreemium: LicenseBlocked}, LicenseBlocked},
+		{"unset value blocks", freepik, "", nil, blockPolicy{BlockFreemium: LicenseUnset}, LicenseBlocked},
+		{"other categories still blocked", stock, "", nil, freemiumSafe, LicenseBlocked},
+		{"blocked source wins", freepik, stock, nil, freemiumSafe, LicenseBlocked},
+		{"editorial allowed", wire, "", nil, blockPolicy{BlockEditorial: LicenseUnknown}, LicenseUnknown},
+		{"URL pattern always blocks", stockPath, "", nil, everything, LicenseBlocked},
+		{"extra domain always blocks", freepik, "", []string{"freepik"}, freemiumSafe, LicenseBlocked},
+	}
+	for _, tt := range tests {
+		if got := checkLicense(tt.imageURL, tt.source, tt.extraBlocked, nil, tt.policy); got != tt.want {
+			t.Errorf("%s: checkLicense = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAssessLicense_BlockPolicyMetadata(t *testing.T) {
+	t.Parallel()
+
+	cand := ImageCandidate{ImgURL: "https://cdn.example/a.jpg", Source: "https://example.org/a", License: LicenseUnknown}
+	meta := &ImageMetadata{IPTCCredit: "Designed by Freepik"}
+
+	if a := (&Config{}).AssessLicense(cand, meta); a.License != LicenseBlocked {
+		t.Errorf("default policy: AssessLicense = %v, want blocked by the Freepik credit", a.License)
+	}
+	cfg := &Config{BlockPolicy: map[BlockCategory]ImageLicense{BlockFreemium: LicenseUnknown}}
+	if a := cfg.AssessLicense(cand, meta); a.License != LicenseUnknown || len(a.Signals) != 0 {
+		t.Errorf("freemium allowed: AssessLicense = %+v, want unknown without signals", a)
+	}
+	meta.IPTCCredit = "Shutterstock"
+	if a := cfg.AssessLicense(cand, meta); a.License != LicenseBlocked {
+		t.Errorf("freemium allowed: Shutterstock credit = %v, want blocked", a.License)
+	}
+}
+
+func TestGatherCandidates_BlockPolicy(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buildSearxngJSON([]searxngResult{
+			{ImgSrc: "https://img.freepik.com/free-photo/kazan.jpg", URL: "https://www.freepik.com/free-photo/kazan_1.htm"},
+			{ImgSrc: "https://www.shutterstock.com/image-photo/kazan.jpg", URL: "https://www.shutterstock.com/image-photo/kazan-1"},
+			{ImgSrc: "https://example.org/kazan.jpg", URL: "https://example.org/kazan"},
+		}))
+	}))
+	t.Cleanup(srv.Close)
+
+	searxng := &SearXNGProvider{URL: srv.URL, HTTPClient: srv.Client()}
+	providers := []SearchProvider{searxng}
+	gather := func(cfg *Config) map[string]ImageLicense {
+		got := make(map[string]ImageLicense)
+		for _, c := range cfg.gatherCandidates(context.Background(), providers, "Kazan", SearchOpts{}, newSearchState()) {
+			got[extractHost(c.ImgURL)] = c.License
+		}
+		return got
+	}
+
+	if got := gather(&Config{}); len(got) != 1 {
+		t.Errorf("default policy: got %v, want only example.org", got)
+	}
+	got := gather(&Config{BlockPolicy: map[BlockCategory]ImageLicense{BlockFreemium: LicenseSafe}})
+	if len(got) != 2 || got["img.freepik.com"] != LicenseSafe {
+		t.Errorf("freemium allowed as safe: got %v, want freepik safe and shutterstock dropped", got)
+	}
+
+	providers = []SearchProvider{&FallbackProvider{Providers: []SearchProvider{searxng}}}
+	if got := gather(&Config{BlockPolicy: map[BlockCategory]ImageLicense{BlockFreemium: LicenseSafe}}); len(got) != 2 {
+		t.Errorf("through a wrapping provider: got %v, want the policy passed on", got)
+	}
+	if got, _ := searxng.Search(context.Background(), "Kazan", SearchOpts{}); len(got) != 1 {
+		t.Errorf("outside a search: got %d candidates, want the default policy", len(got))
+	}
+}
+
+func TestExplainLicense_BlockPolicy(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{BlockPolicy: map[BlockCategory]ImageLicense{BlockFreemium: LicenseUnknown}}
+	signals := ExplainLicense("https://img.freepik.com/free-photo/kazan.jpg", "", cfg)
+	if len(signals) != 1 || signals[0].License != LicenseUnknown || !strings.Contains(signals[0].Detail, "(freemium)") {
+		t.Errorf("ExplainLicense = %+v, want one unknown freemium signal", signals)
+	}
+}
+
+func TestValidate_BlockPolicy(t *testing.T) {
+	t.Parallel()
+
+	for name, policy := range map[string]map[BlockCategory]ImageLicense{
+		"unknown category": {"premium": LicenseSafe},
+		"unset license":    {BlockFreemium: LicenseUnset},
+	} {
+		cfg := &Config{BlockPolicy: policy}
+		if err := cfg.checkBlockPolicy(); err == nil {
+			t.Errorf("%s: checkBlockPolicy = nil, want an error", name)
+		}
+	}
+	ok := &Config{BlockPolicy: map[BlockCategory]ImageLicense{BlockStock: LicenseBlocked, BlockEditorial: LicenseUnknown, BlockFreemium: LicenseSafe}}
+	if err := ok.checkBlockPolicy(); err != nil {
+		t.Errorf("checkBlockPolicy = %v, want nil", err)
+	}
+}
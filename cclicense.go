@@ -4,6 +4,7 @@ import (
 	"html"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 // ccLicensePathSegments are URL path prefixes that identify a Creative Commons
@@ -53,8 +54,11 @@ var (
 )
 
 // ExtractCCLicense scans HTML for Creative Commons license references.
+// Only the first 2MB of pageHTML are scanned.
 // Returns the first CC license URL found, or empty string if none.
 func ExtractCCLicense(pageHTML string) string {
+	pageHTML = truncateHTML(pageHTML)
+
 	// Try rel="license" patterns first (most authoritative).
 	if url := matchCCFromRel(ccRelHrefRe, pageHTML); url != "" {
 		return url
@@ -86,3 +90,39 @@ func matchCCFromRel(re *regexp.Regexp, pageHTML string) string {
 	}
 	return ""
 }
+
+// licenseFromName classifies a license name as reported by a provider, such
+// as Wikimedia Commons' "CC BY-SA 4.0", "Public domain", or "GFDL".
+// Non-free and fair-use material is LicenseBlocked. Free licenses (Creative
+// Commons, public domain, GFDL) are LicenseSafe unless NonCommercial or
+// NoDerivatives, which restrict editorial reuse and are LicenseUnknown, as
+// is an empty or unrecognized name.
+func licenseFromName(name string) ImageLicense {
+	lower := strings.ToLower(name)
+	if strings.Contains(lower, "non-free") || strings.Contains(lower, "fair use") {
+		return LicenseBlocked
+	}
+	tokens := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, t := range tokens {
+		switch t {
+		case "nc", "nd", "noncommercial", "noderivatives", "noderivs":
+			return LicenseUnknown
+		}
+	}
+	if strings.Contains(lower, "non-commercial") || strings.Contains(lower, "no derivative") {
+		return LicenseUnknown
+	}
+	if len(tokens) == 0 {
+		return LicenseUnknown
+	}
+	switch {
+	case tokens[0] == "cc", tokens[0] == "cc0", tokens[0] == "gfdl",
+		strings.HasPrefix(tokens[0], "pd"), // PD-old, PD-US, PD-self
+		strings.Contains(lower, "creative commons"),
+		strings.Contains(lower, "public domain"):
+		return LicenseSafe
+	}
+	return LicenseUnknown
+}
@@ -0,0 +1,157 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	defaultClassifierMaxRetries = 3
+	defaultClassifierRetryAfter = time.Second
+)
+
+// RateLimitedError is returned by a Classifier when the LLM provider throttled
+// the request (HTTP 429, quota exhausted). The call is retried after
+// RetryAfter, up to Config.ClassifierMaxRetries times, and every
+// classification of the same Config waits until then.
+type RateLimitedError struct {
+	RetryAfter time.Duration // delay requested by the provider (0 = 1s)
+	Err        error         // optional underlying error
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("imagefy: classifier rate limited (retry after %s): %v", e.RetryAfter, e.Err)
+	}
+	return fmt.Sprintf("imagefy: classifier rate limited (retry after %s)", e.RetryAfter)
+}
+
+func (e *RateLimitedError) Unwrap() error { return e.Err }
+
+// ThrottleEvent describes one rate-limited Classify call, for
+// Config.OnClassifierThrottle.
+type ThrottleEvent struct {
+	RetryAfter time.Duration // delay before the next attempt
+	Attempt    int           // 1 for the first call, 2 for the first retry, ...
+	GaveUp     bool          // true if no retry follows (retries exhausted or deadline too close)
+	Variant    string        // Variant.Label of the search's experiment arm ("" = none)
+}
+
+// classifierLimiter is the per-Config classifier state: the
+// ClassifierConcurrency semaphore, the shared rate-limit pause, and the last
+// warmup health.
+type classifierLimiter struct {
+	slots chan struct{} // nil = unlimited
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+	health      ClassifierHealth
+}
+
+// classifierLimiterMu guards the lazy creation of Config.classifier.
+var classifierLimiterMu sync.Mutex
+
+func (cfg *Config) limiter() *classifierLimiter {
+	classifierLimiterMu.Lock()
+	defer classifierLimiterMu.Unlock()
+	if cfg.classifier == nil {
+		cfg.classifier = &classifierLimiter{}
+		if cfg.ClassifierConcurrency > 0 {
+			cfg.classifier.slots = make(chan struct{}, cfg.ClassifierConcurrency)
+		}
+	}
+	return cfg.classifier
+}
+
+// pause holds back every classification until at least now+d.
+func (l *classifierLimiter) pause(d time.Duration) {
+	until := time.Now().Add(d)
+	l.mu.Lock()
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.mu.Unlock()
+}
+
+// wait blocks until the rate-limit pause is over. The pause may be extended
+// while waiting, so it loops.
+func (l *classifierLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		d := time.Until(l.pausedUntil)
+		l.mu.Unlock()
+		if d <= 0 {
+			return ctx.Err()
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// acquireClassifier waits out any rate-limit pause and for a
+// ClassifierConcurrency slot, and returns the function that frees the slot.
+func (cfg *Config) acquireClassifier(ctx context.Context) (release func(), err error) {
+	l := cfg.limiter()
+	if err := l.wait(ctx); err != nil {
+		return nil, err
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// callClassifier calls cfg.Classifier under the concurrency limit, retrying
+// *RateLimitedError responses after their RetryAfter delay. A retry is not
+// attempted if it would start after ctx's deadline; the RateLimitedError is
+// returned instead.
+func (cfg *Config) callClassifier(ctx context.Context, prompt string, images []ImageInput) (string, error) {
+	maxRetries := cfg.ClassifierMaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultClassifierMaxRetries
+	}
+
+	for attempt := 1; ; attempt++ {
+		release, err := cfg.acquireClassifier(ctx)
+		if err != nil {
+			return "", err
+		}
+		resp, err := cfg.Classifier.Classify(ctx, prompt, images)
+		release()
+
+		var rl *RateLimitedError
+		if !errors.As(err, &rl) {
+			return resp, err
+		}
+
+		delay := rl.RetryAfter
+		if delay <= 0 {
+			delay = defaultClassifierRetryAfter
+		}
+		cfg.limiter().pause(delay)
+
+		deadline, hasDeadline := ctx.Deadline()
+		gaveUp := attempt > maxRetries || (hasDeadline && time.Now().Add(delay).After(deadline))
+		slog.Debug("imagefy: classifier rate limited", "retry_after", delay, "attempt", attempt, "gave_up", gaveUp)
+		if cfg.OnClassifierThrottle != nil {
+			cfg.OnClassifierThrottle(ThrottleEvent{RetryAfter: delay, Attempt: attempt, GaveUp: gaveUp, Variant: cfg.variant.Label})
+		}
+		if gaveUp {
+			return "", err
+		}
+	}
+}
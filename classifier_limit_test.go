@@ -0,0 +1,140 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// throttledClassifier returns a RateLimitedError for the first `limited`
+// calls, then PHOTO.
+type throttledClassifier struct {
+	limited    int32
+	retryAfter time.Duration
+	calls      atomic.Int32
+}
+
+func (c *throttledClassifier) Classify(_ context.Context, _ string, _ []ImageInput) (string, error) {
+	if c.calls.Add(1) <= c.limited {
+		return "", &RateLimitedError{RetryAfter: c.retryAfter, Err: errors.New("429 Too Many Requests")}
+	}
+	return "PHOTO", nil
+}
+
+func TestCallClassifier_RetriesRateLimited(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var events []ThrottleEvent
+	cls := &throttledClassifier{limited: 2, retryAfter: 20 * time.Millisecond}
+	cfg := &Config{Classifier: cls, OnClassifierThrottle: func(e ThrottleEvent) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}}
+
+	start := time.Now()
+	resp, err := cfg.callClassifier(context.Background(), "p", nil)
+	if err != nil || resp != "PHOTO" {
+		t.Fatalf("callClassifier = %q, %v; want PHOTO, nil", resp, err)
+	}
+	if got := cls.calls.Load(); got != 3 {
+		t.Errorf("Classify calls = %d, want 3", got)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("elapsed %v, want >= 40ms of Retry-After waits", elapsed)
+	}
+	if len(events) != 2 || events[0].Attempt != 1 || events[1].Attempt != 2 || events[1].GaveUp {
+		t.Errorf("throttle events = %+v, want attempts 1 and 2 without GaveUp", events)
+	}
+}
+
+func TestCallClassifier_GivesUp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		timeout    time.Duration
+		retryAfter time.Duration
+		wantCalls  int32
+	}{
+		{"retries exhausted", 2, 0, time.Millisecond, 3},
+		{"retries disabled", -1, 0, time.Millisecond, 1},
+		{"deadline too close", 0, 50 * time.Millisecond, time.Second, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gaveUp atomic.Bool
+			cls := &throttledClassifier{limited: 100, retryAfter: tt.retryAfter}
+			cfg := &Config{Classifier: cls, ClassifierMaxRetries: tt.maxRetries,
+				OnClassifierThrottle: func(e ThrottleEvent) {
+					if e.GaveUp {
+						gaveUp.Store(true)
+					}
+				}}
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			_, err := cfg.callClassifier(ctx, "p", nil)
+			var rl *RateLimitedError
+			if !errors.As(err, &rl) {
+				t.Fatalf("err = %v, want *RateLimitedError", err)
+			}
+			if got := cls.calls.Load(); got != tt.wantCalls {
+				t.Errorf("Classify calls = %d, want %d", got, tt.wantCalls)
+			}
+			if !gaveUp.Load() {
+				t.Error("no ThrottleEvent with GaveUp")
+			}
+		})
+	}
+}
+
+func TestCallClassifier_PauseIsShared(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Classifier: &throttledClassifier{}}
+	cfg.limiter().pause(50 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := cfg.callClassifier(context.Background(), "p", nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("call ran after %v, want it held back by the pause", elapsed)
+	}
+}
+
+func TestClassifyFromData_RateLimited(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Classifier: &throttledClassifier{limited: 100, retryAfter: time.Millisecond}, ClassifierMaxRetries: -1}
+	cfg.defaults()
+	got, err := cfg.classifyFromData(context.Background(), "", makeJPEG(800, 600), "image/jpeg")
+	var rl *RateLimitedError
+	if got.Class != "" || !errors.As(err, &rl) {
+		t.Errorf("classifyFromData = %+v, %v; want zero result and the RateLimitedError", got, err)
+	}
+}
+
+func TestRateLimitedError_Unwrap(t *testing.T) {
+	t.Parallel()
+
+	base := errors.New("quota")
+	err := error(&RateLimitedError{RetryAfter: time.Second, Err: base})
+	if !errors.Is(err, base) {
+		t.Error("errors.Is(RateLimitedError, base) = false")
+	}
+	if err.Error() == "" {
+		t.Error("empty Error()")
+	}
+}
@@ -0,0 +1,119 @@
+// Package gemini implements imagefy.Classifier against the Google Gemini
+// generateContent API (Google AI Studio keys).
+//
+//	cfg := &imagefy.Config{Classifier: gemini.New(os.Getenv("GEMINI_API_KEY"), "gemini-2.0-flash")}
+package gemini
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+	"github.com/anatolykoptev/go-imagefy/internal/llmhttp"
+)
+
+// DefaultBaseURL is the Gemini API root.
+const DefaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// DefaultMaxImageBytes caps one inline image, leaving room for several
+// previews within the 20MB request limit.
+const DefaultMaxImageBytes = 4 * 1024 * 1024
+
+// formats are the inline image types the API accepts; others are re-encoded as JPEG.
+var formats = []string{"image/jpeg", "image/png", "image/webp", "image/heic", "image/heif"}
+
+// Classifier sends Classify calls to models/{Model}:generateContent.
+// Images are sent inline; HTTP URLs are downloaded first with HTTPClient.
+// HTTP 429 and 503 responses are returned as *imagefy.RateLimitedError.
+type Classifier struct {
+	APIKey        string
+	Model         string       // e.g. "gemini-2.0-flash"
+	BaseURL       string       // default: DefaultBaseURL
+	MaxTokens     int          // output token limit, thinking included (default: 256, or 4096 for Gemini 2.5 and later)
+	MaxImageBytes int          // larger images are sent as downscaled JPEG (default: DefaultMaxImageBytes)
+	HTTPClient    *http.Client // nil = http.DefaultClient
+}
+
+// New returns a Classifier for model authenticated with apiKey.
+func New(apiKey, model string) *Classifier {
+	return &Classifier{APIKey: apiKey, Model: model}
+}
+
+type generateRequest struct {
+	Contents         []content        `json:"contents"`
+	GenerationConfig generationConfig `json:"generationConfig"`
+}
+
+type content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []part `json:"parts"`
+}
+
+type part struct {
+	Text       string      `json:"text,omitempty"`
+	InlineData *inlineData `json:"inlineData,omitempty"`
+}
+
+type inlineData struct {
+	MIMEType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type generationConfig struct {
+	MaxOutputTokens int `json:"maxOutputTokens,omitempty"`
+}
+
+type generateResponse struct {
+	Candidates []struct {
+		Content content `json:"content"`
+	} `json:"candidates"`
+}
+
+// Classify implements imagefy.Classifier.
+func (c *Classifier) Classify(ctx context.Context, prompt string, images []imagefy.ImageInput) (string, error) {
+	lim := llmhttp.Limits{Formats: formats, MaxBytes: c.MaxImageBytes}
+	if lim.MaxBytes <= 0 {
+		lim.MaxBytes = DefaultMaxImageBytes
+	}
+	parts := []part{{Text: prompt}}
+	for _, img := range images {
+		data, mime, err := llmhttp.Load(ctx, c.HTTPClient, img, lim)
+		if err != nil {
+			return "", fmt.Errorf("gemini: %w", err)
+		}
+		parts = append(parts, part{InlineData: &inlineData{MIMEType: mime, Data: base64.StdEncoding.EncodeToString(data)}})
+	}
+	maxTokens := llmhttp.MaxTokens(c.MaxTokens, c.Model)
+	req := generateRequest{
+		Contents:         []content{{Role: "user", Parts: parts}},
+		GenerationConfig: generationConfig{MaxOutputTokens: maxTokens},
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/models/" + url.PathEscape(c.Model) + ":generateContent"
+	header := http.Header{}
+	if c.APIKey != "" {
+		header.Set("x-goog-api-key", c.APIKey)
+	}
+
+	var resp generateResponse
+	if err := llmhttp.PostJSON(ctx, c.HTTPClient, endpoint, header, req, &resp); err != nil {
+		return "", fmt.Errorf("gemini: %w", err)
+	}
+	if len(resp.Candidates) == 0 {
+		return "", errors.New("gemini: response has no candidates")
+	}
+	var sb strings.Builder
+	for _, p := range resp.Candidates[0].Content.Parts {
+		sb.WriteString(p.Text)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
@@ -0,0 +1,69 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	imgSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("png-bytes"))
+	}))
+	defer imgSrv.Close()
+
+	var got generateRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-2.0-flash:generateContent" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if k := r.Header.Get("x-goog-api-key"); k != "key" {
+			t.Errorf("x-goog-api-key = %q", k)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"STOCK "},{"text":"0.8"}]}}]}`))
+	}))
+	defer srv.Close()
+
+	c := New("key", "gemini-2.0-flash")
+	c.BaseURL = srv.URL
+	images := []imagefy.ImageInput{
+		{URL: "data:image/jpeg;base64,AAAA"},
+		{URL: imgSrv.URL + "/a.png", MIMEType: "image/png"},
+	}
+	resp, err := c.Classify(context.Background(), "classify", images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "STOCK 0.8" {
+		t.Errorf("resp = %q, want %q", resp, "STOCK 0.8")
+	}
+
+	if len(got.Contents) != 1 || len(got.Contents[0].Parts) != 3 {
+		t.Fatalf("request = %+v", got)
+	}
+	if d := got.Contents[0].Parts[1].InlineData; d == nil || d.MIMEType != "image/jpeg" || d.Data != "AAAA" {
+		t.Errorf("data URI part = %+v", d)
+	}
+	if d := got.Contents[0].Parts[2].InlineData; d == nil || d.MIMEType != "image/png" || d.Data != "cG5nLWJ5dGVz" {
+		t.Errorf("fetched image part = %+v", d)
+	}
+}
+
+func TestClassify_BadImage(t *testing.T) {
+	t.Parallel()
+
+	c := &Classifier{BaseURL: "http://127.0.0.1:1"}
+	if _, err := c.Classify(context.Background(), "p", []imagefy.ImageInput{{URL: "data:image/jpeg,raw"}}); err == nil {
+		t.Error("want error for non-base64 data URI")
+	}
+}
@@ -0,0 +1,94 @@
+// Package ollama implements imagefy.Classifier against a local Ollama server
+// running a multimodal model (llava, llama3.2-vision, qwen2.5vl, gemma3).
+//
+//	cfg := &imagefy.Config{Classifier: ollama.New("llama3.2-vision")}
+package ollama
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+	"github.com/anatolykoptev/go-imagefy/internal/llmhttp"
+)
+
+// DefaultBaseURL is the address of a default local Ollama install.
+const DefaultBaseURL = "http://localhost:11434"
+
+// DefaultMaxImageBytes caps one image; local models process previews, not originals.
+const DefaultMaxImageBytes = 5 * 1024 * 1024
+
+// formats are the image types every Ollama vision model decodes; WebP and
+// GIF are re-encoded as JPEG.
+var formats = []string{"image/jpeg", "image/png"}
+
+// Classifier sends Classify calls to /api/chat with streaming disabled.
+// Images are sent as base64; HTTP URLs are downloaded first with HTTPClient.
+type Classifier struct {
+	Model         string       // e.g. "llama3.2-vision"
+	BaseURL       string       // default: DefaultBaseURL
+	MaxTokens     int          // num_predict, <think> block included (default: 256, or 4096 for deepseek-r1, qwq, qwen3)
+	KeepAlive     string       // how long the model stays loaded, e.g. "30m" ("" = server default)
+	MaxImageBytes int          // larger images are sent as downscaled JPEG (default: DefaultMaxImageBytes)
+	HTTPClient    *http.Client // nil = http.DefaultClient
+}
+
+// New returns a Classifier for model on the local Ollama server.
+func New(model string) *Classifier {
+	return &Classifier{Model: model}
+}
+
+type chatRequest struct {
+	Model     string         `json:"model"`
+	Messages  []chatMessage  `json:"messages"`
+	Stream    bool           `json:"stream"`
+	KeepAlive string         `json:"keep_alive,omitempty"`
+	Options   map[string]any `json:"options,omitempty"`
+}
+
+type chatMessage struct {
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"`
+}
+
+type chatResponse struct {
+	Message chatMessage `json:"message"`
+}
+
+// Classify implements imagefy.Classifier.
+func (c *Classifier) Classify(ctx context.Context, prompt string, images []imagefy.ImageInput) (string, error) {
+	lim := llmhttp.Limits{Formats: formats, MaxBytes: c.MaxImageBytes}
+	if lim.MaxBytes <= 0 {
+		lim.MaxBytes = DefaultMaxImageBytes
+	}
+	msg := chatMessage{Role: "user", Content: prompt}
+	for _, img := range images {
+		data, _, err := llmhttp.Load(ctx, c.HTTPClient, img, lim)
+		if err != nil {
+			return "", fmt.Errorf("ollama: %w", err)
+		}
+		msg.Images = append(msg.Images, base64.StdEncoding.EncodeToString(data))
+	}
+	maxTokens := llmhttp.MaxTokens(c.MaxTokens, c.Model)
+	req := chatRequest{
+		Model:     c.Model,
+		Messages:  []chatMessage{msg},
+		KeepAlive: c.KeepAlive,
+		Options:   map[string]any{"num_predict": maxTokens},
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	var resp chatResponse
+	if err := llmhttp.PostJSON(ctx, c.HTTPClient, strings.TrimSuffix(baseURL, "/")+"/api/chat", nil, req, &resp); err != nil {
+		return "", fmt.Errorf("ollama: %w", err)
+	}
+	return strings.TrimSpace(resp.Message.Content), nil
+}
@@ -0,0 +1,96 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"MAP 0.7"},"done":true}`))
+	}))
+	defer srv.Close()
+
+	c := New("llava")
+	c.BaseURL = srv.URL
+	c.KeepAlive = "30m"
+	resp, err := c.Classify(context.Background(), "classify", []imagefy.ImageInput{{URL: "data:image/jpeg;base64,AAAA"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "MAP 0.7" {
+		t.Errorf("resp = %q, want %q", resp, "MAP 0.7")
+	}
+	if got.Model != "llava" || got.Stream || got.KeepAlive != "30m" {
+		t.Errorf("request = %+v", got)
+	}
+	if len(got.Messages) != 1 || len(got.Messages[0].Images) != 1 || got.Messages[0].Images[0] != "AAAA" {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+}
+
+func TestClassify_Overloaded(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "server busy", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	_, err := (&Classifier{BaseURL: srv.URL}).Classify(context.Background(), "p", nil)
+	var rl *imagefy.RateLimitedError
+	if !errors.As(err, &rl) {
+		t.Errorf("err = %v, want *imagefy.RateLimitedError", err)
+	}
+}
+
+func TestClassify_ReencodesUnsupportedFormat(t *testing.T) {
+	t.Parallel()
+
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{"message":{"content":"PHOTO 0.9"}}`))
+	}))
+	defer srv.Close()
+
+	var gifBuf bytes.Buffer
+	if err := gif.Encode(&gifBuf, image.NewPaletted(image.Rect(0, 0, 8, 8), color.Palette{color.White}), nil); err != nil {
+		t.Fatal(err)
+	}
+	img := imagefy.ImageInput{URL: "data:image/gif;base64,unused", MIMEType: "image/gif", Data: gifBuf.Bytes()}
+	if _, err := (&Classifier{BaseURL: srv.URL}).Classify(context.Background(), "p", []imagefy.ImageInput{img}); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(got.Messages[0].Images[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jpeg.DecodeConfig(bytes.NewReader(raw)); err != nil {
+		t.Errorf("GIF was not re-encoded as JPEG: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package openai implements imagefy.Classifier against the OpenAI Chat
+// Completions API with image inputs. It also works with OpenAI-compatible
+// servers (vLLM, LM Studio, OpenRouter, LiteLLM) via BaseURL.
+//
+//	cfg := &imagefy.Config{Classifier: openai.New(os.Getenv("OPENAI_API_KEY"), "gpt-4o-mini")}
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+	"github.com/anatolykoptev/go-imagefy/internal/llmhttp"
+)
+
+// DefaultBaseURL is the OpenAI API root.
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// DefaultMaxImageBytes caps one inline image (the API accepts up to 20MB).
+const DefaultMaxImageBytes = 20 * 1024 * 1024
+
+// formats are the inline image types the API accepts; others are re-encoded as JPEG.
+var formats = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// Classifier sends Classify calls to /chat/completions.
+// HTTP 429 and 503 responses are returned as *imagefy.RateLimitedError.
+// Reasoning models (o-series, GPT-5, DeepSeek-R1, QwQ) get
+// max_completion_tokens instead of max_tokens, which the o-series rejects,
+// and a limit that leaves room for their reasoning tokens.
+type Classifier struct {
+	APIKey        string
+	Model         string       // e.g. "gpt-4o-mini"
+	BaseURL       string       // default: DefaultBaseURL
+	Detail        string       // image detail: "low", "high", "auto" (default: "low")
+	MaxTokens     int          // completion limit (default: 256, or 4096 for reasoning models)
+	MaxImageBytes int          // larger images are sent as downscaled JPEG (default: DefaultMaxImageBytes)
+	HTTPClient    *http.Client // nil = http.DefaultClient
+}
+
+// New returns a Classifier for model authenticated with apiKey.
+func New(apiKey, model string) *Classifier {
+	return &Classifier{APIKey: apiKey, Model: model}
+}
+
+type chatRequest struct {
+	Model               string        `json:"model"`
+	Messages            []chatMessage `json:"messages"`
+	MaxTokens           int           `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int           `json:"max_completion_tokens,omitempty"`
+}
+
+type chatMessage struct {
+	Role    string        `json:"role"`
+	Content []contentPart `json:"content"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// Classify implements imagefy.Classifier. HTTP image URLs are passed through
+// for the API to fetch; in-memory images are sent as data: URIs in an
+// accepted format and size.
+func (c *Classifier) Classify(ctx context.Context, prompt string, images []imagefy.ImageInput) (string, error) {
+	detail := c.Detail
+	if detail == "" {
+		detail = "low"
+	}
+	lim := llmhttp.Limits{Formats: formats, MaxBytes: c.MaxImageBytes}
+	if lim.MaxBytes <= 0 {
+		lim.MaxBytes = DefaultMaxImageBytes
+	}
+	parts := []contentPart{{Type: "text", Text: prompt}}
+	for _, img := range images {
+		u := img.URL
+		if len(img.Data) > 0 || strings.HasPrefix(u, "data:") {
+			data, mimeType, err := llmhttp.Load(ctx, c.HTTPClient, img, lim)
+			if err != nil {
+				return "", fmt.Errorf("openai: %w", err)
+			}
+			u = imagefy.EncodeDataURL(data, mimeType)
+		}
+		parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: u, Detail: detail}})
+	}
+	maxTokens := llmhttp.MaxTokens(c.MaxTokens, c.Model)
+	req := chatRequest{
+		Model:    c.Model,
+		Messages: []chatMessage{{Role: "user", Content: parts}},
+	}
+	if llmhttp.ReasoningModel(c.Model) {
+		req.MaxCompletionTokens = maxTokens
+	} else {
+		req.MaxTokens = maxTokens
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	header := http.Header{}
+	if c.APIKey != "" {
+		header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	var resp chatResponse
+	if err := llmhttp.PostJSON(ctx, c.HTTPClient, strings.TrimSuffix(baseURL, "/")+"/chat/completions", header, req, &resp); err != nil {
+		return "", fmt.Errorf("openai: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("openai: response has no choices")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,108 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if a := r.Header.Get("Authorization"); a != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", a)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":" PHOTO 0.91\n"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := New("sk-test", "gpt-4o-mini")
+	c.BaseURL = srv.URL + "/v1"
+	resp, err := c.Classify(context.Background(), "classify", []imagefy.ImageInput{{URL: "data:image/jpeg;base64,AAAA"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "PHOTO 0.91" {
+		t.Errorf("resp = %q, want %q", resp, "PHOTO 0.91")
+	}
+	if got.Model != "gpt-4o-mini" || len(got.Messages) != 1 || len(got.Messages[0].Content) != 2 {
+		t.Fatalf("request = %+v", got)
+	}
+	if img := got.Messages[0].Content[1].ImageURL; img == nil || img.URL != "data:image/jpeg;base64,AAAA" || img.Detail != "low" {
+		t.Errorf("image part = %+v", img)
+	}
+	if got.MaxTokens != 256 || got.MaxCompletionTokens != 0 {
+		t.Errorf("max_tokens = %d, max_completion_tokens = %d; want 256, unset", got.MaxTokens, got.MaxCompletionTokens)
+	}
+}
+
+func TestClassify_ReasoningModel(t *testing.T) {
+	t.Parallel()
+
+	var raw map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"PHOTO 0.9"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := &Classifier{BaseURL: srv.URL, Model: "o4-mini"}
+	if _, err := c.Classify(context.Background(), "p", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["max_tokens"]; ok {
+		t.Errorf("request has max_tokens, which o-series models reject: %v", raw)
+	}
+	if raw["max_completion_tokens"] != float64(4096) {
+		t.Errorf("max_completion_tokens = %v, want 4096", raw["max_completion_tokens"])
+	}
+}
+
+func TestClassify_RateLimited(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		http.Error(w, `{"error":{"message":"Rate limit reached"}}`, http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := &Classifier{BaseURL: srv.URL, Model: "m"}
+	_, err := c.Classify(context.Background(), "p", nil)
+	var rl *imagefy.RateLimitedError
+	if !errors.As(err, &rl) {
+		t.Fatalf("err = %v, want *imagefy.RateLimitedError", err)
+	}
+	if rl.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want 7s", rl.RetryAfter)
+	}
+}
+
+func TestClassify_NoChoices(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[]}`))
+	}))
+	defer srv.Close()
+
+	if _, err := (&Classifier{BaseURL: srv.URL}).Classify(context.Background(), "p", nil); err == nil {
+		t.Error("want error for empty choices")
+	}
+}
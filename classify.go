@@ -1,8 +1,13 @@
 package imagefy
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"image"
 	"log/slog"
+	"net/http"
+	"strings"
 )
 
 // ClassifyImageFull uses a multimodal LLM to classify the image at imageURL.
@@ -10,27 +15,51 @@ import (
 // On error, returns a zero-value result (graceful degradation — never blocks the pipeline).
 // Uses Config.VisionPrompt if set, otherwise DefaultVisionPrompt.
 // Cache key prefix is "vision_cls_v2" (distinct from the legacy "vision_cls" prefix).
+// Failed classifications are not cached.
 func (cfg *Config) ClassifyImageFull(ctx context.Context, imageURL string) ClassificationResult {
+	cfg = cfg.orZero()
+	result, _ := cfg.classifyFull(ctx, imageURL)
+	return result
+}
+
+// classifyFull implements ClassifyImageFull, also returning the download or
+// Classifier error behind a zero result.
+func (cfg *Config) classifyFull(ctx context.Context, imageURL string) (ClassificationResult, error) {
 	cfg.defaults()
 
 	if cfg.Classifier == nil {
-		return ClassificationResult{} // no classifier → accept
+		// no classifier → accept, unless Strict reports it as a failed check
+		return ClassificationResult{}, cfg.requireDependency(ErrNoClassifier)
+	}
+
+	if f, ok := cfg.lookupFeedback(ctx, imageURL, nil); ok {
+		return cfg.feedbackResult(imageURL, f), nil
 	}
 
 	if cfg.Cache != nil {
-		cacheKey := cfg.Cache.Key("vision_cls_v2", imageURL)
+		cacheKey := cfg.visionCacheKey(imageURL)
 		var cached ClassificationResult
 		if cfg.Cache.Get(ctx, cacheKey, &cached) {
-			return cached
+			return cached, nil
+		}
+		result, err := cfg.doClassifyFull(ctx, imageURL)
+		if err == nil {
+			cfg.Cache.Set(ctx, cacheKey, result)
 		}
-		result := cfg.doClassifyFull(ctx, imageURL)
-		cfg.Cache.Set(ctx, cacheKey, result)
-		return result
+		return result, err
 	}
 
 	return cfg.doClassifyFull(ctx, imageURL)
 }
 
+// ClassifyImageErr is like ClassifyImageFull but also returns the error
+// behind a zero result: the download or Classifier error, or
+// ErrNoClassifier when Config.Strict is set and no Classifier is configured.
+func (cfg *Config) ClassifyImageErr(ctx context.Context, imageURL string) (ClassificationResult, error) {
+	cfg = cfg.orZero()
+	return cfg.classifyFull(ctx, imageURL)
+}
+
 // ClassifyImage uses a multimodal LLM to classify the image at imageURL.
 // Returns "PHOTO", "STOCK", "REJECT", or "" on error (graceful degradation).
 func (cfg *Config) ClassifyImage(ctx context.Context, imageURL string) string {
@@ -38,69 +67,134 @@ func (cfg *Config) ClassifyImage(ctx context.Context, imageURL string) string {
 }
 
 // IsRealPhoto returns true if the image is a real photograph (PHOTO class or graceful-degrade empty).
-// Returns true on any error (graceful degradation — never blocks the pipeline).
+// Returns true on any error (graceful degradation — never blocks the pipeline),
+// unless Config.DegradationPolicy is DegradeReject. Under Config.Strict a
+// missing Classifier is a failed check: IsRealPhoto returns false.
 func (cfg *Config) IsRealPhoto(ctx context.Context, imageURL string) bool {
-	cls := cfg.ClassifyImage(ctx, imageURL)
-	return cls == ClassPhoto || cls == ""
+	cfg = cfg.orZero()
+	result, err := cfg.classifyFull(ctx, imageURL)
+	if errors.Is(err, ErrNoClassifier) {
+		return false
+	}
+	if err != nil {
+		return cfg.DegradationPolicy != DegradeReject
+	}
+	return result.Class == ClassPhoto || result.Class == ""
 }
 
-func (cfg *Config) doClassifyFull(ctx context.Context, imageURL string) ClassificationResult {
-	r, err := cfg.Download(ctx, imageURL, DownloadOpts{
-		MaxBytes: visionMaxBytes,
-	})
-	if r == nil || err != nil {
-		return ClassificationResult{} // can't download → accept
+func (cfg *Config) doClassifyFull(ctx context.Context, imageURL string) (ClassificationResult, error) {
+	if cfg.classifiesByURL(imageURL) {
+		return cfg.classifyFromData(ctx, imageURL, nil, "")
+	}
+	opts := DownloadOpts{MaxBytes: visionMaxBytes}
+	r, err := cfg.Download(ctx, imageURL, opts)
+	if err != nil {
+		return ClassificationResult{}, err
 	}
+	if r == nil {
+		return ClassificationResult{}, errors.New("imagefy: empty download")
+	}
+	r = cfg.completeProgressive(ctx, r, imageURL, "", opts)
 
 	return cfg.classifyFromData(ctx, imageURL, r.Data, r.MIMEType)
 }
 
 // classifyPredownloaded classifies an already-downloaded image, avoiding a
 // redundant HTTP download. Uses the same cache key as ClassifyImageFull.
-func (cfg *Config) classifyPredownloaded(ctx context.Context, imageURL string, data []byte, mimeType string) ClassificationResult {
+func (cfg *Config) classifyPredownloaded(ctx context.Context, imageURL string, data []byte, mimeType string) (ClassificationResult, error) {
 	cfg.defaults()
 
 	if cfg.Classifier == nil {
-		return ClassificationResult{} // no classifier → accept
+		return ClassificationResult{}, nil // no classifier → accept
 	}
 
 	if cfg.Cache != nil {
-		cacheKey := cfg.Cache.Key("vision_cls_v2", imageURL)
+		cacheKey := cfg.visionCacheKey(imageURL)
 		var cached ClassificationResult
 		if cfg.Cache.Get(ctx, cacheKey, &cached) {
-			return cached
+			return cached, nil
+		}
+		result, err := cfg.classifyFromData(ctx, imageURL, data, mimeType)
+		if err == nil {
+			cfg.Cache.Set(ctx, cacheKey, result)
 		}
-		result := cfg.classifyFromData(ctx, imageURL, data, mimeType)
-		cfg.Cache.Set(ctx, cacheKey, result)
-		return result
+		return result, err
 	}
 
 	return cfg.classifyFromData(ctx, imageURL, data, mimeType)
 }
 
-// classifyFromData sends image data to the LLM classifier and parses the result.
-func (cfg *Config) classifyFromData(ctx context.Context, imageURL string, data []byte, mimeType string) ClassificationResult {
-	if len(data) == 0 {
-		return ClassificationResult{} // no data → accept
+// classifyFromData sends image data — or, with Config.ClassifyByURL, the
+// image URL itself — to the LLM classifier and parses the result. A
+// Classifier error is returned with a zero result, which callers treat
+// according to Config.DegradationPolicy.
+func (cfg *Config) classifyFromData(ctx context.Context, imageURL string, data []byte, mimeType string) (ClassificationResult, error) {
+	input := ImageInput{URL: imageURL, MIMEType: mimeType}
+	switch {
+	case cfg.classifiesByURL(imageURL):
+	case len(data) == 0:
+		return ClassificationResult{}, nil // no data → accept
+	default:
+		input = cfg.previewInput(data, mimeType)
 	}
 
-	dataURL := EncodeDataURL(data, mimeType)
+	if result, ok := cfg.quickClassify(ctx, imageURL, data); ok {
+		slog.Debug("imagefy: quick vision result", "url", imageURL, "class", result.Class, "confidence", result.Confidence)
+		cfg.emitEvent(classificationEvent(imageURL, result, "llm_quick"))
+		return result, nil
+	}
 
 	prompt := cfg.VisionPrompt
 	if prompt == "" {
 		prompt = DefaultVisionPrompt
 	}
 
-	resp, err := cfg.Classifier.Classify(ctx, prompt, []ImageInput{{URL: dataURL}})
+	prompt = withLabelHint(withLanguageHint(prompt, cfg.language), cfg.requireLabels)
+	prompt, images := withFewShot(prompt, input, cfg.fewShotExamples(ctx))
+	resp, err := cfg.callClassifier(ctx, prompt, images)
 	if err != nil {
 		slog.Debug("imagefy: vision LLM error", "url", imageURL, "error", err.Error())
-		return ClassificationResult{} // LLM error → accept
+		return ClassificationResult{}, err
 	}
 
 	slog.Debug("imagefy: vision result", "url", imageURL, "response", resp)
 	result := ParseClassificationResult(resp)
+	cfg.locateWatermark(ctx, imageURL, input, &result)
 
-	cfg.emitClassification(imageURL, result.Class, result.Confidence, "llm")
+	cfg.emitEvent(classificationEvent(imageURL, result, "llm"))
+	return result, nil
+}
 
-	return result
+// classificationEvent returns the event reporting a Classifier verdict.
+func classificationEvent(imageURL string, result ClassificationResult, source string) ClassificationEvent {
+	event := ClassificationEvent{URL: imageURL, Class: result.Class, Confidence: result.Confidence, Source: source, Rationale: result.Reason, Watermark: result.Watermark, Labels: result.Labels}
+	if result.Class != ClassPhoto && result.Class != "" {
+		event.Reason = ReasonVisionReject
+	}
+	return event
+}
+
+// classifiesByURL reports whether imageURL is sent to the Classifier as is
+// (Config.ClassifyByURL and an HTTP(S) URL) rather than downloaded and
+// inlined.
+func (cfg *Config) classifiesByURL(imageURL string) bool {
+	if !cfg.ClassifyByURL {
+		return false
+	}
+	lower := strings.ToLower(imageURL)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")
+}
+
+// newImageInput returns the ImageInput of downloaded image data: a data: URI
+// with the MIME type (sniffed from data when mimeType is not an image type)
+// and the dimensions from the image header, when it decodes.
+func newImageInput(data []byte, mimeType string) ImageInput {
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	in := ImageInput{URL: EncodeDataURL(data, mimeType), MIMEType: mimeType, Data: data}
+	if c, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		in.Width, in.Height = c.Width, c.Height
+	}
+	return in
 }
@@ -2,9 +2,14 @@ package imagefy
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func TestParseVisionResponse(t *testing.T) {
@@ -441,3 +446,257 @@ func TestIsRealPhoto_NewClasses(t *testing.T) {
 		})
 	}
 }
+
+func TestParseClassificationResult_Reasoning(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		resp      string
+		wantClass string
+		wantConf  float64
+	}{
+		{name: "think block before answer", resp: "<think>The image shows a watermark pattern.</think>\nSTOCK 0.88", wantClass: "STOCK", wantConf: 0.88},
+		{name: "multiline think block", resp: "<think>\nLooking at the facade...\nNo watermark.\n</think>\n\nPHOTO 0.9", wantClass: "PHOTO", wantConf: 0.9},
+		{name: "orphan closing tag", resp: "reasoning without opening tag</think>REJECT 0.7", wantClass: "REJECT", wantConf: 0.7},
+		{name: "reasoning text then verdict", resp: "The image is a street scene.\nThere is no overlay text.\nPHOTO 0.93", wantClass: "PHOTO", wantConf: 0.93},
+		{name: "answer label", resp: "Let me check for watermarks.\nAnswer: MAP 0.8", wantClass: "MAP", wantConf: 0.8},
+		{name: "markdown bold verdict", resp: "Analysis: it is a drawing.\n**ILLUSTRATION** 0.75", wantClass: "ILLUSTRATION", wantConf: 0.75},
+		{name: "reasoning starting with class-like word", resp: "Photograph of a cathedral with a big banner.\nREJECT 0.6", wantClass: "REJECT", wantConf: 0.6},
+		{name: "verdict too far from end", resp: "PHOTOS are hard.\nSTOCK 0.9\na\nb\nc\nd\ne", wantClass: "", wantConf: 0},
+		{name: "only reasoning", resp: "<think>PHOTO 0.9</think>", wantClass: "", wantConf: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := ParseClassificationResult(tc.resp)
+			if got.Class != tc.wantClass {
+				t.Errorf("ParseClassificationResult(%q).Class = %q, want %q", tc.resp, got.Class, tc.wantClass)
+			}
+			const eps = 1e-9
+			diff := got.Confidence - tc.wantConf
+			if diff < -eps || diff > eps {
+				t.Errorf("ParseClassificationResult(%q).Confidence = %v, want %v", tc.resp, got.Confidence, tc.wantConf)
+			}
+		})
+	}
+}
+
+// concurrencyClassifier answers PHOTO after a delay and records the peak
+// number of concurrent Classify calls.
+type concurrencyClassifier struct {
+	delay    time.Duration
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (c *concurrencyClassifier) Classify(_ context.Context, _ string, _ []ImageInput) (string, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		p := c.peak.Load()
+		if n <= p || c.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+	return "PHOTO", nil
+}
+
+func TestClassifierConcurrency(t *testing.T) {
+	t.Parallel()
+
+	srv := newJPEGServer(t)
+	var cands []ImageCandidate
+	for i := range 8 {
+		cands = append(cands, ImageCandidate{ImgURL: fmt.Sprintf("%s/%d.jpg", srv.URL, i), Source: srv.URL + "/page", License: LicenseUnknown})
+	}
+
+	tests := []struct {
+		name        string
+		concurrency int
+		wantPeakMax int32 // peak must not exceed
+		wantPeakMin int32 // peak must reach
+	}{
+		{"limited to one", 1, 1, 1},
+		{"above validation slots", 6, 6, validationSemaphore + 1},
+		{"unset shares validation slots", 0, validationSemaphore, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cls := &concurrencyClassifier{delay: 50 * time.Millisecond}
+			cfg := &Config{HTTPClient: srv.Client(), Classifier: cls, ClassifierConcurrency: tt.concurrency}
+
+			if got := cfg.ValidateCandidates(context.Background(), cands, len(cands)); len(got) != len(cands) {
+				t.Fatalf("accepted %d, want %d", len(got), len(cands))
+			}
+			if p := cls.peak.Load(); p > tt.wantPeakMax || p < tt.wantPeakMin {
+				t.Errorf("peak concurrent Classify calls = %d, want in [%d, %d]", p, tt.wantPeakMin, tt.wantPeakMax)
+			}
+		})
+	}
+}
+
+func TestAcquireClassifier_Canceled(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{ClassifierConcurrency: 1}
+	release, err := cfg.acquireClassifier(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cfg.acquireClassifier(ctx); err == nil {
+		t.Error("second acquire succeeded, want context error while the slot is held")
+	}
+}
+
+func TestClassifyByURL(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(makeJPEG(100, 100))
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("skips the download", func(t *testing.T) {
+		cl := &imagesCapturingClassifier{}
+		cfg := &Config{Classifier: cl, ClassifyByURL: true}
+		got := cfg.ClassifyImageFull(context.Background(), srv.URL+"/big.jpg")
+		if got.Class != ClassPhoto {
+			t.Fatalf("Class = %q, want PHOTO", got.Class)
+		}
+		if hits.Load() != 0 {
+			t.Errorf("image fetched %d times, want 0", hits.Load())
+		}
+		if len(cl.images) != 1 || cl.images[0].URL != srv.URL+"/big.jpg" || cl.images[0].Data != nil {
+			t.Errorf("images = %+v, want the bare HTTP URL", cl.images)
+		}
+	})
+
+	t.Run("inlines predownloaded data otherwise", func(t *testing.T) {
+		cl := &imagesCapturingClassifier{}
+		cfg := &Config{Classifier: cl}
+		if _, err := cfg.classifyPredownloaded(context.Background(), srv.URL+"/a.jpg", makeJPEG(10, 10), "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+		if len(cl.images) != 1 || !strings.HasPrefix(cl.images[0].URL, "data:image/jpeg;base64,") {
+			t.Errorf("images = %+v, want a data: URI", cl.images)
+		}
+	})
+
+	t.Run("pipeline sends the URL without data", func(t *testing.T) {
+		cl := &imagesCapturingClassifier{}
+		cfg := &Config{Classifier: cl, ClassifyByURL: true}
+		if _, err := cfg.classifyPredownloaded(context.Background(), srv.URL+"/b.jpg", nil, ""); err != nil {
+			t.Fatal(err)
+		}
+		if len(cl.images) != 1 || cl.images[0].URL != srv.URL+"/b.jpg" {
+			t.Errorf("images = %+v, want the HTTP URL", cl.images)
+		}
+	})
+}
+
+func TestClassifyImageFull_ImageInputMetadata(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg; charset=binary", makeJPEG(300, 200))
+	cl := &imagesCapturingClassifier{}
+	cfg := &Config{Classifier: cl}
+	cfg.ClassifyImageFull(context.Background(), srv.URL+"/a.jpg")
+
+	if len(cl.images) != 1 {
+		t.Fatalf("got %d images, want 1", len(cl.images))
+	}
+	in := cl.images[0]
+	if in.MIMEType != "image/jpeg" || in.Width != 300 || in.Height != 200 || len(in.Data) == 0 {
+		t.Errorf("ImageInput = {MIMEType %q, %dx%d, %d bytes}, want image/jpeg 300x200 with data", in.MIMEType, in.Width, in.Height, len(in.Data))
+	}
+
+	if sniffed := newImageInput(makeJPEG(10, 10), ""); sniffed.MIMEType != "image/jpeg" || !strings.HasPrefix(sniffed.URL, "data:image/jpeg;") {
+		t.Errorf("newImageInput without MIME type = %q", sniffed.MIMEType)
+	}
+}
+
+func TestParseClassificationResult_Reason(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, resp, wantClass, wantReason string
+		wantConf                          float64
+	}{
+		{"text after confidence", "STOCK 0.93 — Getty watermark across the center", ClassStock, "Getty watermark across the center", 0.93},
+		{"reason label on next line", "STOCK 0.8\nReason: tiled diagonal   watermark", ClassStock, "tiled diagonal watermark", 0.8},
+		{"no confidence", "REJECT: large promotional text", ClassReject, "large promotional text", 0},
+		{"no reason", "PHOTO 0.9", ClassPhoto, "", 0.9},
+		{"json", `{"class": "stock", "confidence": 0.91, "reason": "iStock logo bottom right"}`, ClassStock, "iStock logo bottom right", 0.91},
+		{"fenced json", "```json\n{\"class\":\"MAP\",\"confidence\":1.4,\"reason\":\"street map\"}\n```", ClassMap, "street map", 0},
+		{"json with unknown class falls back", `{"class": "cat"} PHOTO 0.7`, "", "", 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := ParseClassificationResult(tc.resp)
+			if got.Class != tc.wantClass || got.Confidence != tc.wantConf || got.Reason != tc.wantReason {
+				t.Errorf("ParseClassificationResult(%q) = %+v, want {%s %v %q}", tc.resp, got, tc.wantClass, tc.wantConf, tc.wantReason)
+			}
+		})
+	}
+
+	if got := ParseClassificationResult("PHOTO 0.9 " + strings.Repeat("я", 400)); len(got.Reason) > maxReasonLen || !utf8.ValidString(got.Reason) {
+		t.Errorf("long reason: %d bytes, valid UTF-8 %v", len(got.Reason), utf8.ValidString(got.Reason))
+	}
+}
+
+func TestClassifyImageFull_RationaleEvent(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(100, 100))
+	var events []ClassificationEvent
+	cfg := &Config{
+		Classifier:       &mockClassifier{response: "STOCK 0.9 - Shutterstock watermark"},
+		OnClassification: func(e ClassificationEvent) { events = append(events, e) },
+	}
+	got := cfg.ClassifyImageFull(context.Background(), srv.URL+"/a.jpg")
+	if got.Reason != "Shutterstock watermark" {
+		t.Errorf("Reason = %q", got.Reason)
+	}
+	if len(events) != 1 || events[0].Rationale != "Shutterstock watermark" {
+		t.Errorf("events = %+v, want one with the rationale", events)
+	}
+}
+
+func TestParseClassificationResult_Labels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, resp, wantClass string
+		wantConf              float64
+		wantLabels            []string
+	}{
+		{"comma list", "PHOTO,FOOD 0.9", ClassPhoto, 0.9, []string{"FOOD"}},
+		{"class not first", "food, architecture, Photo 0.8", ClassPhoto, 0.8, []string{"FOOD", "ARCHITECTURE"}},
+		{"slash list without confidence", "STOCK/PEOPLE", ClassStock, 0, []string{"PEOPLE"}},
+		{"no class in list", "FOOD,DRINK 0.9", "", 0, nil},
+		{"json array", `["PHOTO", "food", "food"]`, ClassPhoto, 0, []string{"FOOD"}},
+		{"json object labels", `{"class":"PHOTO","confidence":0.7,"labels":["Nature"]}`, ClassPhoto, 0.7, []string{"NATURE"}},
+		{"single class", "PHOTO 0.9", ClassPhoto, 0.9, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := ParseClassificationResult(tc.resp)
+			if got.Class != tc.wantClass || got.Confidence != tc.wantConf || fmt.Sprint(got.Labels) != fmt.Sprint(tc.wantLabels) {
+				t.Errorf("ParseClassificationResult(%q) = %+v, want {%s %v %v}", tc.resp, got, tc.wantClass, tc.wantConf, tc.wantLabels)
+			}
+		})
+	}
+}
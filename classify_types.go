@@ -1,6 +1,9 @@
 package imagefy
 
 import (
+	"encoding/json"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -83,31 +86,190 @@ var classificationClasses = []string{
 
 // ClassificationEvent is emitted by the audit log callback for each classification decision.
 type ClassificationEvent struct {
-	URL        string  // image URL that was classified
-	Class      string  // classification result (PHOTO, STOCK, etc.)
-	Confidence float64 // 0.0–1.0
-	Source     string  // "llm", "license_assessment", or "prefilter" (legacy)
+	URL        string        // image URL that was classified
+	Class      string        // classification result (PHOTO, STOCK, etc.)
+	Confidence float64       // 0.0–1.0
+	Source     string        // "llm", "llm_quick" (Config.QuickVision first pass), "license_assessment", "feedback", or "prefilter" (legacy)
+	Reason     RejectReason  // why the candidate was rejected; "" when accepted
+	Variant    string        // Variant.Label of the search's experiment arm ("" = none)
+	Rationale  string        // the model's stated reason for Class (ClassificationResult.Reason); "" if none
+	Watermark  *WatermarkBox // watermark location of a STOCK verdict, when known (ClassificationResult.Watermark)
+	Labels     []string      // topical labels besides Class (ClassificationResult.Labels)
 }
 
 // ClassificationResult holds the output of ClassifyImageFull.
 type ClassificationResult struct {
-	Class      string  // PHOTO, STOCK, REJECT, SCREENSHOT, ILLUSTRATION, MAP, PLACEHOLDER, or ""
-	Confidence float64 // 0.0–1.0; 0 if not provided or out of range
+	Class      string  `json:"class"`            // PHOTO, STOCK, REJECT, SCREENSHOT, ILLUSTRATION, MAP, PLACEHOLDER, or ""
+	Confidence float64 `json:"confidence"`       // 0.0–1.0; 0 if not provided or out of range
+	Reason     string  `json:"reason,omitempty"` // the model's explanation, e.g. "Getty watermark across the center"; "" if none
+
+	// Watermark is the rough location of the watermark of a STOCK verdict,
+	// from a JSON "watermark": [left, top, right, bottom] answer field or
+	// Config.WatermarkLocator / LocateWatermarks; nil if unknown.
+	Watermark *WatermarkBox `json:"watermark,omitempty"`
+
+	// Labels are the topical labels the model gave besides Class, upper-cased
+	// (e.g. ["FOOD"] for "PHOTO,FOOD 0.9"), for routing images by topic
+	// without a second call; nil if none.
+	Labels []string `json:"labels,omitempty"`
 }
 
-// ParseClassificationResult parses an LLM response of the form "CLASS 0.95".
-// It handles case insensitivity, extra whitespace, and trailing LLM noise.
+// maxReasonLen caps ClassificationResult.Reason, in bytes.
+const maxReasonLen = 500
+
+// thinkBlockRe matches chain-of-thought blocks emitted by reasoning models
+// (DeepSeek-R1, QwQ, etc.) before the final answer.
+var thinkBlockRe = regexp.MustCompile(`(?is)<(think|thinking|reasoning)>.*?</(think|thinking|reasoning)>`)
+
+// thinkCloseTags are closing tags checked when the opening tag was stripped by
+// the serving stack and only the trailing "</think>" survives.
+var thinkCloseTags = []string{"</think>", "</thinking>", "</reasoning>"}
+
+// maxClassificationResponse caps how much of a model response is parsed.
+// Longer responses keep their head and tail, where the verdict appears.
+const maxClassificationResponse = 64 * 1024
+
+// answerScanLines is how many trailing lines of a response are scanned for the
+// final verdict when the response does not start with a class label.
+const answerScanLines = 5
+
+// ParseClassificationResult parses an LLM response of the form "CLASS 0.95",
+// a label list such as "PHOTO,FOOD 0.9" (see Labels), a JSON array of
+// labels, or a JSON object {"class": ..., "confidence": ..., "reason": ...,
+// "labels": [...]}.
+// It handles case insensitivity and extra whitespace; text after the
+// confidence (or after the class, without one) becomes Reason, up to 500
+// bytes, with a leading "Reason:" label and separator dashes stripped.
+// Reasoning models that prepend chain-of-thought text are supported: <think>
+// blocks are stripped, and unless the response is one line or its first line
+// is the bare verdict ("PHOTO 0.9" with the reason on the lines below), the
+// verdict is taken from the last few lines (e.g. "Answer: PHOTO 0.9"), so
+// reasoning that opens with a class word ("Photo shows a watermark") does
+// not count as the answer.
 // Confidence must be in (0, 1]; otherwise it is set to 0.
+// Responses longer than 64KB are parsed by head and tail only.
 // Returns a zero-value ClassificationResult for unrecognized responses.
 func ParseClassificationResult(resp string) ClassificationResult {
-	upper := strings.ToUpper(strings.TrimSpace(resp))
+	if len(resp) > maxClassificationResponse {
+		half := maxClassificationResponse / 2
+		resp = resp[:half] + "\n" + resp[len(resp)-half:]
+	}
+	cleaned := stripReasoning(resp)
+
+	if result, ok := parseClassificationJSON(cleaned); ok {
+		return result
+	}
+	first, rest, multiline := strings.Cut(cleaned, "\n")
+	if !multiline || bareVerdict(first) {
+		// "PHOTO 0.9" alone, or as the first line with the reason below it.
+		if result := parseClassificationLine(cleaned); result.Class != "" || !multiline {
+			return result
+		}
+	}
+
+	// Reasoning first: the verdict is on one of the last lines, and a class
+	// word opening the reasoning ("Photo shows a watermark") is not one.
+	lines := strings.Split(rest, "\n")
+	lo := max(len(lines)-answerScanLines, 0)
+	for i := len(lines) - 1; i >= lo; i-- {
+		if line := trimAnswerDecoration(lines[i]); bareVerdict(line) {
+			return parseClassificationLine(line)
+		}
+	}
+	for i := len(lines) - 1; i >= lo; i-- {
+		if result := parseClassificationLine(trimAnswerDecoration(lines[i])); result.Class != "" {
+			return result
+		}
+	}
+	return ClassificationResult{}
+}
+
+// bareVerdict reports whether line is a class or label list with at most a
+// confidence after it, nothing else.
+func bareVerdict(line string) bool {
+	result := parseClassificationLine(line)
+	return result.Class != "" && result.Reason == ""
+}
+
+// stripReasoning removes chain-of-thought blocks from a model response.
+func stripReasoning(resp string) string {
+	resp = thinkBlockRe.ReplaceAllString(resp, "")
+	lower := asciiLower(resp)
+	for _, tag := range thinkCloseTags {
+		if idx := strings.LastIndex(lower, tag); idx >= 0 {
+			resp = resp[idx+len(tag):]
+			lower = lower[idx+len(tag):]
+		}
+	}
+	return strings.TrimSpace(resp)
+}
+
+// asciiLower lowercases ASCII letters only, preserving byte offsets
+// (strings.ToLower may change the length of invalid UTF-8 input).
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+
+// asciiUpper uppercases ASCII letters only, preserving byte offsets.
+func asciiUpper(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'a' && c <= 'z' {
+			b[i] = c - ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+
+// trimAnswerDecoration strips markdown emphasis and "Answer:"-style labels
+// that models commonly wrap around the final verdict line.
+func trimAnswerDecoration(line string) string {
+	line = strings.Trim(strings.TrimSpace(line), "*_`#>- ")
+	upper := strings.ToUpper(line)
+	for _, label := range []string{"FINAL ANSWER:", "ANSWER:", "CLASSIFICATION:", "CLASS:"} {
+		if strings.HasPrefix(upper, label) {
+			line = line[len(label):]
+			break
+		}
+	}
+	return answerMarkupReplacer.Replace(strings.TrimSpace(line))
+}
+
+// answerMarkupReplacer removes inline markdown emphasis and code markers.
+var answerMarkupReplacer = strings.NewReplacer("**", "", "__", "", "`", "")
+
+// labelListRe matches a leading comma-separated label list, e.g. "PHOTO,FOOD"
+// or "food / PHOTO".
+var labelListRe = regexp.MustCompile(`^\p{L}[\p{L}_-]*(?:\s*[,/+|]\s*\p{L}[\p{L}_-]*)+`)
+
+// parseClassificationLine parses a single "CLASS 0.95" answer that starts
+// with a class label, or with a label list containing one ("PHOTO,FOOD 0.9").
+func parseClassificationLine(line string) ClassificationResult {
+	line = strings.TrimSpace(line)
+	if list := labelListRe.FindString(line); list != "" {
+		class, labels := splitLabels(strings.FieldsFunc(list, func(r rune) bool { return strings.ContainsRune(",/+| \t", r) }))
+		if class == "" {
+			return ClassificationResult{}
+		}
+		result := parseAfterClass(class, line[len(list):])
+		result.Labels = labels
+		return result
+	}
+
+	upper := asciiUpper(line)
 	if upper == "" {
 		return ClassificationResult{}
 	}
 
 	var matched string
 	for _, cls := range classificationClasses {
-		if strings.HasPrefix(upper, cls) {
+		if strings.HasPrefix(upper, cls) && !startsWithLetter(upper[len(cls):]) {
 			matched = cls
 			break
 		}
@@ -116,18 +278,117 @@ func ParseClassificationResult(resp string) ClassificationResult {
 		return ClassificationResult{}
 	}
 
-	remainder := strings.TrimSpace(upper[len(matched):])
+	return parseAfterClass(matched, line[len(matched):])
+}
+
+// parseAfterClass parses the optional confidence and reason following the
+// class label of an answer.
+func parseAfterClass(matched, remainder string) ClassificationResult {
+	remainder = strings.TrimSpace(remainder)
 	if remainder == "" {
 		return ClassificationResult{Class: matched}
 	}
 
-	fields := strings.Fields(remainder)
-	conf, err := strconv.ParseFloat(fields[0], 64)
-	if err != nil || conf <= 0 || conf > 1 {
-		return ClassificationResult{Class: matched}
+	first := strings.Fields(remainder)[0]
+	conf, err := strconv.ParseFloat(first, 64)
+	if err != nil {
+		return ClassificationResult{Class: matched, Reason: cleanReason(remainder)}
+	}
+	reason := cleanReason(remainder[len(first):])
+	if !(conf > 0 && conf <= 1) { // also rejects NaN
+		return ClassificationResult{Class: matched, Reason: reason}
+	}
+
+	return ClassificationResult{Class: matched, Confidence: conf, Reason: reason}
+}
+
+// classificationJSON is the JSON answer form of a classification.
+type classificationJSON struct {
+	Class      string    `json:"class"`
+	Confidence float64   `json:"confidence"`
+	Reason     string    `json:"reason"`
+	Watermark  []float64 `json:"watermark"` // left, top, right, bottom
+	Labels     []string  `json:"labels"`
+}
+
+// parseClassificationJSON parses a JSON answer object — the span from the
+// first "{" to the last "}", so code fences around it are ignored — or a
+// JSON array of labels such as ["PHOTO", "FOOD"]. It reports false unless
+// the answer names a known class.
+func parseClassificationJSON(resp string) (ClassificationResult, bool) {
+	start, end := strings.IndexByte(resp, '{'), strings.LastIndexByte(resp, '}')
+	if start < 0 || end < start {
+		return parseLabelArray(resp)
+	}
+	var v classificationJSON
+	if err := json.Unmarshal([]byte(resp[start:end+1]), &v); err != nil {
+		return ClassificationResult{}, false
 	}
+	class, labels := splitLabels(append([]string{v.Class}, v.Labels...))
+	if class == "" || !strings.EqualFold(class, strings.TrimSpace(v.Class)) {
+		return ClassificationResult{}, false
+	}
+	result := ClassificationResult{Class: class, Reason: cleanReason(v.Reason), Labels: labels}
+	if v.Confidence > 0 && v.Confidence <= 1 {
+		result.Confidence = v.Confidence
+	}
+	if len(v.Watermark) == boxEdges {
+		if box := (WatermarkBox{Left: v.Watermark[0], Top: v.Watermark[1], Right: v.Watermark[2], Bottom: v.Watermark[3]}); box.valid() {
+			result.Watermark = &box
+		}
+	}
+	return result, true
+}
+
+// parseLabelArray parses a JSON array answer of labels, one of them a class.
+func parseLabelArray(resp string) (ClassificationResult, bool) {
+	start, end := strings.IndexByte(resp, '['), strings.LastIndexByte(resp, ']')
+	if start < 0 || end < start {
+		return ClassificationResult{}, false
+	}
+	var list []string
+	if err := json.Unmarshal([]byte(resp[start:end+1]), &list); err != nil {
+		return ClassificationResult{}, false
+	}
+	class, labels := splitLabels(list)
+	return ClassificationResult{Class: class, Labels: labels}, class != ""
+}
+
+// splitLabels upper-cases labels and returns the first that is a class,
+// and the other distinct ones in order.
+func splitLabels(labels []string) (class string, rest []string) {
+	for _, l := range labels {
+		l = strings.ToUpper(strings.TrimSpace(l))
+		switch {
+		case l == "" || l == class || slices.Contains(rest, l):
+		case class == "" && slices.Contains(classificationClasses, l):
+			class = l
+		default:
+			rest = append(rest, l)
+		}
+	}
+	return class, rest
+}
+
+// cleanReason normalizes the explanation following a verdict: whitespace is
+// collapsed, separators and a "Reason:" label are stripped, and the result
+// is capped at maxReasonLen bytes.
+func cleanReason(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.TrimLeft(s, "-–—:;,.| ")
+	if len(s) >= len("reason:") && strings.EqualFold(s[:len("reason:")], "reason:") {
+		s = strings.TrimSpace(s[len("reason:"):])
+	}
+	if len(s) > maxReasonLen {
+		s = strings.ToValidUTF8(s[:maxReasonLen], "")
+	}
+	return s
+}
 
-	return ClassificationResult{Class: matched, Confidence: conf}
+// startsWithLetter reports whether s begins with an ASCII letter, i.e. a class
+// match like "PHOTO" in "PHOTOGRAPHS" is not at a word boundary.
+func startsWithLetter(s string) bool {
+	return s != "" && s[0] >= 'A' && s[0] <= 'Z'
 }
 
 // ParseVisionResponse normalizes an LLM response to one of: "PHOTO", "STOCK", "REJECT", or "".
@@ -0,0 +1,32 @@
+package imagefy
+
+import "testing"
+
+func TestParseClassificationResult_VerdictAfterReasoning(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		resp       string
+		wantClass  string
+		wantConf   float64
+		wantReason string
+	}{
+		{name: "class word opens reasoning", resp: "Photo shows a Shutterstock watermark across the center.\nSTOCK 0.9", wantClass: "STOCK", wantConf: 0.9},
+		{name: "bare verdict on first line", resp: "STOCK 0.9\nPhoto shows a watermark.", wantClass: "STOCK", wantConf: 0.9, wantReason: "Photo shows a watermark."},
+		{name: "bare verdict after reasoning with class words", resp: "Photo of a street.\nPhoto looks real.\nPHOTO 0.8", wantClass: "PHOTO", wantConf: 0.8},
+		{name: "bare verdict beats later loose line", resp: "Looking closer.\nREJECT 0.7\nPhoto is blurry", wantClass: "REJECT", wantConf: 0.7},
+		{name: "single line with reason", resp: "PHOTO 0.9 real facade", wantClass: "PHOTO", wantConf: 0.9, wantReason: "real facade"},
+		{name: "no verdict after reasoning", resp: "Photo shows a watermark.\nHard to say.", wantClass: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := ParseClassificationResult(tc.resp)
+			if got.Class != tc.wantClass || got.Confidence != tc.wantConf || got.Reason != tc.wantReason {
+				t.Errorf("ParseClassificationResult(%q) = %q %v %q, want %q %v %q", tc.resp, got.Class, got.Confidence, got.Reason, tc.wantClass, tc.wantConf, tc.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package imagefy
+
+import "maps"
+
+// Clone returns a copy of c whose slices and maps (Providers, domain
+// lists, HostProfiles, EnginesByScript, FewShot, ...) are duplicated, so
+// appending to or editing the copy never changes c. Interfaces, callbacks,
+// HTTP clients, DoH, and Subscription are shared, as are the bytes of
+// FewShot images. Reputation options are copied.
+//
+// Like the per-search copies made internally, the clone shares c's
+// classifier limiter, download pool, and PrefetchDNS cache:
+// ClassifierConcurrency, DownloadConcurrency, and DownloadBandwidth caps
+// apply across c and all its clones, with the values c was created with.
+// A nil c clones a zero-value Config.
+func (c *Config) Clone() *Config {
+	c = c.orZero()
+	d := c.derive()
+
+	d.HotlinkPlaceholders = cloneStrings(c.HotlinkPlaceholders)
+	d.PlaceholderHashes = cloneStrings(c.PlaceholderHashes)
+	d.ExtraBlockedDomains = cloneStrings(c.ExtraBlockedDomains)
+	d.ExtraSafeDomains = cloneStrings(c.ExtraSafeDomains)
+	d.BlockPolicy = maps.Clone(c.BlockPolicy)
+	d.TrustedCreators = cloneStrings(c.TrustedCreators)
+	d.StealthHosts = cloneStrings(c.StealthHosts)
+	if c.Providers != nil {
+		d.Providers = append([]SearchProvider(nil), c.Providers...)
+	}
+	if c.FewShot != nil {
+		d.FewShot = append([]FewShotExample(nil), c.FewShot...)
+	}
+	if c.EnginesByScript != nil {
+		d.EnginesByScript = make(map[Script][]string, len(c.EnginesByScript))
+		for s, engines := range c.EnginesByScript {
+			d.EnginesByScript[s] = cloneStrings(engines)
+		}
+	}
+	if c.HostProfiles != nil {
+		d.HostProfiles = make(map[string]Profile, len(c.HostProfiles))
+		for host, p := range c.HostProfiles {
+			if p.Headers != nil {
+				headers := make(map[string]string, len(p.Headers))
+				for k, v := range p.Headers {
+					headers[k] = v
+				}
+				p.Headers = headers
+			}
+			d.HostProfiles[host] = p
+		}
+	}
+	d.SourceBoosts = maps.Clone(c.SourceBoosts)
+	if c.Reputation != nil {
+		rep := *c.Reputation
+		d.Reputation = &rep
+	}
+
+	d.requireLabels = cloneStrings(c.requireLabels)
+	if c.queryVec != nil {
+		d.queryVec = append([]float32(nil), c.queryVec...)
+	}
+	return d
+}
+
+// With returns a Clone of c with overrides applied in order, for
+// request-scoped tweaks:
+//
+//	cfg := base.With(func(c *imagefy.Config) {
+//		c.ExtraBlockedDomains = append(c.ExtraBlockedDomains, "example-stock.com")
+//		c.MinImageWidth = 600
+//	})
+func (c *Config) With(overrides ...func(*Config)) *Config {
+	d := c.Clone()
+	for _, o := range overrides {
+		o(d)
+	}
+	return d
+}
+
+// cloneStrings returns a copy of s, keeping nil as nil.
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
@@ -0,0 +1,105 @@
+package imagefy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigClone_Independent(t *testing.T) {
+	t.Parallel()
+
+	base := &Config{
+		Providers:           []SearchProvider{&mockProvider{name: "a"}},
+		ExtraBlockedDomains: make([]string, 1, 4), // spare capacity: a shared append would leak
+		EnginesByScript:     map[Script][]string{ScriptCyrillic: {"yandex images"}},
+		HostProfiles:        map[string]Profile{"cdn.example": {Headers: map[string]string{"Referer": "a"}}},
+		Reputation:          &DomainReputation{SkipBelow: 0.1},
+		OnImageSearch:       func() {},
+	}
+	base.ExtraBlockedDomains[0] = "stock.example"
+
+	c := base.Clone()
+	c.Providers[0] = &mockProvider{name: "b"}
+	c.ExtraBlockedDomains = append(c.ExtraBlockedDomains, "more.example")
+	c.EnginesByScript[ScriptCyrillic][0] = "bing images"
+	c.HostProfiles["cdn.example"].Headers["Referer"] = "b"
+	c.Reputation.SkipBelow = 0.5
+	_ = append(c.ExtraBlockedDomains[:1], "overwritten.example")
+
+	if base.Providers[0].Name() != "a" {
+		t.Error("Providers shared with the clone")
+	}
+	if got := base.ExtraBlockedDomains[:cap(base.ExtraBlockedDomains)][1]; got != "" {
+		t.Errorf("append to the clone wrote %q into the original's backing array", got)
+	}
+	if base.EnginesByScript[ScriptCyrillic][0] != "yandex images" {
+		t.Error("EnginesByScript engine lists shared with the clone")
+	}
+	if base.HostProfiles["cdn.example"].Headers["Referer"] != "a" {
+		t.Error("HostProfiles headers shared with the clone")
+	}
+	if base.Reputation.SkipBelow != 0.1 {
+		t.Error("Reputation options shared with the clone")
+	}
+	if c.OnImageSearch == nil {
+		t.Error("callbacks not copied")
+	}
+	if c.limiter() != base.limiter() || c.pool() != base.pool() {
+		t.Error("clone does not share the classifier limiter and download pool")
+	}
+}
+
+// TestConfigClone_CoversAllFields fails when a slice or map field is added
+// to Config without Clone duplicating it.
+func TestConfigClone_CoversAllFields(t *testing.T) {
+	t.Parallel()
+
+	base := &Config{}
+	v := reflect.ValueOf(base).Elem()
+	for i := range v.NumField() {
+		f := v.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Slice:
+			f.Set(reflect.MakeSlice(f.Type(), 1, 1))
+		case reflect.Map:
+			m := reflect.MakeMap(f.Type())
+			m.SetMapIndex(reflect.Zero(f.Type().Key()), reflect.Zero(f.Type().Elem()))
+			f.Set(m)
+		}
+	}
+
+	c := reflect.ValueOf(base.Clone()).Elem()
+	for i := range v.NumField() {
+		f, name := v.Field(i), v.Type().Field(i).Name
+		if !f.CanSet() || (f.Kind() != reflect.Slice && f.Kind() != reflect.Map) {
+			continue
+		}
+		if c.Field(i).Pointer() == f.Pointer() {
+			t.Errorf("Clone shares Config.%s", name)
+		}
+	}
+}
+
+func TestConfigWith(t *testing.T) {
+	t.Parallel()
+
+	base := &Config{MinImageWidth: 880, TrustedCreators: []string{"Staff"}}
+	c := base.With(
+		func(c *Config) { c.MinImageWidth = 600 },
+		func(c *Config) { c.TrustedCreators = append(c.TrustedCreators, "Partner") },
+	)
+	if c.MinImageWidth != 600 || len(c.TrustedCreators) != 2 {
+		t.Errorf("With = {MinImageWidth:%d TrustedCreators:%v}", c.MinImageWidth, c.TrustedCreators)
+	}
+	if base.MinImageWidth != 880 || len(base.TrustedCreators) != 1 {
+		t.Errorf("base changed: {MinImageWidth:%d TrustedCreators:%v}", base.MinImageWidth, base.TrustedCreators)
+	}
+
+	var nilCfg *Config
+	if got := nilCfg.With(func(c *Config) { c.MinImageWidth = 1 }); got == nil || got.MinImageWidth != 1 {
+		t.Errorf("nil.With = %+v", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Command imagefy is an operations helper for go-imagefy.
+//
+// Usage:
+//
+//	imagefy why-blocked [-blocked a,b] [-safe c,d] IMAGE_URL [SOURCE_URL]
+//	imagefy doctor (-openai|-gemini|-ollama) MODEL [-base-url URL] [-timeout D]
+//
+// why-blocked prints the license verdict for the URLs and every domain list
+// entry or URL pattern that matched, without fetching anything. -blocked and
+// -safe supply the service's ExtraBlockedDomains / ExtraSafeDomains.
+//
+// doctor sends a canary classification to a vision model through the matching
+// classifiers/ adapter and reports its health and latency; it exits 1 when the
+// model is unreachable. API keys come from OPENAI_API_KEY and GEMINI_API_KEY.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+	"github.com/anatolykoptev/go-imagefy/classifiers/gemini"
+	"github.com/anatolykoptev/go-imagefy/classifiers/ollama"
+	"github.com/anatolykoptev/go-imagefy/classifiers/openai"
+)
+
+const usage = `usage: imagefy why-blocked [-blocked a,b] [-safe c,d] IMAGE_URL [SOURCE_URL]
+       imagefy doctor (-openai|-gemini|-ollama) MODEL [-base-url URL] [-timeout D]`
+
+const defaultDoctorTimeout = 2 * time.Minute // a local model may load from disk first
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the process exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "why-blocked":
+		return whyBlocked(args[1:], stdout, stderr)
+	case "doctor":
+		return doctor(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "imagefy: unknown command %q\n%s\n", args[0], usage)
+		return 2
+	}
+}
+
+func whyBlocked(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("why-blocked", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	blocked := fs.String("blocked", "", "comma-separated extra blocked domains")
+	safe := fs.String("safe", "", "comma-separated extra safe domains")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fmt.Fprintln(stderr, usage)
+		return 2
+	}
+	imageURL, sourceURL := fs.Arg(0), fs.Arg(1)
+
+	cfg := &imagefy.Config{ExtraBlockedDomains: splitList(*blocked), ExtraSafeDomains: splitList(*safe)}
+	verdict := imagefy.CheckLicenseWith(imageURL, sourceURL, cfg.ExtraBlockedDomains, cfg.ExtraSafeDomains)
+	signals := imagefy.ExplainLicense(imageURL, sourceURL, cfg)
+
+	fmt.Fprintf(stdout, "verdict: %s\n", verdict)
+	if len(signals) == 0 {
+		fmt.Fprintln(stdout, "no list entry matched")
+		return 0
+	}
+	for _, s := range signals {
+		fmt.Fprintf(stdout, "  %-8s %-13s %s\n", s.License, s.Source, s.Detail)
+	}
+	return 0
+}
+
+func doctor(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	openaiModel := fs.String("openai", "", "OpenAI (or compatible) model")
+	geminiModel := fs.String("gemini", "", "Gemini model")
+	ollamaModel := fs.String("ollama", "", "Ollama model")
+	baseURL := fs.String("base-url", "", "API base URL (default: the adapter's DefaultBaseURL)")
+	timeout := fs.Duration("timeout", defaultDoctorTimeout, "canary timeout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var name string
+	var cls imagefy.Classifier
+	switch {
+	case *openaiModel != "" && *geminiModel == "" && *ollamaModel == "":
+		c := openai.New(os.Getenv("OPENAI_API_KEY"), *openaiModel)
+		c.BaseURL = *baseURL
+		name, cls = "openai "+*openaiModel, c
+	case *geminiModel != "" && *openaiModel == "" && *ollamaModel == "":
+		c := gemini.New(os.Getenv("GEMINI_API_KEY"), *geminiModel)
+		c.BaseURL = *baseURL
+		name, cls = "gemini "+*geminiModel, c
+	case *ollamaModel != "" && *openaiModel == "" && *geminiModel == "":
+		c := ollama.New(*ollamaModel)
+		c.BaseURL = *baseURL
+		name, cls = "ollama "+*ollamaModel, c
+	default:
+		fmt.Fprintln(stderr, usage)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	cfg := &imagefy.Config{Classifier: cls}
+	_ = cfg.WarmupClassifier(ctx)
+	h := cfg.ClassifierHealth()
+
+	fmt.Fprintf(stdout, "classifier: %s\n", name)
+	fmt.Fprintf(stdout, "latency: %s\n", h.Latency.Round(time.Millisecond))
+	if !h.Healthy {
+		fmt.Fprintf(stdout, "status: unhealthy\nerror: %v\n", h.Err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "status: ok\nresponse: %q\n", h.Response)
+	if imagefy.ParseClassificationResult(h.Response).Class == "" {
+		fmt.Fprintln(stdout, "warning: response has no classification label; check the model supports images")
+	}
+	return 0
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRun_WhyBlocked(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"why-blocked", "https://www.shutterstock.com/a.jpg", "https://example.com/page"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.HasPrefix(out, "verdict: blocked\n") || !strings.Contains(out, `BlockedDomains entry "shutterstock"`) {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRun_WhyBlockedExtraLists(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"why-blocked", "-safe", " OurArchive.org ,", "https://ourarchive.org/a.jpg"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if out := stdout.String(); !strings.HasPrefix(out, "verdict: safe\n") || !strings.Contains(out, "ExtraSafeDomains") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRun_WhyBlockedNoMatch(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	run([]string{"why-blocked", "https://example.com/a.jpg"}, &stdout, &stderr)
+	if want := "verdict: unknown\nno list entry matched\n"; stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{nil, {"frobnicate"}, {"why-blocked"}, {"why-blocked", "a", "b", "c"}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("run(%q) = %d, want 2", args, code)
+		}
+		if stderr.Len() == 0 {
+			t.Errorf("run(%q) wrote no usage", args)
+		}
+	}
+}
+
+func TestRun_Doctor(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"message":{"content":"PHOTO 0.4"}}`))
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"doctor", "-ollama", "llava", "-base-url", srv.URL}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code = %d, stdout = %s, stderr = %s", code, stdout.String(), stderr.String())
+	}
+	if out := stdout.String(); !strings.Contains(out, "classifier: ollama llava\n") || !strings.Contains(out, "status: ok\n") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRun_DoctorUnhealthy(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"doctor", "-ollama", "missing", "-base-url", srv.URL}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if out := stdout.String(); !strings.Contains(out, "status: unhealthy\n") || !strings.Contains(out, "model not found") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRun_DoctorUsage(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"doctor", "-ollama", "a", "-openai", "b"}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code = %d, want 2 for two adapters", code)
+	}
+}
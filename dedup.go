@@ -8,6 +8,7 @@ import (
 	_ "image/jpeg"
 	_ "image/png"
 	"sync"
+	"time"
 
 	"github.com/corona10/goimagehash"
 	_ "golang.org/x/image/webp"
@@ -20,8 +21,36 @@ const dedupThreshold = 10
 // dedupFilter is a per-search-call deduplication filter based on perceptual hashing.
 // It is safe for concurrent use.
 type dedupFilter struct {
-	mu     sync.Mutex
-	hashes []*goimagehash.ImageHash
+	mu       sync.Mutex
+	hashes   []*goimagehash.ImageHash
+	vectors  [][]float32     // image embeddings, for semantic dedup (Config.Embedder)
+	prefixes map[string]bool // probe prefix fingerprints (Config.PrefixDedupBytes)
+}
+
+// seenPrefix reports whether an image with the probe fingerprint prefix has
+// already passed the dedup stage. An empty prefix is never seen.
+func (d *dedupFilter) seenPrefix(prefix string) bool {
+	if prefix == "" {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.prefixes[prefix]
+}
+
+// addPrefix records the probe fingerprint of an image that passed the dedup
+// stage. Recording only then, not at probe time, keeps a copy whose host
+// fails the download from shadowing a working mirror.
+func (d *dedupFilter) addPrefix(prefix string) {
+	if prefix == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.prefixes == nil {
+		d.prefixes = make(map[string]bool)
+	}
+	d.prefixes[prefix] = true
 }
 
 // isDuplicate returns true if img is perceptually identical to a previously seen
@@ -33,7 +62,11 @@ func (d *dedupFilter) isDuplicate(img image.Image) bool {
 		// Graceful degradation: unable to hash → accept the image.
 		return false
 	}
+	return d.isDuplicateHash(hash)
+}
 
+// isDuplicateHash is isDuplicate for an already computed dHash.
+func (d *dedupFilter) isDuplicateHash(hash *goimagehash.ImageHash) bool {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -50,19 +83,23 @@ func (d *dedupFilter) isDuplicate(img image.Image) bool {
 
 // downloadForValidation fetches the image and returns raw bytes, MIME type, and decoded image.
 // Raw bytes are used for metadata extraction and pre-downloaded classification;
-// decoded image is used for perceptual dedup.
+// decoded image is used for perceptual dedup. A progressive JPEG cut at the
+// download cap is completed or replaced by thumbnail (see completeProgressive).
 // Returns (nil, "", nil) on any recoverable failure for graceful degradation.
-func (cfg *Config) downloadForValidation(ctx context.Context, url string) ([]byte, string, image.Image) {
-	result, err := cfg.Download(ctx, url, DownloadOpts{})
+func (cfg *Config) downloadForValidation(ctx context.Context, url, thumbnail, referer string) ([]byte, string, image.Image, time.Time) {
+	opts := DownloadOpts{Referer: referer}
+	result, err := cfg.Download(ctx, url, opts)
 	if err != nil || result == nil {
-		return nil, "", nil
+		return nil, "", nil, time.Time{}
 	}
+	result = cfg.completeProgressive(ctx, result, url, thumbnail, opts)
+	modified := result.modifiedAt(time.Now())
 
 	img, _, err := image.Decode(bytes.NewReader(result.Data))
 	if err != nil {
 		// Raw bytes available for metadata even if image decode fails.
-		return result.Data, result.MIMEType, nil
+		return result.Data, result.MIMEType, nil, modified
 	}
 
-	return result.Data, result.MIMEType, img
+	return result.Data, result.MIMEType, img, modified
 }
@@ -0,0 +1,35 @@
+package imagefy
+
+import "log/slog"
+
+// DegradationPolicy decides what happens to a candidate when a validation
+// check fails to run rather than deciding: the full download fails, the
+// downloaded bytes don't decode for dedup, the reverse image search errors,
+// or the Classifier returns an error. Policy rejections, budgets, and
+// context deadlines are not degradations.
+type DegradationPolicy string
+
+// Degradation policies.
+const (
+	// DegradeAccept treats a failed check as passed (the default).
+	DegradeAccept DegradationPolicy = "accept"
+	// DegradeReject rejects the candidate at the failed stage, with
+	// ReasonDownloadFailed for downloads and ReasonCheckFailed otherwise.
+	DegradeReject DegradationPolicy = "reject"
+	// DegradeMarkUnknown accepts the candidate but sets its License to
+	// LicenseUnknown and ImageCandidate.Degraded to the first failed stage,
+	// so callers can route it to human review.
+	DegradeMarkUnknown DegradationPolicy = "mark_unknown"
+)
+
+// degrade records that the check at stage failed and applies
+// cfg.DegradationPolicy: it returns reason under DegradeReject and "" (carry
+// on) otherwise.
+func (cfg *Config) degrade(stage Stage, reason RejectReason, degraded *[]Stage) RejectReason {
+	*degraded = append(*degraded, stage)
+	slog.Debug("imagefy: check failed, degrading", "stage", stage, "policy", cfg.DegradationPolicy)
+	if cfg.DegradationPolicy == DegradeReject {
+		return reason
+	}
+	return ""
+}
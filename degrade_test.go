@@ -0,0 +1,124 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestDegradationPolicy_ClassifierError(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	tests := []struct {
+		policy      DegradationPolicy
+		wantResults int
+		wantReason  RejectReason
+		wantLicense ImageLicense
+		wantMarked  Stage
+	}{
+		{"", 1, "", LicenseUnknown, ""},
+		{DegradeAccept, 1, "", LicenseUnknown, ""},
+		{DegradeReject, 0, ReasonCheckFailed, LicenseUnknown, ""},
+		{DegradeMarkUnknown, 1, "", LicenseUnknown, StageVision},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			t.Parallel()
+			var (
+				mu     sync.Mutex
+				events []CandidateEvent
+			)
+			record := func(e CandidateEvent) {
+				mu.Lock()
+				events = append(events, e)
+				mu.Unlock()
+			}
+			cfg := &Config{
+				HTTPClient:          srv.Client(),
+				Classifier:          &mockClassifier{err: errors.New("model offline")},
+				DegradationPolicy:   tt.policy,
+				OnCandidateAccepted: record,
+				OnCandidateRejected: record,
+			}
+			cands := []ImageCandidate{{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown}}
+
+			results := cfg.ValidateCandidates(context.Background(), cands, 5)
+			if len(results) != tt.wantResults {
+				t.Fatalf("results = %d, want %d", len(results), tt.wantResults)
+			}
+			if len(results) > 0 && (results[0].License != tt.wantLicense || results[0].Degraded != tt.wantMarked) {
+				t.Errorf("result = %+v, want License %v, Degraded %q", results[0], tt.wantLicense, tt.wantMarked)
+			}
+			if len(events) != 1 {
+				t.Fatalf("events = %d, want 1", len(events))
+			}
+			e := events[0]
+			if e.Reason != tt.wantReason || e.Stage != StageVision || len(e.Degraded) != 1 || e.Degraded[0] != StageVision {
+				t.Errorf("event = {Stage:%q Reason:%q Degraded:%v}, want {vision %q [vision]}", e.Stage, e.Reason, e.Degraded, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDegradationPolicy_ReverseError(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	ox := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer ox.Close()
+
+	cfg := &Config{HTTPClient: srv.Client(), OxBrowserURL: ox.URL, DegradationPolicy: DegradeReject}
+	cfg.defaults()
+	cand := ImageCandidate{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown}
+	stage, reason, degraded := cfg.validateOne(context.Background(), cand, newSearchState(), nil)
+	if stage != StageReverse || reason != ReasonCheckFailed || len(degraded) != 1 {
+		t.Errorf("validateOne = (%q, %q, %v), want (reverse, check_failed, [reverse])", stage, reason, degraded)
+	}
+}
+
+func TestDegradationPolicy_NoFailureNoMark(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	cfg := &Config{HTTPClient: srv.Client(), Classifier: &mockClassifier{response: "PHOTO 0.9"}, DegradationPolicy: DegradeMarkUnknown}
+	cfg.defaults()
+	cand := ImageCandidate{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown}
+	if _, reason, degraded := cfg.validateOne(context.Background(), cand, newSearchState(), nil); reason != "" || degraded != nil {
+		t.Errorf("validateOne = (%q, %v), want accepted without degradations", reason, degraded)
+	}
+}
+
+func TestIsRealPhoto_DegradeReject(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	failing := &mockClassifier{err: errors.New("model offline")}
+	if !(&Config{HTTPClient: srv.Client(), Classifier: failing}).IsRealPhoto(context.Background(), srv.URL+"/a.jpg") {
+		t.Error("IsRealPhoto = false on classifier error, want true by default")
+	}
+	cfg := &Config{HTTPClient: srv.Client(), Classifier: failing, DegradationPolicy: DegradeReject}
+	if cfg.IsRealPhoto(context.Background(), srv.URL+"/a.jpg") {
+		t.Error("IsRealPhoto = true on classifier error under DegradeReject")
+	}
+}
+
+func TestClassifyImageFull_FailureNotCached(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	cache := &mockCache{store: make(map[string]any)}
+	cls := &mockClassifier{err: errors.New("model offline")}
+	cfg := &Config{HTTPClient: srv.Client(), Classifier: cls, Cache: cache}
+	cfg.ClassifyImageFull(context.Background(), srv.URL+"/a.jpg")
+
+	cls.err, cls.response = nil, "STOCK 0.9"
+	if got := cfg.ClassifyImageFull(context.Background(), srv.URL+"/a.jpg"); got.Class != ClassStock {
+		t.Errorf("Class = %q after recovery, want STOCK (failure must not be cached)", got.Class)
+	}
+}
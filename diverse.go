@@ -0,0 +1,182 @@
+package imagefy
+
+import (
+	"context"
+	"image"
+	"sync"
+
+	"github.com/corona10/goimagehash"
+)
+
+const (
+	// diversePoolFactor is how many candidates SearchImagesDiverse validates
+	// per requested image, so there is something to choose from.
+	diversePoolFactor = 3
+	// diverseMaxPool caps the validated pool regardless of n.
+	diverseMaxPool = 30
+
+	// paletteLevels is the number of levels per RGB channel in a palette
+	// histogram (4×4×4 = 64 bins).
+	paletteLevels = 4
+	// paletteSamples is the approximate number of pixels sampled per axis.
+	paletteSamples = 64
+
+	// unknownDistance is the diversity distance assumed when either image
+	// could not be decoded.
+	unknownDistance = 0.5
+)
+
+// imageFeatures are the visual features used to measure diversity.
+type imageFeatures struct {
+	hash    *goimagehash.ImageHash // dHash; nil if hashing failed
+	palette []float64              // normalized RGB histogram (sums to 1)
+}
+
+// featureStore records imageFeatures of validated images, keyed by ImgURL.
+// A nil store records nothing.
+type featureStore struct {
+	mu sync.Mutex
+	m  map[string]imageFeatures
+}
+
+func (f *featureStore) record(imgURL string, img image.Image) {
+	if f == nil || img == nil {
+		return
+	}
+	feat := extractFeatures(img)
+	f.mu.Lock()
+	f.m[imgURL] = feat
+	f.mu.Unlock()
+}
+
+func (f *featureStore) get(imgURL string) (imageFeatures, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	feat, ok := f.m[imgURL]
+	return feat, ok
+}
+
+// SearchImagesDiverse returns up to n validated images chosen to be visually
+// different from each other, for galleries where several near-identical shots
+// are useless. It validates a larger pool (3×n, at most 30) and then greedily
+// picks the candidate farthest from everything already picked, combining
+// perceptual-hash distance and color palette distance. The first result is
+// the same image SearchImages would rank first.
+func (cfg *Config) SearchImagesDiverse(ctx context.Context, query string, n int) []ImageCandidate {
+	cfg = cfg.orZero()
+	if n <= 0 {
+		return nil
+	}
+	st := newSearchState()
+	st.features = &featureStore{m: make(map[string]imageFeatures)}
+
+	pool := cfg.searchImages(ctx, query, min(n*diversePoolFactor, max(n, diverseMaxPool)), SearchOpts{}, st)
+	return selectDiverse(pool, st.features, n)
+}
+
+// selectDiverse picks n candidates from pool by greedy max-min distance.
+// pool[0] is always picked first; ties keep pool order.
+func selectDiverse(pool []ImageCandidate, features *featureStore, n int) []ImageCandidate {
+	if len(pool) <= n {
+		return pool
+	}
+
+	feats := make([]imageFeatures, len(pool))
+	known := make([]bool, len(pool))
+	for i, c := range pool {
+		feats[i], known[i] = features.get(c.ImgURL)
+	}
+
+	// minDist[i] is the distance from pool[i] to its nearest picked image.
+	minDist := make([]float64, len(pool))
+	picked := make([]bool, len(pool))
+	out := make([]ImageCandidate, 0, n)
+
+	next := 0
+	for len(out) < n {
+		picked[next] = true
+		out = append(out, pool[next])
+
+		best, bestDist := -1, -1.0
+		for i := range pool {
+			if picked[i] {
+				continue
+			}
+			d := unknownDistance
+			if known[i] && known[next] {
+				d = featureDistance(feats[i], feats[next])
+			}
+			if len(out) == 1 || d < minDist[i] {
+				minDist[i] = d
+			}
+			if minDist[i] > bestDist {
+				best, bestDist = i, minDist[i]
+			}
+		}
+		if best < 0 {
+			break
+		}
+		next = best
+	}
+	return out
+}
+
+// extractFeatures computes the dHash and a coarse color palette of img.
+func extractFeatures(img image.Image) imageFeatures {
+	var feat imageFeatures
+	if h, err := goimagehash.DifferenceHash(img); err == nil {
+		feat.hash = h
+	}
+
+	b := img.Bounds()
+	stepX := max(1, b.Dx()/paletteSamples)
+	stepY := max(1, b.Dy()/paletteSamples)
+	hist := make([]float64, paletteLevels*paletteLevels*paletteLevels)
+	total := 0.0
+	for y := b.Min.Y; y < b.Max.Y; y += stepY {
+		for x := b.Min.X; x < b.Max.X; x += stepX {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			bin := paletteBin(r)*paletteLevels*paletteLevels + paletteBin(g)*paletteLevels + paletteBin(bl)
+			hist[bin]++
+			total++
+		}
+	}
+	if total > 0 {
+		for i := range hist {
+			hist[i] /= total
+		}
+		feat.palette = hist
+	}
+	return feat
+}
+
+// paletteBin maps a 16-bit color channel to one of paletteLevels buckets.
+func paletteBin(v uint32) int {
+	return int(v>>8) * paletteLevels / 256 //nolint:mnd // 8-bit channel range
+}
+
+// featureDistance returns a 0–1 visual distance: the mean of the normalized
+// dHash Hamming distance and the palette histogram L1 distance.
+func featureDistance(a, b imageFeatures) float64 {
+	hashDist := unknownDistance
+	if a.hash != nil && b.hash != nil {
+		if d, err := a.hash.Distance(b.hash); err == nil {
+			hashDist = float64(d) / 64 //nolint:mnd // dHash is 64 bits
+		}
+	}
+
+	paletteDist := unknownDistance
+	if a.palette != nil && b.palette != nil {
+		sum := 0.0
+		for i := range a.palette {
+			diff := a.palette[i] - b.palette[i]
+			if diff < 0 {
+				diff = -diff
+			}
+			sum += diff
+		}
+		paletteDist = sum / 2 //nolint:mnd // L1 distance of two distributions is at most 2
+	}
+
+	return (hashDist + paletteDist) / 2 //nolint:mnd // mean of two components
+}
@@ -0,0 +1,140 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// makeSolidImage returns an image filled with c.
+func makeSolidImage(width, height int, c color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSelectDiverse_SkipsNearDuplicates(t *testing.T) {
+	t.Parallel()
+
+	images := map[string]image.Image{
+		"g1":    makeGradientImage(200, 100, 0),
+		"g2":    makeGradientImage(200, 100, 8),
+		"check": makeCheckerImage(200, 100, 25),
+		"blue":  makeSolidImage(200, 100, color.RGBA{B: 220, A: 255}),
+	}
+	store := &featureStore{m: make(map[string]imageFeatures)}
+	var pool []ImageCandidate
+	for _, name := range []string{"g1", "g2", "check", "blue"} {
+		store.record(name, images[name])
+		pool = append(pool, ImageCandidate{ImgURL: name})
+	}
+
+	got := selectDiverse(pool, store, 3)
+	if len(got) != 3 {
+		t.Fatalf("selectDiverse returned %d, want 3", len(got))
+	}
+	if got[0].ImgURL != "g1" {
+		t.Errorf("first pick = %s, want g1 (pool order preserved for the top result)", got[0].ImgURL)
+	}
+	for _, c := range got {
+		if c.ImgURL == "g2" {
+			t.Errorf("selectDiverse picked near-duplicate g2: %v", got)
+		}
+	}
+}
+
+func TestSelectDiverse_SmallPoolAndUnknownFeatures(t *testing.T) {
+	t.Parallel()
+
+	store := &featureStore{m: make(map[string]imageFeatures)}
+	pool := []ImageCandidate{{ImgURL: "a"}, {ImgURL: "b"}, {ImgURL: "c"}}
+
+	if got := selectDiverse(pool[:2], store, 3); len(got) != 2 {
+		t.Errorf("pool smaller than n: got %d, want 2", len(got))
+	}
+	// No features at all: falls back to pool order.
+	got := selectDiverse(pool, store, 2)
+	if len(got) != 2 || got[0].ImgURL != "a" || got[1].ImgURL != "b" {
+		t.Errorf("selectDiverse without features = %v, want [a b]", got)
+	}
+}
+
+func TestFeatureDistance(t *testing.T) {
+	t.Parallel()
+
+	red := extractFeatures(makeSolidImage(64, 64, color.RGBA{R: 255, A: 255}))
+	blue := extractFeatures(makeSolidImage(64, 64, color.RGBA{B: 255, A: 255}))
+
+	if d := featureDistance(red, red); d != 0 {
+		t.Errorf("distance(red, red) = %v, want 0", d)
+	}
+	// Same (flat) hash, disjoint palettes.
+	if d := featureDistance(red, blue); d != 0.5 {
+		t.Errorf("distance(red, blue) = %v, want 0.5", d)
+	}
+	if d := featureDistance(imageFeatures{}, imageFeatures{}); d != unknownDistance {
+		t.Errorf("distance of empty features = %v, want %v", d, unknownDistance)
+	}
+}
+
+func TestSearchImagesDiverse(t *testing.T) {
+	t.Parallel()
+
+	bodies := map[string][]byte{
+		"/g1.jpg":    encodeJPEG(t, makeGradientImage(1000, 600, 0)),
+		"/check.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 100)),
+		"/blue.jpg":  encodeJPEG(t, makeSolidImage(1000, 600, color.RGBA{B: 220, A: 255})),
+	}
+	imgSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(imgSrv.Close)
+
+	var results []map[string]string
+	for _, p := range []string{"/g1.jpg", "/check.jpg", "/blue.jpg"} {
+		results = append(results, map[string]string{"img_src": imgSrv.URL + p, "url": imgSrv.URL + "/page", "title": p})
+	}
+	searxSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	t.Cleanup(searxSrv.Close)
+
+	cfg := &Config{SearxngURL: searxSrv.URL, HTTPClient: imgSrv.Client()}
+
+	if got := cfg.SearchImagesDiverse(context.Background(), "query", 0); got != nil {
+		t.Errorf("n=0: got %v, want nil", got)
+	}
+	got := cfg.SearchImagesDiverse(context.Background(), "query", 2)
+	if len(got) != 2 {
+		t.Fatalf("SearchImagesDiverse returned %d results, want 2", len(got))
+	}
+	if got[0].ImgURL == got[1].ImgURL {
+		t.Errorf("duplicate result %s", got[0].ImgURL)
+	}
+}
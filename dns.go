@@ -0,0 +1,254 @@
+package imagefy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	dohBodyLimit = 64 * 1024
+	dohMinTTL    = 30 * time.Second
+	dnsTypeA     = 1
+	dnsTypeAAAA  = 28
+)
+
+// DoHResolver resolves host names over DNS-over-HTTPS (the JSON API served by
+// e.g. Cloudflare's https://cloudflare-dns.com/dns-query and Google's
+// https://dns.google/resolve), for networks where the system resolver is
+// geo-blocked or poisoned. Attach it to Config.DoH to use it for image probes
+// and direct downloads. Answers are cached for their TTL (at least 30s).
+// A DoHResolver is safe for concurrent use.
+type DoHResolver struct {
+	// URL is the DoH JSON endpoint.
+	URL string
+
+	// HTTPClient sends the DoH queries (nil = http.DefaultClient). Its own
+	// dialing uses the system resolver, so URL should be reachable without DoH.
+	HTTPClient *http.Client
+
+	hosts hostCache
+}
+
+// hostCache keeps resolved addresses until they expire, and the transports
+// dialing through them. DoHResolver and the PrefetchDNS system cache each
+// hold their own.
+type hostCache struct {
+	mu         sync.Mutex
+	cache      map[string]dohCacheEntry
+	transports map[*http.Transport]*http.Transport
+}
+
+type dohCacheEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// dohResponse is the subset of the DoH JSON answer format we use.
+type dohResponse struct {
+	Status int `json:"Status"`
+	Answer []struct {
+		Type int    `json:"type"`
+		TTL  int    `json:"TTL"`
+		Data string `json:"data"`
+	} `json:"Answer"`
+}
+
+// LookupHost returns the IPv4 and IPv6 addresses of host. IP literals are
+// returned as-is.
+func (r *DoHResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return r.hosts.lookup(ctx, host, r.resolve)
+}
+
+// lookup returns the cached addresses of host, or resolves and caches them
+// for their TTL (at least dohMinTTL). IP literals are returned as-is.
+func (c *hostCache) lookup(ctx context.Context, host string, resolve func(context.Context, string) ([]string, time.Duration, error)) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	c.mu.Lock()
+	if e, ok := c.cache[host]; ok && time.Now().Before(e.expires) {
+		c.mu.Unlock()
+		return e.addrs, nil
+	}
+	c.mu.Unlock()
+
+	addrs, ttl, err := resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.cache == nil {
+		c.cache = make(map[string]dohCacheEntry)
+	}
+	c.cache[host] = dohCacheEntry{addrs: addrs, expires: time.Now().Add(max(ttl, dohMinTTL))}
+	c.mu.Unlock()
+	return addrs, nil
+}
+
+// resolve looks host up over DoH without the cache, returning its addresses
+// and the shortest answer TTL.
+func (r *DoHResolver) resolve(ctx context.Context, host string) ([]string, time.Duration, error) {
+	var addrs []string
+	var lastErr error
+	ttl := time.Duration(0)
+	for _, qtype := range []int{dnsTypeA, dnsTypeAAAA} {
+		got, t, err := r.query(ctx, host, qtype)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		addrs = append(addrs, got...)
+		if len(got) > 0 && (ttl == 0 || t < ttl) {
+			ttl = t
+		}
+	}
+	if len(addrs) == 0 {
+		if lastErr != nil {
+			return nil, 0, lastErr
+		}
+		return nil, 0, fmt.Errorf("imagefy: doh: no addresses for %s", host)
+	}
+	return addrs, ttl, nil
+}
+
+// query performs one DoH JSON request and returns the answers of qtype.
+func (r *DoHResolver) query(ctx context.Context, host string, qtype int) ([]string, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("imagefy: doh: %w", err)
+	}
+	q := u.Query()
+	q.Set("name", host)
+	q.Set("type", fmt.Sprint(qtype))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/dns-json")
+
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: resolver URL is operator-configured
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("imagefy: doh: status %d", resp.StatusCode)
+	}
+
+	var dr dohResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, dohBodyLimit)).Decode(&dr); err != nil {
+		return nil, 0, fmt.Errorf("imagefy: doh: %w", err)
+	}
+	if dr.Status != 0 {
+		return nil, 0, fmt.Errorf("imagefy: doh: rcode %d for %s", dr.Status, host)
+	}
+
+	var addrs []string
+	ttl := time.Duration(0)
+	for _, a := range dr.Answer {
+		if a.Type != qtype || net.ParseIP(a.Data) == nil {
+			continue // CNAME chain entries and malformed data
+		}
+		addrs = append(addrs, a.Data)
+		if t := time.Duration(a.TTL) * time.Second; ttl == 0 || t < ttl {
+			ttl = t
+		}
+	}
+	return addrs, ttl, nil
+}
+
+// DialContext resolves addr's host with LookupHost and dials each address in
+// turn, returning the first successful connection.
+func (r *DoHResolver) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return dialResolved(ctx, network, addr, r.LookupHost)
+}
+
+// dialResolved resolves addr's host with lookup and dials each address in
+// turn, returning the first successful connection.
+func dialResolved(ctx context.Context, network, addr string, lookup func(context.Context, string) ([]string, error)) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	var errs []error
+	for _, ip := range addrs {
+		conn, err := d.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// wrap returns a copy of rt that dials through the resolver.
+func (r *DoHResolver) wrap(rt http.RoundTripper) http.RoundTripper {
+	return r.hosts.wrap(rt, r.DialContext)
+}
+
+// wrap returns a copy of rt that dials with dial. Only *http.Transport (or
+// nil, meaning http.DefaultTransport) can be wrapped; other RoundTrippers
+// are returned unchanged. Wrapped transports are cached so connection pools
+// are reused across requests.
+func (c *hostCache) wrap(rt http.RoundTripper, dial func(context.Context, string, string) (net.Conn, error)) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		slog.Debug("imagefy: doh: custom RoundTripper, using it unchanged")
+		return rt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.transports[base]; ok {
+		return t
+	}
+	t := base.Clone()
+	t.DialContext = dial
+	if c.transports == nil {
+		c.transports = make(map[*http.Transport]*http.Transport)
+	}
+	c.transports[base] = t
+	return t
+}
+
+// directClient returns cfg.HTTPClient, dialing through cfg.DoH or the
+// PrefetchDNS cache when set. StealthClient is never wrapped: its proxy
+// resolves the image host.
+func (cfg *Config) directClient() *http.Client {
+	r := cfg.resolver()
+	if r == nil {
+		return cfg.HTTPClient
+	}
+	c := *cfg.HTTPClient
+	c.Transport = r.wrap(c.Transport)
+	return &c
+}
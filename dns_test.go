@@ -0,0 +1,117 @@
+package imagefy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newDoHServer answers A queries for every name with 127.0.0.1 and counts queries.
+func newDoHServer(t *testing.T, queries *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		if r.Header.Get("Accept") != "application/dns-json" {
+			http.Error(w, "bad accept", http.StatusBadRequest)
+			return
+		}
+		name := r.URL.Query().Get("name")
+		w.Header().Set("Content-Type", "application/dns-json")
+		if name == "nxdomain.example" {
+			_, _ = w.Write([]byte(`{"Status":3}`))
+			return
+		}
+		if r.URL.Query().Get("type") != "1" {
+			_, _ = w.Write([]byte(`{"Status":0}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Status":0,"Answer":[` +
+			`{"name":"` + name + `","type":5,"TTL":300,"data":"cdn.example."},` +
+			`{"name":"cdn.example","type":1,"TTL":300,"data":"127.0.0.1"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDoHResolver_LookupHostCaches(t *testing.T) {
+	t.Parallel()
+
+	var queries atomic.Int32
+	r := &DoHResolver{URL: newDoHServer(t, &queries).URL + "/dns-query"}
+
+	for range 3 {
+		addrs, err := r.LookupHost(context.Background(), "images.example")
+		if err != nil || len(addrs) != 1 || addrs[0] != "127.0.0.1" {
+			t.Fatalf("LookupHost = %v, %v", addrs, err)
+		}
+	}
+	if n := queries.Load(); n != 2 {
+		t.Errorf("DoH queries = %d, want 2 (A + AAAA, then cached)", n)
+	}
+
+	if addrs, err := r.LookupHost(context.Background(), "10.0.0.1"); err != nil || addrs[0] != "10.0.0.1" {
+		t.Errorf("IP literal: %v, %v", addrs, err)
+	}
+	if _, err := r.LookupHost(context.Background(), "nxdomain.example"); err == nil || !strings.Contains(err.Error(), "rcode 3") {
+		t.Errorf("NXDOMAIN error = %v", err)
+	}
+}
+
+func TestDownload_UsesDoHResolver(t *testing.T) {
+	t.Parallel()
+
+	img := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(img.URL, "http://"))
+	imgURL := "http://images.example:" + port + "/photo.jpg" // resolvable only via DoH
+
+	var queries atomic.Int32
+	cfg := &Config{DoH: &DoHResolver{URL: newDoHServer(t, &queries).URL}}
+
+	res, err := cfg.Download(context.Background(), imgURL, DownloadOpts{})
+	if err != nil || res == nil {
+		t.Fatalf("Download via DoH = %v, %v", res, err)
+	}
+	if !cfg.ValidateImageURL(context.Background(), imgURL) {
+		t.Error("ValidateImageURL via DoH = false, want true")
+	}
+	if queries.Load() == 0 {
+		t.Error("DoH resolver was not queried")
+	}
+}
+
+func TestDownload_HostOverride(t *testing.T) {
+	t.Parallel()
+
+	var gotHost atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost.Store(r.Host)
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(makeJPEG(100, 100))
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &Config{HTTPClient: srv.Client()}
+	if res, _ := cfg.Download(context.Background(), srv.URL+"/a.jpg", DownloadOpts{Host: "cdn.example.com"}); res == nil {
+		t.Fatal("Download returned nil")
+	}
+	if h := gotHost.Load(); h != "cdn.example.com" {
+		t.Errorf("Host header = %v, want cdn.example.com", h)
+	}
+}
+
+func TestDoHResolver_WrapCachesAndSkipsCustomTransports(t *testing.T) {
+	t.Parallel()
+
+	r := &DoHResolver{URL: "http://127.0.0.1:1"}
+	if a, b := r.wrap(nil), r.wrap(nil); a != b {
+		t.Error("wrap(nil) returned different transports, want cached")
+	}
+	custom := roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, nil })
+	if _, ok := r.wrap(custom).(roundTripFunc); !ok {
+		t.Error("wrap(custom) replaced a custom RoundTripper, want it unchanged")
+	}
+}
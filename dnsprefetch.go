@@ -0,0 +1,116 @@
+package imagefy
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// dnsPrefetchTTL is how long system-resolver answers are reused; the
+	// system resolver does not report TTLs.
+	dnsPrefetchTTL = time.Minute
+	// dnsPrefetchConcurrency caps simultaneous prefetch lookups.
+	dnsPrefetchConcurrency = 16
+)
+
+// hostResolver is what direct requests dial through: a DoHResolver or the
+// PrefetchDNS system cache.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	wrap(rt http.RoundTripper) http.RoundTripper
+}
+
+// systemCache is the PrefetchDNS cache used when Config.DoH is nil: system
+// resolver answers kept for dnsPrefetchTTL. Each Config's download pool holds
+// one, with its own lock.
+type systemCache struct {
+	hosts hostCache
+}
+
+// LookupHost returns the cached system-resolver addresses of host.
+func (s *systemCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return s.hosts.lookup(ctx, host, func(ctx context.Context, host string) ([]string, time.Duration, error) {
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		return addrs, dnsPrefetchTTL, err
+	})
+}
+
+func (s *systemCache) wrap(rt http.RoundTripper) http.RoundTripper {
+	return s.hosts.wrap(rt, func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialResolved(ctx, network, addr, s.LookupHost)
+	})
+}
+
+// resolver returns the resolver direct requests dial through: cfg.DoH, the
+// PrefetchDNS cache, or nil for plain system resolution.
+func (cfg *Config) resolver() hostResolver {
+	if cfg.DoH != nil {
+		return cfg.DoH
+	}
+	if !cfg.PrefetchDNS {
+		return nil
+	}
+	return cfg.pool().dns
+}
+
+// prefetchDNS starts resolving the unique image hosts of candidates in the
+// background, so validation dials hit a warm cache. It returns immediately;
+// lookups stop when ctx is done. Blocked candidates are skipped since they
+// are never probed.
+func (cfg *Config) prefetchDNS(ctx context.Context, candidates []ImageCandidate) {
+	if !cfg.PrefetchDNS {
+		return
+	}
+	r := cfg.resolver()
+	hosts := prefetchHosts(candidates)
+	if len(hosts) == 0 {
+		return
+	}
+
+	sem := make(chan struct{}, dnsPrefetchConcurrency)
+	go func() {
+		for _, host := range hosts {
+			if !acquire(ctx, sem) {
+				return
+			}
+			go func() {
+				defer func() { <-sem }()
+				lookupCtx, cancel := context.WithTimeout(ctx, defaultTimeout)
+				defer cancel()
+				if _, err := r.LookupHost(lookupCtx, host); err != nil {
+					slog.Debug("imagefy: dns prefetch failed", "host", host, "error", err)
+				}
+			}()
+		}
+	}()
+}
+
+// prefetchHosts returns the unique host names of the non-blocked candidates'
+// image URLs, in candidate order. IP literals need no lookup and are left out.
+func prefetchHosts(candidates []ImageCandidate) []string {
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, c := range candidates {
+		if c.License == LicenseBlocked {
+			continue
+		}
+		u, err := url.Parse(c.ImgURL)
+		if err != nil {
+			continue
+		}
+		host := u.Hostname()
+		if host == "" || net.ParseIP(host) != nil {
+			continue
+		}
+		host = normalizeHost("", host)
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
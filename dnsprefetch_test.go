@@ -0,0 +1,110 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrefetchHosts(t *testing.T) {
+	t.Parallel()
+
+	got := prefetchHosts([]ImageCandidate{
+		{ImgURL: "https://IMG.Example.com/a.jpg"},
+		{ImgURL: "https://img.example.com/b.jpg"},
+		{ImgURL: "https://stock.example/c.jpg", License: LicenseBlocked},
+		{ImgURL: "http://10.0.0.1/d.jpg"},
+		{ImgURL: "http://[::1]:8080/e.jpg"},
+		{ImgURL: "https://cdn.example.org:8443/f.jpg"},
+		{ImgURL: "not a url"},
+	})
+	want := []string{"img.example.com", "cdn.example.org"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("prefetchHosts = %v, want %v", got, want)
+	}
+}
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPrefetchDNS_WarmsDoHCache(t *testing.T) {
+	t.Parallel()
+
+	var queries atomic.Int32
+	cfg := &Config{DoH: &DoHResolver{URL: newDoHServer(t, &queries).URL + "/dns-query"}, PrefetchDNS: true}
+	cfg.prefetchDNS(context.Background(), []ImageCandidate{
+		{ImgURL: "https://a.example/1.jpg"},
+		{ImgURL: "https://b.example/2.jpg"},
+		{ImgURL: "https://a.example/3.jpg"},
+	})
+	waitFor(t, "prefetch queries", func() bool { return queries.Load() == 4 })
+
+	if _, err := cfg.DoH.LookupHost(context.Background(), "b.example"); err != nil {
+		t.Fatal(err)
+	}
+	if n := queries.Load(); n != 4 {
+		t.Errorf("DoH queries = %d, want 4 (A + AAAA per host, then cached)", n)
+	}
+}
+
+func TestPrefetchDNS_SystemCacheServesDirectRequests(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+	imgURL := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1) + "/a.jpg"
+
+	cfg := &Config{PrefetchDNS: true}
+	cfg.defaults()
+	cfg.prefetchDNS(context.Background(), []ImageCandidate{{ImgURL: imgURL}})
+	waitFor(t, "localhost in the prefetch cache", func() bool {
+		c := &cfg.pool().dns.hosts
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.cache["localhost"].addrs) > 0
+	})
+
+	c := cfg.directClient()
+	if c.Transport == cfg.HTTPClient.Transport {
+		t.Fatal("directClient did not dial through the prefetch cache")
+	}
+	resp, err := c.Get(imgURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if cfg.derive().resolver() != cfg.resolver() {
+		t.Error("per-search copies do not share the prefetch cache")
+	}
+	if other := (&Config{PrefetchDNS: true}); other.resolver() == cfg.resolver() {
+		t.Error("unrelated Configs share a prefetch cache")
+	}
+}
+
+func TestPrefetchDNS_Off(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+	cfg.defaults()
+	cfg.prefetchDNS(context.Background(), []ImageCandidate{{ImgURL: "https://img.example.com/a.jpg"}})
+	if cfg.resolver() != nil {
+		t.Error("resolver created without PrefetchDNS")
+	}
+	if cfg.directClient() != cfg.HTTPClient {
+		t.Error("directClient wrapped the client without PrefetchDNS or DoH")
+	}
+}
@@ -0,0 +1,139 @@
+package imagefy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// domainsJSON is the curated license data: blocked and safe domains, agency
+// CDN hosts, stock URL patterns, and stock metadata keywords, each entry with a category and
+// an optional note. Edit data/domains.json rather than the Go lists, then run
+// go generate to validate every entry and check for shadowed domains.
+//
+//go:generate go test -run ^TestDomain -count=1 .
+//go:embed data/domains.json
+var domainsJSON []byte
+
+// DomainEntry is one curated list entry.
+type DomainEntry struct {
+	Entry    string `json:"entry"`    // lower-case substring matched against hosts, paths, or metadata
+	Category string `json:"category"` // e.g. "global_stock", "regional_stock", "free_stock"; see BlockCategory
+	Note     string `json:"note,omitempty"`
+}
+
+// domainData is the decoded form of data/domains.json.
+type domainData struct {
+	BlockedDomains        []DomainEntry `json:"blocked_domains"`
+	AgencyCDNs            []DomainEntry `json:"agency_cdns"`
+	BlockedURLPatterns    []DomainEntry `json:"blocked_url_patterns"`
+	SafeDomains           []DomainEntry `json:"safe_domains"`
+	StockMetadataKeywords []DomainEntry `json:"stock_metadata_keywords"`
+}
+
+// builtinDomains is parsed once at init; invalid embedded data is a build
+// defect, so it panics like regexp.MustCompile.
+var builtinDomains = mustParseDomainData(domainsJSON)
+
+// BuiltinDomainEntries returns the curated entries behind BlockedDomains,
+// AgencyCDNs, BlockedURLPatterns, SafeDomains, and the stock metadata
+// keywords, keyed by list name ("blocked_domains", "agency_cdns",
+// "blocked_url_patterns", "safe_domains", "stock_metadata_keywords"). The
+// slices are copies.
+func BuiltinDomainEntries() map[string][]DomainEntry {
+	return map[string][]DomainEntry{
+		"blocked_domains":         append([]DomainEntry(nil), builtinDomains.BlockedDomains...),
+		"agency_cdns":             append([]DomainEntry(nil), builtinDomains.AgencyCDNs...),
+		"blocked_url_patterns":    append([]DomainEntry(nil), builtinDomains.BlockedURLPatterns...),
+		"safe_domains":            append([]DomainEntry(nil), builtinDomains.SafeDomains...),
+		"stock_metadata_keywords": append([]DomainEntry(nil), builtinDomains.StockMetadataKeywords...),
+	}
+}
+
+func mustParseDomainData(data []byte) domainData {
+	d, err := parseDomainData(data)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// parseDomainData decodes and validates domain data: entries must be
+// non-empty, lower-case, trimmed, unique within their list, and have a
+// category; URL patterns must start with "/"; agency CDN entries must be
+// host names not already covered by a blocked domain; no safe domain may
+// contain a blocked one or fall under an agency CDN, which would always
+// override it.
+func parseDomainData(data []byte) (domainData, error) {
+	var d domainData
+	if err := json.Unmarshal(data, &d); err != nil {
+		return d, fmt.Errorf("imagefy: decode domain data: %w", err)
+	}
+
+	lists := []struct {
+		name    string
+		entries []DomainEntry
+	}{
+		{"blocked_domains", d.BlockedDomains},
+		{"agency_cdns", d.AgencyCDNs},
+		{"blocked_url_patterns", d.BlockedURLPatterns},
+		{"safe_domains", d.SafeDomains},
+		{"stock_metadata_keywords", d.StockMetadataKeywords},
+	}
+	for _, l := range lists {
+		if len(l.entries) == 0 {
+			return d, fmt.Errorf("imagefy: domain data: %s is empty", l.name)
+		}
+		seen := make(map[string]bool, len(l.entries))
+		for _, e := range l.entries {
+			switch {
+			case e.Entry == "" || e.Entry != strings.TrimSpace(e.Entry) || e.Entry != strings.ToLower(e.Entry):
+				return d, fmt.Errorf("imagefy: domain data: %s entry %q must be non-empty, trimmed, and lower-case", l.name, e.Entry)
+			case e.Category == "":
+				return d, fmt.Errorf("imagefy: domain data: %s entry %q has no category", l.name, e.Entry)
+			case seen[e.Entry]:
+				return d, fmt.Errorf("imagefy: domain data: %s entry %q is duplicated", l.name, e.Entry)
+			case l.name == "blocked_url_patterns" && !strings.HasPrefix(e.Entry, "/"):
+				return d, fmt.Errorf("imagefy: domain data: URL pattern %q must start with /", e.Entry)
+			case l.name == "agency_cdns" && (!strings.Contains(e.Entry, ".") || strings.HasPrefix(e.Entry, ".") || strings.ContainsAny(e.Entry, "/:")):
+				return d, fmt.Errorf("imagefy: domain data: agency CDN %q must be a host name such as \"ftcdn.net\"", e.Entry)
+			}
+			seen[e.Entry] = true
+		}
+	}
+
+	for _, c := range d.AgencyCDNs {
+		for _, b := range d.BlockedDomains {
+			if strings.Contains(c.Entry, b.Entry) {
+				return d, fmt.Errorf("imagefy: domain data: agency CDN %q is already covered by blocked domain %q", c.Entry, b.Entry)
+			}
+		}
+		for _, s := range d.SafeDomains {
+			if s.Entry == c.Entry || strings.HasSuffix(s.Entry, "."+c.Entry) {
+				return d, fmt.Errorf("imagefy: domain data: safe domain %q is shadowed by agency CDN %q", s.Entry, c.Entry)
+			}
+		}
+	}
+
+	for _, s := range d.SafeDomains {
+		for _, b := range d.BlockedDomains {
+			switch {
+			case s.Entry == b.Entry:
+				return d, fmt.Errorf("imagefy: domain data: %q is both blocked and safe", s.Entry)
+			case strings.Contains(s.Entry, b.Entry):
+				return d, fmt.Errorf("imagefy: domain data: safe domain %q is shadowed by blocked domain %q", s.Entry, b.Entry)
+			}
+		}
+	}
+	return d, nil
+}
+
+// entryStrings returns the Entry fields of entries.
+func entryStrings(entries []DomainEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Entry
+	}
+	return out
+}
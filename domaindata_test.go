@@ -0,0 +1,180 @@
+package imagefy
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDomainData_EmbeddedIsValidAndCanonical(t *testing.T) {
+	t.Parallel()
+
+	d, err := parseDomainData(domainsJSON)
+	if err != nil {
+		t.Fatalf("embedded data/domains.json is invalid: %v", err)
+	}
+
+	// The file must stay in the canonical layout so diffs of curated lists
+	// stay reviewable: two-space indent, no HTML escaping, trailing newline.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), domainsJSON) {
+		t.Error("data/domains.json is not canonically formatted (2-space indent, field order entry/category/note)")
+	}
+}
+
+func TestDomainData_ListsLoaded(t *testing.T) {
+	t.Parallel()
+
+	entries := BuiltinDomainEntries()
+	for name, list := range map[string][]string{
+		"blocked_domains":         BlockedDomains,
+		"agency_cdns":             AgencyCDNs,
+		"blocked_url_patterns":    BlockedURLPatterns,
+		"safe_domains":            SafeDomains,
+		"stock_metadata_keywords": stockMetadataKeywords,
+	} {
+		if len(list) == 0 || len(list) != len(entries[name]) {
+			t.Errorf("%s: %d strings, %d entries", name, len(list), len(entries[name]))
+		}
+	}
+
+	// Copies: mutating the result must not affect the built-in data.
+	entries["blocked_domains"][0].Entry = "mutated"
+	if BuiltinDomainEntries()["blocked_domains"][0].Entry == "mutated" {
+		t.Error("BuiltinDomainEntries returned shared slices")
+	}
+}
+
+func TestDomainData_RegionalCoverage(t *testing.T) {
+	t.Parallel()
+
+	blocked := []string{
+		"https://www.gettyimages.co.jp/detail/1",
+		"https://www.wireimage.com/photo/1",
+		"https://pixta.jp/photo/1",
+		"https://www.aflo.com/ja/contents/1",
+		"https://www.vcg.com/creative/1",
+		"https://www.quanjing.com/imgbuy/1",
+		"https://www.imagebroker.com/de/1",
+		"https://www.westend61.de/en/1",
+	}
+	for _, u := range blocked {
+		if got := CheckLicense(u, ""); got != LicenseBlocked {
+			t.Errorf("CheckLicense(%q) = %v, want blocked", u, got)
+		}
+	}
+
+	notBlocked := []string{
+		"https://waflo.example/photo.jpg",
+		"https://vcgames.example/photo.jpg",
+		"https://images.unsplash.com/photo.jpg",
+	}
+	for _, u := range notBlocked {
+		if got := CheckLicense(u, ""); got == LicenseBlocked {
+			t.Errorf("CheckLicense(%q) = blocked, want not blocked", u)
+		}
+	}
+
+	if !IsStockByMetadata(&ImageMetadata{IPTCCredit: "PIXTA"}) {
+		t.Error("IsStockByMetadata(PIXTA credit) = false, want true")
+	}
+}
+
+func TestDomainData_AgencyCDNs(t *testing.T) {
+	t.Parallel()
+
+	blocked := []string{
+		"https://as1.ftcdn.net/v2/jpg/01/23/45/67/1000_F_1234.jpg",
+		"https://stock.adobe.com/images/kazan/1234",
+		"https://ak.picdn.net/shutterstock/videos/1234/thumb/1.jpg",
+		"https://t.pimg.jp/012/345/678/1/12345678.jpg",
+		"https://vcg01.cfp.cn/creative/vcg/800/new/VCG41N1234.jpg",
+		"https://mpic.tiankong.com/abc/def/abcdef.jpg",
+		"https://lori.ru/1234567",
+		// Agency CDNs named after the agency are covered by BlockedDomains.
+		"https://image.shutterstock.com/image-photo/kazan-260nw-1234.jpg",
+		"https://media.gettyimages.com/id/1234/photo/kazan.jpg",
+		"https://thumbs.dreamstime.com/b/kazan-1234.jpg",
+	}
+	for _, u := range blocked {
+		if got := CheckLicense(u, ""); got != LicenseBlocked {
+			t.Errorf("CheckLicense(%q) = %v, want blocked", u, got)
+		}
+	}
+
+	// Suffix match: the CDN name inside another host does not count.
+	for _, u := range []string{"https://myftcdn.net/a.jpg", "https://ftcdn.net.example/a.jpg", "https://adobe.com/a.jpg"} {
+		if got := CheckLicense(u, ""); got == LicenseBlocked {
+			t.Errorf("CheckLicense(%q) = blocked, want not blocked", u)
+		}
+	}
+
+	cfg := &Config{BlockPolicy: map[BlockCategory]ImageLicense{BlockFreemium: LicenseUnknown}}
+	if got := checkLicense("https://cdn-icons-png.flaticon.com/512/1.png", "", nil, nil, cfg.BlockPolicy); got != LicenseUnknown {
+		t.Errorf("freemium CDN under BlockPolicy = %v, want unknown", got)
+	}
+	signals := ExplainLicense("https://t3.ftcdn.net/jpg/1.jpg", "", nil)
+	if len(signals) != 1 || !strings.Contains(signals[0].Detail, `AgencyCDNs entry "ftcdn.net"`) {
+		t.Errorf("ExplainLicense = %+v, want one AgencyCDNs signal", signals)
+	}
+}
+
+func TestParseDomainData_RejectsInvalidEntries(t *testing.T) {
+	t.Parallel()
+
+	valid := func() map[string][]DomainEntry {
+		return map[string][]DomainEntry{
+			"blocked_domains":         {{Entry: "stockco", Category: "global_stock"}},
+			"agency_cdns":             {{Entry: "stkcdn.net", Category: "global_stock"}},
+			"blocked_url_patterns":    {{Entry: "/stock-photo", Category: "stock_page"}},
+			"safe_domains":            {{Entry: "freeco", Category: "free_stock"}},
+			"stock_metadata_keywords": {{Entry: "stockco", Category: "global_stock"}},
+		}
+	}
+	tests := []struct {
+		name    string
+		mutate  func(map[string][]DomainEntry)
+		wantErr string
+	}{
+		{"valid", func(map[string][]DomainEntry) {}, ""},
+		{"upper case", func(m map[string][]DomainEntry) { m["blocked_domains"][0].Entry = "StockCo" }, "lower-case"},
+		{"padded", func(m map[string][]DomainEntry) { m["safe_domains"][0].Entry = " freeco" }, "trimmed"},
+		{"no category", func(m map[string][]DomainEntry) { m["safe_domains"][0].Category = "" }, "no category"},
+		{"duplicate", func(m map[string][]DomainEntry) {
+			m["blocked_domains"] = append(m["blocked_domains"], m["blocked_domains"][0])
+		}, "duplicated"},
+		{"pattern without slash", func(m map[string][]DomainEntry) { m["blocked_url_patterns"][0].Entry = "stock-photo" }, "must start with /"},
+		{"blocked and safe", func(m map[string][]DomainEntry) { m["safe_domains"][0].Entry = "stockco" }, "both blocked and safe"},
+		{"safe shadowed", func(m map[string][]DomainEntry) { m["safe_domains"][0].Entry = "free.stockco.net" }, "shadowed"},
+		{"CDN not a host", func(m map[string][]DomainEntry) { m["agency_cdns"][0].Entry = "stkcdn" }, "must be a host name"},
+		{"CDN covered", func(m map[string][]DomainEntry) { m["agency_cdns"][0].Entry = "img.stockco.net" }, "already covered"},
+		{"safe under CDN", func(m map[string][]DomainEntry) { m["safe_domains"][0].Entry = "free.stkcdn.net" }, "shadowed by agency CDN"},
+		{"empty list", func(m map[string][]DomainEntry) { m["stock_metadata_keywords"] = nil }, "is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := valid()
+			tt.mutate(m)
+			data, err := json.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = parseDomainData(data)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package imagefy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// namedList is a domain list with the name ExplainLicense reports for it.
+type namedList struct {
+	name    string
+	entries []string
+	builtin bool
+}
+
+// ValidateDomainLists checks the domain lists CheckLicenseWith consults —
+// the built-in BlockedDomains and SafeDomains plus cfg's ExtraBlockedDomains,
+// ExtraSafeDomains, and Subscription lists — for entries that can never take
+// effect:
+//   - an empty, untrimmed, or upper-case entry, which matches no host (hosts
+//     are compared lower-cased);
+//   - a safe entry containing a blocked entry, shadowed because blocked
+//     matches win and every host containing the safe entry also contains the
+//     blocked one;
+//   - an extra entry duplicating or containing an entry of the same verdict,
+//     which is redundant.
+//
+// cfg may be nil to check only the built-in lists. All problems are joined
+// into one error; nil means the lists are consistent. Call it from CI on
+// downstream config files, or at startup via Config.Validate.
+func ValidateDomainLists(cfg *Config) error {
+	cfg = cfg.orZero()
+	remote := cfg.Subscription.Lists()
+	blocked := []namedList{
+		{"BlockedDomains", BlockedDomains, true},
+		{"ExtraBlockedDomains", cfg.ExtraBlockedDomains, false},
+		{"RemoteBlockedDomains", remote.Blocked, false},
+	}
+	safe := []namedList{
+		{"SafeDomains", SafeDomains, true},
+		{"ExtraSafeDomains", cfg.ExtraSafeDomains, false},
+		{"RemoteSafeDomains", remote.Safe, false},
+	}
+
+	var errs []error
+	for _, l := range append(append([]namedList(nil), blocked...), safe...) {
+		for _, e := range l.entries {
+			if e == "" || e != strings.TrimSpace(e) || e != strings.ToLower(e) {
+				errs = append(errs, fmt.Errorf("imagefy: %s entry %q never matches: entries must be non-empty, trimmed, and lower-case", l.name, e))
+			}
+		}
+	}
+	for _, l := range safe {
+		for _, s := range l.entries {
+			if b, name := containedEntry(s, blocked); b != "" {
+				errs = append(errs, fmt.Errorf("imagefy: %s entry %q is shadowed by %s entry %q", l.name, s, name, b))
+			}
+		}
+	}
+	errs = append(errs, redundantEntries(blocked)...)
+	errs = append(errs, redundantEntries(safe)...)
+	return errors.Join(errs...)
+}
+
+// containedEntry returns the first non-empty entry of lists that is a
+// substring of s, and the name of its list.
+func containedEntry(s string, lists []namedList) (entry, name string) {
+	if s == "" {
+		return "", ""
+	}
+	for _, l := range lists {
+		for _, e := range l.entries {
+			if e != "" && strings.Contains(s, e) {
+				return e, l.name
+			}
+		}
+	}
+	return "", ""
+}
+
+// redundantEntries reports extra entries already covered by an earlier entry
+// of the same verdict: any built-in entry, or an extra entry listed before it.
+// Overlaps among the built-in entries themselves are allowed.
+func redundantEntries(lists []namedList) []error {
+	var errs []error
+	var earlier []namedList
+	for _, l := range lists {
+		if !l.builtin {
+			for i, e := range l.entries {
+				c, name := containedEntry(e, earlier)
+				if c == "" {
+					c, name = containedEntry(e, []namedList{{name: l.name, entries: l.entries[:i]}})
+				}
+				if c != "" {
+					if c == e {
+						errs = append(errs, fmt.Errorf("imagefy: %s entry %q duplicates %s", l.name, e, name))
+					} else {
+						errs = append(errs, fmt.Errorf("imagefy: %s entry %q is redundant with %s entry %q", l.name, e, name, c))
+					}
+				}
+			}
+		}
+		earlier = append(earlier, l)
+	}
+	return errs
+}
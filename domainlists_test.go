@@ -0,0 +1,63 @@
+package imagefy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDomainLists_BuiltinsAreConsistent(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateDomainLists(nil); err != nil {
+		t.Errorf("ValidateDomainLists(nil) = %v, want nil", err)
+	}
+	cfg := &Config{ExtraBlockedDomains: []string{"stockco.example"}, ExtraSafeDomains: []string{"freeco.example"}}
+	if err := ValidateDomainLists(cfg); err != nil {
+		t.Errorf("ValidateDomainLists(valid extras) = %v, want nil", err)
+	}
+}
+
+func TestValidateDomainLists_ReportsIneffectiveEntries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  *Config
+		want string
+	}{
+		{"extra safe shadowed by built-in blocked", &Config{ExtraSafeDomains: []string{"free.shutterstock.com"}},
+			`ExtraSafeDomains entry "free.shutterstock.com" is shadowed by BlockedDomains entry "shutterstock"`},
+		{"extra safe shadowed by extra blocked", &Config{ExtraBlockedDomains: []string{"partner"}, ExtraSafeDomains: []string{"cdn.partner.example"}},
+			`ExtraSafeDomains entry "cdn.partner.example" is shadowed by ExtraBlockedDomains entry "partner"`},
+		{"upper case", &Config{ExtraBlockedDomains: []string{"StockCo.example"}}, "never matches"},
+		{"empty", &Config{ExtraSafeDomains: []string{""}}, "never matches"},
+		{"duplicate of built-in", &Config{ExtraBlockedDomains: []string{"gettyimages"}},
+			`ExtraBlockedDomains entry "gettyimages" duplicates BlockedDomains`},
+		{"covered by built-in", &Config{ExtraSafeDomains: []string{"images.unsplash.com"}},
+			`ExtraSafeDomains entry "images.unsplash.com" is redundant with SafeDomains entry "unsplash"`},
+		{"duplicate within list", &Config{ExtraBlockedDomains: []string{"stockco.example", "stockco.example"}},
+			`ExtraBlockedDomains entry "stockco.example" duplicates ExtraBlockedDomains`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateDomainLists(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ValidateDomainLists() = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsShadowedDomains(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Classifier:       &mockClassifier{},
+		SearxngURL:       "http://searx.local",
+		ExtraSafeDomains: []string{"free.shutterstock.com"},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "shadowed") {
+		t.Errorf("Validate() = %v, want the shadowed safe domain reported", err)
+	}
+}
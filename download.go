@@ -3,7 +3,9 @@ package imagefy
 import (
 	"context"
 	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -13,7 +15,18 @@ type DownloadOpts struct {
 	MaxBytes  int64         // max response body size (default: 200KB)
 	MinBytes  int           // reject if smaller (default: 0)
 	Timeout   time.Duration // per-request timeout (default: 10s)
-	UserAgent string        // override config user agent
+	UserAgent string        // override config user agent (and the host profile's)
+	Host      string        // override the Host header (e.g. when imageURL names an origin IP or mirror)
+	Referer   string        // Referer header, e.g. the source page for hotlink-protected hosts (default: none)
+
+	// HedgeDelay starts a second, identical GET if the first has not finished
+	// after this delay; whichever succeeds first wins and the other is
+	// canceled. Zero uses Config.HedgeDelay; negative disables hedging.
+	HedgeDelay time.Duration
+
+	// Client selects the HTTP client: ClientAuto (Config.StealthPolicy),
+	// ClientStealth (StealthClient only), or ClientDirect (HTTPClient only).
+	Client ClientSelection
 }
 
 const (
@@ -25,14 +38,20 @@ const (
 type DownloadResult struct {
 	Data     []byte
 	MIMEType string
+
+	LastModified time.Time     // Last-Modified response header (zero if absent or unparsable)
+	Age          time.Duration // Age response header: time the response spent in caches (0 if absent)
+	Truncated    bool          // the body was cut at DownloadOpts.MaxBytes
 }
 
 // Download fetches an image from url. Tries HTTPClient first (fast, no proxy),
 // falls back to StealthClient (proxy with TLS fingerprint) for CDNs that block
-// direct requests (e.g. Tilda, Mamado).
+// direct requests (e.g. Tilda, Mamado). Config.StealthHosts go straight to
+// StealthClient; see StealthPolicy and DownloadOpts.Client for other routing.
 // Returns nil result (not error) on recoverable failures (404, non-image, etc.)
 // for graceful degradation.
 func (cfg *Config) Download(ctx context.Context, url string, opts DownloadOpts) (*DownloadResult, error) {
+	cfg = cfg.orZero()
 	cfg.defaults()
 
 	if opts.MaxBytes <= 0 {
@@ -41,19 +60,16 @@ func (cfg *Config) Download(ctx context.Context, url string, opts DownloadOpts)
 	if opts.Timeout <= 0 {
 		opts.Timeout = defaultTimeout
 	}
-	ua := opts.UserAgent
-	if ua == "" {
-		ua = cfg.UserAgent
+	if opts.HedgeDelay == 0 {
+		opts.HedgeDelay = cfg.HedgeDelay
 	}
-
-	// Try direct HTTP first (fast).
-	if r := fetchImageData(ctx, cfg.HTTPClient, url, ua, opts); r != nil {
-		return r, nil
+	header := cfg.requestHeader(url, opts.UserAgent)
+	if opts.Referer != "" {
+		header.Set("Referer", opts.Referer)
 	}
 
-	// Fallback to stealth client (proxy + TLS fingerprint) for blocked CDNs.
-	if cfg.StealthClient != nil {
-		if r := fetchImageData(ctx, cfg.StealthClient, url, ua, opts); r != nil {
+	for _, c := range cfg.downloadClients(url, opts.Client) {
+		if r := fetchHedged(ctx, cfg.downloadClient(c), url, header, opts); r != nil {
 			return r, nil
 		}
 	}
@@ -61,7 +77,41 @@ func (cfg *Config) Download(ctx context.Context, url string, opts DownloadOpts)
 	return nil, nil
 }
 
-func fetchImageData(ctx context.Context, client *http.Client, imageURL, ua string, opts DownloadOpts) *DownloadResult {
+// fetchHedged runs fetchImageData, starting a second attempt if the first is
+// still running after opts.HedgeDelay. It returns the first non-nil result, or
+// nil once every started attempt has failed.
+func fetchHedged(ctx context.Context, client *http.Client, imageURL string, header http.Header, opts DownloadOpts) *DownloadResult {
+	if opts.HedgeDelay <= 0 {
+		return fetchImageData(ctx, client, imageURL, header, opts)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // stops the losing attempt
+
+	results := make(chan *DownloadResult, 2) //nolint:mnd // at most two attempts
+	attempt := func() { results <- fetchImageData(ctx, client, imageURL, header, opts) }
+	go attempt()
+
+	timer := time.NewTimer(opts.HedgeDelay)
+	defer timer.Stop()
+
+	running := 1
+	for {
+		select {
+		case r := <-results:
+			running--
+			if r != nil || running == 0 {
+				return r // a fast failure is not hedged: only slowness is
+			}
+		case <-timer.C:
+			slog.Debug("imagefy: hedging slow download", "url", imageURL, "delay", opts.HedgeDelay)
+			running++
+			go attempt()
+		}
+	}
+}
+
+func fetchImageData(ctx context.Context, client *http.Client, imageURL string, header http.Header, opts DownloadOpts) *DownloadResult {
 	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
 
@@ -69,7 +119,10 @@ func fetchImageData(ctx context.Context, client *http.Client, imageURL, ua strin
 	if err != nil {
 		return nil
 	}
-	req.Header.Set("User-Agent", ua)
+	req.Header = header.Clone()
+	if opts.Host != "" {
+		req.Host = opts.Host
+	}
 
 	resp, err := client.Do(req) //nolint:gosec // G704: URL is caller-supplied by design — SSRF is caller's responsibility
 	if err != nil {
@@ -90,10 +143,28 @@ func fetchImageData(ctx context.Context, client *http.Client, imageURL, ua strin
 		return nil
 	}
 
-	data, err := io.ReadAll(io.LimitReader(resp.Body, opts.MaxBytes))
-	if err != nil || len(data) < opts.MinBytes {
+	body, ok := decodedBody(resp)
+	if !ok {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(body, opts.MaxBytes+1))
+	if err != nil {
+		return nil
+	}
+	truncated := int64(len(data)) > opts.MaxBytes
+	if truncated {
+		data = data[:opts.MaxBytes]
+	}
+	if len(data) < opts.MinBytes {
 		return nil
 	}
 
-	return &DownloadResult{Data: data, MIMEType: ct}
+	r := &DownloadResult{Data: data, MIMEType: ct, Truncated: truncated}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		r.LastModified = t
+	}
+	if age, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Age"))); err == nil && age > 0 {
+		r.Age = time.Duration(age) * time.Second
+	}
+	return r
 }
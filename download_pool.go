@@ -0,0 +1,166 @@
+package imagefy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DownloadPoolStats is a snapshot of a Config's download pool, for
+// back-pressure metrics.
+type DownloadPoolStats struct {
+	InFlight int // image requests holding a DownloadConcurrency slot
+	Waiting  int // image requests queued for a slot
+}
+
+// downloadPool is the per-Config image download state: the
+// DownloadConcurrency semaphore, the DownloadBandwidth budget, and the
+// PrefetchDNS cache, shared by every search and method of the Config.
+type downloadPool struct {
+	slots       chan struct{} // nil = unlimited
+	bytesPerSec int64         // 0 = unlimited
+	waiting     atomic.Int32
+	dns         *systemCache // PrefetchDNS answers when DoH is nil
+
+	mu   sync.Mutex
+	next time.Time // when the bytes read so far are paid off
+}
+
+// downloadPoolMu guards the lazy creation of Config.downloads.
+var downloadPoolMu sync.Mutex
+
+func (cfg *Config) pool() *downloadPool {
+	downloadPoolMu.Lock()
+	defer downloadPoolMu.Unlock()
+	if cfg.downloads == nil {
+		cfg.downloads = &downloadPool{bytesPerSec: max(cfg.DownloadBandwidth, 0), dns: &systemCache{}}
+		if cfg.DownloadConcurrency > 0 {
+			cfg.downloads.slots = make(chan struct{}, cfg.DownloadConcurrency)
+		}
+	}
+	return cfg.downloads
+}
+
+// DownloadPoolStats reports how many image requests of this Config are in
+// flight and queued under DownloadConcurrency. Both are zero when it is
+// unset.
+func (cfg *Config) DownloadPoolStats() DownloadPoolStats {
+	if cfg == nil {
+		return DownloadPoolStats{}
+	}
+	p := cfg.pool()
+	return DownloadPoolStats{InFlight: len(p.slots), Waiting: int(p.waiting.Load())}
+}
+
+// downloadClient returns c with its transport routed through the download
+// pool and the search's byte meter, or c itself when there is nothing to
+// limit or count.
+func (cfg *Config) downloadClient(c *http.Client) *http.Client {
+	p := cfg.pool()
+	if p.slots == nil && p.bytesPerSec == 0 && cfg.meter == nil {
+		return c
+	}
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	pooled := *c
+	pooled.Transport = &poolTransport{base: base, pool: p, meter: cfg.meter}
+	return &pooled
+}
+
+// acquire waits for a concurrency slot and returns the function that frees
+// it.
+func (p *downloadPool) acquire(ctx context.Context) (release func(), err error) {
+	if p.slots == nil {
+		return func() {}, nil
+	}
+	p.waiting.Add(1)
+	defer p.waiting.Add(-1)
+	select {
+	case p.slots <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-p.slots }) }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// throttle charges n bytes read against the bandwidth budget, sleeping
+// until the pool is back under DownloadBandwidth.
+func (p *downloadPool) throttle(ctx context.Context, n int) error {
+	if p.bytesPerSec == 0 || n <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	p.next = p.next.Add(time.Duration(int64(n) * int64(time.Second) / p.bytesPerSec))
+	d := p.next.Sub(now)
+	p.mu.Unlock()
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// poolTransport holds a download pool slot from the request until the
+// response body is closed, paces body reads to the bandwidth cap, and counts
+// them against the search's byte budget.
+type poolTransport struct {
+	base  http.RoundTripper
+	pool  *downloadPool
+	meter *byteMeter // nil outside a search
+}
+
+func (t *poolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.meter.spent() {
+		return nil, errByteBudget
+	}
+	release, err := t.pool.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &poolBody{ReadCloser: resp.Body, ctx: req.Context(), pool: t.pool, meter: t.meter, host: req.URL.Hostname(), release: release}
+	return resp, nil
+}
+
+type poolBody struct {
+	io.ReadCloser
+	ctx     context.Context //nolint:containedctx // the body outlives RoundTrip and is paced under the request context
+	pool    *downloadPool
+	meter   *byteMeter
+	host    string
+	release func()
+}
+
+func (b *poolBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.meter.add(b.host, n) {
+		return n, errByteBudget
+	}
+	if terr := b.pool.throttle(b.ctx, n); terr != nil && err == nil {
+		err = terr
+	}
+	return n, err
+}
+
+func (b *poolBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
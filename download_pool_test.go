@@ -0,0 +1,123 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadPool_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, peak atomic.Int32
+	img := makeJPEG(1000, 600)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(img)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &Config{HTTPClient: srv.Client(), DownloadConcurrency: 2}
+	cfg.defaults()
+	// Searches work on derived copies; all of them must share one pool.
+	copies := []*Config{cfg, cfg.derive(), cfg.withVariant("q")}
+
+	var wg sync.WaitGroup
+	var stats sync.Once
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(c *Config) {
+			defer wg.Done()
+			r, err := c.Download(context.Background(), srv.URL+"/a.jpg", DownloadOpts{MaxBytes: 1 << 20})
+			if err != nil || r == nil || !bytes.Equal(r.Data, img) {
+				t.Errorf("Download = %v, %v", r, err)
+			}
+			stats.Do(func() {
+				if s := cfg.DownloadPoolStats(); s.InFlight > 2 {
+					t.Errorf("stats = %+v, want at most 2 in flight", s)
+				}
+			})
+		}(copies[i%len(copies)])
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrent downloads = %d, want <= 2", p)
+	}
+	if s := cfg.DownloadPoolStats(); s != (DownloadPoolStats{}) {
+		t.Errorf("stats after downloads = %+v, want all slots released", s)
+	}
+}
+
+func TestDownloadPool_Bandwidth(t *testing.T) {
+	t.Parallel()
+
+	body := bytes.Repeat([]byte{0xff}, 40*1024)
+	srv := newImageServer(t, "image/jpeg", body)
+	cfg := &Config{HTTPClient: srv.Client(), DownloadBandwidth: 100 * 1024}
+
+	start := time.Now()
+	r, err := cfg.Download(context.Background(), srv.URL, DownloadOpts{MaxBytes: 1 << 20})
+	if err != nil || r == nil || len(r.Data) != len(body) {
+		t.Fatalf("Download = %v, %v", r, err)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("40KB at 100KB/s took %v, want about 400ms", elapsed)
+	}
+}
+
+func TestDownloadPool_QueuedRequestHonorsContext(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		srv.Close()
+	})
+	cfg := &Config{HTTPClient: srv.Client(), DownloadConcurrency: 1}
+	cfg.defaults()
+
+	go func() { _, _ = cfg.Download(context.Background(), srv.URL, DownloadOpts{Timeout: 5 * time.Second}) }()
+	for cfg.DownloadPoolStats().InFlight == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if r, _ := cfg.Download(ctx, srv.URL, DownloadOpts{}); r != nil {
+		t.Error("queued download succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("queued download waited %v past its context", elapsed)
+	}
+}
+
+func TestDownloadClient_Unlimited(t *testing.T) {
+	t.Parallel()
+
+	c := &http.Client{}
+	if got := (&Config{}).downloadClient(c); got != c {
+		t.Error("downloadClient wrapped the client without caps")
+	}
+}
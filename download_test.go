@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestDownload_Success(t *testing.T) {
@@ -173,3 +175,71 @@ func TestDownload_MIMEParameterStripping(t *testing.T) {
 		t.Errorf("MIMEType = %q after stripping, want image/jpeg", res.MIMEType)
 	}
 }
+
+// newSlowFirstServer serves an image, stalling the first request until the
+// test ends (or the request is canceled) and answering later ones at once.
+func newSlowFirstServer(t *testing.T, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 && status == http.StatusOK {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte("hedged"))
+	}))
+	t.Cleanup(func() {
+		close(release)
+		srv.Close()
+	})
+	return srv, &requests
+}
+
+func TestDownload_HedgedRequest(t *testing.T) {
+	t.Parallel()
+
+	srv, requests := newSlowFirstServer(t, http.StatusOK)
+	cfg := &Config{HTTPClient: srv.Client(), HedgeDelay: 20 * time.Millisecond}
+
+	start := time.Now()
+	res, err := cfg.Download(context.Background(), srv.URL+"/a.jpg", DownloadOpts{Timeout: 5 * time.Second})
+	if err != nil || res == nil || string(res.Data) != "hedged" {
+		t.Fatalf("Download = %v, %v; want hedged response", res, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Download took %v, want the hedge to win quickly", elapsed)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
+
+func TestDownload_HedgeSkippedOnFastFailureAndWhenDisabled(t *testing.T) {
+	t.Parallel()
+
+	srv, requests := newSlowFirstServer(t, http.StatusNotFound)
+	cfg := &Config{HTTPClient: srv.Client(), HedgeDelay: 50 * time.Millisecond}
+	if res, _ := cfg.Download(context.Background(), srv.URL+"/a.jpg", DownloadOpts{}); res != nil {
+		t.Fatalf("Download = %v, want nil for 404", res)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := requests.Load(); n != 1 {
+		t.Errorf("requests after fast failure = %d, want 1 (no hedge)", n)
+	}
+
+	slow, slowRequests := newSlowFirstServer(t, http.StatusOK)
+	cfg = &Config{HTTPClient: slow.Client(), HedgeDelay: 10 * time.Millisecond}
+	res, _ := cfg.Download(context.Background(), slow.URL+"/a.jpg", DownloadOpts{HedgeDelay: -1, Timeout: 100 * time.Millisecond})
+	if res != nil || slowRequests.Load() != 1 {
+		t.Errorf("HedgeDelay=-1: res = %v, requests = %d; want nil, 1", res, slowRequests.Load())
+	}
+}
@@ -0,0 +1,104 @@
+package imagefy
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// defaultSemanticDedupThreshold is the embedding cosine similarity at or
+// above which two images are treated as the same subject.
+const defaultSemanticDedupThreshold = 0.92
+
+// TextEmbedder is implemented by Embedders that also embed text into the
+// same space as images (CLIP-style). With one configured, the validation
+// pipeline scores each image's relevance to the search query.
+type TextEmbedder interface {
+	EmbedText(ctx context.Context, text string) ([]float32, error)
+}
+
+// withQueryEmbedding returns cfg itself unless Config.Embedder implements
+// TextEmbedder, otherwise a derived copy carrying the embedding of query
+// for relevance scoring. An embedding failure disables relevance scoring
+// for the search.
+func (cfg *Config) withQueryEmbedding(ctx context.Context, query string) *Config {
+	te, ok := cfg.Embedder.(TextEmbedder)
+	if !ok || query == "" {
+		return cfg
+	}
+	vec, err := te.EmbedText(ctx, query)
+	if err != nil || len(vec) == 0 {
+		slog.Debug("imagefy: query embedding failed", "error", err)
+		return cfg
+	}
+	c := cfg.derive()
+	c.queryVec = vec
+	return c
+}
+
+// semanticDedupThreshold returns Config.SemanticDedupThreshold, the default
+// when zero, or 0 (disabled) when negative.
+func (cfg *Config) semanticDedupThreshold() float64 {
+	switch {
+	case cfg.SemanticDedupThreshold < 0:
+		return 0
+	case cfg.SemanticDedupThreshold == 0:
+		return defaultSemanticDedupThreshold
+	}
+	return cfg.SemanticDedupThreshold
+}
+
+// relevance returns the similarity of an image embedding to the query
+// embedding, and false when either is missing.
+func (cfg *Config) relevance(vec []float32) (float64, bool) {
+	if vec == nil || cfg.queryVec == nil {
+		return 0, false
+	}
+	return cosineSimilarity(cfg.queryVec, vec)
+}
+
+// isSemanticDuplicate reports whether vec is at least threshold-similar to
+// an earlier image's embedding; otherwise vec is remembered. A zero
+// threshold disables the check.
+func (d *dedupFilter) isSemanticDuplicate(vec []float32, threshold float64) bool {
+	if vec == nil || threshold <= 0 {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, v := range d.vectors {
+		if sim, ok := cosineSimilarity(vec, v); ok && sim >= threshold {
+			return true
+		}
+	}
+	d.vectors = append(d.vectors, vec)
+	return false
+}
+
+// scoreStore records per-image relevance scores, keyed by ImgURL, so the
+// pipeline can attach them to accepted candidates.
+type scoreStore struct {
+	mu sync.Mutex
+	m  map[string]float64
+}
+
+func (s *scoreStore) set(imgURL string, score float64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.m == nil {
+		s.m = make(map[string]float64)
+	}
+	s.m[imgURL] = score
+	s.mu.Unlock()
+}
+
+func (s *scoreStore) get(imgURL string) float64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[imgURL]
+}
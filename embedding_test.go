@@ -0,0 +1,113 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// tableEmbedder embeds images by looking up their bytes and text by
+// looking up the string.
+type tableEmbedder struct {
+	images map[string][]float32
+	texts  map[string][]float32
+}
+
+func (e *tableEmbedder) Embed(_ context.Context, data []byte, _ string) ([]float32, error) {
+	if v, ok := e.images[string(data)]; ok {
+		return v, nil
+	}
+	return nil, errors.New("unknown image")
+}
+
+func (e *tableEmbedder) EmbedText(_ context.Context, text string) ([]float32, error) {
+	if v, ok := e.texts[text]; ok {
+		return v, nil
+	}
+	return nil, errors.New("unknown text")
+}
+
+// imageOnlyEmbedder hides tableEmbedder's EmbedText.
+type imageOnlyEmbedder struct{ e *tableEmbedder }
+
+func (o imageOnlyEmbedder) Embed(ctx context.Context, data []byte, mimeType string) ([]float32, error) {
+	return o.e.Embed(ctx, data, mimeType)
+}
+
+func TestEmbedder_SemanticDedupAndRelevance(t *testing.T) {
+	t.Parallel()
+
+	front := encodeJPEG(t, makeGradientImage(1000, 600, 0))
+	side := encodeJPEG(t, makeCheckerImage(1000, 600, 25)) // perceptually different
+	beach := encodeJPEG(t, makeCheckerImage(1000, 600, 90))
+	srv := newMultiImageServer(t, map[string][]byte{"/front.jpg": front, "/side.jpg": side, "/beach.jpg": beach})
+	emb := &tableEmbedder{
+		images: map[string][]float32{
+			string(front): {1, 0, 0},
+			string(side):  {0.99, 0.05, 0}, // same landmark, different angle
+			string(beach): {0, 1, 0},
+		},
+		texts: map[string][]float32{"kremlin": {1, 0, 0}},
+	}
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/front.jpg", Source: srv.URL + "/a", License: LicenseUnknown},
+		{ImgURL: srv.URL + "/side.jpg", Source: srv.URL + "/b", License: LicenseUnknown},
+		{ImgURL: srv.URL + "/beach.jpg", Source: srv.URL + "/c", License: LicenseUnknown},
+	}
+
+	var (
+		mu      sync.Mutex
+		reasons = map[string]RejectReason{}
+	)
+	cfg := &Config{
+		HTTPClient:   srv.Client(),
+		Embedder:     emb,
+		MinRelevance: 0.5,
+		Providers:    []SearchProvider{&mockProvider{name: "p", candidates: cands}},
+		OnCandidateRejected: func(e CandidateEvent) {
+			mu.Lock()
+			reasons[e.Candidate.ImgURL] = e.Reason
+			mu.Unlock()
+		},
+	}
+
+	got := cfg.SearchImages(context.Background(), "kremlin", 5)
+	if len(got) != 1 || got[0].Relevance < 0.99 {
+		t.Fatalf("results = %+v, want one scored Kremlin image", got)
+	}
+	if reasons[got[0].ImgURL] != "" {
+		t.Errorf("accepted image also rejected: %v", reasons)
+	}
+	other := srv.URL + "/side.jpg"
+	if got[0].ImgURL == other {
+		other = srv.URL + "/front.jpg"
+	}
+	if reasons[other] != ReasonDuplicate || reasons[srv.URL+"/beach.jpg"] != ReasonIrrelevant {
+		t.Errorf("rejections = %v, want the second angle duplicate and the beach irrelevant", reasons)
+	}
+}
+
+func TestEmbedder_Fallbacks(t *testing.T) {
+	t.Parallel()
+
+	emb := &tableEmbedder{texts: map[string][]float32{"q": {1}}}
+	if c := (&Config{Embedder: imageOnlyEmbedder{emb}}).withQueryEmbedding(context.Background(), "q"); c.queryVec != nil {
+		t.Error("image-only Embedder produced a query embedding")
+	}
+	if c := (&Config{Embedder: emb}).withQueryEmbedding(context.Background(), "unknown"); c.queryVec != nil {
+		t.Error("failed text embedding produced a query embedding")
+	}
+	base := &Config{Embedder: emb}
+	if c := base.withQueryEmbedding(context.Background(), "q"); c == base || c.queryVec == nil || base.queryVec != nil {
+		t.Error("withQueryEmbedding must set the vector on a derived copy only")
+	}
+
+	for _, tt := range []struct {
+		in, want float64
+	}{{0, defaultSemanticDedupThreshold}, {-1, 0}, {0.8, 0.8}} {
+		if got := (&Config{SemanticDedupThreshold: tt.in}).semanticDedupThreshold(); got != tt.want {
+			t.Errorf("semanticDedupThreshold(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package imagefy
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// decodedBody returns resp.Body with its Content-Encoding undone, so size
+// limits and image decoding see the image bytes. net/http already decodes
+// gzip it requested itself, but custom transports, proxies, and stealth
+// clients may hand back encoded bodies. Encodings listed in the header are
+// undone in reverse order. Returns false for an unsupported or corrupt
+// encoding.
+func decodedBody(resp *http.Response) (io.Reader, bool) {
+	var r io.Reader = resp.Body
+	if resp.Uncompressed {
+		return r, true
+	}
+	codings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		var err error
+		switch coding {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(r)
+		case "br":
+			r = brotli.NewReader(r)
+		case "deflate":
+			r, err = deflateReader(r)
+		default:
+			slog.Debug("imagefy: unsupported content encoding", "encoding", coding)
+			return nil, false
+		}
+		if err != nil {
+			slog.Debug("imagefy: corrupt content encoding", "encoding", coding, "error", err)
+			return nil, false
+		}
+	}
+	return r, true
+}
+
+// deflateReader decodes "deflate", which should be zlib-wrapped (RFC 9110)
+// but is sent as raw DEFLATE by some servers.
+func deflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(2) //nolint:mnd // zlib header size
+	if err != nil {
+		return nil, err
+	}
+	// A zlib header has compression method 8 and a check value making the
+	// big-endian 16-bit header a multiple of 31.
+	if head[0]&0x0f == 8 && (uint16(head[0])<<8|uint16(head[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
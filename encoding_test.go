@@ -0,0 +1,111 @@
+package imagefy
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func compress(t *testing.T, data []byte, newWriter func(io.Writer) io.WriteCloser) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newEncodedImageServer serves body as an image with the given
+// Content-Encoding. Its client does not negotiate compression, like custom
+// transports that pass encoded bodies through.
+func newEncodedImageServer(t *testing.T, encoding string, body []byte) (*httptest.Server, *http.Client) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("Content-Encoding", encoding)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &http.Client{Transport: &http.Transport{DisableCompression: true}}
+}
+
+func TestDownload_ContentEncoding(t *testing.T) {
+	t.Parallel()
+
+	img := makeJPEG(1000, 600)
+	gz := compress(t, img, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	tests := []struct {
+		name, encoding string
+		body           []byte
+		want           bool
+	}{
+		{"identity", "identity", img, true},
+		{"gzip", "gzip", gz, true},
+		{"x-gzip", "x-gzip", gz, true},
+		{"brotli", "br", compress(t, img, func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }), true},
+		{"zlib deflate", "deflate", compress(t, img, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }), true},
+		{"raw deflate", "deflate", compress(t, img, func(w io.Writer) io.WriteCloser { fw, _ := flate.NewWriter(w, flate.DefaultCompression); return fw }), true},
+		{"stacked", "gzip, br", compress(t, gz, func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }), true},
+		{"unsupported", "compress", img, false},
+		{"corrupt gzip", "gzip", img, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv, client := newEncodedImageServer(t, tt.encoding, tt.body)
+			cfg := &Config{HTTPClient: client}
+
+			r, _ := cfg.Download(context.Background(), srv.URL, DownloadOpts{MaxBytes: 1 << 20})
+			if !tt.want {
+				if r != nil {
+					t.Errorf("Download returned %d bytes, want nil", len(r.Data))
+				}
+				return
+			}
+			if r == nil || !bytes.Equal(r.Data, img) {
+				t.Fatal("Download did not return the decoded image")
+			}
+		})
+	}
+}
+
+func TestDownload_ContentEncodingMaxBytesCountsDecoded(t *testing.T) {
+	t.Parallel()
+
+	img := makeJPEG(1000, 600)
+	srv, client := newEncodedImageServer(t, "gzip", compress(t, img, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }))
+	cfg := &Config{HTTPClient: client}
+
+	r, _ := cfg.Download(context.Background(), srv.URL, DownloadOpts{MaxBytes: 100})
+	if r == nil || len(r.Data) != 100 {
+		t.Fatalf("Download = %v, want the first 100 decoded bytes", r)
+	}
+	if !bytes.Equal(r.Data, img[:100]) {
+		t.Error("limit applied to the encoded body")
+	}
+}
+
+func TestProbeImageURL_GzipEncoded(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newEncodedImageServer(t, "gzip", compress(t, makeJPEG(400, 300), func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }))
+	cfg := &Config{HTTPClient: client}
+	cfg.defaults()
+
+	// The 400px image must be measured, not accepted as undecodable.
+	if reason := cfg.probeImageURL(context.Background(), srv.URL, ""); reason != ReasonTooNarrow {
+		t.Errorf("probe = %q, want %q", reason, ReasonTooNarrow)
+	}
+}
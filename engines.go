@@ -0,0 +1,67 @@
+package imagefy
+
+import "unicode"
+
+// Script is the writing system of a query, as detected by QueryScript.
+type Script string
+
+// Scripts detected by QueryScript.
+const (
+	ScriptLatin    Script = "latin"
+	ScriptCyrillic Script = "cyrillic"
+	ScriptCJK      Script = "cjk" // Han, Hiragana, Katakana, and Hangul
+	ScriptArabic   Script = "arabic"
+	ScriptOther    Script = "other" // letters of any other script
+)
+
+// scriptTables lists the Unicode tables of each detected script except
+// ScriptOther.
+var scriptTables = []struct {
+	script Script
+	tables []*unicode.RangeTable
+}{
+	{ScriptLatin, []*unicode.RangeTable{unicode.Latin}},
+	{ScriptCyrillic, []*unicode.RangeTable{unicode.Cyrillic}},
+	{ScriptCJK, []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul}},
+	{ScriptArabic, []*unicode.RangeTable{unicode.Arabic}},
+}
+
+// QueryScript returns the script most of the letters of query are written
+// in, or "" when query has no letters. Ties go to the script listed first
+// among the Script constants.
+func QueryScript(query string) Script {
+	counts := make(map[Script]int)
+	for _, r := range query {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		s := ScriptOther
+		for _, st := range scriptTables {
+			if unicode.In(r, st.tables...) {
+				s = st.script
+				break
+			}
+		}
+		counts[s]++
+	}
+	var best Script
+	for _, s := range []Script{ScriptLatin, ScriptCyrillic, ScriptCJK, ScriptArabic, ScriptOther} {
+		if counts[s] > counts[best] {
+			best = s
+		}
+	}
+	return best
+}
+
+// withEngines returns opts with Engines chosen by Config.EnginesByScript
+// for the script of query. opts is returned unchanged when it already
+// names engines or the mapping has no entry for the script.
+func (cfg *Config) withEngines(query string, opts SearchOpts) SearchOpts {
+	if len(opts.Engines) > 0 || len(cfg.EnginesByScript) == 0 {
+		return opts
+	}
+	if engines, ok := cfg.EnginesByScript[QueryScript(query)]; ok {
+		opts.Engines = engines
+	}
+	return opts
+}
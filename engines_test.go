@@ -0,0 +1,71 @@
+package imagefy
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestQueryScript(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]Script{
+		"Красная площадь":    ScriptCyrillic,
+		"Eiffel Tower 2024":  ScriptLatin,
+		"кафе Пушкин Moscow": ScriptCyrillic,
+		"東京タワー":              ScriptCJK,
+		"برج خليفة":          ScriptArabic,
+		"Ακρόπολη":           ScriptOther,
+		"2024 / 12":          "",
+		"":                   "",
+	}
+	for query, want := range cases {
+		if got := QueryScript(query); got != want {
+			t.Errorf("QueryScript(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+// optsCapturingProvider records the SearchOpts of every Search call.
+type optsCapturingProvider struct {
+	mu   sync.Mutex
+	opts []SearchOpts
+}
+
+func (p *optsCapturingProvider) Name() string { return "capture" }
+
+func (p *optsCapturingProvider) Search(_ context.Context, _ string, opts SearchOpts) ([]ImageCandidate, error) {
+	p.mu.Lock()
+	p.opts = append(p.opts, opts)
+	p.mu.Unlock()
+	return nil, nil
+}
+
+func TestEnginesByScript(t *testing.T) {
+	t.Parallel()
+
+	p := &optsCapturingProvider{}
+	cfg := &Config{
+		Providers: []SearchProvider{p},
+		EnginesByScript: map[Script][]string{
+			ScriptCyrillic: {"yandex images", "bing images"},
+			ScriptLatin:    {"bing images"},
+		},
+	}
+	ctx := context.Background()
+	cfg.SearchImages(ctx, "Казанский собор", 1)
+	cfg.SearchImages(ctx, "Kazan Cathedral", 1)
+	cfg.SearchImages(ctx, "東京タワー", 1)
+	cfg.SearchImagesWithOpts(ctx, "Казанский собор", 1, SearchOpts{Engines: []string{"google images"}})
+
+	want := [][]string{{"yandex images", "bing images"}, {"bing images"}, nil, {"google images"}}
+	if len(p.opts) != len(want) {
+		t.Fatalf("got %d searches, want %d", len(p.opts), len(want))
+	}
+	for i, w := range want {
+		if !slices.Equal(p.opts[i].Engines, w) {
+			t.Errorf("search %d: Engines = %v, want %v", i, p.opts[i].Engines, w)
+		}
+	}
+}
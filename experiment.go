@@ -0,0 +1,71 @@
+package imagefy
+
+import "strings"
+
+// Variant is one arm of an A/B experiment, returned by Config.Experiment for
+// a search. Its Label is attached to every CandidateEvent,
+// ClassificationEvent, and ThrottleEvent the search emits, so acceptance
+// precision can be compared across prompt and threshold iterations. Zero
+// fields leave the Config's own settings in place.
+type Variant struct {
+	Label string // experiment arm, e.g. "prompt-b" ("" = unlabeled)
+
+	// VisionPrompt overrides Config.VisionPrompt. Classifications made with
+	// an overriding prompt are cached under a key that includes Label, so
+	// arms never read each other's results.
+	VisionPrompt string
+
+	// MinImageWidth overrides Config.MinImageWidth.
+	MinImageWidth int
+
+	// MinConfidence rejects PHOTO classifications whose confidence is below
+	// it with ReasonVisionReject (0 = accept any PHOTO). A response without a
+	// confidence score counts as 0.
+	MinConfidence float64
+}
+
+// withVariant returns the Config a search runs with: cfg itself when no
+// Experiment is set, otherwise a derived copy carrying the variant assigned
+// to query.
+func (cfg *Config) withVariant(query string) *Config {
+	if cfg.Experiment == nil {
+		return cfg
+	}
+	v := cfg.Experiment(query)
+
+	arm := cfg.derive()
+	arm.variant = v
+	if v.VisionPrompt != "" {
+		arm.VisionPrompt = v.VisionPrompt
+	}
+	if v.MinImageWidth > 0 {
+		arm.MinImageWidth = v.MinImageWidth
+	}
+	return arm
+}
+
+// visionCacheKey returns the cache key for the classification of imageURL
+// (by its NormalizeURL form), namespaced by the variant label when the variant overrides the prompt and
+// by the search language and required labels when they add hints to the prompt.
+func (cfg *Config) visionCacheKey(imageURL string) string {
+	prefix := "vision_cls_v2"
+	if cfg.variant.VisionPrompt != "" {
+		prefix += ":" + cfg.variant.Label
+	}
+	if cfg.language != "" {
+		prefix += ":lang=" + cfg.language
+	}
+	if cfg.QuickVision {
+		prefix += ":quick"
+	}
+	if len(cfg.requireLabels) > 0 {
+		prefix += ":labels=" + strings.Join(cfg.requireLabels, ",")
+	}
+	return cfg.Cache.Key(prefix, NormalizeURL(imageURL))
+}
+
+// belowMinConfidence reports whether a PHOTO result falls short of the
+// variant's MinConfidence.
+func (cfg *Config) belowMinConfidence(result ClassificationResult) bool {
+	return result.Class == ClassPhoto && result.Confidence < cfg.variant.MinConfidence
+}
@@ -0,0 +1,78 @@
+package imagefy
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestExperiment_VariantPerSearch(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	var (
+		mu     sync.Mutex
+		events []CandidateEvent
+		labels []string
+	)
+	record := func(e CandidateEvent) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}
+	cls := &promptCapturingClassifier{response: "PHOTO 0.6"}
+	cfg := &Config{
+		HTTPClient: srv.Client(),
+		Classifier: cls,
+		Cache:      &mockCache{store: make(map[string]any)},
+		Providers: []SearchProvider{&mockProvider{name: "p", candidates: []ImageCandidate{
+			{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown},
+		}}},
+		Experiment: func(query string) Variant {
+			if query == "control" {
+				return Variant{Label: "A"}
+			}
+			return Variant{Label: "B", VisionPrompt: "strict prompt", MinConfidence: 0.8}
+		},
+		OnCandidateAccepted: record,
+		OnCandidateRejected: record,
+		OnClassification: func(e ClassificationEvent) {
+			mu.Lock()
+			labels = append(labels, e.Variant)
+			mu.Unlock()
+		},
+	}
+
+	if got := cfg.SearchImages(context.Background(), "control", 3); len(got) != 1 {
+		t.Fatalf("arm A: results = %d, want 1", len(got))
+	}
+	if cls.capturedPrompt != DefaultVisionPrompt {
+		t.Errorf("arm A prompt = %q, want DefaultVisionPrompt", cls.capturedPrompt)
+	}
+
+	if got := cfg.SearchImages(context.Background(), "treatment", 3); len(got) != 0 {
+		t.Fatalf("arm B: results = %d, want 0 (PHOTO 0.6 below MinConfidence 0.8)", len(got))
+	}
+	if cls.capturedPrompt != "strict prompt" {
+		t.Errorf("arm B prompt = %q, want the variant prompt (cached arm A result must not be reused)", cls.capturedPrompt)
+	}
+
+	if len(events) != 2 || events[0].Variant != "A" || events[1].Variant != "B" || events[1].Reason != ReasonVisionReject {
+		t.Errorf("events = %+v, want accepted in A and vision_reject in B", events)
+	}
+	if len(labels) != 2 || labels[0] != "A" || labels[1] != "B" {
+		t.Errorf("classification event variants = %v, want [A B]", labels)
+	}
+	if cfg.VisionPrompt != "" || cfg.variant != (Variant{}) {
+		t.Error("Experiment modified the shared Config")
+	}
+}
+
+func TestWithVariant_NoExperiment(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+	if cfg.withVariant("q") != cfg {
+		t.Error("withVariant without Experiment should return cfg itself")
+	}
+}
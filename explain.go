@@ -0,0 +1,109 @@
+package imagefy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ExplainLicense is a dry-run of CheckLicenseWith that reports every list
+// entry matching imageURL or sourceURL instead of only the verdict, to answer
+// "why is this URL blocked?". Each signal names the list (BlockedDomains,
+// AgencyCDNs, BlockedURLPatterns, SafeDomains, ExtraBlockedDomains, ExtraSafeDomains, and
+// the Config.Subscription lists RemoteBlockedDomains and RemoteSafeDomains),
+// the matching entry, and the URL it matched. Signal sources are "domain",
+// "url_pattern", and "extra_domain". Blocked matches come first.
+// BlockedDomains and AgencyCDNs signals name the entry's BlockCategory and carry the
+// license Config.BlockPolicy gives it.
+//
+// cfg supplies the extra domain lists and BlockPolicy and may be nil. The result is never
+// nil; an empty result means the URLs are LicenseUnknown.
+func ExplainLicense(imageURL, sourceURL string, cfg *Config) []LicenseSignal {
+	cfg = cfg.orZero()
+	urls := []struct{ role, raw string }{{"image URL", imageURL}, {"source URL", sourceURL}}
+	remote := cfg.Subscription.Lists()
+
+	signals := []LicenseSignal{}
+	for _, u := range urls {
+		signals = append(signals, explainBlocked(u.role, u.raw, cfg.ExtraBlockedDomains, remote.Blocked, cfg.BlockPolicy)...)
+	}
+	for _, u := range urls {
+		signals = append(signals, explainSafe(u.role, u.raw, cfg.ExtraSafeDomains, remote.Safe)...)
+	}
+	return signals
+}
+
+// explainBlocked mirrors blockedLicense, returning one signal per match.
+func explainBlocked(role, rawURL string, extra, remote []string, policy blockPolicy) []LicenseSignal {
+	if rawURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	var out []LicenseSignal
+	host := normalizeHost(parsed.Scheme, parsed.Host)
+	if host != "" {
+		for _, d := range BlockedDomains {
+			if strings.Contains(host, d) {
+				out = append(out, builtinBlockedSignal(role, host, "BlockedDomains", d, policy))
+			}
+		}
+		for _, d := range AgencyCDNs {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				out = append(out, builtinBlockedSignal(role, host, "AgencyCDNs", d, policy))
+			}
+		}
+		out = append(out, hostMatches(role, host, "ExtraBlockedDomains", "extra_domain", extra, LicenseBlocked)...)
+		out = append(out, hostMatches(role, host, "RemoteBlockedDomains", "extra_domain", remote, LicenseBlocked)...)
+	}
+	path := strings.ToLower(parsed.Path)
+	for _, p := range BlockedURLPatterns {
+		if strings.Contains(path, p) {
+			out = append(out, LicenseSignal{
+				Source:  "url_pattern",
+				Detail:  fmt.Sprintf("%s path %q matches BlockedURLPatterns entry %q", role, parsed.Path, p),
+				License: LicenseBlocked,
+			})
+		}
+	}
+	return out
+}
+
+// builtinBlockedSignal reports that host matches built-in blocked entry d of
+// listName, with its BlockCategory and the license policy gives it.
+func builtinBlockedSignal(role, host, listName, d string, policy blockPolicy) LicenseSignal {
+	return LicenseSignal{
+		Source:  "domain",
+		Detail:  fmt.Sprintf("%s host %q matches %s entry %q (%s)", role, host, listName, d, domainCategory(d)),
+		License: policy.domainLicense(d),
+	}
+}
+
+// explainSafe mirrors isSafeWith, returning one signal per match.
+func explainSafe(role, rawURL string, extra, remote []string) []LicenseSignal {
+	host := extractHost(rawURL)
+	if host == "" {
+		return nil
+	}
+	out := hostMatches(role, host, "SafeDomains", "domain", SafeDomains, LicenseSafe)
+	out = append(out, hostMatches(role, host, "ExtraSafeDomains", "extra_domain", extra, LicenseSafe)...)
+	return append(out, hostMatches(role, host, "RemoteSafeDomains", "extra_domain", remote, LicenseSafe)...)
+}
+
+// hostMatches returns a signal for every entry of list contained in host.
+func hostMatches(role, host, listName, source string, list []string, license ImageLicense) []LicenseSignal {
+	var out []LicenseSignal
+	for _, d := range list {
+		if d != "" && strings.Contains(host, d) {
+			out = append(out, LicenseSignal{
+				Source:  source,
+				Detail:  fmt.Sprintf("%s host %q matches %s entry %q", role, host, listName, d),
+				License: license,
+			})
+		}
+	}
+	return out
+}
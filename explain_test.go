@@ -0,0 +1,91 @@
+package imagefy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainLicense(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{ExtraBlockedDomains: []string{"mystock.internal"}, ExtraSafeDomains: []string{"ourarchive.org"}}
+	tests := []struct {
+		name       string
+		imageURL   string
+		sourceURL  string
+		wantDetail []string // substrings, one per expected signal, in order
+	}{
+		{
+			name:       "blocked domain on image",
+			imageURL:   "https://www.shutterstock.com/image.jpg",
+			wantDetail: []string{`image URL host "www.shutterstock.com" matches BlockedDomains entry "shutterstock"`},
+		},
+		{
+			name:       "url pattern on source",
+			imageURL:   "https://cdn.example.com/a.jpg",
+			sourceURL:  "https://example.com/stock-photo/123",
+			wantDetail: []string{`source URL path "/stock-photo/123" matches BlockedURLPatterns entry "/stock-photo"`},
+		},
+		{
+			name:      "extra lists",
+			imageURL:  "https://img.mystock.internal/a.jpg",
+			sourceURL: "https://ourarchive.org/item/1",
+			wantDetail: []string{
+				`matches ExtraBlockedDomains entry "mystock.internal"`,
+				`source URL host "ourarchive.org" matches ExtraSafeDomains entry "ourarchive.org"`,
+			},
+		},
+		{
+			name:       "every matching entry is reported",
+			imageURL:   "https://upload.commons.wikimedia.org/a.jpg",
+			wantDetail: []string{`SafeDomains entry "wikimedia"`, `SafeDomains entry "commons.wikimedia"`},
+		},
+		{
+			name:     "no match",
+			imageURL: "https://example.com/a.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := ExplainLicense(tt.imageURL, tt.sourceURL, cfg)
+			if got == nil {
+				t.Fatal("ExplainLicense returned nil, want non-nil slice")
+			}
+			if len(got) != len(tt.wantDetail) {
+				t.Fatalf("got %d signals %+v, want %d", len(got), got, len(tt.wantDetail))
+			}
+			for i, want := range tt.wantDetail {
+				if !strings.Contains(got[i].Detail, want) {
+					t.Errorf("signal %d detail = %q, want substring %q", i, got[i].Detail, want)
+				}
+			}
+		})
+	}
+}
+
+// TestExplainLicense_AgreesWithCheckLicense guards against the explanation
+// drifting from the matcher it explains.
+func TestExplainLicense_AgreesWithCheckLicense(t *testing.T) {
+	t.Parallel()
+
+	extraBlocked, extraSafe := []string{"mystock"}, []string{"ourarchive"}
+	cfg := &Config{ExtraBlockedDomains: extraBlocked, ExtraSafeDomains: extraSafe}
+	pairs := [][2]string{
+		{"https://www.shutterstock.com/a.jpg", ""},
+		{"https://images.unsplash.com/a.jpg", "https://www.gettyimages.com/detail/1"},
+		{"https://cdn.example.com/a.jpg", "https://example.com/premium-photo/1"},
+		{"https://mystock.example/a.jpg", "https://ourarchive.org/1"},
+		{"https://ourarchive.org/a.jpg", ""},
+		{"https://example.com/a.jpg", "https://example.com/page"},
+		{"", ""},
+	}
+	for _, p := range pairs {
+		want := CheckLicenseWith(p[0], p[1], extraBlocked, extraSafe)
+		got := resolveLicense(ExplainLicense(p[0], p[1], cfg))
+		if got != want {
+			t.Errorf("ExplainLicense(%q, %q) resolves to %v, CheckLicenseWith = %v", p[0], p[1], got, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/corona10/goimagehash"
+)
+
+// ErrNoFeedbackStore is returned by ReportFeedback when Config.Feedback is nil.
+var ErrNoFeedbackStore = errors.New("imagefy: no FeedbackStore configured")
+
+// Feedback is a human moderator's verdict on an image.
+type Feedback struct {
+	URL     string    `json:"url"`
+	Hash    string    `json:"hash,omitempty"`    // perceptual dHash; "" if the image could not be downloaded
+	Verdict string    `json:"verdict"`           // PHOTO, STOCK, REJECT, SCREENSHOT, ILLUSTRATION, MAP, or PLACEHOLDER
+	Preview string    `json:"preview,omitempty"` // data: URI of the image if it fits a vision preview, for few-shot examples
+	At      time.Time `json:"at"`
+}
+
+// FeedbackStore persists human verdicts (Redis, SQL, a file, ...). Keys are
+// "url:<image URL>" and "dhash:<perceptual hash>"; ReportFeedback saves
+// under both so a re-hosted copy of the same image matches too.
+// Implementations must be safe for concurrent use.
+type FeedbackStore interface {
+	SaveFeedback(ctx context.Context, key string, f Feedback) error
+	LookupFeedback(ctx context.Context, key string) (Feedback, bool)
+}
+
+// ReportFeedback records a human verdict for imageURL in Config.Feedback.
+// The image is downloaded to record its perceptual hash as well; if that
+// fails, only the URL is recorded. With Config.UseFeedback set, later
+// classifications of the same URL or image reuse the verdict instead of
+// calling the Classifier.
+func (cfg *Config) ReportFeedback(ctx context.Context, imageURL, verdict string) error {
+	cfg = cfg.orZero()
+	if cfg.Feedback == nil {
+		return ErrNoFeedbackStore
+	}
+	if !slices.Contains(classificationClasses, verdict) {
+		return fmt.Errorf("imagefy: unknown verdict %q", verdict)
+	}
+	cfg.defaults()
+
+	f := Feedback{URL: imageURL, Verdict: verdict, At: time.Now()}
+	if r, err := cfg.Download(ctx, imageURL, DownloadOpts{}); err == nil && r != nil {
+		if len(r.Data) <= visionMaxBytes {
+			f.Preview = EncodeDataURL(r.Data, r.MIMEType)
+		}
+		if img, _, err := image.Decode(bytes.NewReader(r.Data)); err == nil {
+			f.Hash = feedbackHash(img)
+		}
+	}
+
+	if err := cfg.Feedback.SaveFeedback(ctx, "url:"+NormalizeURL(imageURL), f); err != nil {
+		return err
+	}
+	if f.Hash != "" {
+		return cfg.Feedback.SaveFeedback(ctx, "dhash:"+f.Hash, f)
+	}
+	return nil
+}
+
+// lookupFeedback returns the recorded verdict for imageURL, or for img's
+// perceptual hash when img is non-nil. It finds nothing unless both
+// Config.Feedback and Config.UseFeedback are set.
+func (cfg *Config) lookupFeedback(ctx context.Context, imageURL string, img image.Image) (Feedback, bool) {
+	if cfg.Feedback == nil || !cfg.UseFeedback {
+		return Feedback{}, false
+	}
+	if f, ok := cfg.Feedback.LookupFeedback(ctx, "url:"+NormalizeURL(imageURL)); ok {
+		return f, true
+	}
+	if img == nil {
+		return Feedback{}, false
+	}
+	if hash := feedbackHash(img); hash != "" {
+		return cfg.Feedback.LookupFeedback(ctx, "dhash:"+hash)
+	}
+	return Feedback{}, false
+}
+
+// feedbackResult turns a recorded verdict into a classification and reports
+// it through OnClassification with Source "feedback".
+func (cfg *Config) feedbackResult(imageURL string, f Feedback) ClassificationResult {
+	slog.Debug("imagefy: using human feedback", "url", imageURL, "verdict", f.Verdict)
+	event := ClassificationEvent{URL: imageURL, Class: f.Verdict, Confidence: 1.0, Source: "feedback"}
+	if f.Verdict != ClassPhoto {
+		event.Reason = ReasonVisionReject
+	}
+	cfg.emitEvent(event)
+	return ClassificationResult{Class: f.Verdict, Confidence: 1.0}
+}
+
+// feedbackHash returns img's dHash as a string, or "" if hashing fails.
+func feedbackHash(img image.Image) string {
+	hash, err := goimagehash.DifferenceHash(img)
+	if err != nil {
+		return ""
+	}
+	return hash.ToString()
+}
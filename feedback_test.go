@@ -0,0 +1,100 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// memFeedback is a minimal in-memory FeedbackStore.
+type memFeedback struct {
+	mu    sync.Mutex
+	store map[string]Feedback
+}
+
+func (m *memFeedback) SaveFeedback(_ context.Context, key string, f Feedback) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.store == nil {
+		m.store = map[string]Feedback{}
+	}
+	m.store[key] = f
+	return nil
+}
+
+func (m *memFeedback) LookupFeedback(_ context.Context, key string) (Feedback, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.store[key]
+	return f, ok
+}
+
+func TestReportFeedback_Errors(t *testing.T) {
+	t.Parallel()
+
+	if err := (&Config{}).ReportFeedback(context.Background(), "https://example.com/a.jpg", ClassStock); !errors.Is(err, ErrNoFeedbackStore) {
+		t.Errorf("no store: err = %v, want ErrNoFeedbackStore", err)
+	}
+	cfg := &Config{Feedback: &memFeedback{}}
+	if err := cfg.ReportFeedback(context.Background(), "https://example.com/a.jpg", "MAYBE"); err == nil {
+		t.Error("unknown verdict: err = nil, want error")
+	}
+}
+
+func TestReportFeedback_ShortCircuitsClassifyImageFull(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	store := &memFeedback{}
+	cls := &mockClassifier{response: "PHOTO 0.9"}
+	cfg := &Config{HTTPClient: srv.Client(), Classifier: cls, Feedback: store}
+	url := srv.URL + "/a.jpg"
+
+	if err := cfg.ReportFeedback(context.Background(), url, ClassStock); err != nil {
+		t.Fatalf("ReportFeedback: %v", err)
+	}
+	if f, ok := store.LookupFeedback(context.Background(), "url:"+url); !ok || f.Hash == "" || f.Preview == "" || f.Verdict != ClassStock {
+		t.Errorf("stored feedback = %+v, %v; want STOCK with a hash and preview", f, ok)
+	}
+
+	// Recorded but not used until UseFeedback is set.
+	if got := cfg.ClassifyImage(context.Background(), url); got != ClassPhoto || cls.calls != 1 {
+		t.Errorf("without UseFeedback: class = %q after %d calls, want PHOTO from the classifier", got, cls.calls)
+	}
+	cfg.UseFeedback = true
+	if got := cfg.ClassifyImageFull(context.Background(), url); got.Class != ClassStock || cls.calls != 1 {
+		t.Errorf("with UseFeedback: result = %+v after %d calls, want STOCK without calling the classifier", got, cls.calls)
+	}
+}
+
+func TestFeedback_PipelineMatchesByHash(t *testing.T) {
+	t.Parallel()
+
+	body := makeJPEG(1000, 600)
+	original := newImageServer(t, "image/jpeg", body)
+	rehosted := newImageServer(t, "image/jpeg", body)
+	cls := &mockClassifier{response: "PHOTO 0.9"}
+	var sources []string
+	cfg := &Config{
+		HTTPClient:       original.Client(),
+		Classifier:       cls,
+		Feedback:         &memFeedback{},
+		UseFeedback:      true,
+		OnClassification: func(e ClassificationEvent) { sources = append(sources, e.Source) },
+	}
+	if err := cfg.ReportFeedback(context.Background(), original.URL+"/a.jpg", ClassReject); err != nil {
+		t.Fatalf("ReportFeedback: %v", err)
+	}
+
+	cands := []ImageCandidate{{ImgURL: rehosted.URL + "/copy.jpg", Source: rehosted.URL + "/page", License: LicenseUnknown}}
+	if got := cfg.ValidateCandidates(context.Background(), cands, 3); len(got) != 0 {
+		t.Errorf("results = %d, want the re-hosted copy rejected by feedback", len(got))
+	}
+	if cls.calls != 0 {
+		t.Errorf("classifier calls = %d, want 0", cls.calls)
+	}
+	if len(sources) != 1 || sources[0] != "feedback" {
+		t.Errorf("classification sources = %v, want [feedback]", sources)
+	}
+}
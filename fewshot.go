@@ -0,0 +1,79 @@
+package imagefy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// fewShotMaxExamples caps how many labeled examples accompany one
+// classification, leaving room for the image being classified within the
+// inline-image limits of common multimodal APIs.
+const fewShotMaxExamples = 6
+
+// FewShotExample is a labeled reference image sent ahead of the image being
+// classified, to teach the model domain-specific edge cases (menus, posters).
+type FewShotExample struct {
+	Image ImageInput // usually a data: URI built with EncodeDataURL
+	Label string     // expected answer, e.g. "REJECT" or "REJECT (restaurant menu)"
+}
+
+// FeedbackExampleSource is implemented by FeedbackStores that can list
+// recorded verdicts, so Config.FewShotFromFeedback can draw examples from
+// them. It should return the most useful (e.g. most recent) verdicts first.
+type FeedbackExampleSource interface {
+	FeedbackExamples(ctx context.Context, limit int) ([]Feedback, error)
+}
+
+// fewShotExamples returns the examples for one classification: Config.FewShot
+// first, then up to Config.FewShotFromFeedback verdicts from Config.Feedback,
+// capped at fewShotMaxExamples in total. Feedback errors are logged and
+// yield no feedback examples.
+func (cfg *Config) fewShotExamples(ctx context.Context) []FewShotExample {
+	examples := cfg.FewShot
+	if len(examples) > fewShotMaxExamples {
+		examples = examples[:fewShotMaxExamples]
+	}
+	limit := min(cfg.FewShotFromFeedback, fewShotMaxExamples-len(examples))
+	src, ok := cfg.Feedback.(FeedbackExampleSource)
+	if !ok || limit <= 0 {
+		return examples
+	}
+
+	verdicts, err := src.FeedbackExamples(ctx, limit)
+	if err != nil {
+		slog.Debug("imagefy: feedback examples failed", "error", err)
+		return examples
+	}
+	examples = append([]FewShotExample(nil), examples...)
+	for _, f := range verdicts[:min(len(verdicts), limit)] {
+		img := ImageInput{URL: f.URL}
+		if f.Preview != "" {
+			img = ImageInput{URL: f.Preview}
+		}
+		examples = append(examples, FewShotExample{Image: img, Label: f.Verdict})
+	}
+	return examples
+}
+
+// withFewShot prepends examples to the classification request: the images
+// come first, in order, and the prompt gains a list of their labels followed
+// by a pointer to the final image. Without examples prompt and target are
+// returned unchanged.
+func withFewShot(prompt string, target ImageInput, examples []FewShotExample) (string, []ImageInput) {
+	if len(examples) == 0 {
+		return prompt, []ImageInput{target}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "The first %d attached images are labeled examples:\n", len(examples))
+	images := make([]ImageInput, 0, len(examples)+1)
+	for i, ex := range examples {
+		fmt.Fprintf(&b, "Example %d: %s\n", i+1, ex.Label)
+		images = append(images, ex.Image)
+	}
+	b.WriteString("Classify only the last attached image.\n\n")
+	b.WriteString(prompt)
+	return b.String(), append(images, target)
+}
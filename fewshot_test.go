@@ -0,0 +1,79 @@
+package imagefy
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// imagesCapturingClassifier records the prompt and images of the last call.
+type imagesCapturingClassifier struct {
+	prompt string
+	images []ImageInput
+}
+
+func (c *imagesCapturingClassifier) Classify(_ context.Context, prompt string, images []ImageInput) (string, error) {
+	c.prompt, c.images = prompt, images
+	return "PHOTO 0.9", nil
+}
+
+// listingFeedback is a memFeedback that can list its verdicts.
+type listingFeedback struct {
+	memFeedback
+	list []Feedback
+}
+
+func (l *listingFeedback) FeedbackExamples(_ context.Context, limit int) ([]Feedback, error) {
+	return l.list[:min(limit, len(l.list))], nil
+}
+
+func TestFewShot_ExamplesPrecedeTarget(t *testing.T) {
+	t.Parallel()
+
+	cls := &imagesCapturingClassifier{}
+	menu := ImageInput{URL: "data:image/jpeg;base64,bWVudQ=="}
+	cfg := &Config{
+		Classifier: cls,
+		FewShot:    []FewShotExample{{Image: menu, Label: "REJECT (restaurant menu)"}},
+		Feedback: &listingFeedback{list: []Feedback{
+			{URL: "https://example.com/poster.jpg", Verdict: ClassReject, Preview: "data:image/png;base64,cG9zdGVy"},
+			{URL: "https://example.com/street.jpg", Verdict: ClassPhoto},
+			{URL: "https://example.com/unused.jpg", Verdict: ClassPhoto},
+		}},
+		FewShotFromFeedback: 2,
+	}
+	cfg.defaults()
+
+	if _, err := cfg.classifyFromData(context.Background(), "https://example.com/x.jpg", makeJPEG(800, 600), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	if len(cls.images) != 4 {
+		t.Fatalf("images = %d, want 3 examples + target", len(cls.images))
+	}
+	if cls.images[0].URL != menu.URL || cls.images[1].URL != "data:image/png;base64,cG9zdGVy" || cls.images[2].URL != "https://example.com/street.jpg" {
+		t.Errorf("example images = %+v", cls.images[:3])
+	}
+	if cls.images[3].Data == nil {
+		t.Error("target image must come last")
+	}
+	for _, want := range []string{"Example 1: REJECT (restaurant menu)", "Example 3: PHOTO", "last attached image", DefaultVisionPrompt} {
+		if !strings.Contains(cls.prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestFewShot_CapAndNoExamples(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{FewShot: make([]FewShotExample, 10), FewShotFromFeedback: 3, Feedback: &listingFeedback{list: make([]Feedback, 3)}}
+	if got := len(cfg.fewShotExamples(context.Background())); got != fewShotMaxExamples {
+		t.Errorf("examples = %d, want cap %d", got, fewShotMaxExamples)
+	}
+
+	target := ImageInput{URL: "t"}
+	prompt, images := withFewShot("p", target, nil)
+	if prompt != "p" || len(images) != 1 || images[0].URL != target.URL {
+		t.Errorf("withFewShot(nil) = %q, %v; want prompt and target unchanged", prompt, images)
+	}
+}
@@ -2,7 +2,6 @@ package imagefy
 
 import (
 	"context"
-	"sort"
 )
 
 // FindOpts configures a unified image search across all sources.
@@ -27,15 +26,30 @@ const findDefaultMaxResults = 3
 // Backward compat: when PageURL is set but ContentImageProvider finds only og:image,
 // the result is identical to the old OGImageProvider-only behaviour.
 func (cfg *Config) FindImages(ctx context.Context, opts FindOpts) []ImageCandidate {
+	cfg = cfg.orZero()
+	return cfg.findImages(ctx, opts, newSearchState())
+}
+
+// findImages implements FindImages on top of st.
+func (cfg *Config) findImages(ctx context.Context, opts FindOpts, st *searchState) []ImageCandidate {
 	maxResults := opts.MaxResults
 	if maxResults <= 0 {
 		maxResults = findDefaultMaxResults
 	}
 
 	cfg.defaults()
+	cfg = cfg.withVariant(opts.Query).withLanguage(opts.SearchOpts.Language).withTitleLanguage(opts.SearchOpts.RequireTitleLanguage).withRequiredLabels(opts.SearchOpts.RequireLabels).withByteMeter(opts.SearchOpts.MaxTotalBytes)
+	defer cfg.reportBytes()
 
 	var candidates []ImageCandidate
 
+	// A query blocked by moderation skips the provider search only; PageURL
+	// content images and External candidates are still validated.
+	if opts.Query != "" {
+		opts.Query, _ = cfg.moderateQuery(ctx, opts.Query)
+		cfg = cfg.withQueryEmbedding(ctx, opts.Query).withTitleMatch(opts.Query, opts.SearchOpts.MinTitleMatch)
+	}
+
 	// 1. Search providers (if query is set).
 	if opts.Query != "" {
 		searchOpts := opts.SearchOpts
@@ -43,7 +57,10 @@ func (cfg *Config) FindImages(ctx context.Context, opts FindOpts) []ImageCandida
 			searchOpts.PageURL = opts.PageURL
 		}
 		providers := cfg.resolveProviders()
-		candidates = append(candidates, cfg.gatherCandidates(ctx, providers, opts.Query, searchOpts)...)
+		if len(providers) == 0 && cfg.requireDependency(ErrNoProviders) != nil {
+			return nil
+		}
+		candidates = append(candidates, cfg.gatherCandidates(ctx, providers, opts.Query, searchOpts, st)...)
 	}
 
 	// 2. Content image extraction (replaces bare OGImageProvider).
@@ -52,7 +69,8 @@ func (cfg *Config) FindImages(ctx context.Context, opts FindOpts) []ImageCandida
 	//    Skip if the caller has already wired a content or og provider explicitly.
 	if opts.PageURL != "" && !cfg.hasContentProvider() && !cfg.hasOGProvider() {
 		cp := &ContentImageProvider{HTTPClient: cfg.HTTPClient}
-		cpCandidates, _ := cp.Search(ctx, opts.Query, SearchOpts{PageURL: opts.PageURL})
+		cpCandidates, _ := cp.Search(withBlockPolicy(ctx, cfg.BlockPolicy), opts.Query, SearchOpts{PageURL: opts.PageURL})
+		cpCandidates = withProvenance(cpCandidates, cp.Name(), 1)
 		candidates = append(candidates, cpCandidates...)
 	}
 
@@ -63,12 +81,12 @@ func (cfg *Config) FindImages(ctx context.Context, opts FindOpts) []ImageCandida
 		return nil
 	}
 
-	// Sort: safe first.
-	sort.SliceStable(candidates, func(i, j int) bool {
-		return candidates[i].License < candidates[j].License
-	})
+	cfg.prefetchDNS(ctx, candidates)
+
+	// Order: safe first (or interleaved per SearchOpts.Interleave).
+	candidates = orderCandidates(cfg.boostSources(candidates), opts.SearchOpts)
 
-	return cfg.validateCandidates(ctx, candidates, maxResults)
+	return cfg.validateCandidates(ctx, candidates, maxResults, opts.SearchOpts, st)
 }
 
 // hasContentProvider checks if a ContentImageProvider is already in the Providers list.
@@ -0,0 +1,241 @@
+package imagefy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	flickrDefaultURL = "https://api.flickr.com/services/rest/"
+	flickrBodyLimit  = 2 * 1024 * 1024
+	flickrPerPage    = 50
+)
+
+// flickrLicense is one Flickr license ID: its name, reported as
+// ImageCandidate.LicenseName, and its classification.
+type flickrLicense struct {
+	name    string
+	license ImageLicense
+}
+
+// flickrLicenses are the Creative Commons and public domain licenses of
+// flickr.photos.licenses.getInfo. "All Rights Reserved" (0) is absent, as is
+// "No known copyright restrictions" (7, Flickr Commons), which names no
+// license. Licenses that allow commercial reuse are LicenseSafe.
+var flickrLicenses = map[int]flickrLicense{
+	1:  {"CC BY-NC-SA 2.0", LicenseUnknown},
+	2:  {"CC BY-NC 2.0", LicenseUnknown},
+	3:  {"CC BY-NC-ND 2.0", LicenseUnknown},
+	4:  {"CC BY 2.0", LicenseSafe},
+	5:  {"CC BY-SA 2.0", LicenseSafe},
+	6:  {"CC BY-ND 2.0", LicenseUnknown},
+	8:  {"United States Government Work", LicenseSafe},
+	9:  {"CC0 1.0", LicenseSafe},
+	10: {"Public Domain Mark 1.0", LicenseSafe},
+	11: {"CC BY 4.0", LicenseSafe},
+	12: {"CC BY-SA 4.0", LicenseSafe},
+	13: {"CC BY-ND 4.0", LicenseUnknown},
+	14: {"CC BY-NC 4.0", LicenseUnknown},
+	15: {"CC BY-NC-SA 4.0", LicenseUnknown},
+	16: {"CC BY-NC-ND 4.0", LicenseUnknown},
+}
+
+// flickrDefaultLicenses are the IDs searched when FlickrProvider.Licenses is
+// empty: CC BY, CC BY-SA, CC0, public domain, and US Government works.
+var flickrDefaultLicenses = []int{4, 5, 8, 9, 10, 11, 12}
+
+// flickrSizes are the photo size suffixes requested as extras, in order of
+// preference for ImgURL: large (1024px), medium 800, and medium 640.
+var flickrSizes = []string{"l", "c", "z"}
+
+// FlickrProvider searches Flickr photos through the REST API
+// (flickr.photos.search), restricted by its license filter to Creative
+// Commons and public domain photos. Each candidate carries the license name
+// as ImageCandidate.LicenseName, the photo owner's name as Author, and the
+// photo page as Source, for attribution. Engines from SearchOpts are
+// ignored.
+type FlickrProvider struct {
+	APIKey     string       // required: Flickr API key
+	BaseURL    string       // default: "https://api.flickr.com/services/rest/"
+	HTTPClient *http.Client // optional (nil = the default imagefy client)
+	UserAgent  string       // optional
+
+	// Licenses are the Flickr license IDs to search (default: CC BY, CC BY-SA,
+	// CC0, public domain, and US Government works — 4, 5, 8, 9, 10, 11, 12).
+	// IDs that are not Creative Commons or public domain, such as 0 ("All
+	// Rights Reserved"), are ignored. Non-commercial and no-derivatives
+	// licenses yield LicenseUnknown candidates.
+	Licenses []int
+}
+
+// Name returns the provider name.
+func (p *FlickrProvider) Name() string { return "flickr" }
+
+// Search queries Flickr for photos matching query and returns their
+// candidates in relevance order. Photos without a large enough size are
+// skipped.
+func (p *FlickrProvider) Search(ctx context.Context, query string, opts SearchOpts) ([]ImageCandidate, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("flickr: no API key configured")
+	}
+	photos, err := p.fetch(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	return flickrCandidates(photos, opts.KeepRaw), nil
+}
+
+func (p *FlickrProvider) fetch(ctx context.Context, query string, opts SearchOpts) ([]json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.buildURL(query, opts), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.UserAgent != "" {
+		req.Header.Set("User-Agent", p.UserAgent)
+	}
+
+	client := p.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	resp, err := client.Do(req) //nolint:gosec // G107: URL is cfg-supplied by design — SSRF is caller's responsibility
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("flickr: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, flickrBodyLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	var searchResp struct {
+		Stat    string `json:"stat"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Photos  struct {
+			Photo []json.RawMessage `json:"photo"`
+		} `json:"photos"`
+	}
+	if err := json.Unmarshal(body, &searchResp); err != nil {
+		return nil, err
+	}
+	if searchResp.Stat != "ok" {
+		return nil, fmt.Errorf("flickr: %d: %s", searchResp.Code, searchResp.Message)
+	}
+	return searchResp.Photos.Photo, nil
+}
+
+func (p *FlickrProvider) buildURL(query string, opts SearchOpts) string {
+	base := p.BaseURL
+	if base == "" {
+		base = flickrDefaultURL
+	}
+	page := opts.PageNumber
+	if page < 1 {
+		page = 1
+	}
+
+	extras := []string{"license", "owner_name", "url_q"}
+	for _, s := range flickrSizes {
+		extras = append(extras, "url_"+s)
+	}
+
+	v := url.Values{}
+	v.Set("method", "flickr.photos.search")
+	v.Set("api_key", p.APIKey)
+	v.Set("text", query)
+	v.Set("license", p.licenseFilter())
+	v.Set("extras", strings.Join(extras, ","))
+	v.Set("media", "photos")
+	v.Set("content_type", "1") // photos only, no screenshots or art
+	v.Set("sort", "relevance")
+	v.Set("safe_search", "1")
+	v.Set("per_page", strconv.Itoa(flickrPerPage))
+	v.Set("page", strconv.Itoa(page))
+	v.Set("format", "json")
+	v.Set("nojsoncallback", "1")
+	return base + "?" + v.Encode()
+}
+
+// licenseFilter returns the comma-separated license IDs to search: the
+// Creative Commons and public domain IDs among Licenses, or the defaults
+// when there are none.
+func (p *FlickrProvider) licenseFilter() string {
+	var ids []string
+	for _, id := range p.Licenses {
+		if _, ok := flickrLicenses[id]; ok {
+			ids = append(ids, strconv.Itoa(id))
+		}
+	}
+	if len(ids) == 0 {
+		for _, id := range flickrDefaultLicenses {
+			ids = append(ids, strconv.Itoa(id))
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+// flickrCandidates decodes photos field by field, since Flickr has sent
+// sizes and license IDs both as numbers and as strings. keepRaw attaches
+// each photo's JSON.
+func flickrCandidates(photos []json.RawMessage, keepRaw bool) []ImageCandidate {
+	var candidates []ImageCandidate
+	for _, raw := range photos {
+		var photo map[string]json.RawMessage
+		if json.Unmarshal(raw, &photo) != nil || photo == nil {
+			continue
+		}
+		field := func(key string) string { return jsonScalar(photo[key]) }
+		id, _ := strconv.Atoi(field("license"))
+		lic, ok := flickrLicenses[id]
+		if !ok {
+			continue // outside the license filter
+		}
+		var imgURL string
+		var width, height int
+		for _, s := range flickrSizes {
+			if imgURL = field("url_" + s); imgURL != "" {
+				width, _ = strconv.Atoi(field("width_" + s))
+				height, _ = strconv.Atoi(field("height_" + s))
+				break
+			}
+		}
+		if imgURL == "" || IsLogoOrBanner(strings.ToLower(imgURL)) {
+			continue
+		}
+
+		var source string
+		if owner, photoID := field("owner"), field("id"); owner != "" && photoID != "" {
+			source = "https://www.flickr.com/photos/" + url.PathEscape(owner) + "/" + url.PathEscape(photoID) + "/"
+		}
+		c := ImageCandidate{
+			ImgURL:      imgURL,
+			Thumbnail:   field("url_q"),
+			Source:      source,
+			Title:       field("title"),
+			License:     lic.license,
+			LicenseName: lic.name,
+			Author:      field("ownername"),
+			Width:       width,
+			Height:      height,
+		}
+		if keepRaw {
+			c.raw = string(raw)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
@@ -0,0 +1,127 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// flickrFixture is a flickr.photos.search response mixing numeric and string
+// sizes, a photo with only a medium size, one outside the license filter,
+// and one without any requested size.
+const flickrFixture = `{"photos":{"page":1,"pages":1,"perpage":50,"total":4,"photo":[
+ {"id":"5301","owner":"12345@N00","title":"Kul Sharif mosque","license":"5","ownername":"Jane Doe",
+  "url_l":"https://live.staticflickr.com/65535/5301_abc_b.jpg","width_l":1024,"height_l":683,
+  "url_q":"https://live.staticflickr.com/65535/5301_abc_q.jpg"},
+ {"id":"5302","owner":"67890@N01","title":"Kremlin wall","license":"14","ownername":"kazanphoto",
+  "url_c":"https://live.staticflickr.com/65535/5302_def_c.jpg","width_c":"800","height_c":"533"},
+ {"id":"5303","owner":"11111@N02","title":"All rights reserved","license":"0",
+  "url_l":"https://live.staticflickr.com/65535/5303_ghi_b.jpg"},
+ {"id":"5304","owner":"22222@N03","title":"Tiny","license":"4"}
+]},"stat":"ok"}`
+
+func newFlickrServer(t *testing.T, body string, query *url.Values) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if query != nil {
+			*query = r.URL.Query()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFlickrProviderSearch_Attribution(t *testing.T) {
+	t.Parallel()
+
+	srv := newFlickrServer(t, flickrFixture, nil)
+	p := &FlickrProvider{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client()}
+	candidates, err := p.Search(context.Background(), "Kazan", SearchOpts{KeepRaw: true})
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("got %d candidates, want 2 (all-rights-reserved and size-less photos skipped)", len(candidates))
+	}
+
+	want := ImageCandidate{
+		ImgURL:      "https://live.staticflickr.com/65535/5301_abc_b.jpg",
+		Thumbnail:   "https://live.staticflickr.com/65535/5301_abc_q.jpg",
+		Source:      "https://www.flickr.com/photos/12345@N00/5301/",
+		Title:       "Kul Sharif mosque",
+		License:     LicenseSafe,
+		LicenseName: "CC BY-SA 2.0",
+		Author:      "Jane Doe",
+		Width:       1024,
+		Height:      683,
+	}
+	got := candidates[0]
+	if !strings.Contains(got.raw, `"id":"5301"`) {
+		t.Errorf("raw = %q, want the photo's JSON with KeepRaw", got.raw)
+	}
+	got.raw = ""
+	if got != want {
+		t.Errorf("first candidate =\n%+v\nwant\n%+v", got, want)
+	}
+
+	nc := candidates[1]
+	if nc.License != LicenseUnknown || nc.LicenseName != "CC BY-NC 4.0" || nc.Width != 800 || nc.Author != "kazanphoto" {
+		t.Errorf("non-commercial candidate = %+v, want unknown CC BY-NC 4.0 at 800px by kazanphoto", nc)
+	}
+}
+
+func TestFlickrProviderSearch_Request(t *testing.T) {
+	t.Parallel()
+
+	var q url.Values
+	srv := newFlickrServer(t, `{"photos":{"photo":[]},"stat":"ok"}`, &q)
+
+	p := &FlickrProvider{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client()}
+	if _, err := p.Search(context.Background(), "Kazan Kremlin", SearchOpts{PageNumber: 2}); err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	for param, want := range map[string]string{
+		"method": "flickr.photos.search", "api_key": "key", "text": "Kazan Kremlin",
+		"license": "4,5,8,9,10,11,12", "page": "2", "content_type": "1", "nojsoncallback": "1",
+	} {
+		if got := q.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+	if extras := q.Get("extras"); !strings.Contains(extras, "owner_name") || !strings.Contains(extras, "url_l") {
+		t.Errorf("extras = %q, want owner_name and sizes", extras)
+	}
+
+	// Only CC/PD IDs pass: "All Rights Reserved" and unknown IDs are dropped.
+	p.Licenses = []int{0, 9, 10, 99}
+	if _, err := p.Search(context.Background(), "Kazan", SearchOpts{}); err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if got := q.Get("license"); got != "9,10" {
+		t.Errorf("license = %q, want 9,10", got)
+	}
+	p.Licenses = []int{0}
+	_, _ = p.Search(context.Background(), "Kazan", SearchOpts{})
+	if got := q.Get("license"); got != "4,5,8,9,10,11,12" {
+		t.Errorf("license with no CC/PD IDs = %q, want the defaults", got)
+	}
+}
+
+func TestFlickrProviderSearch_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (&FlickrProvider{}).Search(context.Background(), "Kazan", SearchOpts{}); err == nil {
+		t.Error("Search without APIKey returned nil error")
+	}
+
+	srv := newFlickrServer(t, `{"stat":"fail","code":100,"message":"Invalid API Key (Key has invalid format)"}`, nil)
+	p := &FlickrProvider{APIKey: "bad", BaseURL: srv.URL, HTTPClient: srv.Client()}
+	if _, err := p.Search(context.Background(), "Kazan", SearchOpts{}); err == nil || !strings.Contains(err.Error(), "Invalid API Key") {
+		t.Errorf("Search error = %v, want the API failure", err)
+	}
+}
@@ -0,0 +1,54 @@
+package imagefy
+
+import (
+	"sync"
+	"time"
+)
+
+// modifiedAt returns when the downloaded image was last modified: its
+// Last-Modified time or, without one, received minus the Age header — the
+// time a cache stored it, so at least that old. Zero when neither header
+// was sent.
+func (r *DownloadResult) modifiedAt(received time.Time) time.Time {
+	switch {
+	case !r.LastModified.IsZero():
+		return r.LastModified
+	case r.Age > 0:
+		return received.Add(-r.Age)
+	}
+	return time.Time{}
+}
+
+// tooOld reports whether an image last modified at modified is older than
+// Config.MaxImageAge. Images of unknown age are never too old.
+func (cfg *Config) tooOld(modified time.Time) bool {
+	return cfg.MaxImageAge > 0 && !modified.IsZero() && time.Since(modified) > cfg.MaxImageAge
+}
+
+// timeStore records per-image modification times, keyed by ImgURL, so the
+// pipeline can attach them to accepted candidates.
+type timeStore struct {
+	mu sync.Mutex
+	m  map[string]time.Time
+}
+
+func (s *timeStore) set(imgURL string, t time.Time) {
+	if s == nil || t.IsZero() {
+		return
+	}
+	s.mu.Lock()
+	if s.m == nil {
+		s.m = make(map[string]time.Time)
+	}
+	s.m[imgURL] = t
+	s.mu.Unlock()
+}
+
+func (s *timeStore) get(imgURL string) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[imgURL]
+}
@@ -0,0 +1,86 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMaxImageAge(t *testing.T) {
+	t.Parallel()
+
+	old := time.Date(2012, 3, 1, 10, 0, 0, 0, time.UTC)
+	recent := time.Now().Add(-24 * time.Hour).UTC().Truncate(time.Second)
+	body := makeJPEG(1000, 600)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		switch r.URL.Path {
+		case "/old.jpg":
+			w.Header().Set("Last-Modified", old.Format(http.TimeFormat))
+		case "/recent.jpg":
+			w.Header().Set("Last-Modified", recent.Format(http.TimeFormat))
+		case "/cached.jpg":
+			w.Header().Set("Age", "63072000") // two years in caches
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		path     string
+		accepted bool
+		modified time.Time
+	}{
+		{"/old.jpg", false, time.Time{}},
+		{"/cached.jpg", false, time.Time{}},
+		{"/recent.jpg", true, recent},
+		{"/unknown.jpg", true, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+			var reason RejectReason
+			cfg := &Config{
+				MinImageWidth:       100,
+				MaxImageAge:         365 * 24 * time.Hour,
+				OnCandidateRejected: func(e CandidateEvent) { reason = e.Reason },
+			}
+			cand := ImageCandidate{ImgURL: srv.URL + tt.path, Source: "https://commons.wikimedia.org/wiki/A", License: LicenseSafe}
+			got := cfg.ValidateCandidates(context.Background(), []ImageCandidate{cand}, 1)
+			if !tt.accepted {
+				if len(got) != 0 || reason != ReasonTooOld {
+					t.Fatalf("got %d results, reason %q; want rejected as too_old", len(got), reason)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d results, want 1 (reason %q)", len(got), reason)
+			}
+			if !got[0].LastModified.Equal(tt.modified) {
+				t.Errorf("LastModified = %v, want %v", got[0].LastModified, tt.modified)
+			}
+		})
+	}
+}
+
+func TestDownloadResultModifiedAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	lm := now.Add(-48 * time.Hour)
+	cases := []struct {
+		r    DownloadResult
+		want time.Time
+	}{
+		{DownloadResult{LastModified: lm, Age: time.Hour}, lm},
+		{DownloadResult{Age: time.Hour}, now.Add(-time.Hour)},
+		{DownloadResult{}, time.Time{}},
+	}
+	for _, c := range cases {
+		if got := c.r.modifiedAt(now); !got.Equal(c.want) {
+			t.Errorf("modifiedAt(%+v) = %v, want %v", c.r, got, c.want)
+		}
+	}
+}
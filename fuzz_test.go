@@ -0,0 +1,134 @@
+package imagefy
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Fuzz targets for parsers that consume untrusted web content.
+// Seed corpora run as regular tests; use `go test -fuzz=FuzzName` to explore.
+
+func FuzzParseClassificationResult(f *testing.F) {
+	for _, seed := range []string{
+		"PHOTO 0.95", "stock", "<think>x</think>\nREJECT 0.7", "Answer: **MAP** 0.8",
+		"PHOTO NaN", "PHOTO 1e309", "</think>", strings.Repeat("<think>", 100), "",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, resp string) {
+		got := ParseClassificationResult(resp)
+		if got.Confidence < 0 || got.Confidence > 1 || got.Confidence != got.Confidence {
+			t.Errorf("confidence out of range: %v", got.Confidence)
+		}
+		if got.Class == "" && got.Confidence != 0 {
+			t.Errorf("confidence %v without class", got.Confidence)
+		}
+	})
+}
+
+func FuzzExtractCCLicense(f *testing.F) {
+	for _, seed := range []string{
+		`<a rel="license" href="https://creativecommons.org/licenses/by/4.0/">CC</a>`,
+		`<link href='//creativecommons.org/publicdomain/zero/1.0/' rel='license'>`,
+		`<meta content="http://creativecommons.org/licenses/by-sa/3.0/">`,
+		`<a rel="license" href="`, "",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, pageHTML string) {
+		got := ExtractCCLicense(pageHTML)
+		if got != "" && !IsCCLicenseURL(got) {
+			t.Errorf("ExtractCCLicense returned non-CC URL %q", got)
+		}
+	})
+}
+
+func FuzzExtractOGImageURL(f *testing.F) {
+	for _, seed := range []string{
+		`<meta property="og:image" content="https://example.com/a.jpg">`,
+		`<meta content="https://example.com/b.jpg" property="og:image" />`,
+		`<meta property="og:image" content="&amp;&lt;">`,
+		`<meta property="og:image"`, "",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, pageHTML string) {
+		got := ExtractOGImageURL(pageHTML)
+		if len(got) > len(pageHTML) {
+			t.Errorf("extracted URL longer than input: %d > %d", len(got), len(pageHTML))
+		}
+	})
+}
+
+func FuzzExtractImageMetadata(f *testing.F) {
+	for _, seed := range [][]byte{
+		{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10},
+		{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x08, 'E', 'x', 'i', 'f', 0, 0},
+		{0xFF, 0xD8, 0xFF, 0xED, 0xFF, 0xFF},
+		[]byte("\x89PNG\r\n\x1a\n"),
+		[]byte("RIFF\x00\x00\x00\x00WEBPVP8X"),
+		[]byte("II*\x00\x08\x00\x00\x00"),
+		nil,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(_ *testing.T, data []byte) {
+		meta := ExtractImageMetadata(data)
+		_ = IsStockByMetadata(meta)
+		_ = IsCCByMetadata(meta)
+	})
+}
+
+func FuzzDecodeSearxngResults(f *testing.F) {
+	for _, seed := range []string{
+		`{"results":[{"img_src":"https://a.com/x.jpg","url":"https://a.com","title":"t"}]}`,
+		`{"results":[{"img_src":"//shutterstock.com/x.jpg"}]}`,
+		`{"results":[{"img_src":"http://[::1"}]}`,
+		`{"results":null}`, `[]`, `{`, "",
+	} {
+		f.Add(seed)
+	}
+	p := &SearXNGProvider{}
+	f.Fuzz(func(t *testing.T, body string) {
+		results, err := decodeSearxngResults([]byte(body))
+		if err != nil {
+			return
+		}
+		for _, c := range p.filter(results, nil) {
+			if c.License == LicenseBlocked {
+				t.Errorf("blocked candidate returned: %q", c.ImgURL)
+			}
+			if !utf8.ValidString(c.ImgURL) && utf8.ValidString(body) {
+				t.Errorf("invalid UTF-8 in ImgURL %q", c.ImgURL)
+			}
+		}
+	})
+}
+
+func TestParseClassificationResult_HugeInput(t *testing.T) {
+	t.Parallel()
+
+	resp := strings.Repeat("thinking... ", 1<<20) + "\nSTOCK 0.9"
+	got := ParseClassificationResult(resp)
+	if got.Class != ClassStock {
+		t.Errorf("Class = %q, want %q", got.Class, ClassStock)
+	}
+}
+
+func TestExtractors_HugeInputTruncated(t *testing.T) {
+	t.Parallel()
+
+	padding := strings.Repeat("x", maxHTMLScanBytes)
+	tail := `<meta property="og:image" content="https://example.com/a.jpg">` +
+		`<a rel="license" href="https://creativecommons.org/licenses/by/4.0/">`
+	if got := ExtractOGImageURL(padding + tail); got != "" {
+		t.Errorf("ExtractOGImageURL scanned past limit: %q", got)
+	}
+	if got := ExtractCCLicense(padding + tail); got != "" {
+		t.Errorf("ExtractCCLicense scanned past limit: %q", got)
+	}
+	if got := ExtractOGImageURL(tail + padding); got != "https://example.com/a.jpg" {
+		t.Errorf("ExtractOGImageURL = %q, want match within limit", got)
+	}
+}
@@ -12,10 +12,23 @@ var ogImageRe = regexp.MustCompile(
 		`<meta\s+[^>]*content=["']([^"']+)["'][^>]*property=["']og:image["']`,
 )
 
+// maxHTMLScanBytes caps how much HTML the extractors scan. Matches the largest
+// page body limit used by the providers, so real pages are never truncated.
+const maxHTMLScanBytes = 2 * 1024 * 1024
+
+// truncateHTML limits pageHTML to maxHTMLScanBytes.
+func truncateHTML(pageHTML string) string {
+	if len(pageHTML) > maxHTMLScanBytes {
+		return pageHTML[:maxHTMLScanBytes]
+	}
+	return pageHTML
+}
+
 // ExtractOGImageURL pulls the og:image URL from raw HTML.
+// Only the first 2MB of pageHTML are scanned.
 // Returns empty string if not found.
 func ExtractOGImageURL(pageHTML string) string {
-	m := ogImageRe.FindStringSubmatch(pageHTML)
+	m := ogImageRe.FindStringSubmatch(truncateHTML(pageHTML))
 	if m == nil {
 		return ""
 	}
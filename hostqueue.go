@@ -0,0 +1,70 @@
+package imagefy
+
+import "sync"
+
+// maxHostSlots is how many validation slots candidates from one image host
+// may hold while candidates from other hosts wait, so a slow host cannot
+// take every slot.
+const maxHostSlots = validationSemaphore - 1
+
+// hostQueue admits validation candidates sharded by image host: the next
+// candidate is the first pending one whose host holds fewer than
+// maxHostSlots slots and is not held back by the Session's per-host rate
+// limit. When every pending candidate is blocked that way, the first one is
+// admitted anyway, so slots never idle while work is queued. It is safe for
+// concurrent use.
+type hostQueue struct {
+	candidates []ImageCandidate
+	limiter    *hostLimiter // nil = no per-host rate limit
+
+	mu       sync.Mutex
+	pending  []int // positions not yet admitted, in input order
+	inFlight map[string]int
+}
+
+func newHostQueue(candidates []ImageCandidate, limiter *hostLimiter) *hostQueue {
+	pending := make([]int, len(candidates))
+	for i := range pending {
+		pending[i] = i
+	}
+	return &hostQueue{candidates: candidates, limiter: limiter, pending: pending, inFlight: make(map[string]int)}
+}
+
+// next removes and returns the position of the candidate to admit, and
+// false when none are pending. The candidate's host is charged a slot
+// until done is called.
+func (q *hostQueue) next() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return 0, false
+	}
+	pick := 0
+	for k, pos := range q.pending {
+		host := extractHost(q.candidates[pos].ImgURL)
+		if q.inFlight[host] < maxHostSlots && q.limiter.delay(host) == 0 {
+			pick = k
+			break
+		}
+	}
+	pos := q.pending[pick]
+	q.pending = append(q.pending[:pick], q.pending[pick+1:]...)
+	q.inFlight[extractHost(q.candidates[pos].ImgURL)]++
+	return pos, true
+}
+
+// done releases the host slot of the candidate at pos.
+func (q *hostQueue) done(pos int) {
+	q.mu.Lock()
+	q.inFlight[extractHost(q.candidates[pos].ImgURL)]--
+	q.mu.Unlock()
+}
+
+// drain removes and returns the positions still pending, in input order.
+func (q *hostQueue) drain() []int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	rest := q.pending
+	q.pending = nil
+	return rest
+}
@@ -0,0 +1,115 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHostQueue(t *testing.T) {
+	t.Parallel()
+
+	cands := []ImageCandidate{
+		{ImgURL: "https://slow.example/1.jpg"},
+		{ImgURL: "https://slow.example/2.jpg"},
+		{ImgURL: "https://slow.example/3.jpg"},
+		{ImgURL: "https://fast.example/1.jpg"},
+		{ImgURL: "https://slow.example/4.jpg"},
+	}
+	q := newHostQueue(cands, nil)
+	var order []int
+	for range 3 {
+		i, _ := q.next()
+		order = append(order, i)
+	}
+	if want := []int{0, 1, 3}; !equalInts(order, want) {
+		t.Fatalf("admitted %v, want %v: the third slow candidate waits for the other host", order, want)
+	}
+	// Only slow.example is left: it is admitted rather than idling a slot.
+	if i, ok := q.next(); !ok || i != 2 {
+		t.Errorf("next = %d, %v; want 2", i, ok)
+	}
+	q.done(0)
+	if i, ok := q.next(); !ok || i != 4 {
+		t.Errorf("next = %d, %v; want 4", i, ok)
+	}
+	if _, ok := q.next(); ok {
+		t.Error("next on an empty queue reported a candidate")
+	}
+}
+
+func TestHostQueue_RateLimitedHostDeferred(t *testing.T) {
+	t.Parallel()
+
+	limiter := &hostLimiter{interval: time.Hour, next: make(map[string]time.Time)}
+	limiter.wait(context.Background(), "https://limited.example/0.jpg") // reserves the next hour
+	q := newHostQueue([]ImageCandidate{
+		{ImgURL: "https://limited.example/1.jpg"},
+		{ImgURL: "https://free.example/1.jpg"},
+	}, limiter)
+	if i, _ := q.next(); i != 1 {
+		t.Errorf("next = %d, want the candidate whose host is not rate limited", i)
+	}
+	if rest := q.drain(); !equalInts(rest, []int{0}) {
+		t.Errorf("drain = %v, want [0]", rest)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestValidateCandidates_SlowHostDoesNotStallOthers(t *testing.T) {
+	t.Parallel()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(encodeJPEG(t, makeCheckerImage(1000, 600, 25)))
+	}))
+	t.Cleanup(slow.Close)
+	fast := newImageServer(t, "image/jpeg", encodeJPEG(t, makeGradientImage(1000, 600, 0)))
+
+	var (
+		mu       sync.Mutex
+		fastDone time.Duration
+	)
+	start := time.Now()
+	cfg := &Config{
+		MinImageWidth: 100,
+		OnCandidateAccepted: func(e CandidateEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			if e.Candidate.ImgURL == fast.URL+"/a.jpg" {
+				fastDone = time.Since(start)
+			}
+		},
+	}
+	cands := []ImageCandidate{
+		{ImgURL: slow.URL + "/1.jpg", Source: slow.URL + "/1", License: LicenseSafe},
+		{ImgURL: slow.URL + "/2.jpg", Source: slow.URL + "/2", License: LicenseSafe},
+		{ImgURL: slow.URL + "/3.jpg", Source: slow.URL + "/3", License: LicenseSafe},
+		{ImgURL: fast.URL + "/a.jpg", Source: fast.URL + "/a", License: LicenseSafe},
+	}
+	cfg.ValidateCandidates(context.Background(), cands, 5)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fastDone == 0 || fastDone >= 300*time.Millisecond {
+		t.Errorf("fast-host candidate accepted after %v, want before the slow host answered", fastDone)
+	}
+}
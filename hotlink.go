@@ -0,0 +1,86 @@
+package imagefy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// maxPlaceholderSide is the largest width and height of an image treated
+// as a hotlink placeholder (tracking-pixel style 1×1 or 2×2 responses).
+const maxPlaceholderSide = 2
+
+// isHotlinkResponse reports whether a probe response looks like a hotlink
+// block: 401 or 403 with an HTML body (an "access denied" page) instead of
+// the image.
+func isHotlinkResponse(status int, contentType string) bool {
+	return (status == http.StatusForbidden || status == http.StatusUnauthorized) &&
+		strings.Contains(strings.ToLower(contentType), "text/html")
+}
+
+// isPlaceholderSize reports whether an image of width×height is a
+// placeholder pixel rather than content.
+func isPlaceholderSize(width, height int) bool {
+	return width > 0 && height > 0 && width <= maxPlaceholderSide && height <= maxPlaceholderSide
+}
+
+// isHotlinkPlaceholder reports whether data is one of
+// Config.HotlinkPlaceholders by SHA-256.
+func (cfg *Config) isHotlinkPlaceholder(data []byte) bool {
+	if len(cfg.HotlinkPlaceholders) == 0 || len(data) == 0 {
+		return false
+	}
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	for _, h := range cfg.HotlinkPlaceholders {
+		if strings.EqualFold(strings.TrimSpace(h), digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// hotlinkFallback prepares a hotlink-blocked candidate for a second
+// attempt from its source page: the page is fetched and, if it references
+// the same image under another URL (a signed link, another CDN host),
+// ImgURL is switched to it; either way the retry sends the page as
+// Referer, which most hotlink guards require. It reports false when cand
+// has no usable Source or was already retried.
+func (cfg *Config) hotlinkFallback(ctx context.Context, cand ImageCandidate) (ImageCandidate, bool) {
+	if cand.referer != "" || sanitizeURL(cand.Source, "") == "" {
+		return cand, false
+	}
+	cand.referer = cand.Source
+
+	p := &ContentImageProvider{HTTPClient: cfg.validationClient()}
+	found, _ := p.Search(withBlockPolicy(ctx, cfg.BlockPolicy), "", SearchOpts{PageURL: cand.Source})
+	for _, f := range found {
+		if f.ImgURL != cand.ImgURL && sameImageFile(f.ImgURL, cand.ImgURL) {
+			slog.Debug("imagefy: hotlink fallback re-extracted image", "url", cand.ImgURL, "from_page", f.ImgURL)
+			cand.ImgURL = f.ImgURL
+			break
+		}
+	}
+	return cand, true
+}
+
+// sameImageFile reports whether a and b name the same asset (see assetKey)
+// or the same file name on the same registrable domain.
+func sameImageFile(a, b string) bool {
+	ka, kb := assetKey(a), assetKey(b)
+	if ka == kb {
+		return true
+	}
+	ua, errA := url.Parse(ka)
+	ub, errB := url.Parse(kb)
+	if errA != nil || errB != nil || registrableDomain(a) != registrableDomain(b) {
+		return false
+	}
+	name := path.Base(ua.Path)
+	return name != "/" && name != "." && name == path.Base(ub.Path)
+}
@@ -0,0 +1,114 @@
+package imagefy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newHotlinkServer serves /guarded/*.jpg only with a Referer of its /page,
+// /signed/cat.jpg with a signature, and a page referencing the latter.
+func newHotlinkServer(t *testing.T, body []byte) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deny := func() {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("<html><body>Hotlinking is not allowed</body></html>"))
+		}
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><body><img src="` + srv.URL + `/signed/cat.jpg?sig=abc"></body></html>`))
+		case "/guarded/dog.jpg":
+			if r.Header.Get("Referer") != srv.URL+"/page" {
+				deny()
+				return
+			}
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write(body)
+		case "/signed/cat.jpg":
+			if r.URL.Query().Get("sig") != "abc" {
+				deny()
+				return
+			}
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write(body)
+		case "/pixel.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write(makeJPEG(1, 1))
+		case "/blocked.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write(makeJPEG(900, 600))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHotlinkFallback(t *testing.T) {
+	t.Parallel()
+
+	srv := newHotlinkServer(t, makeJPEG(1000, 600))
+	placeholder := sha256.Sum256(makeJPEG(900, 600))
+	page := srv.URL + "/page"
+
+	tests := []struct {
+		name   string
+		cand   ImageCandidate
+		want   string // accepted ImgURL; "" = rejected
+		reason RejectReason
+	}{
+		{"referer retry", ImageCandidate{ImgURL: srv.URL + "/guarded/dog.jpg", Source: page}, srv.URL + "/guarded/dog.jpg", ""},
+		{"re-extracted from page", ImageCandidate{ImgURL: srv.URL + "/signed/cat.jpg?sig=expired", Source: page}, srv.URL + "/signed/cat.jpg?sig=abc", ""},
+		{"no source page", ImageCandidate{ImgURL: srv.URL + "/guarded/dog.jpg"}, "", ReasonHotlinkBlocked},
+		{"placeholder pixel", ImageCandidate{ImgURL: srv.URL + "/pixel.jpg", Source: page}, "", ReasonHotlinkBlocked},
+		{"known placeholder", ImageCandidate{ImgURL: srv.URL + "/blocked.jpg", Source: page}, "", ReasonHotlinkBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var reason RejectReason
+			cfg := &Config{
+				HTTPClient:          srv.Client(),
+				MinImageWidth:       100,
+				HotlinkPlaceholders: []string{hex.EncodeToString(placeholder[:])},
+				OnCandidateRejected: func(e CandidateEvent) { reason = e.Reason },
+			}
+			cand := tt.cand
+			cand.License = LicenseSafe
+			got := cfg.ValidateCandidates(context.Background(), []ImageCandidate{cand}, 1)
+			if tt.want == "" {
+				if len(got) != 0 || reason != tt.reason {
+					t.Fatalf("got %d results, reason %q; want rejected as %q", len(got), reason, tt.reason)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].ImgURL != tt.want {
+				t.Fatalf("got %+v (reason %q), want %s accepted", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsHotlinkResponse(t *testing.T) {
+	t.Parallel()
+
+	if !isHotlinkResponse(http.StatusForbidden, "text/html; charset=utf-8") {
+		t.Error("403 HTML not detected")
+	}
+	for _, c := range []struct {
+		status int
+		ct     string
+	}{{http.StatusForbidden, "application/json"}, {http.StatusNotFound, "text/html"}, {http.StatusOK, "text/html"}} {
+		if isHotlinkResponse(c.status, c.ct) {
+			t.Errorf("isHotlinkResponse(%d, %q) = true", c.status, c.ct)
+		}
+	}
+}
@@ -10,9 +10,16 @@ import (
 const DefaultMinImageWidth = 880
 
 // ImageInput represents an image for multimodal LLM classification.
+// URL is always set; Data carries the same image's bytes when they are
+// already in memory, so adapters can choose their own wire format instead of
+// decoding the data: URI. MIMEType, Width, and Height describe Data, for
+// payload decisions such as image detail levels or resizing.
 type ImageInput struct {
 	URL      string // data: URI or HTTP URL
 	MIMEType string // e.g. "image/jpeg"
+	Data     []byte // raw image bytes, nil if only URL is known
+	Width    int    // decoded pixel width (0 = unknown)
+	Height   int    // decoded pixel height (0 = unknown)
 }
 
 // Cache abstracts key-value caching (Redis, sync.Map, etc.)
@@ -28,30 +35,337 @@ type Classifier interface {
 }
 
 // Config holds all dependencies injected by the consumer.
+//
+// Every field is optional. A zero-value Config — or a nil *Config, which
+// behaves the same — still validates, downloads, and license-checks images
+// using http.DefaultClient and the built-in domain lists; features whose
+// dependency is missing degrade gracefully instead of failing:
+//   - no Providers or SearxngURL: SearchImages returns nil; FindImages still
+//     uses PageURL content images and External candidates;
+//   - no Classifier: ClassifyImage returns "" (accept) and the vision stage is
+//     skipped; PickBest returns ErrNoClassifier;
+//   - no Cache: every classification calls the Classifier, and metadata and
+//     perceptual hashes are recomputed for every download;
+//   - no OxBrowserURL: ReverseCheck returns a zero ReverseResult.
 type Config struct {
 	Cache         Cache        // required for ClassifyImage (nil = no caching)
 	Classifier    Classifier   // required for ClassifyImage (nil = skip classification)
 	StealthClient *http.Client // optional: TLS-fingerprinted client for downloads
-	HTTPClient    *http.Client // optional: default http client (nil = http.DefaultClient)
+	HTTPClient    *http.Client // optional: default http client (nil = a shared client on NewTransport)
 	SearxngURL    string       // required for SearchImages when Providers is empty
 	MinImageWidth int          // default: DefaultMinImageWidth (880)
 	UserAgent     string       // default: "Mozilla/5.0 (compatible; go-imagefy/1.0)"
 
+	// HotlinkPlaceholders lists SHA-256 digests (hex) of the "image
+	// blocked" placeholders some hosts serve in place of hotlinked images.
+	// A download matching one is treated like a hotlink block: retried from
+	// the candidate's source page, then rejected with ReasonHotlinkBlocked
+	// rather than classified. 1×1 and 2×2 pixels always are.
+	HotlinkPlaceholders []string
+
+	// PlaceholderHashes adds perceptual fingerprints (see PlaceholderHash)
+	// to BuiltinPlaceholders. A downloaded image within a few bits of one is
+	// rejected during dedup with ReasonPlaceholder.
+	PlaceholderHashes []string
+
+	// MaxImageAge rejects images whose download response says they were
+	// last modified longer ago than this (see ImageCandidate.LastModified)
+	// with ReasonTooOld, e.g. to keep decade-old photos of a since
+	// renovated venue out of news content. Images without Last-Modified or
+	// Age headers pass. 0 = off.
+	MaxImageAge time.Duration
+
+	// MinAspectRatio and MaxAspectRatio bound width/height of accepted
+	// images (e.g. 1.2 rejects portraits, 2.5 rejects banners); zero = no
+	// bound. Checked with MinImageWidth.
+	MinAspectRatio float64
+	MaxAspectRatio float64
+
 	// Providers is an optional list of search backends. When non-empty, these are
 	// used instead of auto-creating a SearXNGProvider from SearxngURL.
 	// When multiple providers are supplied, results are merged and sorted by license.
 	Providers []SearchProvider
 
+	// PageSize is the number of images SearchPage returns per call
+	// (default: 10).
+	PageSize int
+
 	// VisionPrompt overrides the default classification prompt (DefaultVisionPrompt).
 	// Set this to customize the LLM instruction for ClassifyImageFull / ClassifyImage.
 	VisionPrompt string
 
+	// ClassifierConcurrency caps concurrent Classifier calls across every
+	// method of this Config; further calls queue. When set, the pipeline's
+	// vision stage no longer holds one of the 3 validation slots, so vision
+	// throughput is tuned independently of download parallelism. Zero =
+	// vision shares the validation slots (previous behavior). Read once, on
+	// the first classification.
+	ClassifierConcurrency int
+
+	// Strict makes a missing dependency fail instead of silently degrading,
+	// logged at Error level. Without a Classifier, ClassifyImageErr returns
+	// ErrNoClassifier and IsRealPhoto returns false whatever the
+	// DegradationPolicy. Without a provider, searches (SearchImages,
+	// FindImages with FindOpts.Query set, Session, and SearchImagesBatch)
+	// return no images, even PageURL or External ones, and
+	// SearchImagesResult returns ErrNoProviders as SearchResult.Err.
+	// SearchPage returns ErrNoProviders whether or not Strict is set.
+	// Nothing panics. Use it with Validate to catch mis-wired dependency
+	// injection at startup and in tests.
+	Strict bool
+
+	// DegradationPolicy decides what happens when a validation check fails
+	// to run (download, dedup decode, reverse search, or Classifier error)
+	// instead of deciding (default: DegradeAccept).
+	DegradationPolicy DegradationPolicy
+
+	// ClassifierMaxRetries is how many times a Classify call that returns a
+	// *RateLimitedError is retried (default: 3; negative = never). While
+	// throttled, all classifications of this Config wait out the Retry-After
+	// delay; a retry that cannot finish before the context deadline (e.g.
+	// SearchOpts.PerCandidateTimeout) is not attempted.
+	ClassifierMaxRetries int
+
+	// OnClassifierThrottle is called for every rate-limited Classify call,
+	// for throttle metrics. Called concurrently.
+	OnClassifierThrottle func(ThrottleEvent)
+
+	// EnginesByScript chooses the SearXNG engines of a search from the
+	// script of its query (see QueryScript) when SearchOpts.Engines is
+	// empty, e.g. adding Yandex Images for Cyrillic queries only:
+	//
+	//	EnginesByScript: map[imagefy.Script][]string{
+	//		imagefy.ScriptCyrillic: {"yandex images", "bing images", "google images"},
+	//		imagefy.ScriptLatin:    {"bing images", "google images"},
+	//	}
+	//
+	// A script without an entry searches all engines. Engine names are those
+	// of the SearXNG instance.
+	EnginesByScript map[Script][]string
+
+	// Experiment assigns an A/B Variant to each SearchImages / FindImages
+	// call (including Session, batch, and diverse searches) from its query.
+	// The variant's prompt and thresholds apply to that search only, and
+	// its Label is attached to every event the search emits. Called
+	// concurrently.
+	Experiment func(query string) Variant
+
+	// QueryModerator screens every query before SearchImages, FindImages,
+	// Session, and batch searches send it to a provider, blocking or
+	// rewriting disallowed ones. A blocked SearchImages returns nil; a
+	// blocked FindImages still uses PageURL and External. See BlockTerms.
+	QueryModerator QueryModerator
+
+	// Feedback persists human verdicts reported with ReportFeedback.
+	Feedback FeedbackStore
+
+	// ClassifyByURL sends HTTP(S) image URLs to the Classifier as is,
+	// without Data, instead of downloading the image (up to 200KB) and
+	// inlining it as a data: URI. ClassifyImageFull then skips its download,
+	// and the pipeline's vision stage sends the URL even when its own
+	// download was truncated or failed. Use it only with a Classifier whose
+	// model provider fetches image URLs itself, such as classifiers/openai
+	// against the OpenAI API; the gemini and ollama adapters download URLs
+	// locally, so it saves nothing there.
+	ClassifyByURL bool
+
+	// QuickVision classifies in two passes: first a short YES/NO "is this
+	// an original photograph?" prompt (DefaultQuickVisionPrompt) on a
+	// low-resolution preview, then the full prompt only when the answer is
+	// below QuickVisionConfidence, lacks a confidence, or cannot be parsed.
+	// A confident YES is a PHOTO verdict, a confident NO a REJECT one.
+	// Images sent by URL (ClassifyByURL) and searches with
+	// SearchOpts.RequireLabels always get the full prompt.
+	QuickVision bool
+
+	// QuickVisionConfidence is the confidence a QuickVision answer needs to
+	// decide without the full prompt (default: 0.85).
+	QuickVisionConfidence float64
+
+	// VerifySafeDomains classifies LicenseSafe candidates (free-photo
+	// domains such as Unsplash, CC metadata, TrustedCreators) with the
+	// Classifier like unknown-license ones, instead of accepting them as
+	// photos once the probe and dedup checks pass, so illustrations and
+	// memes hosted on safe domains are rejected. The reverse image search
+	// stays skipped for them. Requires Classifier; ignored otherwise.
+	VerifySafeDomains bool
+
+	// PreviewFormat transcodes inlined vision previews to this MIME type
+	// at PreviewQuality before they are sent to the Classifier, shrinking
+	// multimodal requests. "image/jpeg" is built in; "image/webp" and
+	// "image/avif" need PreviewEncoder. A preview that does not decode (e.g.
+	// a ClassifyImageFull download truncated at 200KB), fails to encode, or
+	// comes out no smaller is sent as downloaded. "" = no transcoding.
+	// classifiers/gemini and classifiers/openai accept WebP; none of the
+	// bundled adapters accepts AVIF.
+	PreviewFormat string
+
+	// PreviewQuality is the lossy quality (1–100) of transcoded previews
+	// (default: 75).
+	PreviewQuality int
+
+	// PreviewEncoder encodes previews in formats other than JPEG. imagefy
+	// has no WebP or AVIF encoder of its own; wrap a codec binding such as
+	// libwebp or libavif.
+	PreviewEncoder PreviewEncoder
+
+	// WatermarkLocator locates the watermark of every STOCK verdict (e.g. a
+	// computer-vision detector) into ClassificationResult.Watermark, so
+	// images whose credit overlay sits in a croppable margin can be rescued
+	// (see WatermarkBox.MarginCrop).
+	WatermarkLocator WatermarkLocator
+
+	// LocateWatermarks asks the Classifier for the watermark location of
+	// every STOCK verdict that lacks one, with DefaultWatermarkPrompt — one
+	// extra call per STOCK image. Ignored when WatermarkLocator is set.
+	LocateWatermarks bool
+
+	// UseFeedback makes classifications (ClassifyImageFull and the
+	// pipeline's vision stage) reuse a recorded verdict for the same URL or
+	// perceptual hash instead of calling the Classifier. Requires Feedback.
+	UseFeedback bool
+
+	// FewShot are labeled example images sent ahead of every image the
+	// Classifier classifies (at most 6 together with FewShotFromFeedback),
+	// for domain-specific edge cases. Requires a Classifier that accepts
+	// several images per request, as PickBest does.
+	FewShot []FewShotExample
+
+	// FewShotFromFeedback adds up to this many recorded verdicts as
+	// examples, when Feedback implements FeedbackExampleSource. Queried on
+	// every classification.
+	FewShotFromFeedback int
+
+	// Embedder optionally supplies image embeddings for FindSimilar, which
+	// otherwise compares perceptual hashes and color palettes. In the
+	// validation pipeline it adds semantic dedup (same landmark, different
+	// angle) after the perceptual check and, if it also implements
+	// TextEmbedder, scores each image's relevance to the search query into
+	// ImageCandidate.Relevance.
+	Embedder Embedder
+
+	// SemanticDedupThreshold is the embedding cosine similarity at or above
+	// which an image duplicates an earlier one (default: 0.92; negative =
+	// no semantic dedup). Used only with Embedder.
+	SemanticDedupThreshold float64
+
+	// PrefixDedupBytes enables a cheap duplicate check before download: the
+	// probe hashes the first PrefixDedupBytes of the image (at most 256KB)
+	// together with its Content-Length, and a later candidate with the same
+	// fingerprint — the same file mirrored on another host — is rejected as
+	// ReasonDuplicate without being downloaded. Responses without a
+	// Content-Length or with a Content-Encoding are not fingerprinted.
+	// 0 = off; 16384 is a good start.
+	PrefixDedupBytes int
+
+	// MinRelevance rejects images whose query relevance is below it with
+	// ReasonIrrelevant (0 = score only). Used only with a TextEmbedder.
+	MinRelevance float64
+
+	// PickBestPrompt overrides DefaultPickBestPrompt for PickBest. It must contain
+	// a single %s verb, which receives the search query.
+	PickBestPrompt string
+
 	// ExtraBlockedDomains are additional stock/copyrighted domains to block.
 	ExtraBlockedDomains []string
 
 	// ExtraSafeDomains are additional free/CC domains to treat as safe.
 	ExtraSafeDomains []string
 
+	// BlockPolicy licenses images from built-in BlockedDomains, and images
+	// credited to a built-in stock agency in their metadata, by
+	// BlockCategory: LicenseUnknown keeps them as any unlisted source
+	// (validated and classified), LicenseSafe treats them as free sources,
+	// e.g. freemium assets under the customer's own plan. Missing
+	// categories stay LicenseBlocked. Built-in providers apply it at search
+	// time, through the search context, so wrapping providers keep it as
+	// long as they pass ctx on; BlockedURLPatterns and ExtraBlockedDomains
+	// always block.
+	BlockPolicy map[BlockCategory]ImageLicense
+
+	// TrustedCreators are staff photographers and partner agencies whose
+	// images are Safe regardless of hosting domain, matched
+	// (case-insensitive, whole words) against the EXIF Artist, IPTC
+	// By-line, and XMP/DC Creator metadata. Stock agency metadata and a
+	// blocked provider license (ImageCandidate.LicenseName) still block
+	// them. With it set, the
+	// validation pipeline downloads ExtraBlockedDomains images instead of
+	// rejecting them up front, to read their metadata. Candidates dropped
+	// at search time by the built-in stock list are not affected.
+	TrustedCreators []string
+
+	// UpgradeSizeVariants makes the validation pipeline try the original of
+	// a resized image URL (WordPress "-300x200" suffixes, ?w=640 resize
+	// parameters, /thumb/ paths) before validating it. When the original
+	// passes the HTTP probe it replaces ImgURL, and the resized URL becomes
+	// the Thumbnail if there is none. Costs one extra probe per matching
+	// candidate.
+	UpgradeSizeVariants bool
+
+	// HedgeDelay is the default DownloadOpts.HedgeDelay, used by the
+	// validation pipeline's downloads: a second GET is started when the first
+	// has not finished after this delay. Zero disables hedging.
+	HedgeDelay time.Duration
+
+	// DownloadConcurrency caps simultaneous image requests (probes and
+	// downloads, including hedged ones) across every search and method of
+	// this Config; further requests queue until a slot frees or their
+	// context ends. Zero = unlimited. Read once, on the first download.
+	DownloadConcurrency int
+
+	// DownloadBandwidth caps the combined image download rate of this
+	// Config in bytes per second (0 = unlimited). Read once, on the first
+	// download.
+	DownloadBandwidth int64
+
+	// StealthHosts are image hosts (and their subdomains) known to block
+	// default Go clients. With StealthClient set, their probes and downloads
+	// go straight to StealthClient instead of failing a direct attempt first.
+	StealthHosts []string
+
+	// HostProfiles tailor the User-Agent and headers of probes and
+	// downloads per image host, keyed by lower-case host name; an entry also
+	// applies to subdomains without their own. A UseStealth profile makes
+	// the host one of StealthHosts.
+	HostProfiles map[string]Profile
+
+	// StealthPolicy decides when other hosts use StealthClient (default:
+	// StealthFallback, after a failed direct download).
+	StealthPolicy StealthPolicy
+
+	// DoH optionally resolves image hosts over DNS-over-HTTPS for probes and
+	// direct (HTTPClient) downloads, for networks where the system resolver is
+	// geo-blocked or poisoned. Requires HTTPClient.Transport to be nil or an
+	// *http.Transport; other RoundTrippers are used unchanged.
+	DoH *DoHResolver
+
+	// PrefetchDNS pre-resolves the unique image hosts of a search's
+	// candidates concurrently once they are gathered, overlapping DNS latency
+	// with the license sort and validation start-up. Without DoH, answers are
+	// kept for a minute and reused by probes and direct downloads, with the
+	// same HTTPClient.Transport requirement as DoH; with DoH, they warm its
+	// cache.
+	PrefetchDNS bool
+
+	// Subscription optionally supplies remotely managed blocked/safe lists,
+	// applied in addition to ExtraBlockedDomains / ExtraSafeDomains.
+	// Start its Run loop separately.
+	Subscription *ListSubscription
+
+	// SourceBoosts raise (positive) or lower (negative) candidates in the
+	// validation order within their license class, by source host. Keys are
+	// host names or domain suffixes such as "gov", "edu", "wikimedia.org",
+	// or an official city portal, and match the host and its subdomains (a
+	// leading "*." is ignored); the most specific matching key applies and
+	// unlisted hosts count as 0. Since the pipeline stops at maxResults,
+	// boosted authoritative sources win over blogs with the same license.
+	SourceBoosts map[string]int
+
+	// Reputation remembers per-domain validation outcomes in Cache across
+	// runs and demotes or skips domains whose images are usually rejected
+	// (see DomainReputation). nil = off.
+	Reputation *DomainReputation
+
 	// OxBrowserURL is the base URL of the ox-browser service for reverse image search.
 	// When set, enables reverse stock detection in the validation pipeline.
 	// Example: "http://ox-browser:8901" or "http://127.0.0.1:8901".
@@ -59,8 +373,27 @@ type Config struct {
 
 	// Optional callbacks for metrics/logging.
 	OnImageSearch    func()
+	OnSearchBytes    func(ByteStats) // optional: image bytes read by each SearchImages / FindImages call, for egress metrics
 	OnPanic          func(tag string, r any)
 	OnClassification func(ClassificationEvent) // optional: audit log for every classification decision
+
+	// Optional per-candidate lifecycle callbacks. Exactly one of them fires for
+	// every candidate the validation pipeline is given, including those left
+	// unvalidated once enough were accepted (ReasonNotValidated). Called concurrently
+	// from validation goroutines — implementations must be safe for concurrent use.
+	OnCandidateAccepted func(CandidateEvent)
+	OnCandidateRejected func(CandidateEvent)
+
+	classifier    *classifierLimiter // concurrency and rate-limit state, created on first use
+	downloads     *downloadPool      // DownloadConcurrency, DownloadBandwidth, and PrefetchDNS state, created on first use
+	meter         *byteMeter         // image bytes of one search, set on the per-search copy made by withByteMeter
+	variant       Variant            // set on the per-search copy made by withVariant
+	language      string             // SearchOpts.Language, set on the per-search copy made by withLanguage
+	titleLanguage bool               // SearchOpts.RequireTitleLanguage, set on the per-search copy made by withTitleLanguage
+	queryVec      []float32          // query text embedding, set on the per-search copy made by withQueryEmbedding
+	requireLabels []string           // SearchOpts.RequireLabels, set on the per-search copy made by withRequiredLabels
+	titleQuery    string             // query for SearchOpts.MinTitleMatch, set on the per-search copy made by withTitleMatch
+	minTitleMatch float64            // SearchOpts.MinTitleMatch, set with titleQuery
 }
 
 // SearchOpts configures image search behavior.
@@ -68,8 +401,123 @@ type Config struct {
 type SearchOpts struct {
 	PageNumber int           // SearXNG page number (default: 1)
 	Engines    []string      // SearXNG engines to use (default: all)
-	Timeout    time.Duration // search timeout (default: 15s)
+	Timeout    time.Duration // overall deadline of provider search and validation together (default: 30s)
 	PageURL    string        // page URL for OG image extraction (used by OGImageProvider)
+
+	// SearchTimeout and ValidationTimeout give the two phases of a search
+	// separate budgets, so slow validation can no longer eat the window that
+	// providers left unused, nor slow providers the validation window. When
+	// either is set, providers are bounded by SearchTimeout and validation
+	// (including PickBest) by ValidationTimeout, measured from when the
+	// providers finish; an unset phase defaults to Timeout (or 30s). When
+	// both are zero, Timeout bounds the two phases together.
+	SearchTimeout     time.Duration
+	ValidationTimeout time.Duration
+
+	// Language is a BCP 47 tag (e.g. "ru") for the search: SearXNG receives
+	// it as its language parameter (and as Accept-Language unless
+	// SearXNGProvider.AcceptLanguage is set), and the vision prompt is told that text
+	// in images may be in that language, so local signage is not mistaken
+	// for a reason to reject. Empty = no hint.
+	Language string
+
+	// RequireTitleLanguage rejects candidates whose title is evidently in
+	// another language than Language — written in another script, or
+	// detected as another language by DetectLanguage — with
+	// ReasonWrongLanguage before they are downloaded, e.g. English-captioned
+	// stock renders in a Russian venue search. Titles shorter than 8
+	// letters or without a clear signal are kept. Ignored without Language.
+	RequireTitleLanguage bool
+
+	// Interleave selects how safe and unknown-license candidates are ordered
+	// for validation (default: InterleaveStrict, all safe before all unknown).
+	Interleave Interleave
+	// SafeWeight is the number of safe candidates taken per unknown one by
+	// InterleaveWeighted (default: 2).
+	SafeWeight int
+
+	// MaxPerDomain caps how many accepted images may come from the same source
+	// host (0 = unlimited), for visually diverse galleries.
+	MaxPerDomain int
+
+	// PerCandidateTimeout bounds the whole validation of one candidate (probe,
+	// download, license checks, reverse search, vision), measured from when it
+	// gets a validation slot, so one slow CDN cannot starve the candidates
+	// queued behind it. Candidates that run out are rejected with
+	// ReasonTimeout. Zero = bounded only by the search context.
+	PerCandidateTimeout time.Duration
+
+	// MaxTotalBytes caps the image bytes one search may read (probes and
+	// downloads, 0 = unlimited). Once spent, further image requests fail and
+	// the remaining candidates are rejected with ReasonByteBudget; results
+	// already accepted are returned. Traffic is reported to
+	// Config.OnSearchBytes either way.
+	MaxTotalBytes int64
+
+	// KeepRaw attaches the JSON each built-in provider (SearXNG,
+	// Openverse, Wikimedia, Flickr, Bing, Pexels) returned for a candidate to its CandidateEvent
+	// and AuditRecord as Raw, so schema mismatches and missing fields can
+	// be diagnosed from production logs. Raw results can be large; enable
+	// it for debugging.
+	KeepRaw bool
+
+	// MinTitleMatch rejects candidates whose TitleMatch score against the
+	// query — the share of query words found in the title and URL slugs,
+	// transliteration-aware — is below it (e.g. 0.5), with
+	// ReasonTitleMismatch before they are downloaded. Candidates without a
+	// title or slug words are not judged. 0 = off. The match is lexical, so
+	// a query in one language does not match titles in another.
+	MinTitleMatch float64
+
+	// RequireLabels restricts the search to images of these topics (e.g.
+	// ["FOOD", "INTERIOR"] for a restaurant): the vision prompt asks the
+	// model to tag each image with the ones that apply, and images tagged
+	// with none are rejected with ReasonOffTopic. Safe-license images are
+	// classified too instead of being accepted unchecked. Requires
+	// Config.Classifier; ignored otherwise.
+	RequireLabels []string
+
+	// PickBest enables a final comparative ranking stage: validated results are
+	// sent to the Classifier in one multimodal request and the model's choice is
+	// moved to the front. The previews are the bytes validation already
+	// downloaded, so the stage costs one Classifier call and no new downloads.
+	// Requires Config.Classifier; ignored otherwise.
+	PickBest bool
+
+	// Ranker re-ranks validated candidates before the results are cut to
+	// maxResults: the pipeline validates a larger pool (Overshoot, or by
+	// default 3×maxResults, at least maxResults and otherwise at most 30)
+	// and keeps the first maxResults the Ranker returns. Each candidate comes
+	// with its query relevance, license verdict, and vision verdict (see
+	// RankedCandidate). PickBest, if set, runs afterwards.
+	Ranker Ranker
+
+	// Overshoot validates up to maxResults×Overshoot candidates (e.g. 2)
+	// instead of stopping at the first maxResults accepted, and returns the
+	// best maxResults of that pool: in the Ranker's order, otherwise by
+	// query relevance (with a TextEmbedder) or in validation order. Values
+	// of 1 or less keep the default.
+	Overshoot float64
+}
+
+// orZero returns c, or a new zero-value Config if c is nil. Exported methods
+// call it first so that a nil *Config behaves like &Config{}.
+func (c *Config) orZero() *Config {
+	if c == nil {
+		return &Config{}
+	}
+	return c
+}
+
+// derive returns a shallow copy of c for one search, so per-search settings
+// never leak into the caller's Config. The copy shares c's classifier
+// limiter and download pool, so concurrency caps, rate-limit pauses, and
+// bandwidth caps still apply across searches.
+func (c *Config) derive() *Config {
+	c.limiter()
+	c.pool()
+	d := *c
+	return &d
 }
 
 // defaults fills zero-value fields with sensible defaults.
@@ -82,6 +530,16 @@ func (c *Config) defaults() { //nolint:unused // called by Layer 1/2 methods add
 		c.UserAgent = "Mozilla/5.0 (compatible; go-imagefy/1.0)"
 	}
 	if c.HTTPClient == nil {
-		c.HTTPClient = http.DefaultClient
+		c.HTTPClient = defaultHTTPClient
+	}
+}
+
+// ApplyDefaults fills zero-value fields (MinImageWidth, UserAgent,
+// HTTPClient) with the defaults every search otherwise fills in place on
+// first use. Call it once before sharing c between goroutines, so their
+// first searches don't race on those writes.
+func (c *Config) ApplyDefaults() {
+	if c != nil {
+		c.defaults()
 	}
 }
@@ -0,0 +1,222 @@
+// Package imagefytest provides ready-made test doubles for code that depends
+// on go-imagefy: a fake SearchProvider, Classifier, Cache and FeedbackStore, plus an
+// in-process image server that serves generated JPEGs.
+//
+//	srv := imagefytest.NewImageServer(t)
+//	cfg := &imagefy.Config{
+//		Providers:  []imagefy.SearchProvider{&imagefytest.Provider{Candidates: []imagefy.ImageCandidate{
+//			{ImgURL: srv.Add("/a.jpg", imagefytest.Image{Width: 1200, Height: 800}), License: imagefy.LicenseUnknown},
+//		}}},
+//		Classifier: &imagefytest.Classifier{Response: "PHOTO 0.9"},
+//		Cache:      imagefytest.NewCache(),
+//		HTTPClient: srv.Client(),
+//	}
+package imagefytest
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+// Provider is a fake imagefy.SearchProvider returning fixed candidates.
+// It is safe for concurrent use.
+type Provider struct {
+	ProviderName string                   // default: "fake"
+	Candidates   []imagefy.ImageCandidate // returned by every Search call
+	Err          error                    // when non-nil, Search returns it instead
+
+	mu      sync.Mutex
+	queries []string
+}
+
+// Compile-time check that Provider satisfies imagefy.SearchProvider.
+var _ imagefy.SearchProvider = (*Provider)(nil)
+
+// Name returns ProviderName or "fake".
+func (p *Provider) Name() string {
+	if p.ProviderName != "" {
+		return p.ProviderName
+	}
+	return "fake"
+}
+
+// Search records the query and returns Candidates or Err.
+func (p *Provider) Search(_ context.Context, query string, _ imagefy.SearchOpts) ([]imagefy.ImageCandidate, error) {
+	p.mu.Lock()
+	p.queries = append(p.queries, query)
+	p.mu.Unlock()
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	out := make([]imagefy.ImageCandidate, len(p.Candidates))
+	copy(out, p.Candidates)
+	return out, nil
+}
+
+// Queries returns the queries received so far, in call order.
+func (p *Provider) Queries() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.queries...)
+}
+
+// Call is one recorded Classifier invocation.
+type Call struct {
+	Prompt string
+	Images []imagefy.ImageInput
+}
+
+// Classifier is a fake imagefy.Classifier. Func takes precedence over
+// Response/Err when set. It is safe for concurrent use.
+type Classifier struct {
+	Response string // returned when Func is nil
+	Err      error  // returned when Func is nil
+	Func     func(prompt string, images []imagefy.ImageInput) (string, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Compile-time check that Classifier satisfies imagefy.Classifier.
+var _ imagefy.Classifier = (*Classifier)(nil)
+
+// Classify records the call and returns the configured response.
+func (c *Classifier) Classify(_ context.Context, prompt string, images []imagefy.ImageInput) (string, error) {
+	c.mu.Lock()
+	c.calls = append(c.calls, Call{Prompt: prompt, Images: images})
+	c.mu.Unlock()
+	if c.Func != nil {
+		return c.Func(prompt, images)
+	}
+	return c.Response, c.Err
+}
+
+// Calls returns the recorded invocations, in call order.
+func (c *Classifier) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// CallCount returns the number of Classify invocations.
+func (c *Classifier) CallCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
+
+// Cache is an in-memory imagefy.Cache. Values are stored as JSON so Get can
+// decode into any destination type, matching the behaviour of Redis-backed caches.
+// It is safe for concurrent use.
+type Cache struct {
+	mu    sync.Mutex
+	store map[string][]byte
+}
+
+// Compile-time check that Cache satisfies imagefy.Cache.
+var _ imagefy.Cache = (*Cache)(nil)
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{store: map[string][]byte{}}
+}
+
+// Key joins prefix and value with a colon.
+func (c *Cache) Key(prefix, value string) string { return prefix + ":" + value }
+
+// Get decodes the cached value for key into dest.
+func (c *Cache) Get(_ context.Context, key string, dest any) bool {
+	c.mu.Lock()
+	data, ok := c.store[key]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(data, dest) == nil
+}
+
+// Set stores value under key. Values that cannot be JSON-encoded are ignored.
+func (c *Cache) Set(_ context.Context, key string, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	if c.store == nil {
+		c.store = map[string][]byte{}
+	}
+	c.store[key] = data
+	c.mu.Unlock()
+}
+
+// Len returns the number of cached entries.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.store)
+}
+
+// FeedbackStore is an in-memory imagefy.FeedbackStore. It is safe for
+// concurrent use.
+type FeedbackStore struct {
+	mu    sync.Mutex
+	store map[string]imagefy.Feedback
+}
+
+// Compile-time checks that FeedbackStore satisfies imagefy.FeedbackStore and
+// imagefy.FeedbackExampleSource.
+var (
+	_ imagefy.FeedbackStore         = (*FeedbackStore)(nil)
+	_ imagefy.FeedbackExampleSource = (*FeedbackStore)(nil)
+)
+
+// NewFeedbackStore returns an empty FeedbackStore.
+func NewFeedbackStore() *FeedbackStore {
+	return &FeedbackStore{store: map[string]imagefy.Feedback{}}
+}
+
+// SaveFeedback stores f under key.
+func (s *FeedbackStore) SaveFeedback(_ context.Context, key string, f imagefy.Feedback) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store == nil {
+		s.store = map[string]imagefy.Feedback{}
+	}
+	s.store[key] = f
+	return nil
+}
+
+// LookupFeedback returns the verdict stored under key.
+func (s *FeedbackStore) LookupFeedback(_ context.Context, key string) (imagefy.Feedback, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.store[key]
+	return f, ok
+}
+
+// FeedbackExamples returns up to limit verdicts saved under "url:" keys,
+// most recent first.
+func (s *FeedbackStore) FeedbackExamples(_ context.Context, limit int) ([]imagefy.Feedback, error) {
+	s.mu.Lock()
+	var out []imagefy.Feedback
+	for key, f := range s.store {
+		if strings.HasPrefix(key, "url:") {
+			out = append(out, f)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].At.After(out[j].At) })
+	return out[:min(limit, len(out))], nil
+}
+
+// Len returns the number of stored entries.
+func (s *FeedbackStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.store)
+}
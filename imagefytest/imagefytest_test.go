@@ -0,0 +1,153 @@
+package imagefytest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	_ "image/jpeg"
+	"net/http"
+	"testing"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+func TestGenerateJPEG_Dimensions(t *testing.T) {
+	t.Parallel()
+
+	data := GenerateJPEG(JPEGOpts{Width: 1000, Height: 600})
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if format != "jpeg" || cfg.Width != 1000 || cfg.Height != 600 {
+		t.Errorf("got %s %dx%d, want jpeg 1000x600", format, cfg.Width, cfg.Height)
+	}
+}
+
+func TestGenerateJPEG_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	a := GenerateJPEG(JPEGOpts{Width: 64, Height: 64, Seed: "x"})
+	b := GenerateJPEG(JPEGOpts{Width: 64, Height: 64, Seed: "x"})
+	c := GenerateJPEG(JPEGOpts{Width: 64, Height: 64, Seed: "y"})
+	if !bytes.Equal(a, b) {
+		t.Error("equal seeds produced different images")
+	}
+	if bytes.Equal(a, c) {
+		t.Error("different seeds produced identical images")
+	}
+}
+
+func TestImageServer_ServesRegisteredImages(t *testing.T) {
+	t.Parallel()
+
+	srv := NewImageServer(t)
+	u := srv.Add("/photo.jpg", Image{Width: 900, Height: 700})
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/jpeg" {
+		t.Errorf("status=%d ct=%q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if srv.Hits("/photo.jpg") != 1 {
+		t.Errorf("Hits = %d, want 1", srv.Hits("/photo.jpg"))
+	}
+
+	req, _ = http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/missing.jpg", nil)
+	resp2, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET missing: %v", err)
+	}
+	resp2.Body.Close()
+	if resp2.StatusCode != http.StatusNotFound {
+		t.Errorf("missing status = %d, want 404", resp2.StatusCode)
+	}
+}
+
+func TestFakes_EndToEndPipeline(t *testing.T) {
+	t.Parallel()
+
+	srv := NewImageServer(t)
+	prov := &Provider{Candidates: []imagefy.ImageCandidate{
+		{ImgURL: srv.Add("/a.jpg", Image{}), Source: srv.URL + "/page-a", License: imagefy.LicenseUnknown},
+		{ImgURL: srv.Add("/b.jpg", Image{}), Source: srv.URL + "/page-b", License: imagefy.LicenseUnknown},
+		{ImgURL: srv.Add("/narrow.jpg", Image{Width: 300}), Source: srv.URL + "/page-c", License: imagefy.LicenseUnknown},
+	}}
+	cls := &Classifier{Response: "PHOTO 0.9"}
+	cache := NewCache()
+	cfg := &imagefy.Config{
+		Providers:  []imagefy.SearchProvider{prov},
+		Classifier: cls,
+		Cache:      cache,
+		HTTPClient: srv.Client(),
+	}
+
+	results := cfg.SearchImages(context.Background(), "venue", 5)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2 (narrow image rejected)", len(results))
+	}
+	if got := prov.Queries(); len(got) != 1 || got[0] != "venue" {
+		t.Errorf("Queries = %v, want [venue]", got)
+	}
+	if cls.CallCount() != 2 {
+		t.Errorf("classifier calls = %d, want 2", cls.CallCount())
+	}
+	if cache.Len() != 4 {
+		t.Errorf("cache entries = %d, want 4 (2 classifications + 2 assets)", cache.Len())
+	}
+}
+
+func TestCache_RoundTripsStructs(t *testing.T) {
+	t.Parallel()
+
+	c := NewCache()
+	key := c.Key("p", "v")
+	c.Set(context.Background(), key, imagefy.ClassificationResult{Class: "PHOTO", Confidence: 0.5})
+
+	var got imagefy.ClassificationResult
+	if !c.Get(context.Background(), key, &got) || got.Class != "PHOTO" || got.Confidence != 0.5 {
+		t.Errorf("Get = %+v", got)
+	}
+	if c.Get(context.Background(), "missing", &got) {
+		t.Error("Get(missing) = true, want false")
+	}
+}
+
+func TestProvider_Error(t *testing.T) {
+	t.Parallel()
+
+	want := errors.New("down")
+	p := &Provider{ProviderName: "x", Err: want}
+	if _, err := p.Search(context.Background(), "q", imagefy.SearchOpts{}); !errors.Is(err, want) {
+		t.Errorf("err = %v, want %v", err, want)
+	}
+	if p.Name() != "x" {
+		t.Errorf("Name = %q, want x", p.Name())
+	}
+}
+
+func TestFeedbackStore_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	s := NewFeedbackStore()
+	f := imagefy.Feedback{URL: "https://example.com/a.jpg", Verdict: imagefy.ClassStock}
+	if err := s.SaveFeedback(context.Background(), "url:"+f.URL, f); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := s.LookupFeedback(context.Background(), "url:"+f.URL); !ok || got != f {
+		t.Errorf("LookupFeedback = %+v, %v", got, ok)
+	}
+	if _, ok := s.LookupFeedback(context.Background(), "url:missing"); ok || s.Len() != 1 {
+		t.Errorf("LookupFeedback(missing) = %v, Len = %d", ok, s.Len())
+	}
+
+	_ = s.SaveFeedback(context.Background(), "dhash:abc", f)
+	if got, _ := s.FeedbackExamples(context.Background(), 5); len(got) != 1 {
+		t.Errorf("FeedbackExamples = %d verdicts, want 1 (dhash keys are duplicates)", len(got))
+	}
+}
@@ -0,0 +1,201 @@
+package imagefytest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"html"
+	"strings"
+)
+
+// JPEG markers and segment headers.
+var (
+	jpegSOI      = []byte{0xFF, 0xD8}
+	exifHeader   = []byte("Exif\x00\x00")
+	xmpHeader    = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	iptcHeader   = []byte("Photoshop 3.0\x00")
+	markerAPP1   = byte(0xE1)
+	markerAPP13  = byte(0xED)
+	iptcBlockID  = uint16(0x0404)
+	iptcTagMark  = byte(0x1C)
+	iptcRecord2  = byte(2)
+	exifTagASCII = uint16(2)
+)
+
+// IPTC application record (2) dataset numbers.
+const (
+	iptcByline    = 80
+	iptcCredit    = 110
+	iptcSource    = 115
+	iptcCopyright = 116
+)
+
+// EXIF IFD0 tag IDs.
+const (
+	exifArtist    = 0x013B
+	exifCopyright = 0x8298
+)
+
+// segment is one JPEG marker segment (marker byte + payload without length).
+type segment struct {
+	marker  byte
+	payload []byte
+}
+
+// metadataSegments builds the APP segments for opts. The EXIF segment is
+// always emitted first when any metadata is present: decoders that share the
+// APP1 marker between EXIF and XMP expect EXIF to precede XMP.
+func metadataSegments(opts JPEGOpts) []segment {
+	xmp := buildXMP(opts)
+	iptc := buildIPTC(opts)
+	hasEXIF := opts.EXIFCopyright != "" || opts.EXIFArtist != ""
+	if !hasEXIF && xmp == nil && iptc == nil {
+		return nil
+	}
+
+	segs := []segment{{marker: markerAPP1, payload: buildEXIF(opts)}}
+	if xmp != nil {
+		segs = append(segs, segment{marker: markerAPP1, payload: xmp})
+	}
+	if iptc != nil {
+		segs = append(segs, segment{marker: markerAPP13, payload: iptc})
+	}
+	return segs
+}
+
+// insertSegments inserts segs right after the SOI marker of jpegData.
+func insertSegments(jpegData []byte, segs []segment) []byte {
+	if len(segs) == 0 || !bytes.HasPrefix(jpegData, jpegSOI) {
+		return jpegData
+	}
+	var buf bytes.Buffer
+	buf.Write(jpegSOI)
+	for _, s := range segs {
+		buf.WriteByte(0xFF)
+		buf.WriteByte(s.marker)
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(s.payload)+2)) //nolint:gosec // payloads are far below 64KB
+		buf.Write(s.payload)
+	}
+	buf.Write(jpegData[len(jpegSOI):])
+	return buf.Bytes()
+}
+
+// buildEXIF returns an "Exif\0\0"-prefixed little-endian TIFF block with an
+// IFD0 holding the ASCII Artist and Copyright tags.
+func buildEXIF(opts JPEGOpts) []byte {
+	type entry struct {
+		tag   uint16
+		value string
+	}
+	var entries []entry
+	// IFD entries must be sorted by tag ID.
+	if opts.EXIFArtist != "" {
+		entries = append(entries, entry{exifArtist, opts.EXIFArtist})
+	}
+	if opts.EXIFCopyright != "" {
+		entries = append(entries, entry{exifCopyright, opts.EXIFCopyright})
+	}
+
+	const tiffHeaderLen, ifdEntryLen = 8, 12
+	le := binary.LittleEndian
+	ifdLen := 2 + len(entries)*ifdEntryLen + 4
+	dataOffset := tiffHeaderLen + ifdLen
+
+	var ifd, data bytes.Buffer
+	_ = binary.Write(&ifd, le, uint16(len(entries))) //nolint:gosec // at most two entries
+	for _, e := range entries {
+		value := append([]byte(e.value), 0)
+		_ = binary.Write(&ifd, le, e.tag)
+		_ = binary.Write(&ifd, le, exifTagASCII)
+		_ = binary.Write(&ifd, le, uint32(len(value))) //nolint:gosec // test strings are short
+		if len(value) <= 4 {                           //nolint:mnd // values up to 4 bytes are stored inline
+			var inline [4]byte
+			copy(inline[:], value)
+			ifd.Write(inline[:])
+			continue
+		}
+		_ = binary.Write(&ifd, le, uint32(dataOffset+data.Len())) //nolint:gosec // offsets are small
+		data.Write(value)
+	}
+	_ = binary.Write(&ifd, le, uint32(0)) // no next IFD
+
+	var buf bytes.Buffer
+	buf.Write(exifHeader)
+	buf.WriteString("II*\x00")
+	_ = binary.Write(&buf, le, uint32(tiffHeaderLen))
+	buf.Write(ifd.Bytes())
+	buf.Write(data.Bytes())
+	return buf.Bytes()
+}
+
+// buildXMP returns an XMP packet segment payload, or nil when no XMP field is set.
+func buildXMP(opts JPEGOpts) []byte {
+	var attrs, children strings.Builder
+	attr := func(name, value string) {
+		if value != "" {
+			attrs.WriteString("\n   " + name + `="` + html.EscapeString(value) + `"`)
+		}
+	}
+	attr("cc:license", opts.XMPLicense)
+	attr("xmpRights:WebStatement", opts.XMPWebStatement)
+	attr("xmpRights:UsageTerms", opts.XMPUsageTerms)
+	if opts.XMPMarked {
+		attr("xmpRights:Marked", "True")
+	}
+	if opts.DCRights != "" {
+		children.WriteString("\n   <dc:rights><rdf:Alt><rdf:li xml:lang=\"x-default\">" +
+			html.EscapeString(opts.DCRights) + "</rdf:li></rdf:Alt></dc:rights>")
+	}
+	if opts.DCCreator != "" {
+		children.WriteString("\n   <dc:creator><rdf:Seq><rdf:li>" +
+			html.EscapeString(opts.DCCreator) + "</rdf:li></rdf:Seq></dc:creator>")
+	}
+	if attrs.Len() == 0 && children.Len() == 0 {
+		return nil
+	}
+
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+   xmlns:dc="http://purl.org/dc/elements/1.1/"
+   xmlns:xmpRights="http://ns.adobe.com/xap/1.0/rights/"
+   xmlns:cc="http://creativecommons.org/ns#"` + attrs.String() + `>` + children.String() + `
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+	return append(append([]byte(nil), xmpHeader...), packet...)
+}
+
+// buildIPTC returns a Photoshop IRB segment payload holding one 8BIM 0x0404
+// IPTC-IIM block, or nil when no IPTC field is set.
+func buildIPTC(opts JPEGOpts) []byte {
+	var records bytes.Buffer
+	record := func(dataset byte, value string) {
+		if value == "" {
+			return
+		}
+		records.WriteByte(iptcTagMark)
+		records.WriteByte(iptcRecord2)
+		records.WriteByte(dataset)
+		_ = binary.Write(&records, binary.BigEndian, uint16(len(value))) //nolint:gosec // test strings are short
+		records.WriteString(value)
+	}
+	record(iptcByline, opts.IPTCByline)
+	record(iptcCredit, opts.IPTCCredit)
+	record(iptcSource, opts.IPTCSource)
+	record(iptcCopyright, opts.IPTCCopyright)
+	if records.Len() == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(iptcHeader)
+	buf.WriteString("8BIM")
+	_ = binary.Write(&buf, binary.BigEndian, iptcBlockID)
+	buf.Write([]byte{0, 0})                                         // empty Pascal name, padded to even length
+	_ = binary.Write(&buf, binary.BigEndian, uint32(records.Len())) //nolint:gosec // small
+	buf.Write(records.Bytes())
+	if records.Len()%2 == 1 {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,127 @@
+package imagefytest
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"testing"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+func TestGenerateJPEG_MetadataRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	data := GenerateJPEG(JPEGOpts{
+		Width:           800,
+		Height:          600,
+		EXIFCopyright:   "(c) Shutterstock",
+		EXIFArtist:      "Jane Doe",
+		IPTCCopyright:   "Getty Images",
+		IPTCCredit:      "Getty Images",
+		IPTCSource:      "iStock",
+		XMPLicense:      "https://creativecommons.org/licenses/by/4.0/",
+		XMPWebStatement: "https://example.com/rights",
+		DCRights:        "CC BY 4.0",
+		DCCreator:       "John Smith",
+	})
+
+	meta := imagefy.ExtractImageMetadata(data)
+	if meta == nil {
+		t.Fatal("ExtractImageMetadata returned nil")
+	}
+	checks := []struct{ name, got, want string }{
+		{"EXIFCopyright", meta.EXIFCopyright, "(c) Shutterstock"},
+		{"EXIFArtist", meta.EXIFArtist, "Jane Doe"},
+		{"IPTCCopyright", meta.IPTCCopyright, "Getty Images"},
+		{"IPTCCredit", meta.IPTCCredit, "Getty Images"},
+		{"IPTCSource", meta.IPTCSource, "iStock"},
+		{"XMPLicense", meta.XMPLicense, "https://creativecommons.org/licenses/by/4.0/"},
+		{"XMPWebStatement", meta.XMPWebStatement, "https://example.com/rights"},
+		{"DCRights", meta.DCRights, "CC BY 4.0"},
+		{"DCCreator", meta.DCCreator, "John Smith"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width != 800 || cfg.Height != 600 {
+		t.Errorf("DecodeConfig = %dx%d, %v; want 800x600", cfg.Width, cfg.Height, err)
+	}
+}
+
+func TestGenerateJPEG_StockAndCCDetection(t *testing.T) {
+	t.Parallel()
+
+	stock := imagefy.ExtractImageMetadata(GenerateJPEG(JPEGOpts{IPTCCopyright: "Shutterstock"}))
+	if !imagefy.IsStockByMetadata(stock) {
+		t.Errorf("IPTC-only stock image not detected: %+v", stock)
+	}
+
+	cc := imagefy.ExtractImageMetadata(GenerateJPEG(JPEGOpts{XMPLicense: "https://creativecommons.org/licenses/by-sa/4.0/"}))
+	if !imagefy.IsCCByMetadata(cc) {
+		t.Errorf("XMP-only CC image not detected: %+v", cc)
+	}
+}
+
+func TestGenerateJPEG_NoMetadata(t *testing.T) {
+	t.Parallel()
+
+	if meta := imagefy.ExtractImageMetadata(GenerateJPEG(JPEGOpts{Width: 32, Height: 32})); meta != nil {
+		t.Errorf("ExtractImageMetadata = %+v, want nil", meta)
+	}
+}
+
+func TestGenerateJPEG_WatermarkChangesImage(t *testing.T) {
+	t.Parallel()
+
+	plain := GenerateJPEG(JPEGOpts{Width: 128, Height: 128, Seed: "w"})
+	marked := GenerateJPEG(JPEGOpts{Width: 128, Height: 128, Seed: "w", Watermark: true})
+	if bytes.Equal(plain, marked) {
+		t.Error("watermarked image is identical to plain image")
+	}
+}
+
+func TestGenerateJPEG_BylineAndMarked(t *testing.T) {
+	t.Parallel()
+
+	meta := imagefy.ExtractImageMetadata(GenerateJPEG(JPEGOpts{IPTCByline: "Alamy Stock Photo", XMPMarked: true}))
+	if meta == nil {
+		t.Fatal("ExtractImageMetadata returned nil")
+	}
+	if meta.IPTCByline != "Alamy Stock Photo" {
+		t.Errorf("IPTCByline = %q, want %q", meta.IPTCByline, "Alamy Stock Photo")
+	}
+	if !meta.XMPMarked {
+		t.Error("XMPMarked = false, want true")
+	}
+}
+
+func TestPipeline_MetadataBlocksStockAndPromotesCC(t *testing.T) {
+	t.Parallel()
+
+	srv := NewImageServer(t)
+	stockURL := srv.Add("/stock.jpg", Image{Body: GenerateJPEG(JPEGOpts{Seed: "stock", IPTCCredit: "Getty Images"})})
+	ccURL := srv.Add("/cc.jpg", Image{Body: GenerateJPEG(JPEGOpts{Seed: "cc", XMPLicense: "https://creativecommons.org/licenses/by/4.0/"})})
+
+	cls := &Classifier{Response: "PHOTO 0.9"}
+	cfg := &imagefy.Config{
+		Providers: []imagefy.SearchProvider{&Provider{Candidates: []imagefy.ImageCandidate{
+			{ImgURL: stockURL, Source: srv.URL + "/a", License: imagefy.LicenseUnknown},
+			{ImgURL: ccURL, Source: srv.URL + "/b", License: imagefy.LicenseUnknown},
+		}}},
+		Classifier: cls,
+		HTTPClient: srv.Client(),
+	}
+
+	results := cfg.SearchImages(context.Background(), "q", 5)
+	if len(results) != 1 || results[0].ImgURL != ccURL {
+		t.Fatalf("results = %+v, want only the CC image", results)
+	}
+	if cls.CallCount() != 0 {
+		t.Errorf("classifier called %d times, want 0 (stock blocked, CC safe by metadata)", cls.CallCount())
+	}
+}
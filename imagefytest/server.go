@@ -0,0 +1,192 @@
+package imagefytest
+
+import (
+	"bytes"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Default generated image dimensions.
+const (
+	DefaultWidth  = 1200
+	DefaultHeight = 800
+)
+
+// blockGrid is the number of flat-colour blocks per side in a generated image.
+// Each path gets a different block pattern so generated images are never
+// perceptual duplicates of each other.
+const blockGrid = 8
+
+// Image describes a response served by ImageServer.
+type Image struct {
+	Width       int    // default: DefaultWidth
+	Height      int    // default: DefaultHeight
+	Status      int    // default: 200
+	ContentType string // default: "image/jpeg"
+	Body        []byte // raw body; when nil a JPEG is generated (use GenerateJPEG for metadata)
+	Seed        string // pattern seed; default: the request path
+}
+
+// ImageServer is an in-process HTTP server serving generated images.
+// Unregistered paths return 404.
+type ImageServer struct {
+	*httptest.Server
+
+	mu     sync.RWMutex
+	images map[string][]byte
+	specs  map[string]Image
+	hits   map[string]int
+}
+
+// NewImageServer starts an ImageServer closed automatically via t.Cleanup.
+func NewImageServer(t testing.TB) *ImageServer {
+	t.Helper()
+	s := &ImageServer{
+		images: map[string][]byte{},
+		specs:  map[string]Image{},
+		hits:   map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Add registers img under path and returns its absolute URL.
+func (s *ImageServer) Add(path string, img Image) string {
+	if img.Seed == "" {
+		img.Seed = path
+	}
+	body := img.Body
+	if body == nil {
+		body = GenerateJPEG(JPEGOpts{Width: img.Width, Height: img.Height, Seed: img.Seed})
+	}
+	s.mu.Lock()
+	s.images[path] = body
+	s.specs[path] = img
+	s.mu.Unlock()
+	return s.URL + path
+}
+
+// Hits returns how many times path was requested.
+func (s *ImageServer) Hits(path string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hits[path]
+}
+
+func (s *ImageServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	body, ok := s.images[r.URL.Path]
+	spec := s.specs[r.URL.Path]
+	s.hits[r.URL.Path]++
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	ct := spec.ContentType
+	if ct == "" {
+		ct = "image/jpeg"
+	}
+	w.Header().Set("Content-Type", ct)
+	if spec.Status != 0 {
+		w.WriteHeader(spec.Status)
+	}
+	_, _ = w.Write(body)
+}
+
+// JPEGOpts configures GenerateJPEG. Non-empty metadata fields are written as
+// real EXIF (APP1), XMP (APP1), and IPTC (APP13 Photoshop IRB) segments.
+type JPEGOpts struct {
+	Width  int    // default: DefaultWidth
+	Height int    // default: DefaultHeight
+	Seed   string // selects the block pattern; equal seeds produce equal images
+
+	EXIFCopyright string
+	EXIFArtist    string
+
+	IPTCCopyright string // 2:116 CopyrightNotice
+	IPTCCredit    string // 2:110 Credit
+	IPTCByline    string // 2:80 By-line
+	IPTCSource    string // 2:115 Source
+
+	XMPLicense      string // cc:license
+	XMPWebStatement string // xmpRights:WebStatement
+	XMPUsageTerms   string // xmpRights:UsageTerms
+	XMPMarked       bool   // xmpRights:Marked
+	DCRights        string // dc:rights
+	DCCreator       string // dc:creator
+
+	// Watermark overlays a repeating diagonal stripe pattern, imitating a
+	// stock-preview watermark.
+	Watermark bool
+}
+
+// GenerateJPEG returns an encoded JPEG of a deterministic block pattern with
+// the requested metadata segments inserted after the SOI marker.
+func GenerateJPEG(opts JPEGOpts) []byte {
+	img := generatePattern(opts)
+	if opts.Watermark {
+		drawWatermark(img)
+	}
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80}) //nolint:mnd // preview quality
+	return insertSegments(buf.Bytes(), metadataSegments(opts))
+}
+
+// generatePattern draws a blockGrid×blockGrid grid of gray levels derived from the seed.
+func generatePattern(opts JPEGOpts) *image.Gray {
+	w, h := opts.Width, opts.Height
+	if w <= 0 {
+		w = DefaultWidth
+	}
+	if h <= 0 {
+		h = DefaultHeight
+	}
+
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(opts.Seed))
+	state := hasher.Sum64() | 1
+
+	var levels [blockGrid * blockGrid]uint8
+	for i := range levels {
+		// xorshift64: cheap deterministic pseudo-random sequence.
+		state ^= state << 13
+		state ^= state >> 7
+		state ^= state << 17
+		levels[i] = uint8(state)
+	}
+
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := range h {
+		by := y * blockGrid / h
+		for x := range w {
+			bx := x * blockGrid / w
+			img.SetGray(x, y, color.Gray{Y: levels[by*blockGrid+bx]})
+		}
+	}
+	return img
+}
+
+// watermarkSpacing is the distance in pixels between diagonal watermark stripes.
+const watermarkSpacing = 48
+
+// drawWatermark lightens pixels along repeating diagonal stripes.
+func drawWatermark(img *image.Gray) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if (x+y)%watermarkSpacing < 4 { //nolint:mnd // stripe thickness
+				g := img.GrayAt(x, y).Y
+				img.SetGray(x, y, color.Gray{Y: g/2 + 128}) //nolint:mnd // 50% white blend
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package llmhttp holds the HTTP plumbing shared by the classifier adapters
+// in classifiers/: JSON requests with rate-limit mapping and image payload
+// loading.
+package llmhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+const (
+	responseLimit = 4 * 1024 * 1024
+	imageLimit    = 20 * 1024 * 1024
+	errorSnippet  = 512
+)
+
+// PostJSON POSTs body as JSON to url and decodes the JSON response into out.
+// A 429 or 503 response is returned as *imagefy.RateLimitedError carrying the
+// Retry-After delay; other non-2xx responses are errors that include the
+// start of the response body.
+func PostJSON(ctx context.Context, client *http.Client, url string, header http.Header, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: endpoint is operator-configured
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, responseLimit))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		return &imagefy.RateLimitedError{
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Err:        statusError(resp.StatusCode, data),
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError(resp.StatusCode, data)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func statusError(code int, body []byte) error {
+	msg := strings.TrimSpace(string(body))
+	if len(msg) > errorSnippet {
+		msg = msg[:errorSnippet]
+	}
+	return fmt.Errorf("status %d: %s", code, msg)
+}
+
+// ParseRetryAfter interprets a Retry-After header (delay seconds or an HTTP
+// date). It returns 0 when the header is absent or invalid.
+func ParseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// ImageBytes returns the bytes and MIME type of img: img.Data when set,
+// otherwise data: URIs are decoded and HTTP(S) URLs are fetched with client.
+func ImageBytes(ctx context.Context, client *http.Client, img imagefy.ImageInput) ([]byte, string, error) {
+	if len(img.Data) > 0 {
+		if img.MIMEType == "" {
+			return img.Data, http.DetectContentType(img.Data), nil
+		}
+		return img.Data, img.MIMEType, nil
+	}
+	if rest, ok := strings.CutPrefix(img.URL, "data:"); ok {
+		meta, enc, ok := strings.Cut(rest, ",")
+		if !ok || !strings.HasSuffix(meta, ";base64") {
+			return nil, "", errors.New("unsupported data URI")
+		}
+		data, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return nil, "", fmt.Errorf("decode data URI: %w", err)
+		}
+		mime := strings.TrimSuffix(meta, ";base64")
+		if mime == "" {
+			mime = img.MIMEType
+		}
+		return data, mime, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, img.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: image URLs come from the pipeline
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetch image: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, imageLimit))
+	if err != nil {
+		return nil, "", err
+	}
+	mime := img.MIMEType
+	if mime == "" {
+		mime = http.DetectContentType(data)
+	}
+	return data, mime, nil
+}
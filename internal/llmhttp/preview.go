@@ -0,0 +1,118 @@
+package llmhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // decoders for re-encoding
+	"image/jpeg"
+	_ "image/png"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+const (
+	jpegQuality    = 85
+	maxShrinkSteps = 8
+)
+
+// Limits describes the inline images a provider accepts.
+type Limits struct {
+	Formats  []string // accepted MIME types, e.g. "image/jpeg"
+	MaxBytes int      // cap on one image's encoded size (0 = no cap)
+}
+
+// Load returns img's bytes in a format and size accepted by lim (see Prepare).
+func Load(ctx context.Context, client *http.Client, img imagefy.ImageInput, lim Limits) ([]byte, string, error) {
+	data, mimeType, err := ImageBytes(ctx, client, img)
+	if err != nil {
+		return nil, "", err
+	}
+	return Prepare(data, mimeType, lim)
+}
+
+// Prepare returns data unchanged when its MIME type is in lim.Formats and it
+// fits lim.MaxBytes. Otherwise the image is decoded and re-encoded as JPEG,
+// downscaled until it fits. Transparent areas are flattened onto white.
+func Prepare(data []byte, mimeType string, lim Limits) ([]byte, string, error) {
+	mimeType = normalizeMIME(mimeType)
+	if mimeType == "" {
+		mimeType = normalizeMIME(http.DetectContentType(data))
+	}
+	fits := lim.MaxBytes <= 0 || len(data) <= lim.MaxBytes
+	if fits && (len(lim.Formats) == 0 || slices.Contains(lim.Formats, mimeType)) {
+		return data, mimeType, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("re-encode %s: %w", mimeType, err)
+	}
+	img := flatten(src)
+	for range maxShrinkSteps {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, "", err
+		}
+		if lim.MaxBytes <= 0 || buf.Len() <= lim.MaxBytes {
+			return buf.Bytes(), "image/jpeg", nil
+		}
+		// Encoded size scales roughly with pixel count.
+		scale := min(math.Sqrt(float64(lim.MaxBytes)/float64(buf.Len()))*0.9, 0.75) //nolint:mnd // safety margin, minimum shrink step
+		img = downscale(img, scale)
+	}
+	return nil, "", errors.New("image does not fit the size limit")
+}
+
+// normalizeMIME lower-cases mimeType and strips parameters.
+func normalizeMIME(mimeType string) string {
+	if t, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return t
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+// flatten draws src onto an opaque white RGBA canvas.
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// downscale resizes src by scale with a box filter.
+func downscale(src *image.RGBA, scale float64) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := max(int(float64(sw)*scale), 1), max(int(float64(sh)*scale), 1)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := range dw {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var r, g, b, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := src.PixOffset(sx, sy)
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(b/n), 0xff
+		}
+	}
+	return dst
+}
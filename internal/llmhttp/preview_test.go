@@ -0,0 +1,89 @@
+package llmhttp
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand/v2"
+	"testing"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+// noisyPNG returns a w×h PNG of random pixels, which compresses poorly.
+func noisyPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	r := rand.New(rand.NewPCG(1, 2))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.NRGBA{uint8(r.IntN(256)), uint8(r.IntN(256)), uint8(r.IntN(256)), 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPrepare(t *testing.T) {
+	t.Parallel()
+
+	src := noisyPNG(t, 300, 200)
+	tests := []struct {
+		name      string
+		lim       Limits
+		wantMIME  string
+		unchanged bool
+	}{
+		{"accepted and small", Limits{Formats: []string{"image/png"}, MaxBytes: len(src)}, "image/png", true},
+		{"no limits", Limits{}, "image/png", true},
+		{"format not accepted", Limits{Formats: []string{"image/jpeg"}}, "image/jpeg", false},
+		{"too large", Limits{Formats: []string{"image/png", "image/jpeg"}, MaxBytes: 20 * 1024}, "image/jpeg", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			data, mimeType, err := Prepare(src, "image/png", tt.lim)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mimeType != tt.wantMIME {
+				t.Errorf("MIME = %q, want %q", mimeType, tt.wantMIME)
+			}
+			if got := bytes.Equal(data, src); got != tt.unchanged {
+				t.Errorf("unchanged = %v, want %v", got, tt.unchanged)
+			}
+			if tt.lim.MaxBytes > 0 && len(data) > tt.lim.MaxBytes {
+				t.Errorf("size %d exceeds cap %d", len(data), tt.lim.MaxBytes)
+			}
+			if !tt.unchanged {
+				if _, err := jpeg.DecodeConfig(bytes.NewReader(data)); err != nil {
+					t.Errorf("output is not a JPEG: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestPrepare_Undecodable(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := Prepare([]byte("not an image"), "image/bmp", Limits{Formats: []string{"image/jpeg"}}); err == nil {
+		t.Error("want error for undecodable input in an unaccepted format")
+	}
+}
+
+func TestImageBytes_PrefersData(t *testing.T) {
+	t.Parallel()
+
+	img := imagefy.ImageInput{URL: "http://127.0.0.1:1/unreachable.jpg", MIMEType: "image/png", Data: []byte("raw")}
+	data, mimeType, err := ImageBytes(context.Background(), nil, img)
+	if err != nil || string(data) != "raw" || mimeType != "image/png" {
+		t.Errorf("ImageBytes = %q, %q, %v; want Data without fetching", data, mimeType, err)
+	}
+}
@@ -0,0 +1,53 @@
+package llmhttp
+
+import "strings"
+
+const (
+	// defaultMaxTokens fits a verdict with its stated reason, multi-label
+	// JSON, or a watermark box.
+	defaultMaxTokens = 256
+	// defaultReasoningMaxTokens leaves a reasoning model room to finish its
+	// chain of thought before the verdict; reasoning tokens count against the
+	// completion limit.
+	defaultReasoningMaxTokens = 4096
+)
+
+// reasoningPrefixes are model name prefixes of reasoning models: OpenAI
+// o-series and GPT-5, Gemini 2.5 and later, and the open reasoning models
+// served by Ollama and OpenAI-compatible servers.
+var reasoningPrefixes = []string{
+	"o1", "o3", "o4", "gpt-5",
+	"gemini-2.5", "gemini-3",
+	"deepseek-r1", "qwq", "qwen3", "magistral",
+}
+
+// ReasoningModel reports whether model names a reasoning model, ignoring
+// case, a router prefix ("openai/o3-mini"), and an Ollama tag
+// ("deepseek-r1:14b"). The short o-series names match only whole or
+// followed by "-", as in "o3-mini".
+func ReasoningModel(model string) bool {
+	name := strings.ToLower(model)
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	name, _, _ = strings.Cut(name, ":")
+	for _, p := range reasoningPrefixes {
+		if rest, ok := strings.CutPrefix(name, p); ok && (len(p) > 2 || rest == "" || rest[0] == '-') {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxTokens returns the completion limit to request: limit when positive,
+// otherwise a default that is larger for reasoning models.
+func MaxTokens(limit int, model string) int {
+	switch {
+	case limit > 0:
+		return limit
+	case ReasoningModel(model):
+		return defaultReasoningMaxTokens
+	default:
+		return defaultMaxTokens
+	}
+}
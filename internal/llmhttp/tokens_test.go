@@ -0,0 +1,29 @@
+package llmhttp
+
+import "testing"
+
+func TestMaxTokens(t *testing.T) {
+	t.Parallel()
+
+	for model, want := range map[string]int{
+		"gpt-4o-mini":          defaultMaxTokens,
+		"llama3.2-vision":      defaultMaxTokens,
+		"gemini-2.0-flash":     defaultMaxTokens,
+		"o1x":                  defaultMaxTokens,
+		"o3-mini":              defaultReasoningMaxTokens,
+		"openai/o4-mini":       defaultReasoningMaxTokens,
+		"gpt-5":                defaultReasoningMaxTokens,
+		"gemini-2.5-flash":     defaultReasoningMaxTokens,
+		"deepseek-r1:14b":      defaultReasoningMaxTokens,
+		"Qwen/QwQ-32B":         defaultReasoningMaxTokens,
+		"qwen3-vl:8b":          defaultReasoningMaxTokens,
+		"deepseek/deepseek-r1": defaultReasoningMaxTokens,
+	} {
+		if got := MaxTokens(0, model); got != want {
+			t.Errorf("MaxTokens(0, %q) = %d, want %d", model, got, want)
+		}
+	}
+	if got := MaxTokens(32, "o3-mini"); got != 32 {
+		t.Errorf("MaxTokens(32, o3-mini) = %d, want the explicit limit", got)
+	}
+}
@@ -0,0 +1,183 @@
+package imagefy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JSON schema
+//
+// ImageCandidate, LicenseSignal, LicenseAssessment, and ClassificationResult
+// marshal to the stable snake_case objects below. Licenses are encoded as the
+// strings "unset", "safe", "unknown", or "blocked" (never as integers), so
+// stored results keep their meaning if the ImageLicense constants change.
+// A missing or null license decodes as LicenseUnset. Unmarshalling also
+// accepts the legacy integer encoding (0 = safe, 1 = unknown, 2 = blocked)
+// and ignores unknown fields.
+//
+//	ImageCandidate:       {"img_url", "thumbnail"?, "source", "title"?, "license",
+//	                       "license_name"?, "author"?, "width"?, "height"?, "engine"?, "provider"?, "page"?,
+//	                       "rank"?, "relevance"?, "degraded"?, "last_modified"?}
+//	LicenseSignal:        {"source", "detail", "license"}
+//	LicenseAssessment:    {"license", "signals": [LicenseSignal...]}
+//	ClassificationResult: {"class", "confidence", "reason"?,
+//	                       "watermark"?: {"left", "top", "right", "bottom"},
+//	                       "labels"?: [string...]}
+//
+// Fields marked ? are omitted when empty or zero. ClassificationResult uses
+// struct tags; legacy records with Go field names ("Class") still decode,
+// since encoding/json matches keys case-insensitively.
+
+// candidateJSON is the wire form of ImageCandidate.
+type candidateJSON struct {
+	ImgURL       string      `json:"img_url"`
+	Thumbnail    string      `json:"thumbnail,omitempty"`
+	Source       string      `json:"source"`
+	Title        string      `json:"title,omitempty"`
+	License      licenseJSON `json:"license"`
+	LicenseName  string      `json:"license_name,omitempty"`
+	Author       string      `json:"author,omitempty"`
+	Width        int         `json:"width,omitempty"`
+	Height       int         `json:"height,omitempty"`
+	Engine       string      `json:"engine,omitempty"`
+	Provider     string      `json:"provider,omitempty"`
+	Page         int         `json:"page,omitempty"`
+	Rank         int         `json:"rank,omitempty"`
+	Relevance    float64     `json:"relevance,omitempty"`
+	Degraded     Stage       `json:"degraded,omitempty"`
+	LastModified time.Time   `json:"last_modified,omitzero"` // RFC 3339
+}
+
+// MarshalJSON encodes the candidate using the documented schema.
+func (c ImageCandidate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(candidateJSON{
+		ImgURL:       c.ImgURL,
+		Thumbnail:    c.Thumbnail,
+		Source:       c.Source,
+		Title:        c.Title,
+		License:      licenseJSON(c.License),
+		LicenseName:  c.LicenseName,
+		Author:       c.Author,
+		Width:        c.Width,
+		Height:       c.Height,
+		Engine:       c.Engine,
+		Provider:     c.Provider,
+		Page:         c.Page,
+		Rank:         c.Rank,
+		Relevance:    c.Relevance,
+		Degraded:     c.Degraded,
+		LastModified: c.LastModified,
+	})
+}
+
+// UnmarshalJSON decodes the documented schema.
+func (c *ImageCandidate) UnmarshalJSON(data []byte) error {
+	var w candidateJSON
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*c = ImageCandidate{
+		ImgURL:       w.ImgURL,
+		Thumbnail:    w.Thumbnail,
+		Source:       w.Source,
+		Title:        w.Title,
+		License:      ImageLicense(w.License),
+		LicenseName:  w.LicenseName,
+		Author:       w.Author,
+		Width:        w.Width,
+		Height:       w.Height,
+		Engine:       w.Engine,
+		Provider:     w.Provider,
+		Page:         w.Page,
+		Rank:         w.Rank,
+		Relevance:    w.Relevance,
+		Degraded:     w.Degraded,
+		LastModified: w.LastModified,
+	}
+	return nil
+}
+
+// signalJSON is the wire form of LicenseSignal.
+type signalJSON struct {
+	Source  string      `json:"source"`
+	Detail  string      `json:"detail"`
+	License licenseJSON `json:"license"`
+}
+
+// MarshalJSON encodes the signal using the documented schema.
+func (s LicenseSignal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(signalJSON{Source: s.Source, Detail: s.Detail, License: licenseJSON(s.License)})
+}
+
+// UnmarshalJSON decodes the documented schema.
+func (s *LicenseSignal) UnmarshalJSON(data []byte) error {
+	var w signalJSON
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*s = LicenseSignal{Source: w.Source, Detail: w.Detail, License: ImageLicense(w.License)}
+	return nil
+}
+
+// assessmentJSON is the wire form of LicenseAssessment.
+type assessmentJSON struct {
+	License licenseJSON     `json:"license"`
+	Signals []LicenseSignal `json:"signals"`
+}
+
+// MarshalJSON encodes the assessment using the documented schema. Signals is
+// always an array, never null.
+func (a LicenseAssessment) MarshalJSON() ([]byte, error) {
+	signals := a.Signals
+	if signals == nil {
+		signals = []LicenseSignal{}
+	}
+	return json.Marshal(assessmentJSON{License: licenseJSON(a.License), Signals: signals})
+}
+
+// UnmarshalJSON decodes the documented schema. Signals is never nil afterwards.
+func (a *LicenseAssessment) UnmarshalJSON(data []byte) error {
+	var w assessmentJSON
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	if w.Signals == nil {
+		w.Signals = []LicenseSignal{}
+	}
+	*a = LicenseAssessment{License: ImageLicense(w.License), Signals: w.Signals}
+	return nil
+}
+
+// legacyLicenses maps the integer encoding written before licenses were
+// serialized as strings (when LicenseSafe was the zero value).
+var legacyLicenses = [...]ImageLicense{LicenseSafe, LicenseUnknown, LicenseBlocked}
+
+// licenseJSON encodes an ImageLicense as its text form and decodes either the
+// text form or the legacy integer value.
+type licenseJSON ImageLicense
+
+func (l licenseJSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ImageLicense(l))
+}
+
+func (l *licenseJSON) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '"' {
+		if string(data) == "null" {
+			*l = licenseJSON(LicenseUnset)
+			return nil
+		}
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("imagefy: invalid license %s", data)
+		}
+		if n < 0 || n >= len(legacyLicenses) {
+			return fmt.Errorf("imagefy: invalid license %d", n)
+		}
+		*l = licenseJSON(legacyLicenses[n])
+		return nil
+	}
+	return json.Unmarshal(data, (*ImageLicense)(l))
+}
@@ -0,0 +1,109 @@
+package imagefy
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestImageCandidateJSON_Schema(t *testing.T) {
+	t.Parallel()
+
+	c := ImageCandidate{
+		ImgURL:      "https://upload.wikimedia.org/a.jpg",
+		Source:      "https://commons.wikimedia.org/wiki/File:A.jpg",
+		Title:       "A",
+		License:     LicenseBlocked,
+		LicenseName: "CC BY-SA 4.0",
+		Author:      "A. Photographer",
+		Width:       1200,
+		Provider:    "searxng",
+		Page:        2,
+		Rank:        5,
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	const want = `{"img_url":"https://upload.wikimedia.org/a.jpg","source":"https://commons.wikimedia.org/wiki/File:A.jpg","title":"A","license":"blocked","license_name":"CC BY-SA 4.0","author":"A. Photographer","width":1200,"provider":"searxng","page":2,"rank":5}`
+	if string(data) != want {
+		t.Errorf("Marshal =\n%s\nwant\n%s", data, want)
+	}
+
+	var got ImageCandidate
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got != c {
+		t.Errorf("round trip = %+v, want %+v", got, c)
+	}
+}
+
+func TestImageCandidateJSON_LegacyAndInvalidLicense(t *testing.T) {
+	t.Parallel()
+
+	var c ImageCandidate
+	if err := json.Unmarshal([]byte(`{"img_url":"x","license":2,"extra":true}`), &c); err != nil {
+		t.Fatalf("legacy integer license: %v", err)
+	}
+	if c.License != LicenseBlocked {
+		t.Errorf("legacy license = %v, want blocked", c.License)
+	}
+
+	for _, bad := range []string{`{"license":"free"}`, `{"license":7}`, `{"license":1.5}`} {
+		if err := json.Unmarshal([]byte(bad), &c); err == nil {
+			t.Errorf("Unmarshal(%s) = nil error, want error", bad)
+		}
+	}
+}
+
+func TestLicenseAssessmentJSON(t *testing.T) {
+	t.Parallel()
+
+	a := LicenseAssessment{
+		License: LicenseSafe,
+		Signals: []LicenseSignal{{Source: "metadata_cc", Detail: "CC BY 4.0", License: LicenseSafe}},
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	const want = `{"license":"safe","signals":[{"source":"metadata_cc","detail":"CC BY 4.0","license":"safe"}]}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+	var got LicenseAssessment
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(got, a) {
+		t.Errorf("round trip = %+v, want %+v", got, a)
+	}
+
+	empty, _ := json.Marshal(LicenseAssessment{License: LicenseUnknown})
+	if string(empty) != `{"license":"unknown","signals":[]}` {
+		t.Errorf("Marshal(no signals) = %s", empty)
+	}
+	var decoded LicenseAssessment
+	if err := json.Unmarshal([]byte(`{"license":"unknown"}`), &decoded); err != nil || decoded.Signals == nil {
+		t.Errorf("Unmarshal without signals = %+v, %v; want non-nil Signals", decoded, err)
+	}
+}
+
+func TestClassificationResultJSON(t *testing.T) {
+	t.Parallel()
+
+	data, _ := json.Marshal(ClassificationResult{Class: ClassPhoto, Confidence: 0.9})
+	if string(data) != `{"class":"PHOTO","confidence":0.9}` {
+		t.Errorf("Marshal = %s", data)
+	}
+
+	// Records cached before the schema was introduced used Go field names.
+	var legacy ClassificationResult
+	if err := json.Unmarshal([]byte(`{"Class":"STOCK","Confidence":0.5}`), &legacy); err != nil {
+		t.Fatalf("Unmarshal legacy: %v", err)
+	}
+	if legacy.Class != ClassStock || legacy.Confidence != 0.5 {
+		t.Errorf("legacy = %+v", legacy)
+	}
+}
@@ -0,0 +1,272 @@
+package imagefy
+
+import (
+	"log/slog"
+	"strings"
+	"unicode"
+)
+
+// languageNames maps common ISO 639-1 codes to the English names used in the
+// vision prompt hint. Other codes are passed to the model as-is.
+var languageNames = map[string]string{
+	"ru": "Russian", "uk": "Ukrainian", "be": "Belarusian", "kk": "Kazakh",
+	"en": "English", "de": "German", "fr": "French", "es": "Spanish",
+	"it": "Italian", "pt": "Portuguese", "pl": "Polish", "tr": "Turkish",
+	"zh": "Chinese", "ja": "Japanese", "ko": "Korean", "ar": "Arabic",
+	"he": "Hebrew", "ka": "Georgian", "hy": "Armenian",
+}
+
+// languageHint returns the sentence added to the vision prompt for lang (a
+// BCP 47 tag such as "ru" or "ru-RU"), or "" when lang is empty.
+func languageHint(lang string) string {
+	if lang == "" {
+		return ""
+	}
+	base, _, _ := strings.Cut(lang, "-")
+	name, ok := languageNames[strings.ToLower(base)]
+	if !ok {
+		name = lang
+	}
+	return "Titles, signage, and other text in these images may be in " + name +
+		". Text in " + name + " alone is not a reason to reject a photograph; judge it by the same rules as English text."
+}
+
+// withLanguage returns cfg itself when lang is empty, otherwise a derived
+// copy whose classifications carry the language hint.
+func (cfg *Config) withLanguage(lang string) *Config {
+	if lang == "" || lang == cfg.language {
+		return cfg
+	}
+	c := cfg.derive()
+	c.language = lang
+	return c
+}
+
+// withLanguageHint prepends the hint for lang to prompt.
+func withLanguageHint(prompt, lang string) string {
+	hint := languageHint(lang)
+	if hint == "" {
+		return prompt
+	}
+	return hint + "\n\n" + prompt
+}
+
+// titleLangMinLetters is the number of letters below which a title is too
+// short (e.g. a file name) for its language to be judged.
+const titleLangMinLetters = 8
+
+// languageScripts maps ISO 639-1 codes to the script their text is written
+// in, for SearchOpts.RequireTitleLanguage. Languages not listed are judged
+// by DetectLanguage alone.
+var languageScripts = map[string]Script{
+	"ru": ScriptCyrillic, "uk": ScriptCyrillic, "be": ScriptCyrillic, "kk": ScriptCyrillic,
+	"bg": ScriptCyrillic, "sr": ScriptCyrillic, "mk": ScriptCyrillic,
+	"en": ScriptLatin, "de": ScriptLatin, "fr": ScriptLatin, "es": ScriptLatin,
+	"it": ScriptLatin, "pt": ScriptLatin, "pl": ScriptLatin, "tr": ScriptLatin,
+	"nl": ScriptLatin, "cs": ScriptLatin, "sv": ScriptLatin,
+	"zh": ScriptCJK, "ja": ScriptCJK, "ko": ScriptCJK,
+	"ar": ScriptArabic, "fa": ScriptArabic, "ur": ScriptArabic,
+}
+
+// latinStopwords are frequent function words of Latin-script languages.
+// Words shared by several languages count for each of them.
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "of", "with", "for", "from", "by", "at", "on", "in", "to", "an", "is", "near", "view", "inside"},
+	"de": {"der", "die", "das", "und", "mit", "von", "im", "am", "auf", "für", "bei", "ein", "eine", "in"},
+	"fr": {"le", "la", "les", "et", "de", "des", "du", "avec", "au", "aux", "dans", "sur", "pour", "une"},
+	"es": {"el", "la", "los", "las", "y", "de", "del", "con", "en", "para", "por", "una", "al"},
+	"it": {"il", "lo", "la", "gli", "le", "e", "di", "del", "della", "con", "in", "per", "una", "al"},
+	"pt": {"o", "os", "as", "e", "de", "do", "da", "dos", "das", "com", "em", "para", "uma", "no", "na"},
+	"pl": {"i", "w", "z", "na", "do", "ze", "od", "przy", "oraz", "jest"},
+	"tr": {"ve", "ile", "bir", "bu", "için", "de", "da"},
+}
+
+// cyrillicStopwords are frequent function words of Russian and Ukrainian,
+// which share most letters.
+var cyrillicStopwords = map[string][]string{
+	"ru": {"и", "с", "из", "от", "при", "или", "над", "под", "вид", "на", "в", "для"},
+	"uk": {"і", "й", "з", "із", "від", "та", "або", "біля", "на", "в", "у", "для"},
+}
+
+// cyrillicMarkers are letters of one of Russian and Ukrainian only.
+var cyrillicMarkers = map[string]string{
+	"ru": "ыэёъ",
+	"uk": "іїєґ",
+}
+
+// latinMarkers are letters that occur in one Latin-script language only.
+var latinMarkers = map[string]string{
+	"de": "äöüß",
+	"fr": "èêëîœç",
+	"es": "ñ¿¡",
+	"pt": "ãõ",
+	"pl": "ąćęłńśźż",
+	"tr": "ğışİ",
+}
+
+// DetectLanguage guesses the language of a short text such as an image
+// title or alt text and returns its ISO 639-1 code, or "" when the text
+// gives no clear signal (too short, no distinctive letters or function
+// words, or a script shared by many languages without markers).
+//
+// Detection is heuristic: scripts used by one language (Hangul, Hebrew,
+// Georgian, Armenian, Greek, kana) decide outright, Cyrillic is told apart
+// by letters specific to Russian, Ukrainian, Belarusian, or Kazakh, and
+// Latin-script text by language-specific letters and function words.
+func DetectLanguage(text string) string {
+	lower := strings.ToLower(text)
+	var letters, kana, han int
+	counts := make(map[string]int)
+	for _, r := range lower {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Georgian, r):
+			counts["ka"]++
+		case unicode.Is(unicode.Armenian, r):
+			counts["hy"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		}
+	}
+	if kana > 0 {
+		counts["ja"] += kana + han
+	}
+	if lang := dominant(counts, letters); lang != "" {
+		return lang
+	}
+
+	switch QueryScript(lower) {
+	case ScriptCyrillic:
+		return cyrillicLanguage(lower)
+	case ScriptLatin:
+		return latinLanguage(lower)
+	}
+	return ""
+}
+
+// dominant returns the language of counts that covers most of the
+// letters, or "" if none does.
+func dominant(counts map[string]int, letters int) string {
+	for lang, n := range counts {
+		if 2*n > letters {
+			return lang
+		}
+	}
+	return ""
+}
+
+// cyrillicLanguage tells Cyrillic languages apart: Kazakh and Belarusian
+// by their own letters, Russian and Ukrainian as scoreLanguage does.
+func cyrillicLanguage(text string) string {
+	switch {
+	case strings.ContainsAny(text, "әғқңөұүһ"):
+		return "kk"
+	case strings.ContainsRune(text, 'ў'):
+		return "be"
+	}
+	return scoreLanguage(text, cyrillicMarkers, cyrillicStopwords)
+}
+
+// latinLanguage guesses the language of Latin-script text.
+func latinLanguage(text string) string {
+	return scoreLanguage(text, latinMarkers, latinStopwords)
+}
+
+// scoreLanguage scores text by marker letters (weighted double) and
+// function words, returning the best-scoring language unless it ties with
+// another.
+func scoreLanguage(text string, markers map[string]string, stopwords map[string][]string) string {
+	scores := make(map[string]int)
+	for lang, markers := range markers {
+		if strings.ContainsAny(text, markers) {
+			scores[lang] += 2
+		}
+	}
+	words := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })
+	for lang, stop := range stopwords {
+		for _, w := range words {
+			for _, s := range stop {
+				if w == s {
+					scores[lang]++
+				}
+			}
+		}
+	}
+	best, bestScore, tie := "", 0, false
+	for lang, n := range scores {
+		switch {
+		case n > bestScore:
+			best, bestScore, tie = lang, n, false
+		case n == bestScore:
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+	return best
+}
+
+// titleLanguageMismatch reports whether title is evidently not in lang (a
+// BCP 47 tag): it is written in another script than lang uses, or
+// DetectLanguage names another language. Titles too short or too
+// ambiguous to judge are not mismatches.
+func titleLanguageMismatch(title, lang string) bool {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	letters := 0
+	for _, r := range title {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if base == "" || letters < titleLangMinLetters {
+		return false
+	}
+	if want, ok := languageScripts[base]; ok && QueryScript(title) != want {
+		return true
+	}
+	got := DetectLanguage(title)
+	return got != "" && got != base
+}
+
+// withTitleLanguage returns cfg itself unless require is set and the search
+// has a language, otherwise a derived copy that rejects candidates whose
+// titles are in another language (see SearchOpts.RequireTitleLanguage).
+func (cfg *Config) withTitleLanguage(require bool) *Config {
+	if !require || cfg.language == "" || cfg.titleLanguage {
+		return cfg
+	}
+	c := cfg.derive()
+	c.titleLanguage = true
+	return c
+}
+
+// filterLanguage drops candidates whose title is evidently not in the
+// search's SearchOpts.Language, before any network request. They are
+// reported as ReasonWrongLanguage at StageRelevance.
+func (cfg *Config) filterLanguage(candidates []ImageCandidate) []ImageCandidate {
+	if !cfg.titleLanguage {
+		return candidates
+	}
+	out := candidates[:0:0]
+	for _, c := range candidates {
+		if titleLanguageMismatch(c.Title, cfg.language) {
+			slog.Debug("imagefy: title in another language", "url", c.ImgURL, "title", c.Title, "language", cfg.language)
+			cfg.emitCandidate(CandidateEvent{Candidate: c, Stage: StageRelevance, Reason: ReasonWrongLanguage})
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
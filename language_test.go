@@ -0,0 +1,169 @@
+package imagefy
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLanguageHint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		lang, want string
+	}{
+		{"", ""},
+		{"ru", "Russian"},
+		{"ru-RU", "Russian"},
+		{"RU", "Russian"},
+		{"sr-Latn", "sr-Latn"},
+	}
+	for _, tt := range tests {
+		got := languageHint(tt.lang)
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("languageHint(%q) = %q, want it to name %q", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestSearchImagesWithOptsLanguage(t *testing.T) {
+	t.Parallel()
+
+	got := captureRequestURL(t, &Config{}, SearchOpts{Language: "ru"})
+	if v := got.Query().Get("language"); v != "ru" {
+		t.Errorf("language = %q, want %q", v, "ru")
+	}
+	if got := captureRequestURL(t, &Config{}, SearchOpts{}); got.Query().Has("language") {
+		t.Error("language param present without SearchOpts.Language")
+	}
+}
+
+func TestLanguage_VisionPromptHint(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	cls := &promptCapturingClassifier{response: "PHOTO 0.9"}
+	cache := &mockCache{store: make(map[string]any)}
+	cfg := &Config{
+		HTTPClient: srv.Client(),
+		Classifier: cls,
+		Cache:      cache,
+		Providers: []SearchProvider{&mockProvider{name: "p", candidates: []ImageCandidate{
+			{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown},
+		}}},
+	}
+
+	cfg.SearchImagesWithOpts(context.Background(), "Эрмитаж", 3, SearchOpts{Language: "ru"})
+	if !strings.HasPrefix(cls.capturedPrompt, languageHint("ru")) || !strings.HasSuffix(cls.capturedPrompt, DefaultVisionPrompt) {
+		t.Errorf("prompt = %q, want the Russian hint before DefaultVisionPrompt", cls.capturedPrompt)
+	}
+	if cfg.language != "" {
+		t.Error("SearchOpts.Language leaked into the shared Config")
+	}
+
+	// Without a language the English-only result is classified and cached apart.
+	cls.capturedPrompt = ""
+	cfg.SearchImages(context.Background(), "Hermitage", 3)
+	if cls.capturedPrompt != DefaultVisionPrompt {
+		t.Errorf("prompt without language = %q, want DefaultVisionPrompt", cls.capturedPrompt)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"Красная площадь и Кремль", "ru"},
+		{"Вид на Кремль с реки", "ru"},
+		{"Москва", ""}, // no letters or words specific to Russian
+		{"Київ, вид на Хрещатик", "uk"},
+		{"Мінск, праспект Незалежнасці ўвечары", "be"},
+		{"Алматы қаласы", "kk"},
+		{"Red Square in the snow", "en"},
+		{"Blick auf die Altstadt mit Dom", "de"},
+		{"Vue sur la tour Eiffel depuis le pont", "fr"},
+		{"Vista de la ciudad con el río", "es"},
+		{"Widok na Stare Miasto w Krakowie, zdjęcie", "pl"},
+		{"Moscow Kremlin", ""},
+		{"東京の夜景", "ja"},
+		{"서울 야경", "ko"},
+		{"תל אביב", "he"},
+	}
+	for _, tc := range tests {
+		if got := DetectLanguage(tc.text); got != tc.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestTitleLanguageMismatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title, lang string
+		want        bool
+	}{
+		{"Luxury restaurant interior 3d render", "ru", true}, // Latin script
+		{"Интерьер ресторана Пушкин", "ru", false},
+		{"Інтер'єр ресторану", "ru", true}, // Ukrainian
+		{"IMG_2041", "ru", false},          // too short to judge
+		{"", "ru", false},
+		{"Restaurant interior with the bar", "en-US", false},
+		{"Intérieur du restaurant avec le bar", "en", true},
+		{"Restaurant Pushkin Moskau", "de", false}, // Latin, no signal
+		{"Interior of the restaurant", "", false},
+	}
+	for _, tc := range tests {
+		if got := titleLanguageMismatch(tc.title, tc.lang); got != tc.want {
+			t.Errorf("titleLanguageMismatch(%q, %q) = %v, want %v", tc.title, tc.lang, got, tc.want)
+		}
+	}
+}
+
+func TestSearchOpts_RequireTitleLanguage(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		rejected []CandidateEvent
+	)
+	prov := &mockProvider{name: "p", candidates: []ImageCandidate{
+		{ImgURL: "https://cdn.example.com/render.jpg", Title: "Luxury restaurant interior 3d render", License: LicenseSafe},
+		{ImgURL: "https://cdn.example.com/hall.jpg", Title: "Зал ресторана", License: LicenseSafe},
+	}}
+	cfg := &Config{
+		Providers: []SearchProvider{prov},
+		OnCandidateRejected: func(e CandidateEvent) {
+			mu.Lock()
+			rejected = append(rejected, e)
+			mu.Unlock()
+		},
+	}
+
+	cfg.SearchImagesWithOpts(context.Background(), "ресторан", 5, SearchOpts{Language: "ru", RequireTitleLanguage: true, Timeout: time.Second})
+	var wrong []string
+	for _, e := range rejected {
+		if e.Reason == ReasonWrongLanguage {
+			if e.Stage != StageRelevance {
+				t.Errorf("stage = %s, want relevance", e.Stage)
+			}
+			wrong = append(wrong, e.Candidate.ImgURL)
+		}
+	}
+	if len(wrong) != 1 || wrong[0] != "https://cdn.example.com/render.jpg" {
+		t.Errorf("wrong_language rejections = %v, want only the English render", wrong)
+	}
+
+	rejected = nil
+	cfg.SearchImagesWithOpts(context.Background(), "ресторан", 5, SearchOpts{RequireTitleLanguage: true, Timeout: time.Second})
+	for _, e := range rejected {
+		if e.Reason == ReasonWrongLanguage {
+			t.Errorf("RequireTitleLanguage without Language rejected %s", e.Candidate.ImgURL)
+		}
+	}
+}
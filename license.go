@@ -1,21 +1,29 @@
 package imagefy
 
 import (
+	"fmt"
 	"net/url"
 	"strings"
 )
 
 // ImageLicense classifies an image source by copyright safety.
+//
+// The zero value is LicenseUnset, so a candidate built without a License (or
+// decoded from a record missing one) is never mistaken for a safe source.
+// ImageLicense marshals to text as "unset", "safe", "unknown", or "blocked".
 type ImageLicense int
 
 const (
-	LicenseSafe    ImageLicense = iota // known free source (unsplash, pixabay, etc.)
+	LicenseUnset   ImageLicense = iota // not determined — treated like LicenseUnknown
+	LicenseSafe                        // known free source (unsplash, pixabay, etc.)
 	LicenseUnknown                     // no info — usable with caution
 	LicenseBlocked                     // stock site — reject entirely
 )
 
 func (l ImageLicense) String() string {
 	switch l {
+	case LicenseUnset:
+		return "unset"
 	case LicenseSafe:
 		return "safe"
 	case LicenseBlocked:
@@ -25,61 +33,69 @@ func (l ImageLicense) String() string {
 	}
 }
 
-// BlockedDomains are stock photo sites that enforce copyright and send invoices.
-var BlockedDomains = []string{
-	"shutterstock",
-	"gettyimages",
-	"istockphoto",
-	"adobestock",
-	"depositphotos",
-	"dreamstime",
-	"123rf",
-	"alamy",
-	"bigstockphoto",
-	"stocksy",
-	"eyeem",
-	"pond5",
-	"thinkstockphotos", // Getty subsidiary
-	"canstockphoto",
-	"masterfile",
-	"superstock",
-	"agefotostock",
-	"colourbox",
-	"photodune",   // Envato marketplace
-	"yayimages",
-	"vectorstock",
-	"loriimages",  // Russian stock (Лори)
-	"fotobank",    // Russian stock
-	"freepik",     // active DMCA enforcement
-	"canva.",      // freemium stock elements (trailing dot avoids matching "canvas")
-	"clipartof",
-	"featurepics",
-	"rfclipart",
+// MarshalText implements encoding.TextMarshaler.
+func (l ImageLicense) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
 }
 
-// BlockedURLPatterns are URL path segments that indicate stock photo pages.
-var BlockedURLPatterns = []string{
-	"/stock-photo",
-	"/stock-image",
-	"/editorial-image",
-	"/premium-photo",
+// UnmarshalText implements encoding.TextUnmarshaler. The empty string decodes
+// as LicenseUnset.
+func (l *ImageLicense) UnmarshalText(text []byte) error {
+	v, err := ParseImageLicense(string(text))
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
 }
 
-// SafeDomains are free / CC / attribution-friendly image sources.
-var SafeDomains = []string{
-	"unsplash",
-	"pexels",
-	"pixabay",
-	"wikimedia",
-	"commons.wikimedia",
-	"flickr",
-	"rawpixel",
-	"stocksnap",
-	"burst.shopify",
-	"kaboompics",
-	"picjumbo",
+// ParseImageLicense parses the text form of an ImageLicense ("safe",
+// "unknown", "blocked", "unset", or "").
+func ParseImageLicense(s string) (ImageLicense, error) {
+	switch s {
+	case "", "unset":
+		return LicenseUnset, nil
+	case "safe":
+		return LicenseSafe, nil
+	case "unknown":
+		return LicenseUnknown, nil
+	case "blocked":
+		return LicenseBlocked, nil
+	default:
+		return LicenseUnset, fmt.Errorf("imagefy: invalid license %q", s)
+	}
 }
 
+// rank orders licenses for validation: safe first, then unknown or unset,
+// then blocked.
+func (l ImageLicense) rank() int {
+	switch l {
+	case LicenseSafe:
+		return 0
+	case LicenseBlocked:
+		return 2 //nolint:mnd // last
+	default:
+		return 1
+	}
+}
+
+// BlockedDomains are stock photo sites that enforce copyright and send invoices.
+// Loaded from data/domains.json (see BuiltinDomainEntries for categories and notes).
+var BlockedDomains = entryStrings(builtinDomains.BlockedDomains)
+
+// AgencyCDNs are image CDN and storefront hosts of stock agencies whose names
+// do not contain a BlockedDomains entry (ftcdn.net serves Adobe Stock,
+// picdn.net Shutterstock, cfp.cn Visual China Group). They match a host and
+// its subdomains, and block like the agency's BlockedDomains entries.
+// Loaded from data/domains.json, where each entry's note names the agency.
+var AgencyCDNs = entryStrings(builtinDomains.AgencyCDNs)
+
+// BlockedURLPatterns are URL path segments that indicate stock photo pages.
+var BlockedURLPatterns = entryStrings(builtinDomains.BlockedURLPatterns)
+
+// SafeDomains are free / CC / attribution-friendly image sources.
+var SafeDomains = entryStrings(builtinDomains.SafeDomains)
+
 // CheckLicense classifies an image by checking its URL and source page URL
 // against known blocked (stock) and safe (free/CC) domain lists.
 // Both URLs are checked — an image hosted on a CDN may still originate from a stock site.
@@ -92,9 +108,21 @@ func CheckLicense(imageURL, sourceURL string) ImageLicense {
 // blocked and safe domain lists. The extra slices use the same substring-match
 // semantics as the built-in BlockedDomains / SafeDomains.
 func CheckLicenseWith(imageURL, sourceURL string, extraBlocked, extraSafe []string) ImageLicense {
+	return checkLicense(imageURL, sourceURL, extraBlocked, extraSafe, nil)
+}
+
+// checkLicense is CheckLicenseWith with matches of built-in BlockedDomains
+// licensed by policy (see Config.BlockPolicy) instead of always blocked. A
+// URL the policy allows as LicenseSafe is safe unless the other URL is
+// blocked.
+func checkLicense(imageURL, sourceURL string, extraBlocked, extraSafe []string, policy blockPolicy) ImageLicense {
+	license := LicenseUnknown
 	for _, u := range []string{imageURL, sourceURL} {
-		if isBlockedWith(u, extraBlocked) {
+		switch blockedLicense(u, extraBlocked, policy) {
+		case LicenseBlocked:
 			return LicenseBlocked
+		case LicenseSafe:
+			license = LicenseSafe
 		}
 	}
 	for _, u := range []string{imageURL, sourceURL} {
@@ -102,39 +130,57 @@ func CheckLicenseWith(imageURL, sourceURL string, extraBlocked, extraSafe []stri
 			return LicenseSafe
 		}
 	}
-	return LicenseUnknown
+	return license
 }
 
-// isBlockedWith reports whether the URL matches a blocked domain, URL pattern,
-// or any of the extra blocked domains.
-func isBlockedWith(rawURL string, extra []string) bool {
+// blockedLicense returns LicenseBlocked when the URL matches a blocked URL
+// pattern, one of the extra blocked domains, or a BlockedDomains or
+// AgencyCDNs entry that policy does not allow. A URL matching only allowed entries gets the
+// policy's license; any other URL is LicenseUnknown.
+func blockedLicense(rawURL string, extra []string, policy blockPolicy) ImageLicense {
 	if rawURL == "" {
-		return false
+		return LicenseUnknown
 	}
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
-		return false
-	}
-	host := strings.ToLower(parsed.Host)
-	if host != "" {
-		for _, d := range BlockedDomains {
-			if strings.Contains(host, d) {
-				return true
-			}
-		}
-		for _, d := range extra {
-			if d != "" && strings.Contains(host, d) {
-				return true
-			}
-		}
+		return LicenseUnknown
 	}
 	path := strings.ToLower(parsed.Path)
 	for _, p := range BlockedURLPatterns {
 		if strings.Contains(path, p) {
-			return true
+			return LicenseBlocked
 		}
 	}
-	return false
+	host := normalizeHost(parsed.Scheme, parsed.Host)
+	if host == "" {
+		return LicenseUnknown
+	}
+	for _, d := range extra {
+		if d != "" && strings.Contains(host, d) {
+			return LicenseBlocked
+		}
+	}
+	var matched []string
+	for _, d := range BlockedDomains {
+		if strings.Contains(host, d) {
+			matched = append(matched, d)
+		}
+	}
+	for _, d := range AgencyCDNs {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			matched = append(matched, d)
+		}
+	}
+	license := LicenseUnknown
+	for _, d := range matched {
+		switch l := policy.domainLicense(d); l {
+		case LicenseBlocked:
+			return l
+		case LicenseSafe:
+			license = l
+		}
+	}
+	return license
 }
 
 // isSafeWith reports whether the URL matches a known safe/free domain
@@ -162,5 +208,5 @@ func extractHost(rawURL string) string {
 	if err != nil {
 		return ""
 	}
-	return strings.ToLower(parsed.Host)
+	return normalizeHost(parsed.Scheme, parsed.Host)
 }
@@ -1,6 +1,7 @@
 package imagefy
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -298,6 +299,7 @@ func TestImageLicenseString(t *testing.T) {
 		license ImageLicense
 		want    string
 	}{
+		{LicenseUnset, "unset"},
 		{LicenseSafe, "safe"},
 		{LicenseUnknown, "unknown"},
 		{LicenseBlocked, "blocked"},
@@ -314,3 +316,55 @@ func TestImageLicenseString(t *testing.T) {
 		})
 	}
 }
+
+func TestImageLicenseText(t *testing.T) {
+	t.Parallel()
+
+	for _, l := range []ImageLicense{LicenseUnset, LicenseSafe, LicenseUnknown, LicenseBlocked} {
+		text, err := l.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%v): %v", l, err)
+		}
+		var got ImageLicense
+		if err := got.UnmarshalText(text); err != nil || got != l {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, got, err, l)
+		}
+	}
+
+	var l ImageLicense = LicenseBlocked
+	if err := l.UnmarshalText([]byte("")); err != nil || l != LicenseUnset {
+		t.Errorf("UnmarshalText(\"\") = %v, %v; want unset", l, err)
+	}
+	if _, err := ParseImageLicense("free"); err == nil {
+		t.Error(`ParseImageLicense("free") = nil error, want error`)
+	}
+}
+
+func TestImageLicense_ZeroValueIsNotSafe(t *testing.T) {
+	t.Parallel()
+
+	var cand ImageCandidate
+	if cand.License == LicenseSafe {
+		t.Fatal("zero-value ImageCandidate has LicenseSafe")
+	}
+	if _, skip := PreClassify(ImageCandidate{ImgURL: "https://example.com/a.jpg"}); skip {
+		t.Error("PreClassify auto-accepted a candidate without a License")
+	}
+
+	var decoded ImageCandidate
+	if err := json.Unmarshal([]byte(`{"img_url":"https://example.com/a.jpg"}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.License != LicenseUnset {
+		t.Errorf("decoded License = %v, want unset", decoded.License)
+	}
+
+	ordered := orderCandidates([]ImageCandidate{
+		{ImgURL: "b", License: LicenseBlocked},
+		{ImgURL: "n", License: LicenseUnset},
+		{ImgURL: "s", License: LicenseSafe},
+	}, SearchOpts{})
+	if got := orderedURLs(ordered); got != "s,n,b" {
+		t.Errorf("order = %s, want s,n,b", got)
+	}
+}
@@ -25,38 +25,20 @@ type ImageMetadata struct {
 }
 
 // stockMetadataKeywords are substrings that indicate a stock-photo agency when
-// found (case-insensitive) in any metadata field.
-var stockMetadataKeywords = []string{
-	"shutterstock",
-	"gettyimages",
-	"getty images",
-	"istockphoto",
-	"istock",
-	"alamy",
-	"depositphotos",
-	"dreamstime",
-	"123rf",
-	"adobestock",
-	"adobe stock",
-	"bigstockphoto",
-	"stocksy",
-	"pond5",
-	"masterfile",
-	"superstock",
-	"agefotostock",
-	"age fotostock",
-	"colourbox",
-	"yayimages",
-	"vectorstock",
-	"freepik",
-	"canstockphoto",
-}
+// found (case-insensitive) in any metadata field. Loaded from data/domains.json.
+var stockMetadataKeywords = entryStrings(builtinDomains.StockMetadataKeywords)
 
 // IsStockByMetadata reports whether the image metadata contains fingerprints
 // of a known stock-photo agency (case-insensitive word-boundary match).
 func IsStockByMetadata(meta *ImageMetadata) bool {
+	return stockMetadataField(meta, nil) != ""
+}
+
+// stockMetadataField returns the first metadata field containing a stock
+// keyword that policy does not allow, or "" if there is none.
+func stockMetadataField(meta *ImageMetadata, policy blockPolicy) string {
 	if meta == nil {
-		return false
+		return ""
 	}
 	fields := []string{
 		meta.EXIFCopyright,
@@ -74,12 +56,12 @@ func IsStockByMetadata(meta *ImageMetadata) bool {
 		}
 		lower := strings.ToLower(f)
 		for _, kw := range stockMetadataKeywords {
-			if containsWord(lower, kw) {
-				return true
+			if containsWord(lower, kw) && policy.keywordBlocked(kw) {
+				return f
 			}
 		}
 	}
-	return false
+	return ""
 }
 
 // containsWord reports whether text contains word at a word boundary.
@@ -129,7 +111,7 @@ var wantedTags = map[imagemeta.Source]map[string]bool{
 	imagemeta.IPTC: {
 		"CopyrightNotice": true,
 		"Credit":          true,
-		"Byline":          true,
+		"By-line":         true,
 		"Source":          true,
 	},
 	imagemeta.EXIF: {
@@ -148,18 +130,30 @@ var wantedTags = map[imagemeta.Source]map[string]bool{
 
 // ExtractImageMetadata parses EXIF/IPTC/XMP metadata from raw image bytes.
 // Returns nil if the data is nil, empty, or cannot be parsed.
-// Graceful degradation: never returns an error.
-func ExtractImageMetadata(data []byte) *ImageMetadata {
+// Graceful degradation: never returns an error and never panics, even on
+// malformed input from untrusted origins.
+func ExtractImageMetadata(data []byte) (meta *ImageMetadata) {
 	if len(data) == 0 {
 		return nil
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			meta = nil
+		}
+	}()
 
-	meta := &ImageMetadata{}
+	format, ok := detectImageFormat(data)
+	if !ok {
+		return nil
+	}
+
+	meta = &ImageMetadata{}
 	found := false
 
 	_, err := imagemeta.Decode(imagemeta.Options{
-		R:       bytes.NewReader(data),
-		Sources: imagemeta.EXIF | imagemeta.IPTC | imagemeta.XMP,
+		R:           bytes.NewReader(data),
+		ImageFormat: format,
+		Sources:     imagemeta.EXIF | imagemeta.IPTC | imagemeta.XMP,
 		ShouldHandleTag: func(ti imagemeta.TagInfo) bool {
 			if tags, ok := wantedTags[ti.Source]; ok {
 				return tags[ti.Tag]
@@ -186,3 +180,19 @@ func ExtractImageMetadata(data []byte) *ImageMetadata {
 	return meta
 }
 
+// detectImageFormat sniffs the container format from magic bytes.
+// imagemeta does not auto-detect formats, so Decode fails without this.
+func detectImageFormat(data []byte) (imagemeta.ImageFormat, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return imagemeta.JPEG, true
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return imagemeta.PNG, true
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return imagemeta.WebP, true
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return imagemeta.TIFF, true
+	default:
+		return imagemeta.ImageFormatAuto, false
+	}
+}
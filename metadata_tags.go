@@ -1,6 +1,10 @@
 package imagefy
 
-import "github.com/bep/imagemeta"
+import (
+	"strings"
+
+	"github.com/bep/imagemeta"
+)
 
 // handleIPTCTag sets the appropriate ImageMetadata field for an IPTC tag.
 func handleIPTCTag(meta *ImageMetadata, ti imagemeta.TagInfo, found *bool) {
@@ -14,7 +18,7 @@ func handleIPTCTag(meta *ImageMetadata, ti imagemeta.TagInfo, found *bool) {
 		meta.IPTCCopyright = s
 	case "Credit":
 		meta.IPTCCredit = s
-	case "Byline":
+	case "By-line":
 		meta.IPTCByline = s
 	case "Source":
 		meta.IPTCSource = s
@@ -48,8 +52,13 @@ func handleEXIFTag(meta *ImageMetadata, ti imagemeta.TagInfo, found *bool) {
 func handleXMPTag(meta *ImageMetadata, ti imagemeta.TagInfo, found *bool) {
 	switch ti.Tag {
 	case "Marked":
-		if b, ok := ti.Value.(bool); ok {
-			meta.XMPMarked = b
+		// XMP attribute values arrive as strings ("True"/"False").
+		switch v := ti.Value.(type) {
+		case bool:
+			meta.XMPMarked = v
+			*found = true
+		case string:
+			meta.XMPMarked = strings.EqualFold(v, "true")
 			*found = true
 		}
 	case "WebStatement":
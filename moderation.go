@@ -0,0 +1,76 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/anatolykoptev/go-imagefy/textutil"
+)
+
+// ErrQueryBlocked is returned by a QueryModerator to block a query.
+var ErrQueryBlocked = errors.New("imagefy: query blocked by moderation")
+
+// QueryModerator screens a search query before any SearchProvider sees it.
+// It returns the query to issue — unchanged, or rewritten to drop
+// disallowed terms — or an error to block the search. Returning "" also
+// blocks it.
+type QueryModerator func(ctx context.Context, query string) (string, error)
+
+// BlockTerms returns a QueryModerator that blocks any query containing one
+// of terms as a whole word (case-insensitive). Multi-word terms match a run
+// of consecutive words.
+func BlockTerms(terms ...string) QueryModerator {
+	blocked := make([][]string, 0, len(terms))
+	for _, t := range terms {
+		if words := textutil.Words(t); len(words) > 0 {
+			blocked = append(blocked, words)
+		}
+	}
+	return func(_ context.Context, query string) (string, error) {
+		words := textutil.Words(query)
+		for _, term := range blocked {
+			if containsRun(words, term) {
+				return "", fmt.Errorf("%w: %q", ErrQueryBlocked, strings.Join(term, " "))
+			}
+		}
+		return query, nil
+	}
+}
+
+// moderateQuery runs Config.QueryModerator on query. It returns the query
+// to search for and false if the search must not be issued; a moderator
+// error blocks the search (fail closed).
+func (cfg *Config) moderateQuery(ctx context.Context, query string) (string, bool) {
+	if cfg.QueryModerator == nil {
+		return query, true
+	}
+	moderated, err := cfg.QueryModerator(ctx, query)
+	if err != nil || moderated == "" {
+		slog.Info("imagefy: query blocked by moderation", "error", err)
+		return "", false
+	}
+	if moderated != query {
+		slog.Debug("imagefy: query rewritten by moderation")
+	}
+	return moderated, true
+}
+
+// containsRun reports whether run occurs as consecutive elements of words.
+func containsRun(words, run []string) bool {
+	for i := 0; i+len(run) <= len(words); i++ {
+		match := true
+		for j, w := range run {
+			if words[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// queryRecordingProvider records the queries it is asked to search.
+type queryRecordingProvider struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (p *queryRecordingProvider) Name() string { return "recorder" }
+
+func (p *queryRecordingProvider) Search(_ context.Context, query string, _ SearchOpts) ([]ImageCandidate, error) {
+	p.mu.Lock()
+	p.queries = append(p.queries, query)
+	p.mu.Unlock()
+	return nil, nil
+}
+
+func TestBlockTerms(t *testing.T) {
+	t.Parallel()
+
+	mod := BlockTerms("casino", "Red Square")
+	tests := []struct {
+		query   string
+		blocked bool
+	}{
+		{"Moscow CASINO night", true},
+		{"red square, Moscow", true},
+		{"red brick square", false},
+		{"casinos of Macau", false}, // whole words only
+		{"Hermitage Museum", false},
+	}
+	for _, tt := range tests {
+		got, err := mod(context.Background(), tt.query)
+		if blocked := errors.Is(err, ErrQueryBlocked); blocked != tt.blocked {
+			t.Errorf("BlockTerms(%q) err = %v, want blocked %v", tt.query, err, tt.blocked)
+		}
+		if !tt.blocked && got != tt.query {
+			t.Errorf("BlockTerms(%q) = %q, want query unchanged", tt.query, got)
+		}
+	}
+}
+
+func TestQueryModerator_SearchImages(t *testing.T) {
+	t.Parallel()
+
+	p := &queryRecordingProvider{}
+	searches := 0
+	cfg := &Config{
+		Providers:     []SearchProvider{p},
+		OnImageSearch: func() { searches++ },
+		QueryModerator: func(_ context.Context, q string) (string, error) {
+			if strings.Contains(q, "forbidden") {
+				return "", ErrQueryBlocked
+			}
+			return strings.ReplaceAll(q, "slang", "street"), nil
+		},
+	}
+
+	cfg.SearchImages(context.Background(), "forbidden place", 3)
+	cfg.SearchImages(context.Background(), "slang art", 3)
+	cfg.FindImages(context.Background(), FindOpts{Query: "forbidden place"})
+
+	if len(p.queries) != 1 || p.queries[0] != "street art" {
+		t.Errorf("provider queries = %q, want only the rewritten [street art]", p.queries)
+	}
+	if searches != 1 {
+		t.Errorf("OnImageSearch calls = %d, want 1 (blocked searches are not issued)", searches)
+	}
+}
+
+func TestQueryModerator_FailsClosed(t *testing.T) {
+	t.Parallel()
+
+	p := &queryRecordingProvider{}
+	cfg := &Config{
+		Providers:      []SearchProvider{p},
+		QueryModerator: func(context.Context, string) (string, error) { return "", errors.New("moderation API down") },
+	}
+	cfg.SearchImages(context.Background(), "anything", 3)
+	if len(p.queries) != 0 {
+		t.Errorf("provider queries = %q, want none when the moderator errors", p.queries)
+	}
+}
@@ -0,0 +1,59 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestNilConfig verifies that every exported Config method treats a nil
+// *Config like a zero-value Config instead of panicking.
+func TestNilConfig(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	imgURL := srv.URL + "/photo.jpg"
+	cand := ImageCandidate{ImgURL: imgURL, Source: srv.URL + "/page", License: LicenseUnknown}
+	ctx := context.Background()
+	var cfg *Config
+
+	if got := cfg.SearchImages(ctx, "query", 3); got != nil {
+		t.Errorf("SearchImages = %v, want nil (no providers)", got)
+	}
+	if got := cfg.SearchImagesDiverse(ctx, "query", 3); got != nil {
+		t.Errorf("SearchImagesDiverse = %v, want nil", got)
+	}
+	if got := cfg.SearchImagesBatch(ctx, []QuerySpec{{Query: "q"}}); len(got) != 1 || got["q"] != nil {
+		t.Errorf("SearchImagesBatch = %v, want {q: nil}", got)
+	}
+	if got := cfg.FindImages(ctx, FindOpts{External: []ImageCandidate{cand}}); len(got) != 1 {
+		t.Errorf("FindImages(External) = %v, want the external candidate", got)
+	}
+	if got := cfg.ValidateCandidates(ctx, []ImageCandidate{cand}, 3); len(got) != 1 {
+		t.Errorf("ValidateCandidates = %v, want 1 result", got)
+	}
+	if !cfg.ValidateImageURL(ctx, imgURL) {
+		t.Error("ValidateImageURL = false, want true for a wide JPEG")
+	}
+	if res, err := cfg.Download(ctx, imgURL, DownloadOpts{}); err != nil || res == nil {
+		t.Errorf("Download = %v, %v; want data", res, err)
+	}
+	if got := cfg.ClassifyImage(ctx, imgURL); got != "" {
+		t.Errorf("ClassifyImage = %q, want \"\" (no classifier)", got)
+	}
+	if !cfg.IsRealPhoto(ctx, imgURL) {
+		t.Error("IsRealPhoto = false, want true (graceful degradation)")
+	}
+	if _, err := cfg.PickBest(ctx, "query", []ImageCandidate{cand}); !errors.Is(err, ErrNoClassifier) {
+		t.Errorf("PickBest error = %v, want ErrNoClassifier", err)
+	}
+	if got := cfg.ReverseCheck(ctx, imgURL); got.IsStock {
+		t.Errorf("ReverseCheck = %+v, want zero result", got)
+	}
+	if got := cfg.AssessLicense(ImageCandidate{ImgURL: "https://www.shutterstock.com/x.jpg", License: LicenseBlocked}, nil); got.License != LicenseBlocked {
+		t.Errorf("AssessLicense = %v, want blocked", got.License)
+	}
+	if s := cfg.NewSession(SessionOpts{}); s == nil || len(s.ValidateCandidates(ctx, []ImageCandidate{cand}, 1)) != 1 {
+		t.Error("NewSession on nil Config is not usable")
+	}
+}
@@ -18,30 +18,45 @@ const (
 
 // openverseResult is the JSON shape of a single Openverse image result.
 type openverseResult struct {
-	ID                 string `json:"id"`
-	Title              string `json:"title"`
-	URL                string `json:"url"`
-	Thumbnail          string `json:"thumbnail"`
-	ForeignLandingURL  string `json:"foreign_landing_url"`
-	Source             string `json:"source"`
-	License            string `json:"license"`
+	ID                string          `json:"id"`
+	Title             string          `json:"title"`
+	URL               string          `json:"url"`
+	Thumbnail         string          `json:"thumbnail"`
+	ForeignLandingURL string          `json:"foreign_landing_url"`
+	Source            string          `json:"source"`
+	License           string          `json:"license"`
+	Raw               json.RawMessage `json:"-"` // the result as received, with SearchOpts.KeepRaw
 }
 
 // OpenverseProvider searches openly-licensed images via the Openverse API.
-// All returned images are pre-licensed (CC or public domain) so they receive LicenseSafe.
+// Every result is CC-licensed or public domain; its License comes from the
+// result's license code (see openverseLicense): public domain, CC BY, and
+// CC BY-SA receive LicenseSafe, while NonCommercial, NoDerivatives, and
+// unrecognized licenses receive LicenseUnknown and go through the usual
+// checks. Licenses and LicenseType restrict the search on the API side.
 // Engines from SearchOpts are ignored — Openverse has its own source catalog.
 // See: https://api.openverse.org/v1/
 type OpenverseProvider struct {
 	BaseURL    string       // default: "https://api.openverse.org/v1"
-	HTTPClient *http.Client // optional (nil = http.DefaultClient)
+	HTTPClient *http.Client // optional (nil = the default imagefy client)
 	UserAgent  string       // optional
+
+	// Licenses limits results to these Openverse license codes, e.g.
+	// {"cc0", "pdm", "by", "by-sa"} (nil = all).
+	Licenses []string
+
+	// LicenseType limits results to a license group: "commercial" (commercial
+	// use allowed), "modification" (derivatives allowed), or "all-cc"
+	// ("" = all).
+	LicenseType string
 }
 
 // Name returns the provider name.
 func (p *OpenverseProvider) Name() string { return "openverse" }
 
 // Search queries the Openverse API for images matching query and returns filtered candidates.
-// Logo/banner URLs are excluded before returning. All results receive LicenseSafe.
+// Logo/banner URLs are excluded before returning. Each result's License is
+// derived from its Openverse license code.
 func (p *OpenverseProvider) Search(ctx context.Context, query string, opts SearchOpts) ([]ImageCandidate, error) {
 	results, err := p.fetch(ctx, query, opts)
 	if err != nil {
@@ -64,7 +79,7 @@ func (p *OpenverseProvider) fetch(ctx context.Context, query string, opts Search
 
 	client := p.HTTPClient
 	if client == nil {
-		client = http.DefaultClient
+		client = defaultHTTPClient
 	}
 
 	resp, err := client.Do(req) //nolint:gosec // G107: URL is cfg-supplied by design — SSRF is caller's responsibility
@@ -88,6 +103,13 @@ func (p *OpenverseProvider) fetch(ctx context.Context, query string, opts Search
 	if err := json.Unmarshal(body, &searchResp); err != nil {
 		return nil, err
 	}
+	if opts.KeepRaw {
+		if raws := rawElements(body, "results"); len(raws) == len(searchResp.Results) {
+			for i := range searchResp.Results {
+				searchResp.Results[i].Raw = raws[i]
+			}
+		}
+	}
 
 	return searchResp.Results, nil
 }
@@ -104,12 +126,34 @@ func (p *OpenverseProvider) buildURL(query string, opts SearchOpts) string {
 		page = 1
 	}
 
-	return fmt.Sprintf("%s/images/?q=%s&page=%d&page_size=%d",
+	searchURL := fmt.Sprintf("%s/images/?q=%s&page=%d&page_size=%d",
 		base,
 		url.QueryEscape(query),
 		page,
 		openverseDefaultLimit,
 	)
+	if len(p.Licenses) > 0 {
+		searchURL += "&license=" + url.QueryEscape(strings.Join(p.Licenses, ","))
+	}
+	if p.LicenseType != "" {
+		searchURL += "&license_type=" + url.QueryEscape(p.LicenseType)
+	}
+	return searchURL
+}
+
+// openverseLicense maps an Openverse license code ("by", "by-nc-sa", "cc0",
+// ...; a "cc-" prefix is tolerated) to an ImageLicense. Licenses that allow
+// commercial reuse with attribution at most are LicenseSafe; NonCommercial,
+// NoDerivatives, and sampling licenses restrict editorial reuse, so they are
+// LicenseUnknown, as is anything unrecognized.
+func openverseLicense(code string) ImageLicense {
+	code = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(code)), "cc-")
+	switch code {
+	case "cc0", "pdm", "by", "by-sa":
+		return LicenseSafe
+	default:
+		return LicenseUnknown
+	}
 }
 
 func (p *OpenverseProvider) filter(results []openverseResult) []ImageCandidate {
@@ -127,7 +171,8 @@ func (p *OpenverseProvider) filter(results []openverseResult) []ImageCandidate {
 			Thumbnail: r.Thumbnail,
 			Source:    r.ForeignLandingURL,
 			Title:     r.Title,
-			License:   LicenseSafe,
+			License:   openverseLicense(r.License),
+			raw:       string(r.Raw),
 		})
 	}
 	return candidates
@@ -272,3 +272,73 @@ func TestOpenverseProviderSearch_NilHTTPClient(t *testing.T) {
 		t.Error("expected a connection error with nil HTTPClient and nothing listening on port 1, got nil")
 	}
 }
+
+// TestOpenverseProviderSearch_LicenseFromAPI verifies that each candidate's License
+// follows the result's license code, not a blanket LicenseSafe.
+func TestOpenverseProviderSearch_LicenseFromAPI(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buildOpenverseJSON([]openverseResult{
+			{URL: "https://live.staticflickr.com/1/a.jpg", License: "by-sa"},
+			{URL: "https://live.staticflickr.com/2/b.jpg", License: "by-nc"},
+			{URL: "https://live.staticflickr.com/3/c.jpg", License: ""},
+		}))
+	}))
+	t.Cleanup(srv.Close)
+
+	p := &OpenverseProvider{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	candidates, err := p.Search(context.Background(), "river", SearchOpts{})
+	if err != nil || len(candidates) != 3 {
+		t.Fatalf("Search = %d candidates, %v; want 3", len(candidates), err)
+	}
+	for i, want := range []ImageLicense{LicenseSafe, LicenseUnknown, LicenseUnknown} {
+		if candidates[i].License != want {
+			t.Errorf("candidates[%d].License = %v, want %v", i, candidates[i].License, want)
+		}
+	}
+}
+
+// TestOpenverseLicense verifies the license code mapping.
+func TestOpenverseLicense(t *testing.T) {
+	t.Parallel()
+
+	for code, want := range map[string]ImageLicense{
+		"cc0": LicenseSafe, "pdm": LicenseSafe, "by": LicenseSafe, "BY-SA": LicenseSafe, "cc-by": LicenseSafe,
+		"by-nd": LicenseUnknown, "by-nc": LicenseUnknown, "by-nc-sa": LicenseUnknown, "by-nc-nd": LicenseUnknown,
+		"sampling+": LicenseUnknown, "nc-sampling+": LicenseUnknown, "": LicenseUnknown, "proprietary": LicenseUnknown,
+	} {
+		if got := openverseLicense(code); got != want {
+			t.Errorf("openverseLicense(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+// TestOpenverseProviderSearch_LicenseFilters verifies that Licenses and LicenseType
+// are sent as the license and license_type params, and omitted when unset.
+func TestOpenverseProviderSearch_LicenseFilters(t *testing.T) {
+	t.Parallel()
+
+	var capturedRawQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedRawQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buildOpenverseJSON(nil))
+	}))
+	t.Cleanup(srv.Close)
+
+	p := &OpenverseProvider{BaseURL: srv.URL, HTTPClient: srv.Client(), Licenses: []string{"cc0", "by"}, LicenseType: "commercial"}
+	_, _ = p.Search(context.Background(), "lake", SearchOpts{})
+	q, _ := url.ParseQuery(capturedRawQuery)
+	if q.Get("license") != "cc0,by" || q.Get("license_type") != "commercial" {
+		t.Errorf("license = %q, license_type = %q; want %q, %q", q.Get("license"), q.Get("license_type"), "cc0,by", "commercial")
+	}
+
+	p = &OpenverseProvider{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	_, _ = p.Search(context.Background(), "lake", SearchOpts{})
+	q, _ = url.ParseQuery(capturedRawQuery)
+	if q.Has("license") || q.Has("license_type") {
+		t.Errorf("unset filters sent: %q", capturedRawQuery)
+	}
+}
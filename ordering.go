@@ -0,0 +1,168 @@
+package imagefy
+
+import (
+	"sort"
+	"strings"
+)
+
+// defaultSafeWeight is the number of safe candidates InterleaveWeighted takes
+// for every unknown-license candidate when SearchOpts.SafeWeight is unset.
+const defaultSafeWeight = 2
+
+// Interleave selects how candidates are ordered before validation. Because the
+// pipeline stops once maxResults candidates pass, the order decides which
+// candidates get a chance at all.
+type Interleave int
+
+const (
+	// InterleaveStrict puts every safe candidate before every unknown one (default).
+	InterleaveStrict Interleave = iota
+	// InterleaveWeighted alternates SafeWeight safe candidates with one unknown
+	// candidate, so unknown-license photos are still considered when a safe
+	// source returns many results.
+	InterleaveWeighted
+	// InterleaveRoundRobin takes one candidate from each source host in turn
+	// (hosts ordered by their best license), so a single prolific source cannot
+	// crowd out the others.
+	InterleaveRoundRobin
+)
+
+func (i Interleave) String() string {
+	switch i {
+	case InterleaveWeighted:
+		return "weighted"
+	case InterleaveRoundRobin:
+		return "round_robin"
+	default:
+		return "strict"
+	}
+}
+
+// orderCandidates returns candidates ordered for validation according to
+// opts.Interleave. Provider order is preserved within each license class and
+// blocked candidates always come last. The input slice is sorted in place.
+func orderCandidates(candidates []ImageCandidate, opts SearchOpts) []ImageCandidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].License.rank() < candidates[j].License.rank()
+	})
+
+	switch opts.Interleave {
+	case InterleaveWeighted:
+		weight := opts.SafeWeight
+		if weight <= 0 {
+			weight = defaultSafeWeight
+		}
+		return interleaveWeighted(candidates, weight)
+	case InterleaveRoundRobin:
+		return interleaveBySource(candidates)
+	default:
+		return candidates
+	}
+}
+
+// boostSources reorders candidates, stably and in place, by descending
+// Config.SourceBoosts, so the license sort in orderCandidates that follows
+// keeps boosted sources ahead within each license class.
+func (cfg *Config) boostSources(candidates []ImageCandidate) []ImageCandidate {
+	if len(cfg.SourceBoosts) == 0 {
+		return candidates
+	}
+	boosts := make([]int, len(candidates))
+	for i, c := range candidates {
+		boosts[i] = cfg.sourceBoost(sourceKey(c))
+	}
+	idx := make([]int, len(candidates))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return boosts[idx[a]] > boosts[idx[b]] })
+	sorted := make([]ImageCandidate, len(candidates))
+	for i, j := range idx {
+		sorted[i] = candidates[j]
+	}
+	copy(candidates, sorted)
+	return candidates
+}
+
+// sourceBoost returns the SourceBoosts value of the most specific key
+// matching host or one of its parent domains, or 0.
+func (cfg *Config) sourceBoost(host string) int {
+	boost, best := 0, -1
+	for key, b := range cfg.SourceBoosts {
+		key = strings.TrimPrefix(strings.ToLower(key), "*.")
+		if key != "" && len(key) > best && (host == key || strings.HasSuffix(host, "."+key)) {
+			boost, best = b, len(key)
+		}
+	}
+	return boost
+}
+
+// interleaveWeighted merges the license-sorted candidates, taking weight safe
+// candidates per unknown one. Blocked candidates are appended unchanged.
+func interleaveWeighted(sorted []ImageCandidate, weight int) []ImageCandidate {
+	var safe, unknown, blocked []ImageCandidate
+	for _, c := range sorted {
+		switch c.License {
+		case LicenseSafe:
+			safe = append(safe, c)
+		case LicenseBlocked:
+			blocked = append(blocked, c)
+		default:
+			unknown = append(unknown, c)
+		}
+	}
+
+	out := make([]ImageCandidate, 0, len(sorted))
+	for len(safe) > 0 || len(unknown) > 0 {
+		n := min(weight, len(safe))
+		out = append(out, safe[:n]...)
+		safe = safe[n:]
+		if len(unknown) > 0 {
+			out = append(out, unknown[0])
+			unknown = unknown[1:]
+		}
+	}
+	return append(out, blocked...)
+}
+
+// interleaveBySource groups the license-sorted candidates by source host and
+// takes one candidate from each group per round. Groups keep the order in
+// which their first (best-licensed) candidate appears. Blocked candidates are
+// appended unchanged.
+func interleaveBySource(sorted []ImageCandidate) []ImageCandidate {
+	var keys []string
+	var blocked []ImageCandidate
+	groups := make(map[string][]ImageCandidate)
+	for _, c := range sorted {
+		if c.License == LicenseBlocked {
+			blocked = append(blocked, c)
+			continue
+		}
+		key := sourceKey(c)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], c)
+	}
+
+	remaining := len(sorted) - len(blocked)
+	out := make([]ImageCandidate, 0, len(sorted))
+	for len(out) < remaining {
+		for _, k := range keys {
+			if g := groups[k]; len(g) > 0 {
+				out = append(out, g[0])
+				groups[k] = g[1:]
+			}
+		}
+	}
+	return append(out, blocked...)
+}
+
+// sourceKey returns the host a candidate is attributed to: the source page
+// host, or the image host when the source page is unknown.
+func sourceKey(c ImageCandidate) string {
+	if h := extractHost(c.Source); h != "" {
+		return h
+	}
+	return extractHost(c.ImgURL)
+}
@@ -0,0 +1,108 @@
+package imagefy
+
+import (
+	"strings"
+	"testing"
+)
+
+// orderedURLs returns the ImgURL path suffixes of candidates for compact comparison.
+func orderedURLs(cands []ImageCandidate) string {
+	parts := make([]string, len(cands))
+	for i, c := range cands {
+		parts[i] = c.ImgURL[strings.LastIndex(c.ImgURL, "/")+1:]
+	}
+	return strings.Join(parts, ",")
+}
+
+func orderingFixture() []ImageCandidate {
+	return []ImageCandidate{
+		{ImgURL: "https://x.example/u1", Source: "https://blog.example/a", License: LicenseUnknown},
+		{ImgURL: "https://upload.wikimedia.org/w1", Source: "https://commons.wikimedia.org/1", License: LicenseSafe},
+		{ImgURL: "https://upload.wikimedia.org/w2", Source: "https://commons.wikimedia.org/2", License: LicenseSafe},
+		{ImgURL: "https://shutterstock.com/b1", Source: "https://shutterstock.com/p", License: LicenseBlocked},
+		{ImgURL: "https://upload.wikimedia.org/w3", Source: "https://commons.wikimedia.org/3", License: LicenseSafe},
+		{ImgURL: "https://y.example/u2", Source: "https://news.example/b", License: LicenseUnknown},
+		{ImgURL: "https://images.unsplash.com/s1", Source: "https://unsplash.com/1", License: LicenseSafe},
+		{ImgURL: "https://x.example/u3", Source: "https://blog.example/c", License: LicenseUnknown},
+	}
+}
+
+func TestOrderCandidates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts SearchOpts
+		want string
+	}{
+		{"strict", SearchOpts{}, "w1,w2,w3,s1,u1,u2,u3,b1"},
+		{"weighted default", SearchOpts{Interleave: InterleaveWeighted}, "w1,w2,u1,w3,s1,u2,u3,b1"},
+		{"weighted 1", SearchOpts{Interleave: InterleaveWeighted, SafeWeight: 1}, "w1,u1,w2,u2,w3,u3,s1,b1"},
+		{"round robin", SearchOpts{Interleave: InterleaveRoundRobin}, "w1,s1,u1,u2,w2,u3,w3,b1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := orderedURLs(orderCandidates(orderingFixture(), tt.opts))
+			if got != tt.want {
+				t.Errorf("orderCandidates() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderCandidates_Empty(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []Interleave{InterleaveStrict, InterleaveWeighted, InterleaveRoundRobin} {
+		if got := orderCandidates(nil, SearchOpts{Interleave: mode}); len(got) != 0 {
+			t.Errorf("%s: orderCandidates(nil) = %v, want empty", mode, got)
+		}
+	}
+}
+
+func TestSourceKey_FallsBackToImageHost(t *testing.T) {
+	t.Parallel()
+
+	if got := sourceKey(ImageCandidate{ImgURL: "https://cdn.example/a.jpg"}); got != "cdn.example" {
+		t.Errorf("sourceKey() = %q, want cdn.example", got)
+	}
+}
+
+func TestBoostSources(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{SourceBoosts: map[string]int{
+		"news.example": 5,
+		"unsplash.com": 3,
+		"*.example":    1,
+		"blog.example": -1, // more specific than *.example
+	}}
+	got := orderedURLs(orderCandidates(cfg.boostSources(orderingFixture()), SearchOpts{}))
+	if want := "s1,w1,w2,w3,u2,u1,u3,b1"; got != want {
+		t.Errorf("boosted order = %s, want %s (license class first, then boost, then provider order)", got, want)
+	}
+
+	if got := orderedURLs((&Config{}).boostSources(orderingFixture())); got != orderedURLs(orderingFixture()) {
+		t.Errorf("without SourceBoosts the order changed: %s", got)
+	}
+}
+
+func TestSourceBoost_MatchesSuffixes(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{SourceBoosts: map[string]int{"gov": 10, "*.EDU": 4, "spam.gov": -3}}
+	for host, want := range map[string]int{
+		"nasa.gov":         10,
+		"gov":              10,
+		"cs.mit.edu":       4,
+		"tips.spam.gov":    -3,
+		"notgov":           0,
+		"gov.example.com":  0,
+		"commons.wiki.org": 0,
+	} {
+		if got := cfg.sourceBoost(host); got != want {
+			t.Errorf("sourceBoost(%q) = %d, want %d", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package imagefy
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/corona10/goimagehash"
+)
+
+const (
+	// defaultPageSize is the number of images SearchPage returns when
+	// Config.PageSize is unset.
+	defaultPageSize = 10
+	// pageMaxFetches caps how many provider result pages one SearchPage call
+	// requests, so a page of mostly rejected candidates cannot stall it.
+	pageMaxFetches = 3
+	// cursorMaxHashes caps the perceptual hashes a Cursor carries; the
+	// oldest are dropped first.
+	cursorMaxHashes = 200
+)
+
+// ErrInvalidCursor is returned by SearchPage for a cursor it did not issue
+// or that belongs to a different query.
+var ErrInvalidCursor = errors.New("imagefy: invalid cursor")
+
+// Cursor is an opaque SearchPage position. The zero value starts at the
+// first page. Cursors are URL-safe strings, so a UI can round-trip them
+// through a "show more" link.
+type Cursor string
+
+// Page is one SearchPage result.
+type Page struct {
+	Images []ImageCandidate
+	Next   Cursor // continues after Images; "" when the providers returned no further results
+}
+
+// cursorState is the decoded form of a Cursor.
+type cursorState struct {
+	Query  string   `json:"q"` // hash of the query the cursor belongs to
+	Page   int      `json:"p"` // provider page to continue on
+	Offset int      `json:"o"` // candidates of Page already considered
+	Hashes []uint64 `json:"h"` // dHashes of images already returned
+}
+
+// SearchPage returns the next Config.PageSize validated images for query,
+// continuing where the call that issued cursor stopped: candidates it
+// already considered are not validated again, and images it returned are
+// excluded as perceptual duplicates even if the providers reorder their
+// results. Pass "" for the first page and Page.Next afterwards.
+//
+// Each call requests at most 3 provider pages (SearchOpts.PageNumber), so a
+// page may hold fewer images while Next is still set. Errors are
+// ErrInvalidCursor, ErrQueryBlocked, and ErrNoProviders.
+func (cfg *Config) SearchPage(ctx context.Context, query string, cursor Cursor) (Page, error) {
+	cfg = cfg.orZero()
+	state, err := decodeCursor(cursor, query)
+	if err != nil {
+		return Page{}, err
+	}
+	if query == "" {
+		return Page{}, nil
+	}
+
+	search, moderated, ok := cfg.prepareSearch(ctx, query, SearchOpts{})
+	if !ok {
+		return Page{}, ErrQueryBlocked
+	}
+	defer search.reportBytes()
+	providers := search.resolveProviders()
+	if len(providers) == 0 {
+		return Page{}, ErrNoProviders
+	}
+	if search.OnImageSearch != nil {
+		search.OnImageSearch()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	st := newSearchState()
+	st.features = &featureStore{m: make(map[string]imageFeatures)}
+	for _, h := range state.Hashes {
+		st.dedup.hashes = append(st.dedup.hashes, goimagehash.NewImageHash(h, goimagehash.DHash))
+	}
+
+	size := search.pageSize()
+	var (
+		images     []ImageCandidate
+		candidates []ImageCandidate
+		gathered   = -1 // provider page candidates holds
+		fetches    int
+	)
+	for len(images) < size && ctx.Err() == nil {
+		if gathered != state.Page {
+			if fetches == pageMaxFetches {
+				break
+			}
+			opts := SearchOpts{PageNumber: state.Page}
+			candidates = orderCandidates(search.boostSources(search.gatherCandidates(ctx, providers, moderated, opts, st)), opts)
+			gathered, fetches = state.Page, fetches+1
+			if len(candidates) == 0 {
+				return Page{Images: images}, nil
+			}
+		}
+		if state.Offset >= len(candidates) {
+			state.Page, state.Offset = state.Page+1, 0
+			continue
+		}
+		// Validate no more candidates than images are missing, so every
+		// accepted one is returned and Offset stays exact.
+		end := min(state.Offset+size-len(images), len(candidates))
+		images = append(images, search.validateCandidates(ctx, candidates[state.Offset:end], size-len(images), SearchOpts{}, st)...)
+		state.Offset = end
+	}
+
+	for _, c := range images {
+		if feat, ok := st.features.get(c.ImgURL); ok && feat.hash != nil {
+			state.Hashes = append(state.Hashes, feat.hash.GetHash())
+		}
+	}
+	return Page{Images: images, Next: state.encode()}, nil
+}
+
+// pageSize returns Config.PageSize or the default.
+func (cfg *Config) pageSize() int {
+	if cfg.PageSize > 0 {
+		return cfg.PageSize
+	}
+	return defaultPageSize
+}
+
+// cursorQuery returns the hash identifying query in a Cursor.
+func cursorQuery(query string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(query))
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+// decodeCursor parses cursor for query; "" yields the first page.
+func decodeCursor(cursor Cursor, query string) (cursorState, error) {
+	if cursor == "" {
+		return cursorState{Query: cursorQuery(query), Page: 1}, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return cursorState{}, ErrInvalidCursor
+	}
+	var s cursorState
+	if err := json.Unmarshal(data, &s); err != nil || s.Query != cursorQuery(query) || s.Page < 1 || s.Offset < 0 {
+		return cursorState{}, ErrInvalidCursor
+	}
+	return s, nil
+}
+
+// encode returns s as a Cursor, keeping the newest cursorMaxHashes hashes.
+func (s cursorState) encode() Cursor {
+	if n := len(s.Hashes); n > cursorMaxHashes {
+		s.Hashes = s.Hashes[n-cursorMaxHashes:]
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(data))
+}
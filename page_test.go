@@ -0,0 +1,126 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// pagedProvider returns pages[PageNumber-1], or nothing past the last page.
+type pagedProvider struct {
+	pages [][]ImageCandidate
+}
+
+func (p *pagedProvider) Name() string { return "paged" }
+
+func (p *pagedProvider) Search(_ context.Context, _ string, opts SearchOpts) ([]ImageCandidate, error) {
+	if opts.PageNumber < 1 || opts.PageNumber > len(p.pages) {
+		return nil, nil
+	}
+	return p.pages[opts.PageNumber-1], nil
+}
+
+func TestSearchPage(t *testing.T) {
+	t.Parallel()
+
+	// Vertical edges at different columns hash far apart.
+	imgs := map[string][]byte{}
+	var cands []ImageCandidate
+	for i, edge := range []float64{0.2, 0.35, 0.5, 0.65, 0.8} {
+		path := fmt.Sprintf("/%c.jpg", 'a'+i)
+		imgs[path] = encodeGrayJPEG(900, 600, 0x20, 0xe0, func(x, _ float64) bool { return x >= edge })
+		cands = append(cands, ImageCandidate{ImgURL: path, Source: path + ".html", License: LicenseSafe})
+	}
+	srv := newMultiImageServer(t, imgs)
+	for i := range cands {
+		cands[i].ImgURL = srv.URL + cands[i].ImgURL
+		cands[i].Source = srv.URL + cands[i].Source
+	}
+
+	var (
+		mu        sync.Mutex
+		validated []string
+	)
+	cfg := &Config{
+		HTTPClient:          srv.Client(),
+		MinImageWidth:       100,
+		PageSize:            2,
+		Providers:           []SearchProvider{&pagedProvider{pages: [][]ImageCandidate{cands[:3], cands[3:]}}},
+		OnCandidateAccepted: func(e CandidateEvent) { mu.Lock(); validated = append(validated, e.Candidate.ImgURL); mu.Unlock() },
+	}
+
+	var got []string
+	var cursor Cursor
+	for call := 1; ; call++ {
+		page, err := cfg.SearchPage(context.Background(), "q", cursor)
+		if err != nil {
+			t.Fatalf("call %d: %v", call, err)
+		}
+		if len(page.Images) > 2 {
+			t.Fatalf("call %d returned %d images, want at most PageSize", call, len(page.Images))
+		}
+		for _, c := range page.Images {
+			got = append(got, c.ImgURL)
+		}
+		if page.Next == "" {
+			break
+		}
+		if call == 5 {
+			t.Fatal("cursor never ran out")
+		}
+		cursor = page.Next
+	}
+
+	if len(got) != len(cands) {
+		t.Fatalf("got %d images over all pages, want %d: %v", len(got), len(cands), got)
+	}
+	seen := map[string]bool{}
+	for _, u := range got {
+		if seen[u] {
+			t.Errorf("%s returned twice", u)
+		}
+		seen[u] = true
+	}
+	if len(validated) != len(cands) {
+		t.Errorf("%d candidates accepted, want each validated once", len(validated))
+	}
+}
+
+func TestSearchPage_SkipsReturnedImages(t *testing.T) {
+	t.Parallel()
+
+	body := encodeGrayJPEG(900, 600, 0x20, 0xe0, func(x, _ float64) bool { return x >= 0.5 })
+	srv := newMultiImageServer(t, map[string][]byte{"/a.jpg": body, "/copy.jpg": body})
+	a := ImageCandidate{ImgURL: srv.URL + "/a.jpg", Source: srv.URL + "/a", License: LicenseSafe}
+	dup := ImageCandidate{ImgURL: srv.URL + "/copy.jpg", Source: srv.URL + "/b", License: LicenseSafe}
+	cfg := &Config{
+		HTTPClient:    srv.Client(),
+		MinImageWidth: 100,
+		PageSize:      1,
+		// The copy only shows up on the second provider page.
+		Providers: []SearchProvider{&pagedProvider{pages: [][]ImageCandidate{{a}, {dup}}}},
+	}
+
+	first, err := cfg.SearchPage(context.Background(), "q", "")
+	if err != nil || len(first.Images) != 1 || first.Next == "" {
+		t.Fatalf("first page = %+v, %v", first, err)
+	}
+	second, err := cfg.SearchPage(context.Background(), "q", first.Next)
+	if err != nil || len(second.Images) != 0 || second.Next != "" {
+		t.Fatalf("second page = %+v, %v; want the copy skipped and no more pages", second, err)
+	}
+}
+
+func TestSearchPage_InvalidCursor(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Providers: []SearchProvider{&pagedProvider{}}}
+	other := cursorState{Query: cursorQuery("other"), Page: 2}.encode()
+	for _, c := range []Cursor{"not base64!", Cursor("e30"), other} {
+		if _, err := cfg.SearchPage(context.Background(), "q", c); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("SearchPage(%q) error = %v, want ErrInvalidCursor", c, err)
+		}
+	}
+}
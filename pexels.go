@@ -21,12 +21,12 @@ const (
 // Supports both official API (with APIKey) and internal API (with SecretKey).
 // When both keys are set, official API is tried first with fallback to internal.
 type PexelsProvider struct {
-	APIKey     string       // official API key (Authorization header)
-	SecretKey  string       // internal API key (Secret-Key header)
-	HTTPClient *http.Client // optional (nil = http.DefaultClient)
-	UserAgent  string       // optional
-	officialBase string     // test override
-	internalBase string     // test override
+	APIKey       string       // official API key (Authorization header)
+	SecretKey    string       // internal API key (Secret-Key header)
+	HTTPClient   *http.Client // optional (nil = http.DefaultClient)
+	UserAgent    string       // optional
+	officialBase string       // test override
+	internalBase string       // test override
 }
 
 // Name returns the provider name.
@@ -69,10 +69,11 @@ type pexelsSrc struct {
 }
 
 type pexelsOfficialPhoto struct {
-	ID  int       `json:"id"`
-	Alt string    `json:"alt"`
-	URL string    `json:"url"`
-	Src pexelsSrc `json:"src"`
+	ID  int             `json:"id"`
+	Alt string          `json:"alt"`
+	URL string          `json:"url"`
+	Src pexelsSrc       `json:"src"`
+	Raw json.RawMessage `json:"-"` // the photo as received, with SearchOpts.KeepRaw
 }
 
 type pexelsInternalImage struct {
@@ -94,6 +95,7 @@ type pexelsInternalAttrs struct {
 
 type pexelsInternalItem struct {
 	Attributes pexelsInternalAttrs `json:"attributes"`
+	Raw        json.RawMessage     `json:"-"` // the item as received, with SearchOpts.KeepRaw
 }
 
 func (p *PexelsProvider) doGet(ctx context.Context, rawURL, hdrKey, hdrVal string) ([]byte, error) {
@@ -140,6 +142,13 @@ func (p *PexelsProvider) searchOfficial(ctx context.Context, base, query string,
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, err
 	}
+	if opts.KeepRaw {
+		if raws := rawElements(body, "photos"); len(raws) == len(resp.Photos) {
+			for i := range resp.Photos {
+				resp.Photos[i].Raw = raws[i]
+			}
+		}
+	}
 	return filterOfficialResults(resp.Photos), nil
 }
 
@@ -151,7 +160,7 @@ func filterOfficialResults(photos []pexelsOfficialPhoto) []ImageCandidate {
 		}
 		out = append(out, ImageCandidate{
 			ImgURL: p.Src.Large, Thumbnail: p.Src.Small,
-			Source: p.URL, Title: p.Alt, License: LicenseSafe,
+			Source: p.URL, Title: p.Alt, License: LicenseSafe, raw: string(p.Raw),
 		})
 	}
 	return out
@@ -170,6 +179,13 @@ func (p *PexelsProvider) searchInternal(ctx context.Context, base, query string,
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, err
 	}
+	if opts.KeepRaw {
+		if raws := rawElements(body, "data"); len(raws) == len(resp.Data) {
+			for i := range resp.Data {
+				resp.Data[i].Raw = raws[i]
+			}
+		}
+	}
 	return filterInternalResults(resp.Data), nil
 }
 
@@ -186,6 +202,7 @@ func filterInternalResults(items []pexelsInternalItem) []ImageCandidate {
 			Source:    fmt.Sprintf("https://www.pexels.com/photo/%s-%d/", a.Slug, a.ID),
 			Title:     a.Title,
 			License:   LicenseSafe,
+			raw:       string(item.Raw),
 		})
 	}
 	return out
@@ -702,7 +702,7 @@ func TestPhase4Edge_DownloadForValidation_NilResult(t *testing.T) {
 	t.Parallel()
 
 	cfg := &Config{}
-	data, mimeType, img := cfg.downloadForValidation(context.Background(), "http://[::1]:0/nonexistent")
+	data, mimeType, img, _ := cfg.downloadForValidation(context.Background(), "http://[::1]:0/nonexistent", "", "")
 	if data != nil {
 		t.Errorf("downloadForValidation(invalid URL) data = %v, want nil", data)
 	}
@@ -0,0 +1,190 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// pickBestMaxImages caps how many previews are sent in one comparative request.
+// Most multimodal APIs degrade (or reject) requests with many inline images.
+const pickBestMaxImages = 8
+
+// DefaultPickBestPrompt is the prompt template used by PickBest. The %s verb
+// receives the search query. Consumers may override it via Config.PickBestPrompt.
+const DefaultPickBestPrompt = `You are an editorial photo editor for a city guide website.
+Several candidate images are attached, numbered from 0 in the order given.
+Choose the single image that best illustrates the query: %s
+
+Prefer real photographs that clearly show the subject, are sharp and well lit,
+and have no text overlays or watermarks.
+
+Answer with the number of the best image only.
+Example: 2
+Answer:`
+
+// ErrNoClassifier is returned by methods that require Config.Classifier when it is nil.
+var ErrNoClassifier = errors.New("imagefy: no classifier configured")
+
+// ErrNoCandidates is returned by PickBest when no candidate preview could be prepared.
+var ErrNoCandidates = errors.New("imagefy: no candidates")
+
+// PickBest sends previews of several candidates in one multimodal request and
+// asks the model to choose the best match for query. Returns the index of the
+// chosen candidate in candidates.
+//
+// Only the first pickBestMaxImages (8) candidates are considered; candidates
+// whose preview cannot be downloaded are skipped. Uses Config.PickBestPrompt
+// if set, otherwise DefaultPickBestPrompt.
+func (cfg *Config) PickBest(ctx context.Context, query string, candidates []ImageCandidate) (int, error) {
+	cfg = cfg.orZero()
+	cfg.defaults()
+	return cfg.pickBest(ctx, query, candidates, nil)
+}
+
+// pickBest implements PickBest, taking previews already downloaded during
+// validation from previews instead of downloading them again.
+func (cfg *Config) pickBest(ctx context.Context, query string, candidates []ImageCandidate, previews *previewStore) (int, error) {
+	if cfg.Classifier == nil {
+		return -1, ErrNoClassifier
+	}
+
+	images, indexes := cfg.pickBestPreviews(ctx, candidates, previews)
+	if len(images) == 0 {
+		return -1, ErrNoCandidates
+	}
+	if len(images) == 1 {
+		return indexes[0], nil
+	}
+
+	prompt := cfg.PickBestPrompt
+	if prompt == "" {
+		prompt = DefaultPickBestPrompt
+	}
+
+	resp, err := cfg.callClassifier(ctx, fmt.Sprintf(prompt, query), images)
+	if err != nil {
+		return -1, fmt.Errorf("imagefy: pick best: %w", err)
+	}
+
+	choice, ok := parsePickBestResponse(resp, len(images))
+	if !ok {
+		return -1, fmt.Errorf("imagefy: pick best: unparseable response %.100q", resp)
+	}
+
+	slog.Debug("imagefy: pick best", "query", query, "choice", choice, "images", len(images))
+	return indexes[choice], nil
+}
+
+// pickBestPreviews returns vision previews for up to pickBestMaxImages
+// candidates, taken from previews when validation kept them and downloaded
+// otherwise. Returns the images and, for each image, its index in candidates.
+func (cfg *Config) pickBestPreviews(ctx context.Context, candidates []ImageCandidate, previews *previewStore) ([]ImageInput, []int) {
+	var images []ImageInput
+	var indexes []int
+	for i, cand := range candidates {
+		if len(images) >= pickBestMaxImages {
+			break
+		}
+		data, mimeType, ok := previews.get(cand.ImgURL)
+		if !ok {
+			r, err := cfg.Download(ctx, cand.ImgURL, DownloadOpts{MaxBytes: visionMaxBytes})
+			if err != nil || r == nil || len(r.Data) == 0 {
+				continue
+			}
+			data, mimeType = r.Data, r.MIMEType
+		}
+		images = append(images, newImageInput(data, mimeType))
+		indexes = append(indexes, i)
+	}
+	return images, indexes
+}
+
+// previewStore keeps the bytes validation downloaded for each candidate,
+// keyed by ImgURL, so SearchOpts.PickBest compares the accepted images
+// without downloading them again. Rejected candidates are dropped.
+type previewStore struct {
+	mu sync.Mutex
+	m  map[string]preview
+}
+
+type preview struct {
+	data     []byte
+	mimeType string
+}
+
+func (s *previewStore) set(imgURL string, data []byte, mimeType string) {
+	if s == nil || len(data) == 0 {
+		return
+	}
+	s.mu.Lock()
+	if s.m == nil {
+		s.m = make(map[string]preview)
+	}
+	s.m[imgURL] = preview{data, mimeType}
+	s.mu.Unlock()
+}
+
+func (s *previewStore) drop(imgURL string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	delete(s.m, imgURL)
+	s.mu.Unlock()
+}
+
+func (s *previewStore) get(imgURL string) ([]byte, string, bool) {
+	if s == nil {
+		return nil, "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.m[imgURL]
+	return p.data, p.mimeType, ok
+}
+
+// withPreviews returns a copy of st for one search that keeps validated
+// previews for PickBest, leaving a Session's shared state untouched.
+func (st *searchState) withPreviews() *searchState {
+	c := *st
+	c.previews = &previewStore{}
+	return &c
+}
+
+// parsePickBestResponse extracts the first integer in [0, n) from a model response.
+func parsePickBestResponse(resp string, n int) (int, bool) {
+	for _, field := range strings.FieldsFunc(stripReasoning(resp), func(r rune) bool {
+		return r < '0' || r > '9'
+	}) {
+		idx, err := strconv.Atoi(field)
+		if err == nil && idx >= 0 && idx < n {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// promoteBest moves the PickBest winner to the front of results.
+// On any error the original order is kept (graceful degradation).
+func (cfg *Config) promoteBest(ctx context.Context, query string, results []ImageCandidate, previews *previewStore) []ImageCandidate {
+	if len(results) < 2 || cfg.Classifier == nil {
+		return results
+	}
+	best, err := cfg.pickBest(ctx, query, results, previews)
+	if err != nil {
+		slog.Debug("imagefy: pick best failed", "query", query, "error", err.Error())
+		return results
+	}
+	if best <= 0 {
+		return results
+	}
+	out := make([]ImageCandidate, 0, len(results))
+	out = append(out, results[best])
+	out = append(out, results[:best]...)
+	return append(out, results[best+1:]...)
+}
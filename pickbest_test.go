@@ -0,0 +1,215 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// imageCountingClassifier records the prompt and number of images per call.
+type imageCountingClassifier struct {
+	response string
+	err      error
+	prompt   string
+	images   int
+}
+
+func (c *imageCountingClassifier) Classify(_ context.Context, prompt string, images []ImageInput) (string, error) {
+	c.prompt = prompt
+	c.images = len(images)
+	return c.response, c.err
+}
+
+// newPickBestServer serves JPEG bytes on every path except /missing.jpg.
+func newPickBestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(make([]byte, 512))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPickBest_ReturnsModelChoice(t *testing.T) {
+	t.Parallel()
+
+	srv := newPickBestServer(t)
+	cls := &imageCountingClassifier{response: "2"}
+	cfg := &Config{Classifier: cls, HTTPClient: srv.Client()}
+
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/a.jpg"},
+		{ImgURL: srv.URL + "/b.jpg"},
+		{ImgURL: srv.URL + "/c.jpg"},
+	}
+	got, err := cfg.PickBest(context.Background(), "Kazan Cathedral", cands)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("PickBest = %d, want 2", got)
+	}
+	if cls.images != 3 {
+		t.Errorf("classifier received %d images, want 3", cls.images)
+	}
+	if !strings.Contains(cls.prompt, "Kazan Cathedral") {
+		t.Errorf("prompt does not contain query: %q", cls.prompt)
+	}
+}
+
+func TestPickBest_SkipsFailedDownloads(t *testing.T) {
+	t.Parallel()
+
+	srv := newPickBestServer(t)
+	cls := &imageCountingClassifier{response: "1"}
+	cfg := &Config{Classifier: cls, HTTPClient: srv.Client()}
+
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/a.jpg"},
+		{ImgURL: srv.URL + "/missing.jpg"},
+		{ImgURL: srv.URL + "/c.jpg"},
+	}
+	got, err := cfg.PickBest(context.Background(), "q", cands)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Image #1 in the request is candidate #2 (candidate #1 failed to download).
+	if got != 2 {
+		t.Errorf("PickBest = %d, want 2", got)
+	}
+	if cls.images != 2 {
+		t.Errorf("classifier received %d images, want 2", cls.images)
+	}
+}
+
+func TestPickBest_Errors(t *testing.T) {
+	t.Parallel()
+
+	srv := newPickBestServer(t)
+	cands := []ImageCandidate{{ImgURL: srv.URL + "/a.jpg"}, {ImgURL: srv.URL + "/b.jpg"}}
+
+	cfg := &Config{HTTPClient: srv.Client()}
+	if _, err := cfg.PickBest(context.Background(), "q", cands); !errors.Is(err, ErrNoClassifier) {
+		t.Errorf("nil classifier: err = %v, want ErrNoClassifier", err)
+	}
+
+	cfg = &Config{Classifier: &imageCountingClassifier{response: "2"}, HTTPClient: srv.Client()}
+	if _, err := cfg.PickBest(context.Background(), "q", nil); !errors.Is(err, ErrNoCandidates) {
+		t.Errorf("no candidates: err = %v, want ErrNoCandidates", err)
+	}
+
+	cfg = &Config{Classifier: &imageCountingClassifier{response: "7"}, HTTPClient: srv.Client()}
+	if _, err := cfg.PickBest(context.Background(), "q", cands); err == nil {
+		t.Error("out-of-range answer: expected error, got nil")
+	}
+
+	llmErr := errors.New("boom")
+	cfg = &Config{Classifier: &imageCountingClassifier{err: llmErr}, HTTPClient: srv.Client()}
+	if _, err := cfg.PickBest(context.Background(), "q", cands); !errors.Is(err, llmErr) {
+		t.Errorf("classifier error: err = %v, want wrapped %v", err, llmErr)
+	}
+}
+
+func TestPickBest_SingleCandidateSkipsLLM(t *testing.T) {
+	t.Parallel()
+
+	srv := newPickBestServer(t)
+	cls := &mockClassifier{response: "0"}
+	cfg := &Config{Classifier: cls, HTTPClient: srv.Client()}
+
+	got, err := cfg.PickBest(context.Background(), "q", []ImageCandidate{{ImgURL: srv.URL + "/a.jpg"}})
+	if err != nil || got != 0 {
+		t.Errorf("PickBest = (%d, %v), want (0, nil)", got, err)
+	}
+	if cls.calls != 0 {
+		t.Errorf("classifier called %d times, want 0", cls.calls)
+	}
+}
+
+func TestParsePickBestResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		resp   string
+		want   int
+		wantOK bool
+	}{
+		{"2", 2, true},
+		{" 0\n", 0, true},
+		{"Image 3 is best", 3, true},
+		{"<think>Image 0 has a watermark.</think>1", 1, true},
+		{"9", 0, false},
+		{"none", 0, false},
+	}
+	for _, tc := range tests {
+		got, ok := parsePickBestResponse(tc.resp, 4)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("parsePickBestResponse(%q) = (%d, %v), want (%d, %v)", tc.resp, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestPromoteBest_MovesWinnerToFront(t *testing.T) {
+	t.Parallel()
+
+	srv := newPickBestServer(t)
+	cfg := &Config{Classifier: &imageCountingClassifier{response: "1"}, HTTPClient: srv.Client()}
+
+	in := []ImageCandidate{
+		{ImgURL: srv.URL + "/a.jpg"},
+		{ImgURL: srv.URL + "/b.jpg"},
+		{ImgURL: srv.URL + "/c.jpg"},
+	}
+	out := cfg.promoteBest(context.Background(), "q", in, nil)
+	want := []string{"/b.jpg", "/a.jpg", "/c.jpg"}
+	for i, w := range want {
+		if !strings.HasSuffix(out[i].ImgURL, w) {
+			t.Errorf("out[%d] = %q, want suffix %q", i, out[i].ImgURL, w)
+		}
+	}
+}
+
+func TestPromoteBest_ReusesValidationPreviews(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(make([]byte, 512))
+	}))
+	t.Cleanup(srv.Close)
+
+	cls := &imageCountingClassifier{response: "2"}
+	cfg := &Config{Classifier: cls, HTTPClient: srv.Client()}
+	in := []ImageCandidate{
+		{ImgURL: srv.URL + "/a.jpg"},
+		{ImgURL: srv.URL + "/b.jpg"},
+		{ImgURL: srv.URL + "/c.jpg"},
+	}
+	previews := &previewStore{}
+	previews.set(in[0].ImgURL, make([]byte, 64), "image/jpeg")
+	previews.set(in[2].ImgURL, make([]byte, 64), "image/jpeg")
+	previews.set(srv.URL+"/rejected.jpg", make([]byte, 64), "image/jpeg")
+	previews.drop(srv.URL + "/rejected.jpg")
+
+	out := cfg.promoteBest(context.Background(), "q", in, previews)
+	if !strings.HasSuffix(out[0].ImgURL, "/c.jpg") || cls.images != 3 {
+		t.Errorf("promoteBest = %v with %d images, want c.jpg first of 3", out, cls.images)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("downloads = %d, want 1 (only the preview validation did not keep)", n)
+	}
+	if _, _, ok := previews.get(srv.URL + "/rejected.jpg"); ok {
+		t.Error("dropped preview still stored")
+	}
+}
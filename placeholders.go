@@ -0,0 +1,130 @@
+package imagefy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"image"
+	"log/slog"
+	"strings"
+
+	"github.com/corona10/goimagehash"
+)
+
+// placeholderThreshold is the maximum Hamming distance between a dHash and
+// a placeholder fingerprint for the image to count as that placeholder. It
+// is tighter than dedupThreshold: a false match drops an image outright.
+const placeholderThreshold = 6
+
+// placeholdersJSON holds perceptual hashes of common "no image available"
+// and CDN placeholder graphics. Edit data/placeholders.json; the tests
+// validate every entry.
+//
+//go:embed data/placeholders.json
+var placeholdersJSON []byte
+
+// PlaceholderEntry is one placeholder fingerprint.
+type PlaceholderEntry struct {
+	Hash string `json:"hash"` // dHash in goimagehash string form, e.g. "d:000216160e0e0200" (see PlaceholderHash)
+	Name string `json:"name"`
+	Note string `json:"note,omitempty"`
+}
+
+// builtinPlaceholders is parsed once at init; invalid embedded data is a
+// build defect, so it panics like regexp.MustCompile.
+var builtinPlaceholders, builtinPlaceholderHashes = mustParsePlaceholders(placeholdersJSON)
+
+// BuiltinPlaceholders returns a copy of the embedded placeholder
+// fingerprints that the validation pipeline rejects with ReasonPlaceholder.
+func BuiltinPlaceholders() []PlaceholderEntry {
+	return append([]PlaceholderEntry(nil), builtinPlaceholders...)
+}
+
+// PlaceholderHash returns the fingerprint of img in the form
+// Config.PlaceholderHashes expects, e.g. to add a site's own "no photo"
+// graphic.
+func PlaceholderHash(img image.Image) (string, error) {
+	h, err := goimagehash.DifferenceHash(img)
+	if err != nil {
+		return "", err
+	}
+	return h.ToString(), nil
+}
+
+func mustParsePlaceholders(data []byte) ([]PlaceholderEntry, []*goimagehash.ImageHash) {
+	entries, hashes, err := parsePlaceholders(data)
+	if err != nil {
+		panic(err)
+	}
+	return entries, hashes
+}
+
+// parsePlaceholders decodes and validates placeholder data: every entry
+// needs a unique name and a parsable dHash that is not a flat fill, which
+// would match every solid-color image.
+func parsePlaceholders(data []byte) ([]PlaceholderEntry, []*goimagehash.ImageHash, error) {
+	var d struct {
+		Placeholders []PlaceholderEntry `json:"placeholders"`
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, nil, fmt.Errorf("imagefy: decode placeholder data: %w", err)
+	}
+	if len(d.Placeholders) == 0 {
+		return nil, nil, fmt.Errorf("imagefy: placeholder data is empty")
+	}
+
+	flat := goimagehash.NewImageHash(0, goimagehash.DHash)
+	seen := make(map[string]bool, len(d.Placeholders))
+	hashes := make([]*goimagehash.ImageHash, 0, len(d.Placeholders))
+	for _, e := range d.Placeholders {
+		h, err := parsePlaceholderHash(e.Hash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("imagefy: placeholder data: %q: %w", e.Name, err)
+		}
+		switch dist, _ := h.Distance(flat); {
+		case e.Name == "":
+			return nil, nil, fmt.Errorf("imagefy: placeholder data: entry %q has no name", e.Hash)
+		case seen[e.Name]:
+			return nil, nil, fmt.Errorf("imagefy: placeholder data: entry %q is duplicated", e.Name)
+		case dist <= dedupThreshold:
+			return nil, nil, fmt.Errorf("imagefy: placeholder data: %q is too close to a flat fill", e.Name)
+		}
+		seen[e.Name] = true
+		hashes = append(hashes, h)
+	}
+	return d.Placeholders, hashes, nil
+}
+
+// parsePlaceholderHash parses a dHash string as produced by PlaceholderHash.
+func parsePlaceholderHash(s string) (*goimagehash.ImageHash, error) {
+	if !strings.HasPrefix(s, "d:") {
+		return nil, fmt.Errorf("hash %q is not a dHash", s)
+	}
+	return goimagehash.ImageHashFromString(s)
+}
+
+// isPlaceholderHash reports whether an image's dHash is within
+// placeholderThreshold of one of the built-in placeholders or
+// Config.PlaceholderHashes. Unparsable user hashes are skipped.
+func (cfg *Config) isPlaceholderHash(hash *goimagehash.ImageHash) bool {
+	matches := func(h *goimagehash.ImageHash) bool {
+		dist, err := hash.Distance(h)
+		return err == nil && dist <= placeholderThreshold
+	}
+	for _, h := range builtinPlaceholderHashes {
+		if matches(h) {
+			return true
+		}
+	}
+	for _, s := range cfg.PlaceholderHashes {
+		h, err := parsePlaceholderHash(s)
+		if err != nil {
+			slog.Debug("imagefy: invalid placeholder hash", "hash", s, "error", err)
+			continue
+		}
+		if matches(h) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"testing"
+)
+
+// encodeGrayJPEG renders a w×h image, fg where inside reports true and bg
+// elsewhere, with coordinates scaled to 0–1.
+func encodeGrayJPEG(w, h int, bg, fg uint8, inside func(x, y float64) bool) []byte {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			c := bg
+			if inside(float64(x)/float64(w), float64(y)/float64(h)) {
+				c = fg
+			}
+			img.SetGray(x, y, color.Gray{Y: c})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		panic("encodeGrayJPEG: " + err.Error())
+	}
+	return buf.Bytes()
+}
+
+// makePictureGlyphJPEG draws the framed-landscape "no image" glyph of
+// builtin entry picture_glyph_light.
+func makePictureGlyphJPEG() []byte {
+	const aspect = 1.5
+	mountain := func(x, y, ax, ay, x0, x1, by float64) bool {
+		if y < ay || y > by {
+			return false
+		}
+		f := (y - ay) / (by - ay)
+		return x >= ax-(ax-x0)*f && x <= ax+(x1-ax)*f
+	}
+	return encodeGrayJPEG(600, 400, 0xee, 0xbd, func(x, y float64) bool {
+		frame := x >= 0.35 && x <= 0.65 && y >= 0.3 && y <= 0.7 &&
+			!(x >= 0.37 && x <= 0.63 && y >= 0.32 && y <= 0.68)
+		sun := math.Hypot((x-0.57)*aspect, y-0.40) <= 0.04
+		return frame || sun || mountain(x, y, 0.45, 0.45, 0.37, 0.55, 0.68) || mountain(x, y, 0.56, 0.52, 0.5, 0.63, 0.68)
+	})
+}
+
+func TestBuiltinPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	entries := BuiltinPlaceholders()
+	if len(entries) == 0 || len(entries) != len(builtinPlaceholderHashes) {
+		t.Fatalf("got %d entries, %d hashes", len(entries), len(builtinPlaceholderHashes))
+	}
+	entries[0].Name = "changed"
+	if BuiltinPlaceholders()[0].Name == "changed" {
+		t.Error("BuiltinPlaceholders returned the embedded slice, want a copy")
+	}
+}
+
+func TestParsePlaceholders_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, data := range map[string]string{
+		"empty":     `{"placeholders": []}`,
+		"no name":   `{"placeholders": [{"hash": "d:000216160e0e0200"}]}`,
+		"duplicate": `{"placeholders": [{"hash": "d:000216160e0e0200", "name": "a"}, {"hash": "d:0000686870700000", "name": "a"}]}`,
+		"not dhash": `{"placeholders": [{"hash": "p:000216160e0e0200", "name": "a"}]}`,
+		"bad hex":   `{"placeholders": [{"hash": "d:zz", "name": "a"}]}`,
+		"flat fill": `{"placeholders": [{"hash": "d:0000000000000000", "name": "a"}]}`,
+	} {
+		if _, _, err := parsePlaceholders([]byte(data)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestPlaceholderRejected(t *testing.T) {
+	t.Parallel()
+
+	halves := encodeGrayJPEG(900, 600, 0x30, 0xd0, func(x, _ float64) bool { return x >= 0.5 })
+	img, _, err := image.Decode(bytes.NewReader(halves))
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom, err := PlaceholderHash(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newMultiImageServer(t, map[string][]byte{
+		"/glyph.jpg":  makePictureGlyphJPEG(),
+		"/custom.jpg": halves,
+		"/photo.jpg":  makeJPEG(900, 600),
+	})
+	tests := []struct {
+		path   string
+		hashes []string
+		reason RejectReason
+	}{
+		{"/glyph.jpg", nil, ReasonPlaceholder},
+		{"/custom.jpg", []string{"not a hash", custom}, ReasonPlaceholder},
+		{"/custom.jpg", nil, ""},
+		{"/photo.jpg", nil, ""}, // solid fill is never a placeholder
+	}
+	for _, tt := range tests {
+		var reason RejectReason
+		cfg := &Config{
+			HTTPClient:          srv.Client(),
+			MinImageWidth:       100,
+			PlaceholderHashes:   tt.hashes,
+			OnCandidateRejected: func(e CandidateEvent) { reason = e.Reason },
+		}
+		cand := ImageCandidate{ImgURL: srv.URL + tt.path, License: LicenseSafe}
+		got := cfg.ValidateCandidates(context.Background(), []ImageCandidate{cand}, 1)
+		if tt.reason == "" {
+			if len(got) != 1 {
+				t.Errorf("%s %v: rejected as %q, want accepted", tt.path, tt.hashes, reason)
+			}
+			continue
+		}
+		if len(got) != 0 || reason != tt.reason {
+			t.Errorf("%s %v: got %d results, reason %q; want %q", tt.path, tt.hashes, len(got), reason, tt.reason)
+		}
+	}
+}
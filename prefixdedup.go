@@ -0,0 +1,60 @@
+package imagefy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// probeDecodeLimit caps how much of a probe response is read to decode the
+// image dimensions, and with it Config.PrefixDedupBytes.
+const probeDecodeLimit = 256 * 1024
+
+// prefixCapture records the first n bytes read through it.
+type prefixCapture struct {
+	r   io.Reader
+	n   int
+	buf []byte
+}
+
+// capturePrefix wraps *src to record the first Config.PrefixDedupBytes of
+// a probe body. It returns nil, leaving *src as is, when prefix dedup is off
+// or the response lacks a reliable length: no Content-Length, or a
+// content-encoded body whose length is not that of the image.
+func (cfg *Config) capturePrefix(resp *http.Response, src *io.Reader) *prefixCapture {
+	n := min(cfg.PrefixDedupBytes, probeDecodeLimit)
+	if n <= 0 || resp.ContentLength <= 0 || resp.Uncompressed || resp.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	c := &prefixCapture{r: *src, n: n, buf: make([]byte, 0, n)}
+	*src = c
+	return c
+}
+
+func (c *prefixCapture) Read(p []byte) (int, error) {
+	k, err := c.r.Read(p)
+	if room := c.n - len(c.buf); room > 0 {
+		c.buf = append(c.buf, p[:min(k, room)]...)
+	}
+	return k, err
+}
+
+// fingerprint reads on until n bytes are captured and returns the SHA-256 of
+// the prefix joined with the body length, which identifies a byte-identical
+// file on any host. It returns "" for a nil capture or a body that ended
+// before both n bytes and its declared length.
+func (c *prefixCapture) fingerprint(length int64) string {
+	if c == nil {
+		return ""
+	}
+	if rest := c.n - len(c.buf); rest > 0 {
+		_, _ = io.CopyN(io.Discard, c, int64(rest))
+	}
+	if len(c.buf) < c.n && int64(len(c.buf)) != length {
+		return ""
+	}
+	sum := sha256.Sum256(c.buf)
+	return hex.EncodeToString(sum[:]) + ":" + strconv.FormatInt(length, 10)
+}
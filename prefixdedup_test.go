@@ -0,0 +1,99 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// newLengthServer serves body as image/jpeg, with a Content-Length header
+// unless chunked, counting requests.
+func newLengthServer(t *testing.T, body []byte, chunked bool, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "image/jpeg")
+		if !chunked {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPrefixCapture_Fingerprint(t *testing.T) {
+	t.Parallel()
+
+	fingerprint := func(n int, body []byte, length int64) string {
+		resp := &http.Response{ContentLength: length, Header: http.Header{}}
+		var src io.Reader = bytes.NewReader(body)
+		c := (&Config{PrefixDedupBytes: n}).capturePrefix(resp, &src)
+		_, _ = io.CopyN(io.Discard, src, 10) // the decoder reads less than n
+		return c.fingerprint(length)
+	}
+
+	body := bytes.Repeat([]byte("imagefy-"), 512)
+	a := fingerprint(1024, body, int64(len(body)))
+	if a == "" {
+		t.Fatal("no fingerprint for a body with Content-Length")
+	}
+	if b := fingerprint(1024, append(body[:1024:1024], 'x'), int64(len(body))); b != a {
+		t.Error("fingerprint depends on bytes past the prefix")
+	}
+	if b := fingerprint(1024, body, int64(len(body))+1); b == a {
+		t.Error("fingerprint ignores the content length")
+	}
+	if short := fingerprint(1024, body[:100], 100); short == "" {
+		t.Error("no fingerprint for a body shorter than the prefix")
+	}
+	if cut := fingerprint(1024, body[:100], int64(len(body))); cut != "" {
+		t.Errorf("fingerprint of a body cut short = %q, want empty", cut)
+	}
+	if off := fingerprint(0, body, int64(len(body))); off != "" {
+		t.Errorf("fingerprint with PrefixDedupBytes = 0 is %q, want empty", off)
+	}
+	if unknown := fingerprint(1024, body, -1); unknown != "" {
+		t.Errorf("fingerprint without Content-Length = %q, want empty", unknown)
+	}
+}
+
+func TestValidateOne_PrefixDedupSkipsMirrorDownload(t *testing.T) {
+	t.Parallel()
+
+	body := makeJPEG(1000, 600)
+	var hitsA, hitsB, hitsC atomic.Int32
+	a := newLengthServer(t, body, false, &hitsA)
+	b := newLengthServer(t, body, false, &hitsB)
+	c := newLengthServer(t, body, true, &hitsC)
+
+	cfg := &Config{PrefixDedupBytes: 1024}
+	cfg.defaults()
+	st := newSearchState()
+	ctx := context.Background()
+
+	if _, reason, _ := cfg.validateOne(ctx, ImageCandidate{ImgURL: a.URL + "/a.jpg"}, st, nil); reason != "" {
+		t.Fatalf("original rejected: %s", reason)
+	}
+	stage, reason, _ := cfg.validateOne(ctx, ImageCandidate{ImgURL: b.URL + "/b.jpg"}, st, nil)
+	if reason != ReasonDuplicate || stage != StageDedup {
+		t.Errorf("mirror: %s at %s, want duplicate at dedup", reason, stage)
+	}
+	if n := hitsB.Load(); n != 1 {
+		t.Errorf("mirror host saw %d requests, want only the probe", n)
+	}
+
+	// Without a Content-Length there is no fingerprint: the perceptual check
+	// still catches the copy, after downloading it.
+	if _, reason, _ := cfg.validateOne(ctx, ImageCandidate{ImgURL: c.URL + "/c.jpg"}, st, nil); reason != ReasonDuplicate {
+		t.Errorf("chunked mirror: %s, want duplicate", reason)
+	}
+	if n := hitsC.Load(); n != 2 {
+		t.Errorf("chunked mirror host saw %d requests, want probe and download", n)
+	}
+}
@@ -0,0 +1,102 @@
+package imagefy
+
+// Presets are opt-in starting points that bundle block lists, prompts,
+// widths, and aspect ratios tuned for common use cases. Each call returns a
+// new Config with no dependencies wired; set Classifier, Cache, Providers,
+// and so on as usual, and adjust any field:
+//
+//	cfg := imagefy.PresetNews()
+//	cfg.Classifier = llm
+//	cfg.Providers = providers
+
+// NewsVisionPrompt is the classification prompt of PresetNews.
+const NewsVisionPrompt = `You are a photo editor for a news website.
+We only accept real documentary photographs without stock or agency watermarks.
+
+Classify this image. Answer with one word and your confidence (0.0 to 1.0).
+
+Categories:
+- PHOTO — real photograph of people, places, or events. Small corner credit is OK.
+- STOCK — photograph with a visible stock or wire agency watermark (Shutterstock,
+  Getty, AP, Reuters, AFP, EPA, etc.), or an obviously staged stock scene.
+- REJECT — banner, ad, promotional graphic, large text overlay, collage, meme,
+  TV screen grab with tickers or channel logos.
+- SCREENSHOT — screenshot of a website, social media post, app, or document.
+- ILLUSTRATION — drawing, painting, digital art, cartoon, AI-generated art.
+- MAP — map, chart, infographic, diagram.
+- PLACEHOLDER — error page, "no permission" message, blank image with centered
+  text, or site logo used as article image.
+
+Answer format: CLASS 0.95
+Example: PHOTO 0.92
+Answer:`
+
+// EcommerceVisionPrompt is the classification prompt of PresetEcommerce.
+const EcommerceVisionPrompt = `You are an image moderator for an online store catalog.
+We only accept clean product photographs.
+
+Classify this image. Answer with one word and your confidence (0.0 to 1.0).
+
+Categories:
+- PHOTO — real photograph of a product, on a plain background or in use.
+  Small brand marks on the product itself are OK.
+- STOCK — photograph with a visible stock watermark (Shutterstock, Getty, iStock, etc.)
+- REJECT — banner, ad, price tag or discount overlay, large text, collage of
+  several products, marketplace badges, meme.
+- SCREENSHOT — screenshot of a website, marketplace listing, or app.
+- ILLUSTRATION — drawing, 3D render, vector graphic, cartoon.
+- MAP — map, size chart, diagram, infographic.
+- PLACEHOLDER — "no image" placeholder, error page, blank image, or store logo.
+
+Answer format: CLASS 0.95
+Example: PHOTO 0.92
+Answer:`
+
+// PresetCityGuide returns a Config for city-guide and travel content:
+// landscape-leaning photographs of places at least DefaultMinImageWidth wide,
+// DefaultVisionPrompt, and blocked travel-booking and review sites whose
+// photos are user-posted or licensed to the site only.
+func PresetCityGuide() *Config {
+	return &Config{
+		MinImageWidth:  DefaultMinImageWidth,
+		MinAspectRatio: 0.75, //nolint:mnd // 3:4 portrait at most
+		MaxAspectRatio: 2.5,  //nolint:mnd // wider is usually a banner or panorama strip
+		VisionPrompt:   DefaultVisionPrompt,
+		ExtraBlockedDomains: []string{
+			"tripadvisor", "booking.com", "bstatic.com", "expedia", "hotels.com",
+			"agoda", "yelp", "foursquare",
+		},
+	}
+}
+
+// PresetNews returns a Config for news articles: wide landscape hero images
+// (at least 1200px, 4:3 to 2:1), NewsVisionPrompt, and blocked wire and
+// picture agencies that license photos per use.
+func PresetNews() *Config {
+	return &Config{
+		MinImageWidth:  1200, //nolint:mnd // hero image width
+		MinAspectRatio: 1.3,  //nolint:mnd // 4:3
+		MaxAspectRatio: 2.0,  //nolint:mnd // 2:1
+		VisionPrompt:   NewsVisionPrompt,
+		ExtraBlockedDomains: []string{
+			"apimages", "apnews", "reutersconnect", "pictures.reuters", "afpforum",
+			"epa.eu", "photo.tass.ru", "pa-images", "wireimage",
+		},
+	}
+}
+
+// PresetEcommerce returns a Config for product catalogs: near-square images
+// (at least 600px, 4:5 to 5:4), EcommerceVisionPrompt, and blocked
+// marketplace image hosts whose photos belong to the listing sellers.
+func PresetEcommerce() *Config {
+	return &Config{
+		MinImageWidth:  600,  //nolint:mnd // catalog thumbnail width
+		MinAspectRatio: 0.8,  //nolint:mnd // 4:5
+		MaxAspectRatio: 1.25, //nolint:mnd // 5:4
+		VisionPrompt:   EcommerceVisionPrompt,
+		ExtraBlockedDomains: []string{
+			"media-amazon", "ssl-images-amazon", "ebayimg", "alicdn", "aliexpress",
+			"wbbasket", "wildberries", "ozon.ru", "etsystatic",
+		},
+	}
+}
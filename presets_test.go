@@ -0,0 +1,71 @@
+package imagefy
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	t.Parallel()
+
+	presets := map[string]func() *Config{
+		"city guide": PresetCityGuide,
+		"news":       PresetNews,
+		"ecommerce":  PresetEcommerce,
+	}
+	for name, preset := range presets {
+		cfg := preset()
+		if cfg.MinImageWidth <= 0 || cfg.MinAspectRatio <= 0 || cfg.MaxAspectRatio <= cfg.MinAspectRatio {
+			t.Errorf("%s: width/aspect bounds = %d, %v..%v", name, cfg.MinImageWidth, cfg.MinAspectRatio, cfg.MaxAspectRatio)
+		}
+		if !strings.Contains(cfg.VisionPrompt, "CLASS 0.95") || len(cfg.ExtraBlockedDomains) == 0 {
+			t.Errorf("%s: prompt or block list missing", name)
+		}
+		cfg.ExtraBlockedDomains[0] = "changed"
+		if preset().ExtraBlockedDomains[0] == "changed" {
+			t.Errorf("%s: presets must not share slices", name)
+		}
+	}
+}
+
+func TestCheckAspectRatio(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{MinAspectRatio: 1.3, MaxAspectRatio: 2.0}
+	tests := []struct {
+		w, h int
+		want RejectReason
+	}{
+		{1600, 900, ""},
+		{1200, 1200, ReasonBadAspectRatio},
+		{3000, 1000, ReasonBadAspectRatio},
+		{1000, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.checkAspectRatio(tt.w, tt.h); got != tt.want {
+			t.Errorf("checkAspectRatio(%d, %d) = %q, want %q", tt.w, tt.h, got, tt.want)
+		}
+	}
+	if got := (&Config{}).checkAspectRatio(5000, 10); got != "" {
+		t.Errorf("no bounds: checkAspectRatio = %q, want accept", got)
+	}
+}
+
+func TestAspectRatio_ProbeAndBytes(t *testing.T) {
+	t.Parallel()
+
+	banner := makeJPEG(1500, 400)
+	srv := newImageServer(t, "image/jpeg", banner)
+	cfg := PresetCityGuide()
+	cfg.HTTPClient = srv.Client()
+	cfg.defaults()
+	if got := cfg.probeImageURL(context.Background(), srv.URL+"/wide.jpg", ""); got != ReasonBadAspectRatio {
+		t.Errorf("probe = %q, want %q", got, ReasonBadAspectRatio)
+	}
+
+	report, err := PresetEcommerce().ValidateImageBytes(context.Background(), makeJPEG(1000, 600))
+	if err != nil || report.Valid || report.Reason != ReasonBadAspectRatio || report.Stage != StageProbe {
+		t.Errorf("ValidateImageBytes = %+v, %v; want probe bad_aspect_ratio", report, err)
+	}
+}
@@ -0,0 +1,87 @@
+package imagefy
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"log/slog"
+)
+
+// defaultPreviewQuality is the Config.PreviewQuality used when unset.
+const defaultPreviewQuality = 75
+
+// Preview formats accepted by Config.PreviewFormat.
+const (
+	PreviewJPEG = "image/jpeg"
+	PreviewWebP = "image/webp"
+	PreviewAVIF = "image/avif"
+)
+
+// PreviewEncoder encodes a vision preview for Config.PreviewFormat.
+// Encode returns img encoded as format (a MIME type such as "image/webp")
+// at quality 1–100, or an error if the format is unsupported.
+type PreviewEncoder interface {
+	Encode(img image.Image, format string, quality int) ([]byte, error)
+}
+
+// checkPreviewFormat reports a Config.PreviewFormat that cannot be encoded.
+func (cfg *Config) checkPreviewFormat() error {
+	switch cfg.PreviewFormat {
+	case "", PreviewJPEG:
+		return nil
+	case PreviewWebP, PreviewAVIF:
+		if cfg.PreviewEncoder == nil {
+			return fmt.Errorf("imagefy: PreviewFormat %q needs a PreviewEncoder", cfg.PreviewFormat)
+		}
+		return nil
+	default:
+		return fmt.Errorf("imagefy: unknown PreviewFormat %q", cfg.PreviewFormat)
+	}
+}
+
+// previewInput returns the ImageInput sent to the Classifier for downloaded
+// data, transcoded to Config.PreviewFormat when that makes it smaller.
+func (cfg *Config) previewInput(data []byte, mimeType string) ImageInput {
+	if cfg.PreviewFormat == "" {
+		return newImageInput(data, mimeType)
+	}
+	out, w, h, err := cfg.transcodePreview(data)
+	if err != nil {
+		slog.Debug("imagefy: preview sent as downloaded", "format", cfg.PreviewFormat, "error", err)
+		return newImageInput(data, mimeType)
+	}
+	if len(out) == 0 || len(out) >= len(data) {
+		return newImageInput(data, mimeType)
+	}
+	// Set the dimensions directly: no decoder may be registered for the
+	// target format (AVIF).
+	return ImageInput{URL: EncodeDataURL(out, cfg.PreviewFormat), MIMEType: cfg.PreviewFormat, Data: out, Width: w, Height: h}
+}
+
+// transcodePreview decodes data and re-encodes it as Config.PreviewFormat,
+// returning the encoded bytes and the image dimensions.
+func (cfg *Config) transcodePreview(data []byte) ([]byte, int, int, error) {
+	if err := cfg.checkPreviewFormat(); err != nil {
+		return nil, 0, 0, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	quality := cfg.PreviewQuality
+	if quality <= 0 || quality > 100 {
+		quality = defaultPreviewQuality
+	}
+	b := img.Bounds()
+	if cfg.PreviewEncoder != nil {
+		out, err := cfg.PreviewEncoder.Encode(img, cfg.PreviewFormat, quality)
+		return out, b.Dx(), b.Dy(), err
+	}
+	// Without an encoder, checkPreviewFormat only lets JPEG through.
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), b.Dx(), b.Dy(), nil
+}
@@ -0,0 +1,128 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// fakeEncoder records calls and returns out (or err).
+type fakeEncoder struct {
+	out     []byte
+	err     error
+	format  string
+	quality int
+}
+
+func (e *fakeEncoder) Encode(_ image.Image, format string, quality int) ([]byte, error) {
+	e.format, e.quality = format, quality
+	return e.out, e.err
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// makeNoiseImage returns a photo-like image that PNG compresses poorly.
+func makeNoiseImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.IntN(256))
+	}
+	return img
+}
+
+func TestPreviewInput(t *testing.T) {
+	t.Parallel()
+
+	pngData := encodePNG(t, makeNoiseImage(400, 300))
+	tests := []struct {
+		name     string
+		cfg      Config
+		wantMIME string
+		wantRaw  bool
+	}{
+		{"no format", Config{}, "image/png", true},
+		{"built-in jpeg", Config{PreviewFormat: PreviewJPEG, PreviewQuality: 60}, PreviewJPEG, false},
+		{"webp without encoder", Config{PreviewFormat: PreviewWebP}, "image/png", true},
+		{"webp encoder", Config{PreviewFormat: PreviewWebP, PreviewEncoder: &fakeEncoder{out: []byte("RIFF....WEBP")}}, PreviewWebP, false},
+		{"encoder error", Config{PreviewFormat: PreviewAVIF, PreviewEncoder: &fakeEncoder{err: errors.New("no codec")}}, "image/png", true},
+		{"not smaller", Config{PreviewFormat: PreviewWebP, PreviewEncoder: &fakeEncoder{out: bytes.Repeat([]byte{0}, len(pngData))}}, "image/png", true},
+		{"unknown format", Config{PreviewFormat: "image/bmp"}, "image/png", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			in := tc.cfg.previewInput(pngData, "image/png")
+			if in.MIMEType != tc.wantMIME {
+				t.Errorf("MIMEType = %q, want %q", in.MIMEType, tc.wantMIME)
+			}
+			if raw := bytes.Equal(in.Data, pngData); raw != tc.wantRaw {
+				t.Errorf("sent as downloaded = %v, want %v", raw, tc.wantRaw)
+			}
+			if !strings.HasPrefix(in.URL, "data:"+in.MIMEType+";base64,") {
+				t.Errorf("URL = %.40q, want a %s data: URI", in.URL, in.MIMEType)
+			}
+			if in.Width != 400 || in.Height != 300 {
+				t.Errorf("dimensions = %dx%d, want 400x300", in.Width, in.Height)
+			}
+		})
+	}
+}
+
+func TestPreviewInput_EncoderQuality(t *testing.T) {
+	t.Parallel()
+
+	data := encodePNG(t, makeCheckerImage(200, 200, 10))
+	enc := &fakeEncoder{out: []byte("avif")}
+	cfg := &Config{PreviewFormat: PreviewAVIF, PreviewEncoder: enc}
+	cfg.previewInput(data, "image/png")
+	if enc.format != PreviewAVIF || enc.quality != defaultPreviewQuality {
+		t.Errorf("Encode(%q, %d), want (%q, %d)", enc.format, enc.quality, PreviewAVIF, defaultPreviewQuality)
+	}
+}
+
+func TestClassify_SendsTranscodedPreview(t *testing.T) {
+	t.Parallel()
+
+	pngData := encodePNG(t, makeNoiseImage(400, 300))
+	cls := &imagesCapturingClassifier{}
+	cfg := &Config{PreviewFormat: PreviewJPEG, Classifier: cls}
+	if _, err := cfg.classifyPredownloaded(context.Background(), "https://example.com/a.png", pngData, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cls.images; len(got) != 1 || got[0].MIMEType != PreviewJPEG || len(got[0].Data) >= len(pngData) {
+		t.Errorf("classifier got %d images, want one smaller JPEG preview", len(got))
+	}
+}
+
+func TestValidate_PreviewFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		cfg     Config
+		wantErr bool
+	}{
+		{Config{}, false},
+		{Config{PreviewFormat: PreviewJPEG}, false},
+		{Config{PreviewFormat: PreviewWebP}, true},
+		{Config{PreviewFormat: PreviewWebP, PreviewEncoder: &fakeEncoder{}}, false},
+		{Config{PreviewFormat: "image/bmp", PreviewEncoder: &fakeEncoder{}}, true},
+	} {
+		err := tc.cfg.Validate()
+		if got := err != nil && strings.Contains(err.Error(), "PreviewFormat"); got != tc.wantErr {
+			t.Errorf("Validate(PreviewFormat %q) = %v, want PreviewFormat error %v", tc.cfg.PreviewFormat, err, tc.wantErr)
+		}
+	}
+}
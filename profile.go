@@ -0,0 +1,66 @@
+package imagefy
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Profile tailors the requests sent to one image host, for CDNs that reject
+// or degrade generic clients (e.g. require a Referer, or block unknown user
+// agents). See Config.HostProfiles.
+type Profile struct {
+	UserAgent  string            // replaces Config.UserAgent ("" = keep it)
+	Headers    map[string]string // extra request headers, e.g. "Referer"
+	UseStealth bool              // send probes and downloads through Config.StealthClient, as for StealthHosts
+}
+
+// hostProfile returns the Config.HostProfiles entry for the host of rawURL
+// or, failing that, for its closest parent domain.
+func (cfg *Config) hostProfile(rawURL string) (Profile, bool) {
+	if len(cfg.HostProfiles) == 0 {
+		return Profile{}, false
+	}
+	host := urlHostname(rawURL)
+	for host != "" {
+		if p, ok := cfg.HostProfiles[host]; ok {
+			return p, true
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+	return Profile{}, false
+}
+
+// requestHeader returns the headers of an image request to rawURL: the
+// host profile's Headers and a User-Agent of ua, else the profile's, else
+// Config.UserAgent.
+func (cfg *Config) requestHeader(rawURL, ua string) http.Header {
+	h := make(http.Header)
+	p, _ := cfg.hostProfile(rawURL)
+	for k, v := range p.Headers {
+		h.Set(k, v)
+	}
+	switch {
+	case ua != "":
+	case p.UserAgent != "":
+		ua = p.UserAgent
+	default:
+		ua = cfg.UserAgent
+	}
+	h.Set("User-Agent", ua)
+	return h
+}
+
+// urlHostname returns the normalized host of rawURL without its port, or
+// "" if it does not parse.
+func urlHostname(rawURL string) string {
+	host := extractHost(rawURL)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host
+}
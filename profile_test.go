@@ -0,0 +1,105 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestHostProfile(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{HostProfiles: map[string]Profile{
+		"yandex.net":      {UserAgent: "parent"},
+		"mds.example.net": {UserAgent: "exact"},
+	}}
+	tests := []struct {
+		url, want string
+	}{
+		{"https://avatars.mds.yandex.net/i?id=1", "parent"},
+		{"https://YANDEX.NET:443/img.jpg", "parent"},
+		{"https://mds.example.net/img.jpg", "exact"},
+		{"https://cdn.mds.example.net/img.jpg", "exact"},
+		{"https://example.net/img.jpg", ""},
+		{"https://notyandex.net/img.jpg", ""},
+	}
+	for _, tt := range tests {
+		p, ok := cfg.hostProfile(tt.url)
+		if p.UserAgent != tt.want || ok != (tt.want != "") {
+			t.Errorf("hostProfile(%q) = %q, %v; want %q", tt.url, p.UserAgent, ok, tt.want)
+		}
+	}
+}
+
+func TestHostProfileHeaders(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu   sync.Mutex
+		seen []http.Header
+	)
+	body := makeJPEG(1000, 600)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Clone())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &Config{
+		UserAgent: "global",
+		HostProfiles: map[string]Profile{"127.0.0.1": {
+			UserAgent: "picky-cdn",
+			Headers:   map[string]string{"Referer": "https://example.com/"},
+		}},
+	}
+	ctx := context.Background()
+	if !cfg.ValidateImageURL(ctx, srv.URL+"/a.jpg") {
+		t.Fatal("ValidateImageURL = false")
+	}
+	if r, _ := cfg.Download(ctx, srv.URL+"/a.jpg", DownloadOpts{}); r == nil {
+		t.Fatal("Download = nil")
+	}
+	if r, _ := cfg.Download(ctx, srv.URL+"/a.jpg", DownloadOpts{UserAgent: "per-request"}); r == nil {
+		t.Fatal("Download with UserAgent = nil")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	wantUA := []string{"picky-cdn", "picky-cdn", "per-request"}
+	if len(seen) != len(wantUA) {
+		t.Fatalf("got %d requests, want %d", len(seen), len(wantUA))
+	}
+	for i, h := range seen {
+		if h.Get("User-Agent") != wantUA[i] || h.Get("Referer") != "https://example.com/" {
+			t.Errorf("request %d: User-Agent %q, Referer %q", i, h.Get("User-Agent"), h.Get("Referer"))
+		}
+	}
+}
+
+func TestHostProfileUseStealth(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(100, 100))
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+	cfg := &Config{
+		HTTPClient:    &http.Client{Transport: recordingTransport{name: "direct", mu: &mu, log: &log}},
+		StealthClient: &http.Client{Transport: recordingTransport{name: "stealth", mu: &mu, log: &log}},
+		HostProfiles:  map[string]Profile{"127.0.0.1": {UseStealth: true}},
+	}
+	if r, _ := cfg.Download(context.Background(), srv.URL+"/a.jpg", DownloadOpts{}); r == nil {
+		t.Fatal("Download = nil")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(log) != 1 || log[0] != "stealth" {
+		t.Errorf("clients used = %v, want [stealth]", log)
+	}
+}
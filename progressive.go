@@ -0,0 +1,73 @@
+package imagefy
+
+import (
+	"context"
+	"log/slog"
+)
+
+// progressiveMaxBytes caps the re-download of a truncated progressive JPEG.
+// A progressive file cut at the usual preview cap holds only its first
+// coarse scans, which decode to a blurry gray wash.
+const progressiveMaxBytes = 2 << 20 // 2MB
+
+// completeProgressive replaces r when it is a progressive JPEG cut at
+// opts.MaxBytes: the image is fetched again with up to progressiveMaxBytes,
+// and if it is still incomplete, thumbnail (when set) is fetched instead and
+// used for hashing, metadata, and classification. r is returned unchanged
+// when it is complete, baseline, or no replacement could be downloaded.
+func (cfg *Config) completeProgressive(ctx context.Context, r *DownloadResult, imgURL, thumbnail string, opts DownloadOpts) *DownloadResult {
+	if r == nil || !r.Truncated || !isProgressiveJPEG(r.Data) {
+		return r
+	}
+	slog.Debug("imagefy: truncated progressive jpeg", "url", imgURL, "bytes", len(r.Data))
+
+	if opts.MaxBytes < progressiveMaxBytes {
+		full := opts
+		full.MaxBytes = progressiveMaxBytes
+		if f, _ := cfg.Download(ctx, imgURL, full); f != nil && !f.Truncated {
+			return f
+		}
+	}
+	if thumbnail != "" && thumbnail != imgURL {
+		if t, _ := cfg.Download(ctx, thumbnail, opts); t != nil && !(t.Truncated && isProgressiveJPEG(t.Data)) {
+			slog.Debug("imagefy: using thumbnail for truncated progressive jpeg", "url", imgURL, "thumbnail", thumbnail)
+			return t
+		}
+	}
+	return r
+}
+
+// isProgressiveJPEG reports whether data is a JPEG whose frame header
+// (SOF2, SOF6, SOF10, or SOF14) declares progressive encoding. It walks the
+// marker segments up to the first frame header, so a truncated file is
+// recognized as long as its headers survived.
+func isProgressiveJPEG(data []byte) bool {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return false
+	}
+	for i := 2; i+1 < len(data); {
+		if data[i] != 0xFF {
+			return false
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF: // fill byte
+			i++
+			continue
+		case marker == 0xC2 || marker == 0xC6 || marker == 0xCA || marker == 0xCE:
+			return true
+		case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			return false // baseline or sequential frame
+		case marker == 0xDA || marker == 0xD9: // scan or end of image before any frame
+			return false
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // standalone markers
+			i += 2
+			continue
+		}
+		if i+3 >= len(data) {
+			return false
+		}
+		i += 2 + (int(data[i+2])<<8 | int(data[i+3]))
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// fakeJPEG returns size bytes starting with SOI, an APP0 segment, and a frame
+// header with the given SOF marker, padded with scan-like filler. It is not
+// decodable past the headers, like a large file cut mid-scan.
+func fakeJPEG(sof byte, size int) []byte {
+	b := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x04, 'J', 'F', 0xFF, sof, 0x00, 0x0B, 8, 0x01, 0x00, 0x01, 0x00, 1, 1, 0x11, 0}
+	return append(b, bytes.Repeat([]byte{0x5A}, size-len(b))...)
+}
+
+func TestIsProgressiveJPEG(t *testing.T) {
+	t.Parallel()
+
+	baseline := makeJPEG(16, 16)
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"baseline", baseline, false},
+		{"progressive", fakeJPEG(0xC2, 64), true},
+		{"progressive headers only", fakeJPEG(0xC2, 64)[:12], true},
+		{"sequential frame", fakeJPEG(0xC0, 64), false},
+		{"cut inside APP segment", baseline[:5], false},
+		{"png", []byte("\x89PNG\r\n\x1a\n"), false},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		if got := isProgressiveJPEG(tt.data); got != tt.want {
+			t.Errorf("%s: isProgressiveJPEG = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDownload_ReportsTruncation(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultiImageServer(t, map[string][]byte{
+		"/exact.jpg": bytes.Repeat([]byte{1}, 1000),
+		"/big.jpg":   bytes.Repeat([]byte{1}, 1001),
+	})
+	cfg := &Config{}
+	for path, want := range map[string]bool{"/exact.jpg": false, "/big.jpg": true} {
+		r, err := cfg.Download(context.Background(), srv.URL+path, DownloadOpts{MaxBytes: 1000})
+		if err != nil || r == nil {
+			t.Fatalf("%s: Download = %v, %v", path, r, err)
+		}
+		if r.Truncated != want || len(r.Data) != 1000 {
+			t.Errorf("%s: Truncated = %v with %d bytes, want %v with 1000", path, r.Truncated, len(r.Data), want)
+		}
+	}
+}
+
+// countingImageServer serves bodies by path and counts requests per path.
+func countingImageServer(t *testing.T, bodies map[string][]byte) (*httptest.Server, func(string) int) {
+	t.Helper()
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits[path]
+	}
+}
+
+func TestDownloadForValidation_TruncatedProgressive(t *testing.T) {
+	t.Parallel()
+
+	thumb := makeJPEG(320, 200)
+	large := fakeJPEG(0xC2, defaultMaxBytes+5000)
+	srv, hits := countingImageServer(t, map[string][]byte{
+		"/large.jpg":    large,
+		"/huge.jpg":     fakeJPEG(0xC2, progressiveMaxBytes+1),
+		"/baseline.jpg": fakeJPEG(0xC0, defaultMaxBytes+5000),
+		"/thumb.jpg":    thumb,
+	})
+	cfg := &Config{}
+	cfg.defaults()
+	ctx := context.Background()
+
+	// Re-fetched whole when it fits under progressiveMaxBytes.
+	data, _, _, _ := cfg.downloadForValidation(ctx, srv.URL+"/large.jpg", srv.URL+"/thumb.jpg", "")
+	if !bytes.Equal(data, large) {
+		t.Errorf("large progressive: got %d bytes, want the complete %d", len(data), len(large))
+	}
+	if n := hits("/thumb.jpg"); n != 0 {
+		t.Errorf("thumbnail fetched %d times for a completed download", n)
+	}
+
+	// Too big even then: the thumbnail stands in.
+	data, _, img, _ := cfg.downloadForValidation(ctx, srv.URL+"/huge.jpg", srv.URL+"/thumb.jpg", "")
+	if !bytes.Equal(data, thumb) || img == nil || img.Bounds().Dx() != 320 {
+		t.Errorf("huge progressive: got %d bytes (img %v), want the decoded thumbnail", len(data), img != nil)
+	}
+
+	// Without a thumbnail, the truncated bytes are kept.
+	data, _, _, _ = cfg.downloadForValidation(ctx, srv.URL+"/huge.jpg", "", "")
+	if len(data) != defaultMaxBytes {
+		t.Errorf("huge progressive without thumbnail: got %d bytes, want the truncated %d", len(data), defaultMaxBytes)
+	}
+
+	// Truncated baseline JPEGs still show the top of the image: no re-fetch.
+	_, _, _, _ = cfg.downloadForValidation(ctx, srv.URL+"/baseline.jpg", srv.URL+"/thumb.jpg", "")
+	if n := hits("/baseline.jpg"); n != 1 {
+		t.Errorf("baseline fetched %d times, want 1", n)
+	}
+}
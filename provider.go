@@ -1,12 +1,15 @@
 package imagefy
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -26,6 +29,17 @@ type SearXNGProvider struct {
 	URL        string       // SearXNG base URL (required)
 	HTTPClient *http.Client // optional (nil = http.DefaultClient)
 	UserAgent  string       // optional
+
+	// AcceptLanguage is sent as the Accept-Language header (e.g.
+	// "ru-RU,ru;q=0.9,en;q=0.5"); engines that honour it return local
+	// results for venues and places. Empty = SearchOpts.Language when set.
+	AcceptLanguage string
+
+	// ForwardedFor, when set, is sent as X-Forwarded-For: a client IP in the
+	// target region for instances and engines that localize results by the
+	// forwarded address rather than the language (e.g. one from the city
+	// being searched). Empty = no header.
+	ForwardedFor string
 }
 
 // Name returns the provider name.
@@ -38,17 +52,45 @@ func (p *SearXNGProvider) Search(ctx context.Context, query string, opts SearchO
 	if err != nil {
 		return nil, err
 	}
-	return p.filter(results), nil
+	if !opts.KeepRaw {
+		for i := range results {
+			results[i].Raw = nil
+		}
+	}
+	return p.filter(results, blockPolicyFrom(ctx)), nil
 }
 
-// searxngResult is the JSON shape of a single SearXNG image result.
+// searxngResult is a single SearXNG image result. The JSON tags are the
+// current field names; decodeSearxngResults also reads older and newer
+// alternatives.
 type searxngResult struct {
-	ImgSrc    string `json:"img_src"`
-	Thumbnail string `json:"thumbnail_src"`
-	URL       string `json:"url"`
-	Title     string `json:"title"`
+	ImgSrc    string          `json:"img_src"`
+	Thumbnail string          `json:"thumbnail_src"`
+	URL       string          `json:"url"`
+	Title     string          `json:"title"`
+	Engine    string          `json:"engine"`
+	Width     int             `json:"-"` // from "resolution" or "img_format" ("1920 x 1080"), 0 if absent
+	Height    int             `json:"-"`
+	Raw       json.RawMessage `json:"-"` // the result as received
 }
 
+// searxngFields lists, per searxngResult field, the JSON keys SearXNG
+// releases have used for it, preferred first.
+var searxngFields = struct {
+	imgSrc, thumbnail, url, title, engine, size []string
+}{
+	imgSrc:    []string{"img_src", "image_url", "img_url"},
+	thumbnail: []string{"thumbnail_src", "thumbnail"},
+	url:       []string{"url", "page_url"},
+	title:     []string{"title"},
+	engine:    []string{"engine", "engines"},
+	size:      []string{"resolution", "img_format"},
+}
+
+// searxngSize matches image dimensions such as "1920 x 1080" or
+// "jpeg 1920×1080".
+var searxngSize = regexp.MustCompile(`(\d{1,5})\s*[x×X]\s*(\d{1,5})`)
+
 func (p *SearXNGProvider) fetch(ctx context.Context, query string, opts SearchOpts) ([]searxngResult, error) {
 	searchURL := p.buildURL(query, opts)
 
@@ -56,7 +98,7 @@ func (p *SearXNGProvider) fetch(ctx context.Context, query string, opts SearchOp
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Accept", "application/json")
+	p.setHeaders(req.Header, opts)
 
 	client := p.HTTPClient
 	if client == nil {
@@ -74,14 +116,107 @@ func (p *SearXNGProvider) fetch(ctx context.Context, query string, opts SearchOp
 		return nil, err
 	}
 
+	return decodeSearxngResults(body)
+}
+
+// setHeaders sets the Accept, User-Agent, Accept-Language, and
+// X-Forwarded-For headers of a SearXNG request.
+func (p *SearXNGProvider) setHeaders(h http.Header, opts SearchOpts) {
+	h.Set("Accept", "application/json")
+	if p.UserAgent != "" {
+		h.Set("User-Agent", p.UserAgent)
+	}
+	if lang := cmp.Or(p.AcceptLanguage, opts.Language); lang != "" {
+		h.Set("Accept-Language", lang)
+	}
+	if p.ForwardedFor != "" {
+		h.Set("X-Forwarded-For", p.ForwardedFor)
+	}
+}
+
+// decodeSearxngResults decodes the "results" array of a SearXNG JSON
+// response, tolerating schema drift across SearXNG releases: alternative
+// field names (see searxngFields), numbers and strings in place of each
+// other, an "engines" array instead of "engine", and missing fields. A
+// result that is not an object, or whose fields have unusable types, is
+// decoded as far as possible instead of failing the whole response.
+func decodeSearxngResults(body []byte) ([]searxngResult, error) {
 	var searchResp struct {
-		Results []searxngResult `json:"results"`
+		Results []json.RawMessage `json:"results"`
 	}
 	if err := json.Unmarshal(body, &searchResp); err != nil {
 		return nil, err
 	}
 
-	return searchResp.Results, nil
+	results := make([]searxngResult, 0, len(searchResp.Results))
+	for _, raw := range searchResp.Results {
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(raw, &fields) != nil || fields == nil {
+			continue
+		}
+		f := searxngFields
+		r := searxngResult{
+			ImgSrc:    jsonField(fields, f.imgSrc),
+			Thumbnail: jsonField(fields, f.thumbnail),
+			URL:       jsonField(fields, f.url),
+			Title:     jsonField(fields, f.title),
+			Engine:    jsonField(fields, f.engine),
+			Raw:       raw,
+		}
+		if m := searxngSize.FindStringSubmatch(jsonField(fields, f.size)); m != nil {
+			r.Width, _ = strconv.Atoi(m[1])
+			r.Height, _ = strconv.Atoi(m[2])
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// rawElements returns the elements of the array under key in the JSON
+// object body, or nil if there is none. Providers that decode typed results
+// use it to attach each result's JSON with SearchOpts.KeepRaw.
+func rawElements(body []byte, key string) []json.RawMessage {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(body, &obj) != nil {
+		return nil
+	}
+	var items []json.RawMessage
+	if json.Unmarshal(obj[key], &items) != nil {
+		return nil
+	}
+	return items
+}
+
+// jsonField returns the first non-empty value among keys of fields as a
+// string: strings as-is, numbers in their JSON form, and the first element
+// of an array. Other types count as missing.
+func jsonField(fields map[string]json.RawMessage, keys []string) string {
+	for _, k := range keys {
+		if s := jsonScalar(fields[k]); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func jsonScalar(raw json.RawMessage) string {
+	var v any
+	if len(raw) == 0 || json.Unmarshal(raw, &v) != nil {
+		return ""
+	}
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strings.TrimSpace(string(raw))
+	case []any:
+		if len(v) > 0 {
+			if first, err := json.Marshal(v[0]); err == nil {
+				return jsonScalar(first)
+			}
+		}
+	}
+	return ""
 }
 
 func (p *SearXNGProvider) buildURL(query string, opts SearchOpts) string {
@@ -91,13 +226,16 @@ func (p *SearXNGProvider) buildURL(query string, opts SearchOpts) string {
 	if opts.PageNumber > 1 {
 		searchURL += fmt.Sprintf("&pageno=%d", opts.PageNumber)
 	}
+	if opts.Language != "" {
+		searchURL += "&language=" + url.QueryEscape(opts.Language)
+	}
 	if len(opts.Engines) > 0 {
 		searchURL += "&engines=" + url.QueryEscape(strings.Join(opts.Engines, ","))
 	}
 	return searchURL
 }
 
-func (p *SearXNGProvider) filter(results []searxngResult) []ImageCandidate {
+func (p *SearXNGProvider) filter(results []searxngResult, policy blockPolicy) []ImageCandidate {
 	var candidates []ImageCandidate
 	for _, r := range results {
 		if r.ImgSrc == "" {
@@ -107,7 +245,7 @@ func (p *SearXNGProvider) filter(results []searxngResult) []ImageCandidate {
 			continue
 		}
 
-		license := CheckLicense(r.ImgSrc, r.URL)
+		license := policy.checkLicense(r.ImgSrc, r.URL)
 		if license == LicenseBlocked {
 			continue
 		}
@@ -118,6 +256,10 @@ func (p *SearXNGProvider) filter(results []searxngResult) []ImageCandidate {
 			Source:    r.URL,
 			Title:     r.Title,
 			License:   license,
+			Engine:    r.Engine,
+			Width:     r.Width,
+			Height:    r.Height,
+			raw:       string(r.Raw),
 		})
 	}
 	return candidates
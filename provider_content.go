@@ -105,7 +105,7 @@ func (p *ContentImageProvider) Search(ctx context.Context, query string, opts Se
 		if IsLogoOrBanner(strings.ToLower(clean)) {
 			return
 		}
-		license := CheckLicense(clean, opts.PageURL)
+		license := blockPolicyFrom(ctx).checkLicense(clean, opts.PageURL)
 		if license == LicenseBlocked {
 			return
 		}
@@ -27,7 +27,7 @@ type DDGImageProvider struct {
 func (p *DDGImageProvider) Name() string { return "ddg" }
 
 // Search queries DuckDuckGo Images and returns filtered candidates.
-func (p *DDGImageProvider) Search(ctx context.Context, query string, _ SearchOpts) ([]ImageCandidate, error) {
+func (p *DDGImageProvider) Search(ctx context.Context, query string, opts SearchOpts) ([]ImageCandidate, error) {
 	token, err := p.fetchToken(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("ddg token: %w", err)
@@ -38,7 +38,7 @@ func (p *DDGImageProvider) Search(ctx context.Context, query string, _ SearchOpt
 		return nil, fmt.Errorf("ddg images: %w", err)
 	}
 
-	return p.filter(results), nil
+	return p.filter(results, blockPolicyFrom(ctx)), nil
 }
 
 // ddgImageResult is a single result from the DDG image API.
@@ -104,7 +104,7 @@ func (p *DDGImageProvider) get(ctx context.Context, rawURL string) ([]byte, erro
 
 	client := p.HTTPClient
 	if client == nil {
-		client = http.DefaultClient
+		client = defaultHTTPClient
 	}
 
 	resp, err := client.Do(req)
@@ -120,7 +120,7 @@ func (p *DDGImageProvider) get(ctx context.Context, rawURL string) ([]byte, erro
 	return io.ReadAll(io.LimitReader(resp.Body, ddgBodyLimit))
 }
 
-func (p *DDGImageProvider) filter(results []ddgImageResult) []ImageCandidate {
+func (p *DDGImageProvider) filter(results []ddgImageResult, policy blockPolicy) []ImageCandidate {
 	var candidates []ImageCandidate
 	for _, r := range results {
 		if r.Image == "" {
@@ -130,7 +130,7 @@ func (p *DDGImageProvider) filter(results []ddgImageResult) []ImageCandidate {
 			continue
 		}
 
-		license := CheckLicense(r.Image, r.URL)
+		license := policy.checkLicense(r.Image, r.URL)
 		if license == LicenseBlocked {
 			continue
 		}
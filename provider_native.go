@@ -23,7 +23,7 @@ func NewNativeImageProvider(ms *imagesearch.MultiSearch) *NativeImageProvider {
 func (p *NativeImageProvider) Name() string { return "native" }
 
 // Search queries all native engines, converts results, and applies license + logo filters.
-func (p *NativeImageProvider) Search(ctx context.Context, query string, _ SearchOpts) ([]ImageCandidate, error) {
+func (p *NativeImageProvider) Search(ctx context.Context, query string, opts SearchOpts) ([]ImageCandidate, error) {
 	results := p.search.Search(ctx, query, nativeMaxResults)
 
 	candidates := make([]ImageCandidate, 0, len(results))
@@ -31,7 +31,7 @@ func (p *NativeImageProvider) Search(ctx context.Context, query string, _ Search
 		if IsLogoOrBanner(strings.ToLower(r.URL)) {
 			continue
 		}
-		license := CheckLicense(r.URL, r.Source)
+		license := blockPolicyFrom(ctx).checkLicense(r.URL, r.Source)
 		if license == LicenseBlocked {
 			continue
 		}
@@ -38,7 +38,7 @@ func (p *OGImageProvider) Search(ctx context.Context, _ string, opts SearchOpts)
 		return nil, nil
 	}
 
-	license := CheckLicense(imgURL, opts.PageURL)
+	license := blockPolicyFrom(ctx).checkLicense(imgURL, opts.PageURL)
 	if license == LicenseBlocked {
 		return nil, nil
 	}
@@ -63,7 +63,7 @@ func (p *OGImageProvider) fetchOG(ctx context.Context, pageURL string) string {
 
 	client := p.HTTPClient
 	if client == nil {
-		client = http.DefaultClient
+		client = defaultHTTPClient
 	}
 
 	resp, err := client.Do(req) //nolint:gosec // G107: URL is caller-supplied
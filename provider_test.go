@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -323,3 +327,174 @@ func TestSearXNGProviderNilHTTPClientUsesDefault(t *testing.T) {
 		t.Error("expected a connection error with nothing listening on port 1, got nil")
 	}
 }
+
+// TestSearXNGProviderSearch_Headers verifies that the locale and geo headers
+// reach the SearXNG instance.
+func TestSearXNGProviderSearch_Headers(t *testing.T) {
+	t.Parallel()
+
+	headers := make(chan http.Header, 3)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buildSearxngJSON(nil))
+	}))
+	t.Cleanup(srv.Close)
+
+	p := &SearXNGProvider{
+		URL:            srv.URL,
+		HTTPClient:     srv.Client(),
+		UserAgent:      "test-agent",
+		AcceptLanguage: "ru-RU,ru;q=0.9",
+		ForwardedFor:   "95.165.0.1",
+	}
+	if _, err := p.Search(context.Background(), "park", SearchOpts{Language: "en"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	h := <-headers
+	for name, want := range map[string]string{
+		"Accept":          "application/json",
+		"User-Agent":      "test-agent",
+		"Accept-Language": "ru-RU,ru;q=0.9",
+		"X-Forwarded-For": "95.165.0.1",
+	} {
+		if got := h.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	bare := &SearXNGProvider{URL: srv.URL, HTTPClient: srv.Client()}
+	if _, err := bare.Search(context.Background(), "park", SearchOpts{Language: "de"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	h = <-headers
+	if got := h.Get("Accept-Language"); got != "de" {
+		t.Errorf("Accept-Language from SearchOpts.Language = %q, want de", got)
+	}
+	if got := h.Get("X-Forwarded-For"); got != "" {
+		t.Errorf("X-Forwarded-For = %q, want none", got)
+	}
+
+	if _, err := bare.Search(context.Background(), "park", SearchOpts{}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if got := (<-headers).Get("Accept-Language"); got != "" {
+		t.Errorf("Accept-Language without a language = %q, want none", got)
+	}
+}
+
+// TestDecodeSearxngResults_Releases decodes fixture responses of two SearXNG
+// releases whose image result schemas differ (thumbnail_src / img_format in
+// 2023, thumbnail / resolution and strings for numbers in 2025).
+func TestDecodeSearxngResults_Releases(t *testing.T) {
+	t.Parallel()
+
+	const (
+		wikiImg   = "https://upload.wikimedia.org/wikipedia/commons/a/a1/Kazan_Cathedral.jpg"
+		wikiThumb = "https://upload.wikimedia.org/wikipedia/commons/thumb/a/a1/Kazan_Cathedral.jpg/320px-Kazan_Cathedral.jpg"
+	)
+	want := map[string][]searxngResult{
+		"2023.06.json": {
+			{ImgSrc: wikiImg, Thumbnail: wikiThumb, URL: "https://commons.wikimedia.org/wiki/File:Kazan_Cathedral.jpg", Title: "Kazan Cathedral", Engine: "wikicommons.images", Width: 4000, Height: 2667},
+			{ImgSrc: "https://travel.example.com/img/kazan-night.jpg", URL: "https://travel.example.com/spb/kazan-cathedral", Title: "Kazan Cathedral at night", Engine: "bing images"},
+			{URL: "https://example.org/page", Title: "No image source"},
+		},
+		"2025.01.json": {
+			{ImgSrc: wikiImg, Thumbnail: wikiThumb, URL: "https://commons.wikimedia.org/wiki/File:Kazan_Cathedral.jpg", Title: "Kazan Cathedral", Engine: "wikicommons.images", Width: 4000, Height: 2667},
+			{ImgSrc: "https://travel.example.com/img/kazan-night.jpg", URL: "https://travel.example.com/spb/kazan-cathedral", Title: "1811", Engine: "bing images"},
+			{Thumbnail: "https://example.org/thumb.jpg", Title: "No image source"},
+		},
+	}
+	for name, w := range want {
+		body, err := os.ReadFile(filepath.Join("testdata", "searxng", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeSearxngResults(body)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for i := range got {
+			if !json.Valid(got[i].Raw) {
+				t.Errorf("%s: result %d: Raw = %q, want the result JSON", name, i, got[i].Raw)
+			}
+			got[i].Raw = nil
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("%s:\n got %+v\nwant %+v", name, got, w)
+		}
+
+		candidates := (&SearXNGProvider{}).filter(got, nil)
+		if len(candidates) != 2 || candidates[0].Width != 4000 || candidates[0].Thumbnail != wikiThumb {
+			t.Errorf("%s: filter = %+v", name, candidates)
+		}
+	}
+}
+
+// TestDecodeSearxngResults_Malformed verifies that only an unparsable
+// response is an error.
+func TestDecodeSearxngResults_Malformed(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{`{}`, `{"results":null}`, `{"results":[1,"x",null,[]]}`} {
+		got, err := decodeSearxngResults([]byte(body))
+		if err != nil || len(got) != 0 {
+			t.Errorf("%s: got %v, %v; want no results, no error", body, got, err)
+		}
+	}
+	for _, body := range []string{`[]`, `{"results":{}}`, `{`} {
+		if _, err := decodeSearxngResults([]byte(body)); err == nil {
+			t.Errorf("%s: want error", body)
+		}
+	}
+}
+
+// TestSearXNGProvider_KeepRaw verifies that SearchOpts.KeepRaw carries each
+// result's JSON to the candidate's events and audit records, and only then.
+func TestSearXNGProvider_KeepRaw(t *testing.T) {
+	t.Parallel()
+
+	const result = `{"img_src":"http://127.0.0.1:1/a.jpg","url":"http://127.0.0.1:1/","title":"A","engine":"bing images","extra":{"n":1}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[` + result + `]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	for _, keep := range []bool{true, false} {
+		var (
+			mu     sync.Mutex
+			events []CandidateEvent
+			buf    syncBuffer
+		)
+		audit := NewAuditLog(&buf)
+		cfg := &Config{
+			Providers: []SearchProvider{&SearXNGProvider{URL: srv.URL, HTTPClient: srv.Client()}},
+			OnCandidateRejected: func(e CandidateEvent) {
+				mu.Lock()
+				events = append(events, e)
+				mu.Unlock()
+				audit.LogCandidate(e)
+			},
+		}
+		cfg.SearchImagesWithOpts(context.Background(), "a", 1, SearchOpts{KeepRaw: keep})
+
+		if len(events) != 1 {
+			t.Fatalf("KeepRaw=%v: got %d events, want 1", keep, len(events))
+		}
+		recs := decodeAudit(t, buf.b.Bytes())
+		if len(recs) != 1 {
+			t.Fatalf("KeepRaw=%v: got %d audit records, want 1", keep, len(recs))
+		}
+		want := ""
+		if keep {
+			want = result
+		}
+		if got := string(events[0].Raw); got != want {
+			t.Errorf("KeepRaw=%v: event Raw = %s, want %s", keep, got, want)
+		}
+		if got := string(recs[0].Raw); got != want {
+			t.Errorf("KeepRaw=%v: audit Raw = %s, want %s", keep, got, want)
+		}
+	}
+}
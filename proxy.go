@@ -0,0 +1,107 @@
+package imagefy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strings"
+)
+
+// ErrProxyTemplate is returned by ImageProxy.URL when Template has no image
+// URL placeholder.
+var ErrProxyTemplate = errors.New("imagefy: proxy template has no {url}, {url_hex}, or {url_b64} placeholder")
+
+// ErrProxyNoKey is returned by ImageProxy.URL when Template has {sig} but
+// neither Key nor Sign is set: an HMAC with an empty key is forgeable by
+// anyone.
+var ErrProxyNoKey = errors.New("imagefy: proxy template has {sig} but no Key or Sign")
+
+// ImageProxy rewrites image URLs through an image proxy (weserv, camo,
+// imgproxy, an internal resizer, ...), so end users never load third-party
+// hosts directly. Template is the proxy URL with placeholders:
+//
+//	{url}     the image URL, query-escaped
+//	{url_hex} the image URL, hex-encoded (camo)
+//	{url_b64} the image URL, unpadded base64url
+//	{sig}     the signature of the image URL (see Key and Sign)
+//
+// For example "https://images.weserv.nl/?url={url}&w=1200", or for camo
+// "https://camo.example.com/{sig}/{url_hex}" with Hash set to sha1.New.
+type ImageProxy struct {
+	Template string
+
+	// Key is the HMAC key of {sig}, which is the hex HMAC of the image URL.
+	// A template with {sig} requires Key or Sign.
+	Key []byte
+	// Hash is the HMAC hash function (default: sha256.New).
+	Hash func() hash.Hash
+	// Sign overrides the {sig} value, for proxies that sign something other
+	// than the plain image URL.
+	Sign func(imageURL string) string
+}
+
+// URL returns imageURL rewritten through the proxy. Only absolute http(s)
+// URLs are proxied; anything else is an error rather than passed through.
+func (p *ImageProxy) URL(imageURL string) (string, error) {
+	if !strings.Contains(p.Template, "{url") {
+		return "", ErrProxyTemplate
+	}
+	u, err := url.Parse(imageURL)
+	if err != nil || !isHTTPScheme(u.Scheme) || u.Host == "" {
+		return "", fmt.Errorf("imagefy: proxy: not an http(s) image URL: %.100q", imageURL)
+	}
+
+	pairs := []string{
+		"{url}", url.QueryEscape(imageURL),
+		"{url_hex}", hex.EncodeToString([]byte(imageURL)),
+		"{url_b64}", base64.RawURLEncoding.EncodeToString([]byte(imageURL)),
+	}
+	if strings.Contains(p.Template, "{sig}") {
+		if len(p.Key) == 0 && p.Sign == nil {
+			return "", ErrProxyNoKey
+		}
+		pairs = append(pairs, "{sig}", p.signature(imageURL))
+	}
+	return strings.NewReplacer(pairs...).Replace(p.Template), nil
+}
+
+// signature returns the {sig} value for imageURL.
+func (p *ImageProxy) signature(imageURL string) string {
+	if p.Sign != nil {
+		return p.Sign(imageURL)
+	}
+	newHash := p.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	mac := hmac.New(newHash, p.Key)
+	mac.Write([]byte(imageURL))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Rewrite returns copies of candidates with ImgURL and Thumbnail rewritten
+// through the proxy; Source still links to the original page. Call it on
+// accepted results just before serving them, since history and dedup key on
+// the original URLs. Candidates whose ImgURL cannot be proxied are dropped,
+// and a Thumbnail that cannot be proxied is cleared, so no third-party URL
+// leaks through.
+func (p *ImageProxy) Rewrite(candidates []ImageCandidate) []ImageCandidate {
+	out := make([]ImageCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		imgURL, err := p.URL(c.ImgURL)
+		if err != nil {
+			continue
+		}
+		c.ImgURL = imgURL
+		if c.Thumbnail != "" {
+			c.Thumbnail, _ = p.URL(c.Thumbnail)
+		}
+		out = append(out, c)
+	}
+	return out
+}
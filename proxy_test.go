@@ -0,0 +1,61 @@
+package imagefy
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // camo signs with HMAC-SHA1
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestImageProxyURL(t *testing.T) {
+	t.Parallel()
+
+	const img = "https://example.com/photos/a b.jpg?w=1"
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(img))
+	camoSig := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name  string
+		proxy ImageProxy
+		want  string
+	}{
+		{"weserv", ImageProxy{Template: "https://images.weserv.nl/?url={url}&w=1200"},
+			"https://images.weserv.nl/?url=https%3A%2F%2Fexample.com%2Fphotos%2Fa+b.jpg%3Fw%3D1&w=1200"},
+		{"camo", ImageProxy{Template: "https://camo.example.com/{sig}/{url_hex}", Key: []byte("secret"), Hash: sha1.New},
+			"https://camo.example.com/" + camoSig + "/" + hex.EncodeToString([]byte(img))},
+		{"custom sign", ImageProxy{Template: "/p/{sig}/{url_b64}", Sign: func(string) string { return "s" }},
+			"/p/s/aHR0cHM6Ly9leGFtcGxlLmNvbS9waG90b3MvYSBiLmpwZz93PTE"},
+	}
+	for _, tt := range tests {
+		got, err := tt.proxy.URL(img)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: URL = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := (&ImageProxy{Template: "https://proxy.example.com/"}).URL(img); !errors.Is(err, ErrProxyTemplate) {
+		t.Errorf("template without placeholder: error = %v, want ErrProxyTemplate", err)
+	}
+	if got, err := (&ImageProxy{Template: "https://camo.example.com/{sig}/{url_hex}"}).URL(img); !errors.Is(err, ErrProxyNoKey) || got != "" {
+		t.Errorf("{sig} without Key or Sign: URL = %q, %v; want \"\", ErrProxyNoKey", got, err)
+	}
+}
+
+func TestImageProxyRewrite(t *testing.T) {
+	t.Parallel()
+
+	p := &ImageProxy{Template: "https://proxy.example.com/?u={url}"}
+	got := p.Rewrite([]ImageCandidate{
+		{ImgURL: "https://a.example/1.jpg", Thumbnail: "data:image/png;base64,AAAA", Source: "https://a.example/page"},
+		{ImgURL: "javascript:alert(1)"},
+	})
+	if len(got) != 1 {
+		t.Fatalf("got %d candidates, want the unproxyable one dropped", len(got))
+	}
+	c := got[0]
+	if c.ImgURL != "https://proxy.example.com/?u=https%3A%2F%2Fa.example%2F1.jpg" || c.Thumbnail != "" || c.Source != "https://a.example/page" {
+		t.Errorf("rewritten = %+v", c)
+	}
+}
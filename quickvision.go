@@ -0,0 +1,136 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// DefaultQuickVisionPrompt is the first-pass prompt of Config.QuickVision.
+const DefaultQuickVisionPrompt = `Is this image an original real-world photograph? Answer NO for stock photos with a watermark, illustrations, 3D renders, screenshots, maps, logos, banners, and placeholders.
+
+Reply with YES or NO and your confidence from 0 to 1, e.g. "YES 0.9".
+
+Answer:`
+
+// Quick vision defaults.
+const (
+	defaultQuickVisionConfidence = 0.85
+	quickPreviewSide             = 384 // longest side of the first-pass preview, in pixels
+	quickPreviewQuality          = 70
+)
+
+// quickClassify runs the first pass of Config.QuickVision on a low-res
+// preview of data. It returns the verdict and true when the model answered
+// with enough confidence; otherwise (unparsable or uncertain answer,
+// undecodable data, Classifier error) false, and the full prompt decides.
+func (cfg *Config) quickClassify(ctx context.Context, imageURL string, data []byte) (ClassificationResult, bool) {
+	if !cfg.QuickVision || len(cfg.requireLabels) > 0 || len(data) == 0 || cfg.classifiesByURL(imageURL) {
+		return ClassificationResult{}, false
+	}
+	preview, ok := lowResPreview(data)
+	if !ok {
+		return ClassificationResult{}, false
+	}
+	prompt := withLanguageHint(DefaultQuickVisionPrompt, cfg.language)
+	resp, err := cfg.callClassifier(ctx, prompt, []ImageInput{newImageInput(preview, "image/jpeg")})
+	if err != nil {
+		slog.Debug("imagefy: quick vision error", "url", imageURL, "error", err.Error())
+		return ClassificationResult{}, false
+	}
+	yes, confidence, ok := parseQuickAnswer(resp)
+	minConfidence := cfg.QuickVisionConfidence
+	if minConfidence <= 0 {
+		minConfidence = defaultQuickVisionConfidence
+	}
+	if !ok || confidence < minConfidence {
+		slog.Debug("imagefy: quick vision uncertain", "url", imageURL, "response", resp)
+		return ClassificationResult{}, false
+	}
+	if yes {
+		return ClassificationResult{Class: ClassPhoto, Confidence: confidence, Reason: "quick check: original photograph"}, true
+	}
+	return ClassificationResult{Class: ClassReject, Confidence: confidence, Reason: "quick check: not an original photograph"}, true
+}
+
+// parseQuickAnswer parses a "YES 0.9" / "NO 0.8" answer, on the first line
+// or one of the last few. A bare YES or NO parses with confidence 0.
+func parseQuickAnswer(resp string) (yes bool, confidence float64, ok bool) {
+	lines := strings.Split(stripReasoning(resp), "\n")
+	for i := len(lines) - 1; i >= 0 && i >= len(lines)-answerScanLines; i-- {
+		if yes, confidence, ok = parseQuickLine(lines[i]); ok {
+			return yes, confidence, true
+		}
+	}
+	return parseQuickLine(lines[0])
+}
+
+func parseQuickLine(line string) (yes bool, confidence float64, ok bool) {
+	fields := strings.Fields(strings.Trim(asciiUpper(trimAnswerDecoration(line)), ".!"))
+	if len(fields) == 0 {
+		return false, 0, false
+	}
+	switch strings.Trim(fields[0], ".,:;!") {
+	case "YES":
+		yes = true
+	case "NO":
+	default:
+		return false, 0, false
+	}
+	if len(fields) > 1 {
+		if c, err := strconv.ParseFloat(strings.Trim(fields[1], "(),;"), 64); err == nil && c >= 0 && c <= 1 {
+			confidence = c
+		}
+	}
+	return yes, confidence, true
+}
+
+// lowResPreview returns data decoded, shrunk to quickPreviewSide on its
+// longest side, flattened onto white, and encoded as JPEG.
+func lowResPreview(data []byte) ([]byte, bool) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, shrink(src, quickPreviewSide), &jpeg.Options{Quality: quickPreviewQuality}); err != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+// shrink returns src box-filtered down so its longest side is at most side
+// pixels, composited onto white.
+func shrink(src image.Image, side int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := sw, sh
+	if longest := max(sw, sh); longest > side {
+		dw, dh = max(sw*side/longest, 1), max(sh*side/longest, 1)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := range dw {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.RGBAModel.Convert(src.At(b.Min.X+sx, b.Min.Y+sy)).(color.RGBA)
+					white := 0xff - uint32(c.A) // premultiplied: add white behind transparency
+					r += uint32(c.R) + white
+					g += uint32(c.G) + white
+					bl += uint32(c.B) + white
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(bl / n), 0xff})
+		}
+	}
+	return dst
+}
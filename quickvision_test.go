@@ -0,0 +1,104 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// twoPassClassifier answers the quick prompt with quick and any other
+// prompt with full, recording the images of each call.
+type twoPassClassifier struct {
+	quick, full string
+
+	mu    sync.Mutex
+	calls []string // "quick" or "full"
+	sizes []int    // bytes of the first image of each call
+}
+
+func (c *twoPassClassifier) Classify(_ context.Context, prompt string, images []ImageInput) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sizes = append(c.sizes, len(images[0].Data))
+	if strings.Contains(prompt, "YES or NO") {
+		c.calls = append(c.calls, "quick")
+		return c.quick, nil
+	}
+	c.calls = append(c.calls, "full")
+	return c.full, nil
+}
+
+func TestQuickVision(t *testing.T) {
+	t.Parallel()
+
+	data := encodeJPEG(t, makeCheckerImage(1600, 1200, 7))
+	tests := []struct {
+		name      string
+		quick     string
+		wantClass string
+		wantCalls string
+	}{
+		{"confident yes", "YES 0.95", ClassPhoto, "quick"},
+		{"confident no", "no 0.9", ClassReject, "quick"},
+		{"uncertain", "YES 0.6", ClassStock, "quick,full"},
+		{"bare answer", "YES", ClassStock, "quick,full"},
+		{"unparsable", "I think so", ClassStock, "quick,full"},
+		{"reasoning then answer", "<think>watermark?</think>\n**Answer:** NO 0.97", ClassReject, "quick"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cls := &twoPassClassifier{quick: tt.quick, full: "STOCK 0.9"}
+			cfg := &Config{Classifier: cls, QuickVision: true}
+			result, err := cfg.classifyPredownloaded(context.Background(), "https://example.com/a.jpg", data, "image/jpeg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Class != tt.wantClass {
+				t.Errorf("class = %q, want %q", result.Class, tt.wantClass)
+			}
+			if got := strings.Join(cls.calls, ","); got != tt.wantCalls {
+				t.Errorf("calls = %s, want %s", got, tt.wantCalls)
+			}
+			if cls.sizes[0] >= len(data) {
+				t.Errorf("quick preview is %d bytes, want smaller than the %d-byte image", cls.sizes[0], len(data))
+			}
+		})
+	}
+}
+
+func TestQuickVision_SkippedForLabels(t *testing.T) {
+	t.Parallel()
+
+	cls := &twoPassClassifier{quick: "YES 0.99", full: "PHOTO,FOOD 0.9"}
+	cfg := (&Config{Classifier: cls, QuickVision: true}).withRequiredLabels([]string{"FOOD"})
+	if _, err := cfg.classifyPredownloaded(context.Background(), "https://example.com/a.jpg", makeJPEG(1000, 600), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cls.calls, ","); got != "full" {
+		t.Errorf("calls = %s, want full only", got)
+	}
+}
+
+func TestLowResPreview(t *testing.T) {
+	t.Parallel()
+
+	out, ok := lowResPreview(encodeJPEG(t, makeGradientImage(1600, 900, 0)))
+	if !ok {
+		t.Fatal("lowResPreview failed")
+	}
+	img, _, err := image.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != quickPreviewSide || b.Dy() != 216 {
+		t.Errorf("preview = %dx%d, want %dx216", b.Dx(), b.Dy(), quickPreviewSide)
+	}
+	if _, ok := lowResPreview([]byte("not an image")); ok {
+		t.Error("lowResPreview accepted non-image data")
+	}
+}
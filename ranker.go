@@ -0,0 +1,149 @@
+package imagefy
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// rankerPoolFactor is how many candidates a search with SearchOpts.Ranker
+	// validates per requested image, so the Ranker has something to reorder.
+	rankerPoolFactor = 3
+	// rankerMaxPool caps the validated pool regardless of maxResults.
+	rankerMaxPool = 30
+)
+
+// Ranker re-ranks validated candidates, e.g. with a click-through model.
+// It receives them in the order the pipeline considered them, with the
+// signals the pipeline gathered, and returns them best first. It may drop
+// candidates; entries whose ImgURL is not in its input are ignored.
+type Ranker func([]RankedCandidate) []RankedCandidate
+
+// RankedCandidate is a validated candidate as a Ranker sees it.
+type RankedCandidate struct {
+	Candidate ImageCandidate
+
+	// Relevance is the query-image embedding similarity, 0–1, when Embedded
+	// is set (a TextEmbedder scored the query); otherwise it is 0.
+	Relevance float64
+	Embedded  bool
+
+	// License is the pipeline's license verdict from domain and metadata
+	// signals (see AssessLicense), which may be stronger than the provider's
+	// Candidate.License.
+	License ImageLicense
+
+	// Class and Confidence are the vision verdict. Class is "" and
+	// Confidence 0 for candidates accepted without classification, e.g. safe
+	// licenses or once the vision budget is spent.
+	Class      string
+	Confidence float64
+}
+
+// verdict is what the pipeline concluded about a validated image, for the
+// Ranker.
+type verdict struct {
+	license ImageLicense
+	vision  ClassificationResult
+}
+
+// verdictStore holds the verdicts of validated images by URL.
+type verdictStore struct {
+	mu sync.Mutex
+	m  map[string]verdict
+}
+
+// update applies fn to the verdict of imgURL.
+func (s *verdictStore) update(imgURL string, fn func(*verdict)) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.m == nil {
+		s.m = make(map[string]verdict)
+	}
+	v := s.m[imgURL]
+	fn(&v)
+	s.m[imgURL] = v
+	s.mu.Unlock()
+}
+
+func (s *verdictStore) get(imgURL string) verdict {
+	if s == nil {
+		return verdict{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[imgURL]
+}
+
+// poolSize returns how many candidates to validate for maxResults results:
+// maxResults×Overshoot when Overshoot is above 1, otherwise a default pool
+// with a Ranker and maxResults itself without one.
+func (opts SearchOpts) poolSize(maxResults int) int {
+	switch {
+	case opts.Overshoot > 1:
+		return int(math.Ceil(float64(maxResults) * opts.Overshoot))
+	case opts.Ranker != nil:
+		return min(maxResults*rankerPoolFactor, max(maxResults, rankerMaxPool))
+	}
+	return maxResults
+}
+
+// rankByPipeline orders an overshooting pool when no Ranker is set: by query
+// relevance when it was scored, otherwise in the order the pipeline
+// considered the candidates.
+func rankByPipeline(in []RankedCandidate) []RankedCandidate {
+	out := append([]RankedCandidate(nil), in...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Embedded && out[j].Embedded && out[i].Relevance > out[j].Relevance
+	})
+	return out
+}
+
+// rerank passes the accepted pool, in input order, to ranker and returns
+// at most maxResults candidates in its order. The pool's accepted events,
+// held back until now, are emitted: as accepted for the returned
+// candidates, as ReasonMaxResults for the rest, which are also released
+// from the used-image history.
+func (cfg *Config) rerank(ranker Ranker, accepted []CandidateEvent, maxResults int, st *searchState) []ImageCandidate {
+	pool := make(map[string]CandidateEvent, len(accepted))
+	ranked := make([]RankedCandidate, len(accepted))
+	for i, e := range accepted {
+		pool[e.Candidate.ImgURL] = e
+		v := st.verdicts.get(e.Candidate.ImgURL)
+		ranked[i] = RankedCandidate{
+			Candidate:  e.Candidate,
+			Relevance:  e.Candidate.Relevance,
+			Embedded:   cfg.queryVec != nil,
+			License:    v.license,
+			Class:      v.vision.Class,
+			Confidence: v.vision.Confidence,
+		}
+	}
+
+	var out []ImageCandidate
+	for _, sc := range ranker(ranked) {
+		if len(out) >= maxResults {
+			break
+		}
+		e, ok := pool[sc.Candidate.ImgURL]
+		if !ok {
+			continue
+		}
+		delete(pool, e.Candidate.ImgURL)
+		out = append(out, e.Candidate)
+		cfg.emitCandidate(e)
+	}
+
+	for _, e := range accepted {
+		if _, dropped := pool[e.Candidate.ImgURL]; !dropped {
+			continue
+		}
+		st.used.release(e.Candidate.ImgURL)
+		e.Stage, e.Reason = StageCollect, ReasonMaxResults
+		cfg.emitCandidate(e)
+	}
+	return out
+}
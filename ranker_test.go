@@ -0,0 +1,177 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSearchOpts_Ranker(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultiImageServer(t, map[string][]byte{
+		"/a.jpg": encodeJPEG(t, makeGradientImage(1000, 600, 0)),
+		"/b.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 25)),
+		"/c.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 90)),
+	})
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/a.jpg", Source: srv.URL + "/a", License: LicenseSafe},
+		{ImgURL: srv.URL + "/b.jpg", Source: srv.URL + "/b", License: LicenseSafe},
+		{ImgURL: srv.URL + "/c.jpg", Source: srv.URL + "/c", License: LicenseSafe},
+	}
+
+	var (
+		mu       sync.Mutex
+		accepted []string
+		rejected = map[string]RejectReason{}
+		pool     []string
+	)
+	cfg := &Config{
+		HTTPClient:          srv.Client(),
+		MinImageWidth:       100,
+		Providers:           []SearchProvider{&mockProvider{name: "p", candidates: cands}},
+		OnCandidateAccepted: func(e CandidateEvent) { mu.Lock(); accepted = append(accepted, e.Candidate.ImgURL); mu.Unlock() },
+		OnCandidateRejected: func(e CandidateEvent) { mu.Lock(); rejected[e.Candidate.ImgURL] = e.Reason; mu.Unlock() },
+	}
+	// Rank by URL descending; an unknown candidate must be ignored.
+	ranker := func(in []RankedCandidate) []RankedCandidate {
+		for _, sc := range in {
+			pool = append(pool, sc.Candidate.ImgURL)
+		}
+		out := append([]RankedCandidate{{Candidate: ImageCandidate{ImgURL: srv.URL + "/x.jpg"}}}, in...)
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Candidate.ImgURL > out[j].Candidate.ImgURL })
+		return out
+	}
+	s := cfg.NewSession(SessionOpts{})
+	got := s.SearchImagesWithOpts(context.Background(), "q", 1, SearchOpts{Ranker: ranker})
+
+	if want := []string{cands[0].ImgURL, cands[1].ImgURL, cands[2].ImgURL}; !slices.Equal(pool, want) {
+		t.Errorf("ranker saw %v, want the pool of 3 in validation order", pool)
+	}
+	if len(got) != 1 || got[0].ImgURL != srv.URL+"/c.jpg" {
+		t.Fatalf("got %+v, want only c.jpg", got)
+	}
+	if len(accepted) != 1 || accepted[0] != srv.URL+"/c.jpg" {
+		t.Errorf("accepted events = %v, want only c.jpg", accepted)
+	}
+	for _, u := range []string{"/a.jpg", "/b.jpg"} {
+		if rejected[srv.URL+u] != ReasonMaxResults {
+			t.Errorf("%s rejected as %q, want %q", u, rejected[srv.URL+u], ReasonMaxResults)
+		}
+	}
+	if used := s.Used(); len(used) != 1 {
+		t.Errorf("used history = %v, want only the returned image", used)
+	}
+}
+
+// answerClassifier always gives the same answer.
+type answerClassifier string
+
+func (c answerClassifier) Classify(context.Context, string, []ImageInput) (string, error) {
+	return string(c), nil
+}
+
+func TestSearchOpts_RankerSignals(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultiImageServer(t, map[string][]byte{
+		"/safe.jpg":    encodeJPEG(t, makeGradientImage(1000, 600, 0)),
+		"/unknown.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 25)),
+	})
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/safe.jpg", Source: srv.URL + "/a", License: LicenseUnknown, LicenseName: "CC BY 4.0"},
+		{ImgURL: srv.URL + "/unknown.jpg", Source: "https://blog.example/post", License: LicenseUnknown},
+	}
+	cfg := &Config{
+		HTTPClient:    srv.Client(),
+		MinImageWidth: 100,
+		Classifier:    answerClassifier("PHOTO 0.8"),
+		Providers:     []SearchProvider{&mockProvider{name: "p", candidates: cands}},
+	}
+
+	var mu sync.Mutex
+	seen := map[string]RankedCandidate{}
+	ranker := func(in []RankedCandidate) []RankedCandidate {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rc := range in {
+			seen[rc.Candidate.ImgURL] = rc
+		}
+		return in
+	}
+	cfg.SearchImagesWithOpts(context.Background(), "q", 2, SearchOpts{Ranker: ranker})
+
+	if rc := seen[cands[0].ImgURL]; rc.License != LicenseSafe || rc.Class != "" || rc.Embedded {
+		t.Errorf("safe-domain candidate = {License:%v Class:%q Embedded:%v}, want safe from its license name, unclassified", rc.License, rc.Class, rc.Embedded)
+	}
+	if rc := seen[cands[1].ImgURL]; rc.License != LicenseUnknown || rc.Class != ClassPhoto || rc.Confidence != 0.8 {
+		t.Errorf("unknown candidate = {License:%v Class:%q Confidence:%v}, want unknown, PHOTO 0.8", rc.License, rc.Class, rc.Confidence)
+	}
+}
+
+func TestSearchOpts_Overshoot(t *testing.T) {
+	t.Parallel()
+
+	imgs := map[string][]byte{
+		"/a.jpg": encodeJPEG(t, makeGradientImage(1000, 600, 0)),
+		"/b.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 25)),
+		"/c.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 90)),
+	}
+	// The first-ranked image is the slowest, so it is accepted last.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/a.jpg" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(imgs[r.URL.Path])
+	}))
+	t.Cleanup(srv.Close)
+
+	var cands []ImageCandidate
+	for _, p := range []string{"/a.jpg", "/b.jpg", "/c.jpg"} {
+		cands = append(cands, ImageCandidate{ImgURL: srv.URL + p, Source: srv.URL + p + ".html", License: LicenseSafe})
+	}
+	cfg := &Config{
+		HTTPClient:    srv.Client(),
+		MinImageWidth: 100,
+		Providers:     []SearchProvider{&mockProvider{name: "p", candidates: cands}},
+	}
+
+	got := cfg.SearchImagesWithOpts(context.Background(), "q", 1, SearchOpts{})
+	if len(got) != 1 || got[0].ImgURL == cands[0].ImgURL {
+		t.Fatalf("without overshoot got %+v, want the first image accepted, not a.jpg", got)
+	}
+	got = cfg.SearchImagesWithOpts(context.Background(), "q", 1, SearchOpts{Overshoot: 3})
+	if len(got) != 1 || got[0].ImgURL != cands[0].ImgURL {
+		t.Fatalf("with overshoot got %+v, want a.jpg", got)
+	}
+}
+
+func TestSearchOpts_PoolSize(t *testing.T) {
+	t.Parallel()
+
+	rank := func(in []RankedCandidate) []RankedCandidate { return in }
+	for _, tt := range []struct {
+		opts SearchOpts
+		n    int
+		want int
+	}{
+		{SearchOpts{}, 5, 5},
+		{SearchOpts{Overshoot: 1}, 5, 5},
+		{SearchOpts{Overshoot: 2}, 5, 10},
+		{SearchOpts{Overshoot: 1.5}, 3, 5},
+		{SearchOpts{Ranker: rank}, 5, 15},
+		{SearchOpts{Ranker: rank}, 20, 30},
+		{SearchOpts{Ranker: rank}, 40, 40},
+		{SearchOpts{Ranker: rank, Overshoot: 2}, 5, 10},
+	} {
+		if got := tt.opts.poolSize(tt.n); got != tt.want {
+			t.Errorf("poolSize(%d) with Overshoot %v, Ranker %v = %d, want %d", tt.n, tt.opts.Overshoot, tt.opts.Ranker != nil, got, tt.want)
+		}
+	}
+}
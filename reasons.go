@@ -0,0 +1,129 @@
+package imagefy
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RejectReason identifies why the validation pipeline dropped a candidate.
+// Values are stable snake_case strings, safe to use as metric labels and to
+// persist; the empty string means the candidate was not rejected.
+type RejectReason string
+
+// Rejection reasons, roughly in pipeline order.
+const (
+	// ReasonLogoOrBanner: the URL matches a logo/banner/icon pattern.
+	ReasonLogoOrBanner RejectReason = "logo_or_banner"
+	// ReasonProbeFailed: the HTTP probe failed (network error or non-200 status).
+	ReasonProbeFailed RejectReason = "probe_failed"
+	// ReasonNotImage: the probe response is not an image/* content type.
+	ReasonNotImage RejectReason = "not_image"
+	// ReasonHotlinkBlocked: the host refused the hotlinked image (401/403
+	// with an HTML page) or served a placeholder instead — a 1×1 or 2×2
+	// pixel, or one of Config.HotlinkPlaceholders — even after a retry from
+	// the source page.
+	ReasonHotlinkBlocked RejectReason = "hotlink_blocked"
+	// ReasonTooNarrow: the decoded width is below Config.MinImageWidth.
+	ReasonTooNarrow RejectReason = "too_narrow"
+	// ReasonBadAspectRatio: the width/height ratio is outside
+	// Config.MinAspectRatio / MaxAspectRatio.
+	ReasonBadAspectRatio RejectReason = "bad_aspect_ratio"
+	// ReasonBlockedDomain: the image or source URL is on a blocked domain list
+	// or matches a stock URL pattern.
+	ReasonBlockedDomain RejectReason = "blocked_domain"
+	// ReasonPoorReputation: candidates from the source domain are usually
+	// rejected (Config.Reputation, DomainReputation.SkipBelow).
+	ReasonPoorReputation RejectReason = "poor_reputation"
+	// ReasonDownloadFailed: the image could not be downloaded for validation
+	// because the context ended, or for any reason under DegradeReject.
+	// Otherwise download failures degrade: the candidate continues without bytes.
+	ReasonDownloadFailed RejectReason = "download_failed"
+	// ReasonTooOld: the downloaded image was last modified longer than
+	// Config.MaxImageAge ago.
+	ReasonTooOld RejectReason = "too_old"
+	// ReasonDuplicate: the image is the same asset (by URL) as an earlier candidate
+	// or a perceptual duplicate of an accepted one.
+	ReasonDuplicate RejectReason = "duplicate"
+	// ReasonPlaceholder: the image is perceptually a known "no image
+	// available" graphic (see BuiltinPlaceholders and Config.PlaceholderHashes).
+	ReasonPlaceholder RejectReason = "placeholder"
+	// ReasonIrrelevant: the image's embedding is less similar to the query
+	// than Config.MinRelevance.
+	ReasonIrrelevant RejectReason = "irrelevant"
+	// ReasonOffTopic: the Classifier tagged the image with none of
+	// SearchOpts.RequireLabels.
+	ReasonOffTopic RejectReason = "off_topic"
+	// ReasonTitleMismatch: the candidate's title and URL slugs match fewer
+	// query words than SearchOpts.MinTitleMatch.
+	ReasonTitleMismatch RejectReason = "title_mismatch"
+	// ReasonWrongLanguage: the candidate's title is in another language
+	// than SearchOpts.Language (SearchOpts.RequireTitleLanguage).
+	ReasonWrongLanguage RejectReason = "wrong_language"
+	// ReasonAlreadyUsed: a Session already returned this image URL.
+	ReasonAlreadyUsed RejectReason = "already_used"
+	// ReasonStockMetadata: embedded EXIF/IPTC/XMP metadata names a stock agency.
+	ReasonStockMetadata RejectReason = "stock_metadata"
+	// ReasonReverseStock: reverse image search found the image on stock sites.
+	ReasonReverseStock RejectReason = "reverse_stock"
+	// ReasonVisionReject: the vision classifier returned a non-PHOTO class.
+	ReasonVisionReject RejectReason = "vision_reject"
+	// ReasonMaxResults: the candidate passed but maxResults was already reached.
+	ReasonMaxResults RejectReason = "max_results"
+	// ReasonNotValidated: maxResults candidates were accepted before this one
+	// got a validation slot, so it was never validated.
+	ReasonNotValidated RejectReason = "not_validated"
+	// ReasonDomainCap: SearchOpts.MaxPerDomain images from the candidate's
+	// source host were already accepted.
+	ReasonDomainCap RejectReason = "domain_cap"
+	// ReasonCheckFailed: a dedup decode, reverse search, or vision check
+	// failed to run and Config.DegradationPolicy is DegradeReject.
+	ReasonCheckFailed RejectReason = "check_failed"
+	// ReasonTimeout: validating the candidate exceeded SearchOpts.PerCandidateTimeout.
+	ReasonTimeout RejectReason = "timeout"
+	// ReasonSlotTimeout: the search deadline passed while the candidate was
+	// still queued for a validation slot, so it was never validated.
+	ReasonSlotTimeout RejectReason = "slot_timeout"
+	// ReasonCanceled: the search context was canceled before the candidate
+	// finished validating, or reached its deadline while it was being
+	// validated.
+	ReasonCanceled RejectReason = "canceled"
+	// ReasonByteBudget: SearchOpts.MaxTotalBytes was spent before the
+	// candidate could be probed or downloaded.
+	ReasonByteBudget RejectReason = "byte_budget"
+	// ReasonPanic: validation panicked; the panic was recovered.
+	ReasonPanic RejectReason = "panic"
+)
+
+// String returns the reason as a plain string.
+func (r RejectReason) String() string { return string(r) }
+
+// Stage names a step of the validation pipeline.
+type Stage string
+
+// Validation pipeline stages, in order.
+const (
+	StageProbe     Stage = "probe"     // HTTP probe: logo pattern, status, content type, width
+	StageDomain    Stage = "domain"    // ExtraBlockedDomains pre-check
+	StageDownload  Stage = "download"  // full download for dedup/metadata/vision
+	StageDedup     Stage = "dedup"     // URL-level pre-check, then perceptual (and, with Config.Embedder, semantic) duplicate check
+	StageRelevance Stage = "relevance" // title pre-check (SearchOpts.MinTitleMatch); query-image embedding relevance (Config.Embedder)
+	StageLicense   Stage = "license"   // domain + metadata license assessment
+	StageReverse   Stage = "reverse"   // reverse image search
+	StageVision    Stage = "vision"    // LLM vision classification
+	StageCollect   Stage = "collect"   // appending to the result set (maxResults, MaxPerDomain)
+)
+
+// CandidateEvent describes the outcome of validating a single candidate.
+type CandidateEvent struct {
+	Candidate ImageCandidate
+	Stage     Stage         // stage that accepted or rejected the candidate
+	Reason    RejectReason  // "" for accepted candidates
+	Duration  time.Duration // wall time spent validating the candidate
+	Degraded  []Stage       // stages whose check failed and was handled by Config.DegradationPolicy
+	Variant   string        // Variant.Label of the search's experiment arm ("" = none)
+
+	// Raw is the built-in provider's JSON for the candidate when it was
+	// searched with SearchOpts.KeepRaw (nil otherwise), for diagnosing
+	// schema mismatches and missing fields.
+	Raw json.RawMessage
+}
@@ -0,0 +1,166 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProbeImageURL_Reasons(t *testing.T) {
+	t.Parallel()
+
+	wide := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	narrow := newImageServer(t, "image/jpeg", makeJPEG(200, 100))
+	html := newImageServer(t, "text/html", []byte("<html></html>"))
+	missing := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(missing.Close)
+
+	tests := []struct {
+		name string
+		url  string
+		want RejectReason
+	}{
+		{"accepted", wide.URL + "/photo.jpg", ""},
+		{"logo", wide.URL + "/logo.png", ReasonLogoOrBanner},
+		{"too narrow", narrow.URL + "/photo.jpg", ReasonTooNarrow},
+		{"not image", html.URL + "/photo.jpg", ReasonNotImage},
+		{"404", missing.URL + "/photo.jpg", ReasonProbeFailed},
+	}
+
+	cfg := &Config{MinImageWidth: 880}
+	cfg.defaults()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.probeImageURL(context.Background(), tt.url, ""); got != tt.want {
+				t.Errorf("probeImageURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLicenseAssessment_RejectReason(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a    LicenseAssessment
+		want RejectReason
+	}{
+		{"unknown", LicenseAssessment{License: LicenseUnknown}, ""},
+		{"safe", LicenseAssessment{License: LicenseSafe, Signals: []LicenseSignal{{Source: "metadata_cc"}}}, ""},
+		{"domain", LicenseAssessment{License: LicenseBlocked, Signals: []LicenseSignal{{Source: "domain"}}}, ReasonBlockedDomain},
+		{"metadata", LicenseAssessment{License: LicenseBlocked, Signals: []LicenseSignal{
+			{Source: "domain", License: LicenseSafe},
+			{Source: "metadata_stock", License: LicenseBlocked},
+		}}, ReasonStockMetadata},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.rejectReason(); got != tt.want {
+			t.Errorf("%s: rejectReason() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateOne_Reasons(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+
+	t.Run("vision reject", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			events []ClassificationEvent
+		)
+		cfg := &Config{
+			HTTPClient: srv.Client(),
+			Classifier: &mockClassifier{response: "STOCK"},
+			OnClassification: func(e ClassificationEvent) {
+				mu.Lock()
+				events = append(events, e)
+				mu.Unlock()
+			},
+		}
+		cfg.defaults()
+		cand := ImageCandidate{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown}
+		if _, got, _ := cfg.validateOne(context.Background(), cand, newSearchState(), nil); got != ReasonVisionReject {
+			t.Fatalf("validateOne() = %q, want %q", got, ReasonVisionReject)
+		}
+		if len(events) != 1 || events[0].Reason != ReasonVisionReject {
+			t.Errorf("events = %+v, want one llm event with reason %q", events, ReasonVisionReject)
+		}
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		cfg := &Config{HTTPClient: srv.Client()}
+		cfg.defaults()
+		st := newSearchState()
+		cand := ImageCandidate{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown}
+		if _, got, _ := cfg.validateOne(context.Background(), cand, st, nil); got != "" {
+			t.Fatalf("first validateOne() = %q, want accepted", got)
+		}
+		if stage, got, _ := cfg.validateOne(context.Background(), cand, st, nil); got != ReasonDuplicate || stage != StageDedup {
+			t.Errorf("second validateOne() = (%q, %q), want (%q, %q)", stage, got, StageDedup, ReasonDuplicate)
+		}
+	})
+
+	t.Run("extra blocked domain", func(t *testing.T) {
+		cfg := &Config{HTTPClient: srv.Client(), ExtraBlockedDomains: []string{"127.0.0.1"}}
+		cfg.defaults()
+		cand := ImageCandidate{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown}
+		if stage, got, _ := cfg.validateOne(context.Background(), cand, newSearchState(), nil); got != ReasonBlockedDomain || stage != StageDomain {
+			t.Errorf("validateOne() = (%q, %q), want (%q, %q)", stage, got, StageDomain, ReasonBlockedDomain)
+		}
+	})
+}
+
+func TestValidateCandidates_LifecycleCallbacks(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+
+	var (
+		mu       sync.Mutex
+		accepted []CandidateEvent
+		rejected []CandidateEvent
+	)
+	cfg := &Config{
+		HTTPClient: srv.Client(),
+		OnCandidateAccepted: func(e CandidateEvent) {
+			mu.Lock()
+			accepted = append(accepted, e)
+			mu.Unlock()
+		},
+		OnCandidateRejected: func(e CandidateEvent) {
+			mu.Lock()
+			rejected = append(rejected, e)
+			mu.Unlock()
+		},
+	}
+
+	candidates := []ImageCandidate{
+		{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown},
+		{ImgURL: srv.URL + "/logo.png", Source: srv.URL + "/page", License: LicenseUnknown},
+	}
+	results := cfg.ValidateCandidates(context.Background(), candidates, 5)
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+
+	if len(accepted) != 1 {
+		t.Fatalf("accepted events = %d, want 1", len(accepted))
+	}
+	if e := accepted[0]; e.Reason != "" || e.Stage != StageVision || e.Candidate.ImgURL != srv.URL+"/photo.jpg" || e.Duration <= 0 {
+		t.Errorf("accepted event = %+v", e)
+	}
+
+	if len(rejected) != 1 {
+		t.Fatalf("rejected events = %d, want 1", len(rejected))
+	}
+	if e := rejected[0]; e.Reason != ReasonLogoOrBanner || e.Stage != StageProbe || e.Duration < 0 || e.Duration > time.Minute {
+		t.Errorf("rejected event = %+v", e)
+	}
+}
@@ -0,0 +1,58 @@
+package replay
+
+import (
+	"context"
+	"errors"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+// classifyFixture is the on-disk form of one Classify call.
+// Image payloads are not stored; they are part of the key only.
+type classifyFixture struct {
+	Response string `json:"response"`
+	Error    string `json:"error,omitempty"`
+}
+
+// classifier wraps a Classifier with record/replay.
+type classifier struct {
+	store *Store
+	inner imagefy.Classifier
+}
+
+// Classifier wraps c so that Classify responses are recorded or replayed.
+// Calls are keyed by prompt and image URLs (data: URIs included), so replays
+// only match when the replayed downloads produce identical previews.
+func (s *Store) Classifier(c imagefy.Classifier) imagefy.Classifier {
+	return &classifier{store: s, inner: c}
+}
+
+// Classify implements imagefy.Classifier.
+func (c *classifier) Classify(ctx context.Context, prompt string, images []imagefy.ImageInput) (string, error) {
+	parts := []string{prompt}
+	for _, img := range images {
+		parts = append(parts, img.URL)
+	}
+	key := fixtureKey(parts...)
+
+	if c.store.shouldReplay(dirClassify, key) {
+		var fx classifyFixture
+		if err := c.store.load(dirClassify, key, &fx); err != nil {
+			return "", err
+		}
+		if fx.Error != "" {
+			return fx.Response, errors.New(fx.Error)
+		}
+		return fx.Response, nil
+	}
+
+	resp, err := c.inner.Classify(ctx, prompt, images)
+	fx := classifyFixture{Response: resp}
+	if err != nil {
+		fx.Error = err.Error()
+	}
+	if saveErr := c.store.save(dirClassify, key, fx); saveErr != nil {
+		return "", saveErr
+	}
+	return resp, err
+}
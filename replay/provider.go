@@ -0,0 +1,60 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+// searchFixture is the on-disk form of one provider Search call.
+type searchFixture struct {
+	Provider   string                   `json:"provider"`
+	Query      string                   `json:"query"`
+	Candidates []imagefy.ImageCandidate `json:"candidates"`
+	Error      string                   `json:"error,omitempty"`
+}
+
+// provider wraps a SearchProvider with record/replay.
+type provider struct {
+	store *Store
+	inner imagefy.SearchProvider
+}
+
+// Provider wraps p so that Search results are recorded or replayed. Calls are
+// keyed by provider name, query, page number, engines, and page URL.
+func (s *Store) Provider(p imagefy.SearchProvider) imagefy.SearchProvider {
+	return &provider{store: s, inner: p}
+}
+
+// Name returns the wrapped provider's name.
+func (p *provider) Name() string { return p.inner.Name() }
+
+// Search implements imagefy.SearchProvider.
+func (p *provider) Search(ctx context.Context, query string, opts imagefy.SearchOpts) ([]imagefy.ImageCandidate, error) {
+	key := fixtureKey(p.inner.Name(), query,
+		fmt.Sprint(opts.PageNumber), strings.Join(opts.Engines, ","), opts.PageURL)
+
+	if p.store.shouldReplay(dirSearch, key) {
+		var fx searchFixture
+		if err := p.store.load(dirSearch, key, &fx); err != nil {
+			return nil, err
+		}
+		if fx.Error != "" {
+			return nil, errors.New(fx.Error)
+		}
+		return fx.Candidates, nil
+	}
+
+	cands, err := p.inner.Search(ctx, query, opts)
+	fx := searchFixture{Provider: p.inner.Name(), Query: query, Candidates: cands}
+	if err != nil {
+		fx.Error = err.Error()
+	}
+	if saveErr := p.store.save(dirSearch, key, fx); saveErr != nil {
+		return nil, saveErr
+	}
+	return cands, err
+}
@@ -0,0 +1,158 @@
+// Package replay records the external interactions of an imagefy pipeline —
+// search provider responses, HTTP downloads, and classifier calls — to a
+// fixture directory and replays them deterministically.
+//
+// Consuming services use it to run integration tests of their image sourcing
+// without a live SearXNG instance or vision model:
+//
+//	store := replay.New("testdata/imagefy", replay.ModeAuto)
+//	cfg := &imagefy.Config{Providers: providers, Classifier: llm}
+//	results := store.Wrap(cfg).SearchImages(ctx, "Hermitage Museum", 3)
+//
+// Run once with network access (ModeRecord or ModeAuto) to create fixtures,
+// then commit the directory and run with ModeReplay in CI.
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+// Mode selects whether a Store records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay serves every interaction from fixtures and fails on a miss.
+	ModeReplay Mode = iota
+	// ModeRecord performs every interaction live and overwrites its fixture.
+	ModeRecord
+	// ModeAuto replays when a fixture exists and records it otherwise.
+	ModeAuto
+)
+
+// Fixture subdirectories, one per interaction kind.
+const (
+	dirHTTP     = "http"
+	dirSearch   = "search"
+	dirClassify = "classify"
+)
+
+// ErrFixtureNotFound is returned in ModeReplay when no fixture matches an interaction.
+var ErrFixtureNotFound = errors.New("replay: fixture not found")
+
+// Store reads and writes fixtures under Dir.
+type Store struct {
+	Dir  string
+	Mode Mode
+}
+
+// New returns a Store rooted at dir.
+func New(dir string, mode Mode) *Store {
+	return &Store{Dir: dir, Mode: mode}
+}
+
+// Wrap returns a copy of cfg (see imagefy.Config.Clone) whose external
+// dependencies are recording/replaying wrappers: every provider in
+// Providers, the Classifier, and both HTTP clients. cfg itself is left
+// untouched, so a Config shared with other code keeps its live
+// dependencies. When Providers is empty and SearxngURL is set, the
+// auto-created SearXNG provider is covered through the wrapped HTTPClient.
+func (s *Store) Wrap(cfg *imagefy.Config) *imagefy.Config {
+	w := cfg.Clone() // with its own Providers slice
+	for i, p := range w.Providers {
+		w.Providers[i] = s.Provider(p)
+	}
+	if w.Classifier != nil {
+		w.Classifier = s.Classifier(w.Classifier)
+	}
+	w.HTTPClient = s.HTTPClient(w.HTTPClient)
+	if w.StealthClient != nil {
+		w.StealthClient = s.HTTPClient(w.StealthClient)
+	}
+	return w
+}
+
+// fixtureKey derives a stable file name from the identifying parts of an interaction.
+func fixtureKey(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]) + ".json"
+}
+
+func (s *Store) path(kind, key string) string {
+	return filepath.Join(s.Dir, kind, key)
+}
+
+// shouldReplay reports whether the interaction must be served from a fixture.
+func (s *Store) shouldReplay(kind, key string) bool {
+	switch s.Mode {
+	case ModeRecord:
+		return false
+	case ModeAuto:
+		_, err := os.Stat(s.path(kind, key))
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// load decodes the fixture for (kind, key) into dest.
+func (s *Store) load(kind, key string, dest any) error {
+	data, err := os.ReadFile(s.path(kind, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s/%s", ErrFixtureNotFound, kind, key)
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// save writes v as the fixture for (kind, key). The write goes through a
+// temporary file so concurrent readers never observe a partial fixture.
+func (s *Store) save(kind, key string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(s.Dir, kind)
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:mnd // standard directory permissions
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(kind, key))
+}
+
+// HTTPClient returns a copy of c (nil = http.DefaultClient) whose transport
+// records or replays every request.
+func (s *Store) HTTPClient(c *http.Client) *http.Client {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	wrapped := *c
+	wrapped.Transport = s.Transport(c.Transport)
+	return &wrapped
+}
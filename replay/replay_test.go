@@ -0,0 +1,165 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+// staticProvider returns a fixed candidate list and counts calls.
+type staticProvider struct {
+	cands []imagefy.ImageCandidate
+	calls int
+}
+
+func (p *staticProvider) Name() string { return "static" }
+
+func (p *staticProvider) Search(_ context.Context, _ string, _ imagefy.SearchOpts) ([]imagefy.ImageCandidate, error) {
+	p.calls++
+	return p.cands, nil
+}
+
+// countingClassifier returns a fixed response and counts calls.
+type countingClassifier struct {
+	response string
+	calls    int
+}
+
+func (c *countingClassifier) Classify(_ context.Context, _ string, _ []imagefy.ImageInput) (string, error) {
+	c.calls++
+	return c.response, nil
+}
+
+func newImageServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(make([]byte, 2048))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStore_RecordThenReplayPipeline(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	imgSrv := newImageServer(t)
+	cands := []imagefy.ImageCandidate{
+		{ImgURL: imgSrv.URL + "/a.jpg", Source: imgSrv.URL + "/page", License: imagefy.LicenseUnknown},
+	}
+
+	// Record.
+	prov := &staticProvider{cands: cands}
+	cls := &countingClassifier{response: "PHOTO 0.9"}
+	cfg := &imagefy.Config{
+		Providers:  []imagefy.SearchProvider{prov},
+		Classifier: cls,
+		HTTPClient: imgSrv.Client(),
+	}
+	recorded := New(dir, ModeRecord).Wrap(cfg).SearchImages(context.Background(), "test", 3)
+	if len(recorded) != 1 {
+		t.Fatalf("recorded %d results, want 1", len(recorded))
+	}
+	if cls.calls != 1 {
+		t.Fatalf("classifier called %d times while recording, want 1", cls.calls)
+	}
+	if cfg.Providers[0] != prov || cfg.Classifier != cls || cfg.HTTPClient != imgSrv.Client() {
+		t.Error("Wrap modified the caller's Config")
+	}
+
+	// Replay with the image server gone.
+	imgSrv.Close()
+	prov2 := &staticProvider{}
+	cls2 := &countingClassifier{response: "STOCK 0.9"}
+	cfg2 := &imagefy.Config{
+		Providers:  []imagefy.SearchProvider{prov2},
+		Classifier: cls2,
+	}
+	replayed := New(dir, ModeReplay).Wrap(cfg2).SearchImages(context.Background(), "test", 3)
+	if len(replayed) != 1 || replayed[0].ImgURL != recorded[0].ImgURL {
+		t.Fatalf("replayed %+v, want %+v", replayed, recorded)
+	}
+	if prov2.calls != 0 || cls2.calls != 0 {
+		t.Errorf("live dependencies called during replay: provider=%d classifier=%d", prov2.calls, cls2.calls)
+	}
+}
+
+func TestStore_ReplayMissingFixture(t *testing.T) {
+	t.Parallel()
+
+	s := New(t.TempDir(), ModeReplay)
+
+	_, err := s.Provider(&staticProvider{}).Search(context.Background(), "q", imagefy.SearchOpts{})
+	if !errors.Is(err, ErrFixtureNotFound) {
+		t.Errorf("provider: err = %v, want ErrFixtureNotFound", err)
+	}
+
+	_, err = s.Classifier(&countingClassifier{}).Classify(context.Background(), "p", nil)
+	if !errors.Is(err, ErrFixtureNotFound) {
+		t.Errorf("classifier: err = %v, want ErrFixtureNotFound", err)
+	}
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.invalid/x.jpg", nil)
+	resp, err := s.Transport(nil).RoundTrip(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrFixtureNotFound) {
+		t.Errorf("transport: err = %v, want ErrFixtureNotFound", err)
+	}
+}
+
+func TestStore_AutoModeRecordsOnce(t *testing.T) {
+	t.Parallel()
+
+	s := New(t.TempDir(), ModeAuto)
+	cls := &countingClassifier{response: "PHOTO 0.8"}
+	wrapped := s.Classifier(cls)
+
+	for range 3 {
+		resp, err := wrapped.Classify(context.Background(), "prompt", []imagefy.ImageInput{{URL: "data:image/jpeg;base64,AA=="}})
+		if err != nil || resp != "PHOTO 0.8" {
+			t.Fatalf("Classify = (%q, %v), want (PHOTO 0.8, nil)", resp, err)
+		}
+	}
+	if cls.calls != 1 {
+		t.Errorf("inner classifier called %d times, want 1", cls.calls)
+	}
+}
+
+func TestTransport_ReplaysStatusHeadersAndBody(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	dir := t.TempDir()
+
+	rec := New(dir, ModeRecord).HTTPClient(srv.Client())
+	resp, err := rec.Get(srv.URL + "/api") //nolint:noctx // test
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	resp.Body.Close()
+	srv.Close()
+
+	play := New(dir, ModeReplay).HTTPClient(nil)
+	resp, err = play.Get(srv.URL + "/api") //nolint:noctx // test
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusTeapot)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+}
@@ -0,0 +1,102 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// httpFixture is the on-disk form of one HTTP exchange.
+type httpFixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// transport is an http.RoundTripper that records or replays exchanges.
+type transport struct {
+	store *Store
+	next  http.RoundTripper
+}
+
+// Transport returns an http.RoundTripper that records or replays exchanges.
+// Requests are keyed by method, URL, and request body. next is used for live
+// requests (nil = http.DefaultTransport).
+func (s *Store) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{store: s, next: next}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	key := fixtureKey(req.Method, req.URL.String(), string(reqBody))
+
+	if t.store.shouldReplay(dirHTTP, key) {
+		var fx httpFixture
+		if err := t.store.load(dirHTTP, key, &fx); err != nil {
+			return nil, err
+		}
+		if fx.Error != "" {
+			return nil, errors.New(fx.Error)
+		}
+		return fx.response(req), nil
+	}
+
+	fx := httpFixture{Method: req.Method, URL: req.URL.String()}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		fx.Error = err.Error()
+		if saveErr := t.store.save(dirHTTP, key, fx); saveErr != nil {
+			return nil, saveErr
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	fx.Status = resp.StatusCode
+	fx.Header = resp.Header
+	fx.Body = body
+	if err := t.store.save(dirHTTP, key, fx); err != nil {
+		return nil, err
+	}
+	return fx.response(req), nil
+}
+
+// response rebuilds an *http.Response from the fixture.
+func (fx *httpFixture) response(req *http.Request) *http.Response {
+	header := fx.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        http.StatusText(fx.Status),
+		StatusCode:    fx.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(fx.Body)),
+		ContentLength: int64(len(fx.Body)),
+		Request:       req,
+	}
+}
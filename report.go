@@ -0,0 +1,212 @@
+package imagefy
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"sync"
+	"time"
+)
+
+// SearchResult records one search for WriteReport: what was accepted and
+// every decision the pipeline made on the way.
+type SearchResult struct {
+	Query           string
+	Accepted        []ImageCandidate
+	Events          []CandidateEvent           // accepted and rejected candidates, in decision order
+	Classifications []ClassificationEvent      // every classification decision
+	Signals         map[string][]LicenseSignal // ExplainLicense output, keyed by ImgURL
+	Duration        time.Duration              // wall time of the search
+	Bytes           ByteStats                  // image traffic of the search
+	Stats           SearchStats                // candidate counts per provider, engine, domain, and rejecting stage
+
+	// Partial is true when the context was canceled or the search deadline
+	// passed before validation finished: Accepted holds the candidates
+	// validated until then, the ones in flight are rejected with
+	// ReasonCanceled, and the ones still waiting for a validation slot with
+	// ReasonSlotTimeout (or ReasonCanceled on cancellation).
+	Partial bool
+
+	// Err is ErrNoProviders when Config.Strict is set and no provider is
+	// configured; nil otherwise.
+	Err error
+}
+
+// SearchImagesResult is like SearchImagesWithOpts but also records every
+// CandidateEvent and ClassificationEvent of the search, the license
+// signals of each candidate, and summary Stats, for WriteReport and
+// dashboards. Config callbacks still fire.
+func (cfg *Config) SearchImagesResult(ctx context.Context, query string, maxResults int, opts SearchOpts) SearchResult {
+	cfg = cfg.orZero()
+	res := SearchResult{Query: query, Signals: map[string][]LicenseSignal{}}
+	var mu sync.Mutex
+
+	rec := cfg.derive()
+	onEvent := func(next func(CandidateEvent)) func(CandidateEvent) {
+		return func(e CandidateEvent) {
+			signals := ExplainLicense(e.Candidate.ImgURL, e.Candidate.Source, cfg)
+			mu.Lock()
+			res.Events = append(res.Events, e)
+			res.Signals[e.Candidate.ImgURL] = signals
+			mu.Unlock()
+			if next != nil {
+				next(e)
+			}
+		}
+	}
+	rec.OnCandidateAccepted = onEvent(cfg.OnCandidateAccepted)
+	rec.OnCandidateRejected = onEvent(cfg.OnCandidateRejected)
+	rec.OnSearchBytes = func(s ByteStats) {
+		mu.Lock()
+		res.Bytes = s
+		mu.Unlock()
+		if cfg.OnSearchBytes != nil {
+			cfg.OnSearchBytes(s)
+		}
+	}
+	rec.OnClassification = func(e ClassificationEvent) {
+		mu.Lock()
+		res.Classifications = append(res.Classifications, e)
+		mu.Unlock()
+		if cfg.OnClassification != nil {
+			cfg.OnClassification(e)
+		}
+	}
+
+	if cfg.Strict && len(cfg.resolveProviders()) == 0 {
+		res.Err = ErrNoProviders
+	}
+	start := time.Now()
+	accepted := rec.SearchImagesWithOpts(ctx, query, maxResults, opts)
+
+	mu.Lock()
+	defer mu.Unlock()
+	res.Accepted = accepted
+	res.Duration = time.Since(start)
+	res.Partial = ctx.Err() != nil || partial(res.Events)
+	res.Stats = searchStats(res.Events)
+	return res
+}
+
+// partial reports whether events include a candidate that was left
+// unvalidated because the search ran out of time or was canceled.
+func partial(events []CandidateEvent) bool {
+	for _, e := range events {
+		if e.Reason == ReasonCanceled || e.Reason == ReasonSlotTimeout {
+			return true
+		}
+	}
+	return false
+}
+
+// reportCard is one contact-sheet tile.
+type reportCard struct {
+	Event    CandidateEvent
+	Accepted bool
+	Preview  string
+	Verdicts []ClassificationEvent
+	Signals  []LicenseSignal
+}
+
+// WriteReport writes a self-contained HTML contact sheet of result: one
+// tile per candidate with its thumbnail, accept/reject status, deciding
+// stage and reason, classification verdicts with the model's stated
+// rationale, license signals, and scores.
+// Images load from their original URLs; the page needs no other resources.
+func WriteReport(w io.Writer, result SearchResult) error {
+	verdicts := make(map[string][]ClassificationEvent)
+	for _, e := range result.Classifications {
+		verdicts[e.URL] = append(verdicts[e.URL], e)
+	}
+
+	cards := make([]reportCard, 0, len(result.Events))
+	accepted := 0
+	for _, e := range result.Events {
+		c := e.Candidate
+		signals, ok := result.Signals[c.ImgURL]
+		if !ok {
+			signals = ExplainLicense(c.ImgURL, c.Source, nil)
+		}
+		preview := c.Thumbnail
+		if preview == "" {
+			preview = c.ImgURL
+		}
+		if e.Reason == "" {
+			accepted++
+		}
+		cards = append(cards, reportCard{
+			Event:    e,
+			Accepted: e.Reason == "",
+			Preview:  preview,
+			Verdicts: verdicts[c.ImgURL],
+			Signals:  signals,
+		})
+	}
+
+	if err := reportTemplate.Execute(w, map[string]any{
+		"Result":   result,
+		"Cards":    cards,
+		"Accepted": accepted,
+		"Rejected": len(cards) - accepted,
+	}); err != nil {
+		return fmt.Errorf("imagefy: write report: %w", err)
+	}
+	return nil
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct": func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) }, //nolint:mnd // percent
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>imagefy report: {{.Result.Query}}</title>
+<style>
+body{font:14px/1.4 system-ui,sans-serif;margin:1.5em;color:#222}
+h1{font-size:1.4em;margin:0 0 .2em}
+.summary{color:#555;margin-bottom:1em}
+.grid{display:grid;grid-template-columns:repeat(auto-fill,minmax(260px,1fr));gap:12px}
+.card{border:2px solid #c33;border-radius:6px;padding:8px;overflow-wrap:anywhere}
+.card.ok{border-color:#393}
+.card img{width:100%;height:180px;object-fit:contain;background:#eee}
+.status{font-weight:bold;color:#c33}
+.ok .status{color:#393}
+dl{margin:.4em 0;display:grid;grid-template-columns:auto 1fr;gap:0 .6em;font-size:12px}
+dt{color:#777}
+ul{margin:.2em 0;padding-left:1.2em;font-size:12px}
+a{color:#25a}
+</style>
+</head>
+<body>
+<h1>{{.Result.Query}}</h1>
+<div class="summary">{{.Accepted}} accepted, {{.Rejected}} rejected, {{len .Result.Classifications}} classifications, {{.Result.Bytes.Total}} image bytes in {{.Result.Duration}}{{if .Result.Partial}} — partial: the search was canceled or ran out of time{{end}}</div>
+<div class="grid">
+{{- range .Cards}}
+<div class="card{{if .Accepted}} ok{{end}}">
+<a href="{{.Event.Candidate.ImgURL}}"><img src="{{.Preview}}" alt="" loading="lazy"></a>
+<div class="status">{{if .Accepted}}accepted{{else}}{{.Event.Reason}}{{end}} <small>at {{.Event.Stage}}</small></div>
+<dl>
+{{- with .Event.Candidate.Title}}<dt>title</dt><dd>{{.}}</dd>{{end}}
+<dt>source</dt><dd><a href="{{.Event.Candidate.Source}}">{{.Event.Candidate.Source}}</a></dd>
+<dt>license</dt><dd>{{.Event.Candidate.License}}</dd>
+{{- if .Event.Candidate.Width}}<dt>size</dt><dd>{{.Event.Candidate.Width}}×{{.Event.Candidate.Height}}</dd>{{end}}
+{{- with .Event.Candidate.Provider}}<dt>provider</dt><dd>{{.}}</dd>{{end}}
+{{- if .Event.Candidate.Rank}}<dt>rank</dt><dd>{{.Event.Candidate.Rank}} (page {{.Event.Candidate.Page}})</dd>{{end}}
+{{- if .Event.Candidate.Relevance}}<dt>relevance</dt><dd>{{pct .Event.Candidate.Relevance}}</dd>{{end}}
+{{- with .Event.Degraded}}<dt>degraded</dt><dd>{{range .}}{{.}} {{end}}</dd>{{end}}
+{{- with .Event.Variant}}<dt>variant</dt><dd>{{.}}</dd>{{end}}
+<dt>time</dt><dd>{{.Event.Duration}}</dd>
+</dl>
+{{- with .Verdicts}}
+<ul class="verdicts">{{range .}}<li>{{.Class}} {{pct .Confidence}} ({{.Source}}){{with .Reason}} → {{.}}{{end}}{{with .Rationale}}: “{{.}}”{{end}}</li>{{end}}</ul>
+{{- end}}
+{{- with .Signals}}
+<ul class="signals">{{range .}}<li>{{.License}}: {{.Detail}}</li>{{end}}</ul>
+{{- end}}
+</div>
+{{- end}}
+</div>
+</body>
+</html>
+`))
@@ -0,0 +1,151 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSearchImagesResult_RecordsEvents(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultiImageServer(t, map[string][]byte{"/a.jpg": makeJPEG(1000, 600)})
+	var accepted atomic.Int32
+	cfg := &Config{
+		HTTPClient: srv.Client(),
+		Providers: []SearchProvider{&mockProvider{name: "p", candidates: []ImageCandidate{
+			{ImgURL: srv.URL + "/a.jpg", Source: "https://commons.wikimedia.org/wiki/A", License: LicenseSafe},
+			{ImgURL: srv.URL + "/missing.jpg", Source: srv.URL + "/b", License: LicenseUnknown},
+		}}},
+		OnCandidateAccepted: func(CandidateEvent) { accepted.Add(1) },
+	}
+
+	res := cfg.SearchImagesResult(context.Background(), "kremlin", 5, SearchOpts{})
+	if res.Query != "kremlin" || len(res.Accepted) != 1 || len(res.Events) != 2 {
+		t.Fatalf("result = %+v, want 1 accepted of 2 events", res)
+	}
+	if n := accepted.Load(); n != 1 {
+		t.Errorf("OnCandidateAccepted fired %d times, want 1", n)
+	}
+	if len(res.Signals[srv.URL+"/a.jpg"]) == 0 {
+		t.Error("no license signals recorded for the Wikimedia candidate")
+	}
+	if cfg.OnCandidateRejected != nil {
+		t.Error("SearchImagesResult modified the caller's Config")
+	}
+	if s := res.Stats; s.Candidates != 2 || s.Accepted != 1 || s.ByProvider["p"] != 2 ||
+		s.ByDomain["commons.wikimedia.org"] != 1 || s.RejectedByStage[StageProbe] != 1 || s.RejectedByReason[ReasonProbeFailed] != 1 {
+		t.Errorf("Stats = %+v", s)
+	}
+}
+
+func TestSearchStats(t *testing.T) {
+	t.Parallel()
+
+	s := searchStats([]CandidateEvent{
+		{Candidate: ImageCandidate{ImgURL: "https://cdn.a.com/1.jpg", Source: "https://a.com/p", Provider: "searxng", Engine: "bing images"}},
+		{Candidate: ImageCandidate{ImgURL: "https://cdn.a.com/2.jpg", Source: "https://a.com/q", Provider: "searxng", Engine: "yandex images"}, Stage: StageVision, Reason: ReasonVisionReject},
+		{Candidate: ImageCandidate{ImgURL: "https://b.org/3.jpg", Provider: "openverse"}, Stage: StageDedup, Reason: ReasonDuplicate},
+		{Candidate: ImageCandidate{ImgURL: "https://b.org/4.jpg"}, Stage: StageVision, Reason: ReasonVisionReject},
+	})
+	if s.Candidates != 4 || s.Accepted != 1 {
+		t.Errorf("Candidates, Accepted = %d, %d; want 4, 1", s.Candidates, s.Accepted)
+	}
+	checks := []struct {
+		name      string
+		got, want int
+	}{
+		{"provider searxng", s.ByProvider["searxng"], 2},
+		{"provider external", s.ByProvider[""], 1},
+		{"engine bing", s.ByEngine["bing images"], 1},
+		{"engine none", s.ByEngine[""], 2},
+		{"domain a.com", s.ByDomain["a.com"], 2},
+		{"domain b.org", s.ByDomain["b.org"], 2},
+		{"stage vision", s.RejectedByStage[StageVision], 2},
+		{"stage dedup", s.RejectedByStage[StageDedup], 1},
+		{"reason vision_reject", s.RejectedByReason[ReasonVisionReject], 2},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %d, want %d", c.name, c.got, c.want)
+		}
+	}
+	if empty := searchStats(nil); empty.ByProvider == nil || empty.RejectedByStage == nil {
+		t.Error("maps of empty stats are nil")
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	t.Parallel()
+
+	res := SearchResult{
+		Query: "kremlin <b>",
+		Events: []CandidateEvent{
+			{Candidate: ImageCandidate{ImgURL: "https://a.example/1.jpg", Thumbnail: "https://a.example/1_t.jpg", Title: "<script>x</script>", Relevance: 0.42}, Stage: StageCollect},
+			{Candidate: ImageCandidate{ImgURL: "https://shutterstock.com/2.jpg"}, Stage: StageVision, Reason: ReasonVisionReject},
+		},
+		Classifications: []ClassificationEvent{{URL: "https://shutterstock.com/2.jpg", Class: ClassStock, Confidence: 0.9, Source: "llm", Reason: ReasonVisionReject}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, res); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	html := buf.String()
+	for _, want := range []string{
+		"kremlin &lt;b&gt;",
+		"1 accepted, 1 rejected",
+		`src="https://a.example/1_t.jpg"`,
+		"&lt;script&gt;",
+		"42%",
+		"vision_reject",
+		"STOCK 90% (llm)",
+		"<li>blocked: ", // signals computed when missing from the result
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("report contains unescaped title")
+	}
+}
+
+func TestSearchImagesResult_EventPerCandidate(t *testing.T) {
+	t.Parallel()
+
+	const n = 40
+	bodies := map[string][]byte{}
+	var cands []ImageCandidate
+	for i := range n {
+		path := fmt.Sprintf("/%d.jpg", i)
+		bodies[path] = makeJPEG(1000+i, 600)
+		cands = append(cands, ImageCandidate{ImgURL: path, Source: "https://commons.wikimedia.org/wiki/" + path[1:], License: LicenseSafe})
+	}
+	srv := newMultiImageServer(t, bodies)
+	for i := range cands {
+		cands[i].ImgURL = srv.URL + cands[i].ImgURL
+	}
+	cfg := &Config{HTTPClient: srv.Client(), Providers: []SearchProvider{&mockProvider{name: "p", candidates: cands}}}
+
+	res := cfg.SearchImagesResult(context.Background(), "kremlin", 1, SearchOpts{})
+	if len(res.Accepted) != 1 || len(res.Events) != n || res.Stats.Candidates != n {
+		t.Fatalf("%d accepted, %d events, Stats.Candidates = %d; want 1, %d, %d", len(res.Accepted), len(res.Events), res.Stats.Candidates, n, n)
+	}
+	seen := map[string]bool{}
+	for _, e := range res.Events {
+		if seen[e.Candidate.ImgURL] {
+			t.Errorf("%s reported twice", e.Candidate.ImgURL)
+		}
+		seen[e.Candidate.ImgURL] = true
+		if e.Reason == ReasonNotValidated && e.Stage != StageCollect {
+			t.Errorf("%s not validated at stage %q, want %q", e.Candidate.ImgURL, e.Stage, StageCollect)
+		}
+	}
+	if res.Stats.RejectedByReason[ReasonNotValidated] == 0 {
+		t.Errorf("RejectedByReason = %v, want some %q", res.Stats.RejectedByReason, ReasonNotValidated)
+	}
+}
@@ -0,0 +1,230 @@
+package imagefy
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// DomainReputation defaults.
+const (
+	defaultReputationHalfLife   = 14 * 24 * time.Hour
+	defaultReputationMinSamples = 5
+	defaultReputationDemote     = 0.3
+)
+
+// reputationCachePrefix namespaces domain statistics in Config.Cache.
+const reputationCachePrefix = "imagefy_domain_rep_v1"
+
+// reputationReasons are the rejections that say something about the
+// domain's images rather than about the query or the search's limits.
+var reputationReasons = map[RejectReason]bool{
+	ReasonLogoOrBanner: true, ReasonProbeFailed: true, ReasonNotImage: true,
+	ReasonHotlinkBlocked: true, ReasonTooNarrow: true, ReasonBadAspectRatio: true,
+	ReasonPlaceholder: true, ReasonStockMetadata: true,
+	ReasonReverseStock: true, ReasonVisionReject: true,
+}
+
+// DomainReputation remembers how candidates from each source domain fared
+// in validation, across runs, in Config.Cache. Domains with a poor record
+// are validated after the others, or skipped entirely, so they stop taking
+// validation slots and vision calls. Requires Config.Cache; ignored
+// otherwise.
+//
+// Counts decay with HalfLife, so a domain that improves recovers. Stats are
+// read once per search and written back when it ends; concurrent searches
+// may overwrite each other's updates, which only loses a few samples.
+type DomainReputation struct {
+	// HalfLife is the time after which old outcomes count half
+	// (default: 14 days).
+	HalfLife time.Duration
+
+	// MinSamples is the (decayed) number of outcomes a domain needs before
+	// its score is trusted (default: 5).
+	MinSamples float64
+
+	// DemoteBelow moves candidates of domains scoring below it to the end
+	// of the validation order (default: 0.3; negative = never).
+	DemoteBelow float64
+
+	// SkipBelow rejects candidates of domains scoring below it with
+	// ReasonPoorReputation before any request (0 = never).
+	SkipBelow float64
+}
+
+// DomainStats is the validation record of one source domain.
+type DomainStats struct {
+	Domain   string    `json:"domain"`
+	Accepted float64   `json:"accepted"` // decayed count of accepted candidates
+	Rejected float64   `json:"rejected"` // decayed count of rejections attributable to the domain
+	Updated  time.Time `json:"updated"`
+}
+
+// Score returns the smoothed acceptance rate, (Accepted+1)/(Accepted+Rejected+2):
+// 0.5 for an unknown domain, approaching 0 for one whose images are always
+// rejected.
+func (s DomainStats) Score() float64 {
+	return (s.Accepted + 1) / (s.Accepted + s.Rejected + 2)
+}
+
+// decayed returns s with its counts aged to now.
+func (s DomainStats) decayed(now time.Time, halfLife time.Duration) DomainStats {
+	if s.Updated.IsZero() || !now.After(s.Updated) {
+		return s
+	}
+	f := math.Exp2(-float64(now.Sub(s.Updated)) / float64(halfLife))
+	s.Accepted *= f
+	s.Rejected *= f
+	s.Updated = now
+	return s
+}
+
+func (r *DomainReputation) halfLife() time.Duration {
+	if r.HalfLife <= 0 {
+		return defaultReputationHalfLife
+	}
+	return r.HalfLife
+}
+
+func (r *DomainReputation) minSamples() float64 {
+	if r.MinSamples <= 0 {
+		return defaultReputationMinSamples
+	}
+	return r.MinSamples
+}
+
+func (r *DomainReputation) demoteBelow() float64 {
+	if r.DemoteBelow == 0 {
+		return defaultReputationDemote
+	}
+	return r.DemoteBelow
+}
+
+// DomainStats returns the recorded validation statistics of domain (a
+// source host, as in CandidateEvent reporting), decayed to now, or false if
+// none are recorded or Config.Reputation or Config.Cache is unset.
+func (cfg *Config) DomainStats(ctx context.Context, domain string) (DomainStats, bool) {
+	cfg = cfg.orZero()
+	if cfg.Reputation == nil || cfg.Cache == nil || domain == "" {
+		return DomainStats{}, false
+	}
+	var s DomainStats
+	if !cfg.Cache.Get(ctx, cfg.Cache.Key(reputationCachePrefix, domain), &s) {
+		return DomainStats{}, false
+	}
+	return s.decayed(time.Now(), cfg.Reputation.halfLife()), true
+}
+
+// reputationTally is the domain reputation state of one validateCandidates
+// call: the stats loaded for its domains and the outcomes recorded since.
+type reputationTally struct {
+	cfg *Config
+
+	mu    sync.Mutex
+	stats map[string]DomainStats
+	delta map[string]*[2]float64 // accepted, rejected
+}
+
+// loadReputation reads the stats of every source domain among candidates,
+// or returns nil when domain reputation is off.
+func (cfg *Config) loadReputation(ctx context.Context, candidates []ImageCandidate) *reputationTally {
+	if cfg.Reputation == nil || cfg.Cache == nil {
+		return nil
+	}
+	t := &reputationTally{cfg: cfg, stats: make(map[string]DomainStats), delta: make(map[string]*[2]float64)}
+	for _, c := range candidates {
+		domain := sourceKey(c)
+		if _, ok := t.stats[domain]; ok || domain == "" {
+			continue
+		}
+		s, _ := cfg.DomainStats(ctx, domain)
+		s.Domain = domain
+		t.stats[domain] = s
+	}
+	return t
+}
+
+// trusted returns the score of domain if it has enough samples.
+func (t *reputationTally) trusted(domain string) (float64, bool) {
+	s, ok := t.stats[domain]
+	if !ok || s.Accepted+s.Rejected < t.cfg.Reputation.minSamples() {
+		return 0, false
+	}
+	return s.Score(), true
+}
+
+// order drops candidates of domains below DomainReputation.SkipBelow,
+// reporting them as ReasonPoorReputation at StageDomain, and moves those
+// below DemoteBelow to the end, keeping the relative order of both groups.
+func (t *reputationTally) order(candidates []ImageCandidate) []ImageCandidate {
+	if t == nil {
+		return candidates
+	}
+	rep := t.cfg.Reputation
+	out := candidates[:0:0]
+	var demoted []ImageCandidate
+	for _, c := range candidates {
+		score, ok := t.trusted(sourceKey(c))
+		switch {
+		case ok && score < rep.SkipBelow:
+			slog.Debug("imagefy: domain skipped by reputation", "url", c.ImgURL, "domain", sourceKey(c), "score", score)
+			t.cfg.emitCandidate(CandidateEvent{Candidate: c, Stage: StageDomain, Reason: ReasonPoorReputation})
+		case ok && score < rep.demoteBelow():
+			demoted = append(demoted, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return append(out, demoted...)
+}
+
+// record counts the outcome of a validated candidate. Rejections that do
+// not reflect on the domain (limits, timeouts, query mismatches) are
+// ignored.
+func (t *reputationTally) record(e CandidateEvent) {
+	if t == nil || (e.Reason != "" && !reputationReasons[e.Reason]) {
+		return
+	}
+	domain := sourceKey(e.Candidate)
+	if domain == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	d := t.delta[domain]
+	if d == nil {
+		d = new([2]float64)
+		t.delta[domain] = d
+	}
+	if e.Reason == "" {
+		d[0]++
+	} else {
+		d[1]++
+	}
+}
+
+// flush adds the recorded outcomes to the stored stats. It runs even after
+// ctx is done, so a search that hit its deadline still contributes.
+func (t *reputationTally) flush(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	now := time.Now()
+	halfLife := t.cfg.Reputation.halfLife()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for domain, d := range t.delta {
+		s, ok := t.cfg.DomainStats(ctx, domain) // re-read: another search may have written since
+		if !ok {
+			s = t.stats[domain]
+		}
+		s = s.decayed(now, halfLife)
+		s.Domain, s.Updated = domain, now
+		s.Accepted += d[0]
+		s.Rejected += d[1]
+		t.cfg.Cache.Set(ctx, t.cfg.Cache.Key(reputationCachePrefix, domain), s)
+	}
+}
@@ -0,0 +1,111 @@
+package imagefy
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDomainStats_ScoreAndDecay(t *testing.T) {
+	t.Parallel()
+
+	if got := (DomainStats{}).Score(); got != 0.5 {
+		t.Errorf("unknown domain score = %v, want 0.5", got)
+	}
+	now := time.Now()
+	s := DomainStats{Accepted: 2, Rejected: 8, Updated: now.Add(-time.Hour)}
+	if got := s.Score(); math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("score = %v, want 0.25", got)
+	}
+	d := s.decayed(now, time.Hour)
+	if math.Abs(d.Accepted-1) > 1e-9 || math.Abs(d.Rejected-4) > 1e-9 || !d.Updated.Equal(now) {
+		t.Errorf("decayed one half-life = %+v, want counts halved", d)
+	}
+}
+
+func seedReputation(cfg *Config, domain string, s DomainStats) {
+	s.Domain, s.Updated = domain, time.Now()
+	cfg.Cache.Set(context.Background(), cfg.Cache.Key(reputationCachePrefix, domain), s)
+}
+
+func TestReputation_OrderDemotesAndSkips(t *testing.T) {
+	t.Parallel()
+
+	var rejected []CandidateEvent
+	cfg := &Config{
+		Cache:               &jsonCache{},
+		Reputation:          &DomainReputation{SkipBelow: 0.1},
+		OnCandidateRejected: func(e CandidateEvent) { rejected = append(rejected, e) },
+	}
+	seedReputation(cfg, "awful.example", DomainStats{Rejected: 20})            // score 1/22
+	seedReputation(cfg, "poor.example", DomainStats{Accepted: 1, Rejected: 9}) // score 2/12
+	seedReputation(cfg, "new.example", DomainStats{Rejected: 2})               // below MinSamples
+	cands := []ImageCandidate{
+		{ImgURL: "https://cdn.example/1.jpg", Source: "https://poor.example/a"},
+		{ImgURL: "https://cdn.example/2.jpg", Source: "https://awful.example/a"},
+		{ImgURL: "https://cdn.example/3.jpg", Source: "https://new.example/a"},
+		{ImgURL: "https://cdn.example/4.jpg", Source: "https://good.example/a"},
+	}
+
+	out := cfg.loadReputation(context.Background(), cands).order(cands)
+	var got []string
+	for _, c := range out {
+		got = append(got, c.ImgURL)
+	}
+	want := []string{"https://cdn.example/3.jpg", "https://cdn.example/4.jpg", "https://cdn.example/1.jpg"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if len(rejected) != 1 || rejected[0].Reason != ReasonPoorReputation || rejected[0].Stage != StageDomain || rejected[0].Candidate.ImgURL != cands[1].ImgURL {
+		t.Errorf("rejected = %+v, want the awful.example candidate as poor_reputation", rejected)
+	}
+}
+
+func TestReputation_RecordsOutcomes(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultiImageServer(t, map[string][]byte{
+		"/wide.jpg":   makeJPEG(1000, 600),
+		"/narrow.jpg": makeJPEG(300, 200),
+	})
+	cfg := &Config{Cache: &jsonCache{}, Reputation: &DomainReputation{}, MinImageWidth: 800}
+	seedReputation(cfg, "bad.example", DomainStats{Rejected: 1})
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/wide.jpg", Source: "https://good.example/a", License: LicenseSafe},
+		{ImgURL: srv.URL + "/narrow.jpg", Source: "https://bad.example/a", License: LicenseSafe},
+	}
+	if got := cfg.ValidateCandidates(context.Background(), cands, 5); len(got) != 1 {
+		t.Fatalf("accepted %d, want 1", len(got))
+	}
+
+	good, ok := cfg.DomainStats(context.Background(), "good.example")
+	if !ok || math.Round(good.Accepted) != 1 || good.Rejected != 0 {
+		t.Errorf("good.example = %+v, %v; want 1 accepted", good, ok)
+	}
+	bad, ok := cfg.DomainStats(context.Background(), "bad.example")
+	if !ok || math.Round(bad.Rejected) != 2 || bad.Accepted != 0 {
+		t.Errorf("bad.example = %+v, %v; want 2 rejected (seeded + too_narrow)", bad, ok)
+	}
+
+	// Limits and timeouts say nothing about the domain.
+	tally := cfg.loadReputation(context.Background(), cands)
+	tally.record(CandidateEvent{Candidate: cands[0], Reason: ReasonMaxResults})
+	tally.record(CandidateEvent{Candidate: cands[0], Reason: ReasonTimeout})
+	if len(tally.delta) != 0 {
+		t.Errorf("recorded %v for non-domain rejections", tally.delta)
+	}
+}
+
+func TestReputation_OffWithoutCache(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Reputation: &DomainReputation{SkipBelow: 1}}
+	cands := []ImageCandidate{{ImgURL: "https://cdn.example/1.jpg"}}
+	if tally := cfg.loadReputation(context.Background(), cands); tally != nil {
+		t.Errorf("loadReputation without Cache = %+v, want nil", tally)
+	}
+	if _, ok := cfg.DomainStats(context.Background(), "cdn.example"); ok {
+		t.Error("DomainStats without Cache reported stats")
+	}
+}
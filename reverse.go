@@ -44,8 +44,16 @@ type reverseResponse struct {
 // appears on stock photo sites. Returns a zero ReverseResult if disabled
 // (OxBrowserURL empty) or on any error (graceful degradation).
 func (cfg *Config) ReverseCheck(ctx context.Context, imageURL string) ReverseResult {
+	cfg = cfg.orZero()
+	result, _ := cfg.reverseCheck(ctx, imageURL)
+	return result
+}
+
+// reverseCheck implements ReverseCheck, also returning the error behind a
+// zero result. Disabled reverse search is not an error.
+func (cfg *Config) reverseCheck(ctx context.Context, imageURL string) (ReverseResult, error) {
 	if cfg.OxBrowserURL == "" {
-		return ReverseResult{}
+		return ReverseResult{}, nil
 	}
 
 	payload, err := json.Marshal(reverseRequest{
@@ -53,7 +61,7 @@ func (cfg *Config) ReverseCheck(ctx context.Context, imageURL string) ReverseRes
 		MaxResults: reverseMaxResults,
 	})
 	if err != nil {
-		return ReverseResult{}
+		return ReverseResult{}, err
 	}
 
 	// Enforce timeout to prevent blocking pipeline goroutines when ox-browser is slow/down.
@@ -63,7 +71,7 @@ func (cfg *Config) ReverseCheck(ctx context.Context, imageURL string) ReverseRes
 	endpoint := strings.TrimRight(cfg.OxBrowserURL, "/") + "/images/reverse"
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
 	if err != nil {
-		return ReverseResult{}
+		return ReverseResult{}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
@@ -75,24 +83,24 @@ func (cfg *Config) ReverseCheck(ctx context.Context, imageURL string) ReverseRes
 	resp, err := client.Do(req)
 	if err != nil {
 		slog.Debug("imagefy: reverse check failed", "url", imageURL, "error", err)
-		return ReverseResult{}
+		return ReverseResult{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		slog.Debug("imagefy: reverse check bad status", "url", imageURL, "status", resp.StatusCode)
-		return ReverseResult{}
+		return ReverseResult{}, fmt.Errorf("imagefy: reverse check: status %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, reverseBodyLimit))
 	if err != nil {
-		return ReverseResult{}
+		return ReverseResult{}, err
 	}
 
 	var result reverseResponse
 	if err := json.Unmarshal(body, &result); err != nil {
 		slog.Debug("imagefy: reverse check parse error", "url", imageURL, "error", err)
-		return ReverseResult{}
+		return ReverseResult{}, err
 	}
 
 	if result.IsStock {
@@ -105,5 +113,5 @@ func (cfg *Config) ReverseCheck(ctx context.Context, imageURL string) ReverseRes
 	return ReverseResult{
 		IsStock:      result.IsStock,
 		StockDomains: result.StockDomains,
-	}
+	}, nil
 }
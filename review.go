@@ -0,0 +1,184 @@
+package imagefy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	reviewManifest = "manifest.json"  // file listing every ReviewItem of a bundle
+	reviewMaxBytes = 10 * 1024 * 1024 // 10MB full-size preview
+)
+
+// ReviewItem describes one accepted candidate in a review bundle. It is
+// written as a JSON sidecar next to the candidate's preview image and
+// collected in manifest.json.
+type ReviewItem struct {
+	Candidate   ImageCandidate    `json:"candidate"`
+	License     LicenseAssessment `json:"license"`
+	Creator     string            `json:"creator,omitempty"` // IPTC by-line, EXIF artist, or DC creator
+	Credit      string            `json:"credit,omitempty"`  // IPTC credit or copyright notice
+	Attribution string            `json:"attribution"`       // ready-to-print credit line
+	Preview     string            `json:"preview,omitempty"` // preview file name ("" = download failed)
+	Sidecar     string            `json:"sidecar"`           // sidecar file name
+}
+
+// ExportReview writes a review bundle for candidates into dir, creating it
+// if needed: for the i-th candidate a preview image "001.jpg" (extension by
+// content type) and a JSON sidecar "001.json", plus manifest.json listing
+// all items, so editors can approve a batch visually before publishing.
+//
+// Each candidate's ImgURL (or Thumbnail if that fails) is downloaded, up to
+// 10MB and 3 at a time, for the preview and its metadata. A failed download leaves
+// the item without a preview and with domain-only license signals. Only
+// filesystem errors are returned.
+func (cfg *Config) ExportReview(ctx context.Context, dir string, candidates []ImageCandidate) ([]ReviewItem, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:mnd // rwxr-xr-x
+		return nil, fmt.Errorf("imagefy: create review dir: %w", err)
+	}
+	return cfg.exportReview(ctx, candidates, func(name string, data []byte) error {
+		return os.WriteFile(filepath.Join(dir, name), data, 0o644) //nolint:mnd,gosec // rw-r--r--, review files are not secret
+	})
+}
+
+// ExportReviewZip writes the bundle of ExportReview as a zip archive to w.
+func (cfg *Config) ExportReviewZip(ctx context.Context, w io.Writer, candidates []ImageCandidate) ([]ReviewItem, error) {
+	zw := zip.NewWriter(w)
+	items, err := cfg.exportReview(ctx, candidates, func(name string, data []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("imagefy: write review zip: %w", err)
+	}
+	return items, nil
+}
+
+// exportReview builds the review items and passes every bundle file to put.
+func (cfg *Config) exportReview(ctx context.Context, candidates []ImageCandidate, put func(name string, data []byte) error) ([]ReviewItem, error) {
+	cfg = cfg.orZero()
+	cfg.defaults()
+
+	items := make([]ReviewItem, len(candidates))
+	previews := make([]*DownloadResult, len(candidates))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, validationSemaphore)
+	for i, c := range candidates {
+		wg.Add(1)
+		go func(i int, c ImageCandidate) {
+			defer wg.Done()
+			if acquire(ctx, sem) {
+				defer func() { <-sem }()
+			} // else past the deadline: the item is built without a preview
+			items[i], previews[i] = cfg.reviewItem(ctx, i, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i := range items {
+		if previews[i] != nil {
+			if err := put(items[i].Preview, previews[i].Data); err != nil {
+				return nil, fmt.Errorf("imagefy: write review preview: %w", err)
+			}
+		}
+		sidecar, err := json.MarshalIndent(items[i], "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("imagefy: encode review item: %w", err)
+		}
+		if err := put(items[i].Sidecar, sidecar); err != nil {
+			return nil, fmt.Errorf("imagefy: write review sidecar: %w", err)
+		}
+	}
+	manifest, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("imagefy: encode review manifest: %w", err)
+	}
+	if err := put(reviewManifest, manifest); err != nil {
+		return nil, fmt.Errorf("imagefy: write review manifest: %w", err)
+	}
+	return items, nil
+}
+
+// reviewItem downloads the preview of the i-th candidate and assembles its
+// ReviewItem.
+func (cfg *Config) reviewItem(ctx context.Context, i int, c ImageCandidate) (ReviewItem, *DownloadResult) {
+	base := fmt.Sprintf("%03d", i+1)
+	item := ReviewItem{Candidate: c, Sidecar: base + ".json"}
+
+	var preview *DownloadResult
+	for _, u := range []string{c.ImgURL, c.Thumbnail} {
+		if u == "" {
+			continue
+		}
+		if r, err := cfg.Download(ctx, u, DownloadOpts{MaxBytes: reviewMaxBytes}); err == nil && r != nil {
+			preview = r
+			break
+		}
+	}
+
+	var meta *ImageMetadata
+	if preview != nil {
+		item.Preview = base + previewExt(preview.MIMEType)
+		meta = cfg.imageMetadata(ctx, preview.Data)
+	}
+	item.License = cfg.AssessLicense(c, meta)
+	if meta != nil {
+		item.Creator = firstNonEmpty(meta.IPTCByline, meta.EXIFArtist, meta.DCCreator)
+		item.Credit = firstNonEmpty(meta.IPTCCredit, meta.IPTCCopyright, meta.EXIFCopyright, meta.DCRights)
+	}
+	item.Attribution = attributionLine(item.Creator, c)
+	return item, preview
+}
+
+// attributionLine formats a credit line such as
+// "Photo: Jane Doe, via commons.wikimedia.org".
+func attributionLine(creator string, c ImageCandidate) string {
+	host := extractHost(c.Source)
+	if host == "" {
+		host = extractHost(c.ImgURL)
+	}
+	switch {
+	case creator != "" && host != "":
+		return "Photo: " + creator + ", via " + host
+	case creator != "":
+		return "Photo: " + creator
+	case host != "":
+		return "Via " + host
+	}
+	return ""
+}
+
+// previewExt returns the file extension for an image content type.
+func previewExt(mimeType string) string {
+	switch strings.TrimPrefix(mimeType, "image/") {
+	case "jpeg":
+		return ".jpg"
+	case "png", "gif", "webp", "avif":
+		return "." + strings.TrimPrefix(mimeType, "image/")
+	}
+	return ".img"
+}
+
+// firstNonEmpty returns the first non-empty, trimmed value.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package imagefy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportReview_Dir(t *testing.T) {
+	t.Parallel()
+
+	img := makeJPEG(1000, 600)
+	srv := newMultiImageServer(t, map[string][]byte{"/a.jpg": img})
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/a.jpg", Source: "https://commons.wikimedia.org/wiki/File:A.jpg", License: LicenseSafe, Relevance: 0.8},
+		{ImgURL: srv.URL + "/missing.jpg", Source: srv.URL + "/b", License: LicenseUnknown},
+	}
+	dir := filepath.Join(t.TempDir(), "review")
+
+	items, err := (&Config{HTTPClient: srv.Client()}).ExportReview(context.Background(), dir, cands)
+	if err != nil {
+		t.Fatalf("ExportReview: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %d, want 2", len(items))
+	}
+	if items[0].Preview != "001.jpg" || items[0].Sidecar != "001.json" {
+		t.Errorf("item 0 files = %q, %q", items[0].Preview, items[0].Sidecar)
+	}
+	if items[0].Attribution != "Via commons.wikimedia.org" {
+		t.Errorf("attribution = %q", items[0].Attribution)
+	}
+	if items[1].Preview != "" {
+		t.Errorf("failed download got preview %q", items[1].Preview)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "001.jpg"))
+	if err != nil || !bytes.Equal(data, img) {
+		t.Errorf("preview not written intact: %v", err)
+	}
+	var sidecar ReviewItem
+	mustReadJSON(t, filepath.Join(dir, "001.json"), &sidecar)
+	if sidecar.Candidate.Relevance != 0.8 || len(sidecar.License.Signals) == 0 {
+		t.Errorf("sidecar = %+v, want relevance and license signals", sidecar)
+	}
+	var manifest []ReviewItem
+	mustReadJSON(t, filepath.Join(dir, "manifest.json"), &manifest)
+	if len(manifest) != 2 || manifest[1].Sidecar != "002.json" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "002.jpg")); !os.IsNotExist(err) {
+		t.Errorf("preview written for failed download: %v", err)
+	}
+}
+
+func TestExportReviewZip(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultiImageServer(t, map[string][]byte{"/a.jpg": makeJPEG(1000, 600)})
+	var buf bytes.Buffer
+	_, err := (&Config{HTTPClient: srv.Client()}).ExportReviewZip(context.Background(), &buf,
+		[]ImageCandidate{{ImgURL: srv.URL + "/a.jpg", Source: srv.URL + "/a"}})
+	if err != nil {
+		t.Fatalf("ExportReviewZip: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want := []string{"001.jpg", "001.json", "manifest.json"}
+	if len(names) != len(want) {
+		t.Fatalf("zip files = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("zip files = %v, want %v", names, want)
+		}
+	}
+}
+
+func TestAttributionLine(t *testing.T) {
+	t.Parallel()
+
+	c := ImageCandidate{ImgURL: "https://cdn.example.com/a.jpg", Source: "https://news.example.org/story"}
+	tests := []struct {
+		creator string
+		cand    ImageCandidate
+		want    string
+	}{
+		{"Jane Doe", c, "Photo: Jane Doe, via news.example.org"},
+		{"", c, "Via news.example.org"},
+		{"", ImageCandidate{ImgURL: c.ImgURL}, "Via cdn.example.com"},
+		{"Jane Doe", ImageCandidate{}, "Photo: Jane Doe"},
+		{"", ImageCandidate{}, ""},
+	}
+	for _, tt := range tests {
+		if got := attributionLine(tt.creator, tt.cand); got != tt.want {
+			t.Errorf("attributionLine(%q, %+v) = %q, want %q", tt.creator, tt.cand, got, tt.want)
+		}
+	}
+}
+
+func mustReadJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+}
@@ -0,0 +1,105 @@
+package imagefy
+
+import (
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// httpsHosts are image hosts known to serve every URL over HTTPS, so their
+// http:// links are upgraded by sanitizeResults. A subdomain matches.
+var httpsHosts = []string{
+	"wikimedia.org", "wikipedia.org", "staticflickr.com", "googleusercontent.com",
+	"gstatic.com", "unsplash.com", "pexels.com", "pixabay.com", "wp.com",
+	"blogspot.com", "imgur.com", "twimg.com", "fbcdn.net", "cdninstagram.com",
+	"ytimg.com", "yandex.net", "userapi.com", "cloudfront.net",
+}
+
+// sanitizeResults cleans the URLs of a provider's results in place and
+// drops those whose ImgURL is unusable, so malformed values never reach
+// Download. For each candidate:
+//
+//   - whitespace around and line breaks inside URLs are removed;
+//   - protocol-relative ("//host/a.jpg") and root-relative ("/a.jpg") URLs
+//     are resolved against Source, or https when Source is unusable;
+//   - anything but an absolute http(s) URL with a host (data:, javascript:,
+//     blob:, ...) is cleared, and a candidate without an ImgURL dropped;
+//   - http:// image and thumbnail URLs are upgraded to https:// when the
+//     host supports it: it is one of httpsHosts, or the same host served
+//     Source over https.
+func sanitizeResults(results []ImageCandidate, provider string) []ImageCandidate {
+	out := results[:0]
+	for _, c := range results {
+		c.Source = sanitizeURL(c.Source, "")
+		c.ImgURL = preferHTTPS(sanitizeURL(c.ImgURL, c.Source), c.Source)
+		if c.ImgURL == "" {
+			slog.Debug("imagefy: dropping result with unusable image URL", "provider", provider, "source", c.Source)
+			continue
+		}
+		c.Thumbnail = preferHTTPS(sanitizeURL(c.Thumbnail, c.Source), c.Source)
+		out = append(out, c)
+	}
+	return out
+}
+
+// sanitizeURL returns raw as an absolute http(s) URL, resolving a relative
+// one against base, or "" if it is not usable.
+func sanitizeURL(raw, base string) string {
+	raw = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(raw))
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if !u.IsAbs() {
+		b, err := url.Parse(base)
+		switch {
+		case err == nil && isHTTPScheme(b.Scheme) && b.Host != "":
+			u = b.ResolveReference(u)
+		case strings.HasPrefix(raw, "//"):
+			u.Scheme = "https"
+		default:
+			return ""
+		}
+	}
+	if !isHTTPScheme(u.Scheme) || u.Host == "" {
+		return ""
+	}
+	return u.String()
+}
+
+// isHTTPScheme reports whether a url.Parse scheme (always lower case) is
+// http or https.
+func isHTTPScheme(scheme string) bool {
+	return scheme == "http" || scheme == "https"
+}
+
+// preferHTTPS upgrades an http:// rawURL without an explicit port to
+// https:// when its host supports HTTPS (see sanitizeResults).
+func preferHTTPS(rawURL, source string) string {
+	rest, ok := strings.CutPrefix(rawURL, "http://")
+	if !ok {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Port() != "" {
+		return rawURL
+	}
+	host := extractHost(rawURL)
+	if strings.HasPrefix(source, "https://") && extractHost(source) == host {
+		return "https://" + rest
+	}
+	for _, h := range httpsHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return "https://" + rest
+		}
+	}
+	return rawURL
+}
@@ -0,0 +1,79 @@
+package imagefy
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSanitizeURL(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct{ raw, base, want string }{
+		{"  https://cdn.example.com/a.jpg\n", "", "https://cdn.example.com/a.jpg"},
+		{"https://cdn.example.com/photos/\n\tcat.jpg", "", "https://cdn.example.com/photos/cat.jpg"},
+		{"//cdn.example.com/a.jpg", "", "https://cdn.example.com/a.jpg"},
+		{"//cdn.example.com/a.jpg", "http://news.example.com/story", "http://cdn.example.com/a.jpg"},
+		{"/img/a.jpg", "https://news.example.com/story/1", "https://news.example.com/img/a.jpg"},
+		{"/img/a.jpg", "", ""},
+		{"HTTPS://cdn.example.com/a.jpg", "", "https://cdn.example.com/a.jpg"},
+		{"data:image/png;base64,iVBORw0KGgo=", "https://news.example.com/", ""},
+		{"javascript:alert(1)", "https://news.example.com/", ""},
+		{"ftp://files.example.com/a.jpg", "", ""},
+		{"https:///a.jpg", "", ""},
+		{"", "https://news.example.com/", ""},
+	}
+	for _, tc := range cases {
+		if got := sanitizeURL(tc.raw, tc.base); got != tc.want {
+			t.Errorf("sanitizeURL(%q, %q) = %q, want %q", tc.raw, tc.base, got, tc.want)
+		}
+	}
+}
+
+func TestPreferHTTPS(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct{ raw, source, want string }{
+		{"http://upload.wikimedia.org/a.jpg", "", "https://upload.wikimedia.org/a.jpg"},
+		{"http://news.example.com/a.jpg", "https://news.example.com/story", "https://news.example.com/a.jpg"},
+		{"http://news.example.com/a.jpg", "http://news.example.com/story", "http://news.example.com/a.jpg"},
+		{"http://cdn.example.com/a.jpg", "https://news.example.com/story", "http://cdn.example.com/a.jpg"},
+		{"http://upload.wikimedia.org:8080/a.jpg", "", "http://upload.wikimedia.org:8080/a.jpg"},
+		{"https://cdn.example.com/a.jpg", "", "https://cdn.example.com/a.jpg"},
+	}
+	for _, tc := range cases {
+		if got := preferHTTPS(tc.raw, tc.source); got != tc.want {
+			t.Errorf("preferHTTPS(%q, %q) = %q, want %q", tc.raw, tc.source, got, tc.want)
+		}
+	}
+}
+
+func TestGatherCandidates_SanitizesResults(t *testing.T) {
+	t.Parallel()
+
+	p := &mockProvider{name: "mock", candidates: []ImageCandidate{
+		{ImgURL: "data:image/gif;base64,R0lGODlhAQABAAAAACw=", Source: "https://news.example.com/story"},
+		{ImgURL: " //cdn.example.com/a.jpg ", Thumbnail: "javascript:void(0)", Source: "https://news.example.com/story"},
+		{ImgURL: "/img/b.jpg", Thumbnail: "/img/b_thumb.jpg", Source: "https://news.example.com/story"},
+		{ImgURL: "http://upload.wikimedia.org/c.jpg", Source: "javascript:void(0)"},
+	}}
+	cfg := &Config{}
+	got := cfg.gatherCandidates(context.Background(), []SearchProvider{p}, "q", SearchOpts{}, newSearchState())
+
+	want := []ImageCandidate{
+		{ImgURL: "https://cdn.example.com/a.jpg", Source: "https://news.example.com/story", Rank: 2},
+		{ImgURL: "https://news.example.com/img/b.jpg", Thumbnail: "https://news.example.com/img/b_thumb.jpg", Source: "https://news.example.com/story", Rank: 3},
+		{ImgURL: "https://upload.wikimedia.org/c.jpg", Rank: 4},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d candidates, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.ImgURL != w.ImgURL || g.Thumbnail != w.Thumbnail || g.Source != w.Source || g.Rank != w.Rank {
+			t.Errorf("candidate %d = %+v, want %+v", i, g, w)
+		}
+	}
+	if p.candidates[1].ImgURL != " //cdn.example.com/a.jpg " {
+		t.Error("provider slice was modified")
+	}
+}
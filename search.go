@@ -3,7 +3,6 @@ package imagefy
 import (
 	"context"
 	"log/slog"
-	"sort"
 	"sync"
 	"time"
 )
@@ -12,19 +11,53 @@ const searchTimeout = 30 * time.Second
 
 // ImageCandidate holds an image result with metadata.
 type ImageCandidate struct {
-	ImgURL    string       // direct image URL
-	Thumbnail string       // thumbnail URL
-	Source    string       // page URL
-	Title     string       // image/page title
-	License   ImageLicense // license classification
-	Width     int          // image width (0 if unknown)
-	Height    int          // image height (0 if unknown)
-	Engine    string       // search engine name
+	ImgURL      string       // direct image URL
+	Thumbnail   string       // thumbnail URL
+	Source      string       // page URL
+	Title       string       // image/page title
+	License     ImageLicense // license classification
+	LicenseName string       // license reported by the provider, e.g. "CC BY-SA 4.0" ("" = none)
+	Author      string       // creator to credit, as reported by the provider ("" = unknown)
+	Width       int          // image width (0 if unknown)
+	Height      int          // image height (0 if unknown)
+	Engine      string       // search engine name
+	Provider    string       // SearchProvider that produced the candidate ("" = external)
+	Page        int          // provider results page, 1-based (0 = unknown)
+	Rank        int          // 1-based position in the provider's results (0 = unknown)
+	Relevance   float64      // query-image embedding similarity, 0–1 (0 = not scored; see Config.Embedder)
+	Degraded    Stage        // first failed check under DegradeMarkUnknown ("" = none)
+
+	// LastModified is when the image was last modified, from the download
+	// response's Last-Modified header or, without one, its Age header (the
+	// time a cache stored it; the image is at least that old). Zero when
+	// unknown. Set on accepted candidates; see Config.MaxImageAge.
+	LastModified time.Time
+
+	// SignedURL is a pre-signed, expiring URL of the image in the caller's
+	// storage, valid until SignedURLExpires. Set by worker.StorageSink when
+	// it has a Signer; "" otherwise.
+	SignedURL        string
+	SignedURLExpires time.Time
+
+	// raw is a built-in provider's JSON for this result, kept with
+	// SearchOpts.KeepRaw and reported as CandidateEvent.Raw. A string, so
+	// ImageCandidate stays comparable.
+	raw string
+
+	// referer is sent as the Referer of the probe and download after
+	// hotlinkFallback; "" = none.
+	referer string
 }
 
 // SearchImages queries configured search providers for images and returns up to maxResults validated candidates.
 // Stock photo results (LicenseBlocked) are removed entirely.
 // Results are sorted with LicenseSafe first, then LicenseUnknown.
+//
+// If ctx is canceled or the search deadline passes mid-search, the candidates
+// fully validated until then are returned; candidates still in flight are
+// rejected with ReasonCanceled and those still waiting for a validation slot
+// with ReasonSlotTimeout (ReasonCanceled on cancellation), never accepted
+// unchecked. SearchImagesResult reports such a result as Partial.
 func (cfg *Config) SearchImages(ctx context.Context, query string, maxResults int) []ImageCandidate {
 	return cfg.SearchImagesWithOpts(ctx, query, maxResults, SearchOpts{})
 }
@@ -32,37 +65,86 @@ func (cfg *Config) SearchImages(ctx context.Context, query string, maxResults in
 // SearchImagesWithOpts is like SearchImages but accepts SearchOpts for pagination,
 // engine selection and custom timeout.
 func (cfg *Config) SearchImagesWithOpts(ctx context.Context, query string, maxResults int, opts SearchOpts) []ImageCandidate {
+	cfg = cfg.orZero()
+	return cfg.searchImages(ctx, query, maxResults, opts, newSearchState())
+}
+
+// searchImages implements SearchImagesWithOpts on top of st, which carries
+// dedup hashes and (for a Session) state shared across calls.
+func (cfg *Config) searchImages(ctx context.Context, query string, maxResults int, opts SearchOpts, st *searchState) []ImageCandidate {
 	if query == "" {
 		return nil
 	}
 
-	cfg.defaults()
+	cfg, query, ok := cfg.prepareSearch(ctx, query, opts)
+	if !ok {
+		return nil
+	}
+	defer cfg.reportBytes()
 
-	if cfg.OnImageSearch != nil {
-		cfg.OnImageSearch()
+	providers := cfg.resolveProviders()
+	if len(providers) == 0 && cfg.requireDependency(ErrNoProviders) != nil {
+		return nil
 	}
 
-	timeout := searchTimeout
-	if opts.Timeout > 0 {
-		timeout = opts.Timeout
+	if cfg.OnImageSearch != nil {
+		cfg.OnImageSearch()
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	searchCtx, cancel := context.WithTimeout(ctx, opts.phaseTimeout(opts.SearchTimeout))
 	defer cancel()
 
-	providers := cfg.resolveProviders()
-	candidates := cfg.gatherCandidates(ctx, providers, query, opts)
+	candidates := cfg.gatherCandidates(searchCtx, providers, query, opts, st)
 
 	if len(candidates) == 0 {
 		return nil
 	}
 
-	// Sort: safe sources first, then unknown.
-	sort.SliceStable(candidates, func(i, j int) bool {
-		return candidates[i].License < candidates[j].License
-	})
+	cfg.prefetchDNS(searchCtx, candidates)
+
+	// Order: safe sources first, then unknown (or interleaved per opts.Interleave).
+	candidates = orderCandidates(cfg.boostSources(candidates), opts)
+
+	validationCtx := searchCtx // one deadline for both phases
+	if opts.SearchTimeout > 0 || opts.ValidationTimeout > 0 {
+		var cancelValidation context.CancelFunc
+		validationCtx, cancelValidation = context.WithTimeout(ctx, opts.phaseTimeout(opts.ValidationTimeout))
+		defer cancelValidation()
+	}
 
-	return cfg.validateCandidates(ctx, candidates, maxResults)
+	if opts.PickBest {
+		st = st.withPreviews()
+	}
+	validated := cfg.validateCandidates(validationCtx, candidates, maxResults, opts, st)
+	if opts.PickBest {
+		validated = cfg.promoteBest(validationCtx, query, validated, st.previews)
+	}
+	return validated
+}
+
+// prepareSearch returns the derived Config a search for query runs with and
+// the query after Config.QueryModerator, or false if moderation blocked it.
+func (cfg *Config) prepareSearch(ctx context.Context, query string, opts SearchOpts) (*Config, string, bool) {
+	cfg.defaults()
+	cfg = cfg.withVariant(query).withLanguage(opts.Language).withTitleLanguage(opts.RequireTitleLanguage).withRequiredLabels(opts.RequireLabels)
+
+	query, ok := cfg.moderateQuery(ctx, query)
+	if !ok {
+		return nil, "", false
+	}
+	cfg = cfg.withQueryEmbedding(ctx, query).withTitleMatch(query, opts.MinTitleMatch).withByteMeter(opts.MaxTotalBytes)
+	return cfg, query, true
+}
+
+// phaseTimeout returns d if positive, otherwise Timeout or the default.
+func (opts SearchOpts) phaseTimeout(d time.Duration) time.Duration {
+	switch {
+	case d > 0:
+		return d
+	case opts.Timeout > 0:
+		return opts.Timeout
+	}
+	return searchTimeout
 }
 
 // resolveProviders returns the effective provider list.
@@ -84,20 +166,30 @@ func (cfg *Config) resolveProviders() []SearchProvider {
 
 // gatherCandidates collects image candidates from all providers in parallel.
 // Each provider runs in its own goroutine; errors are logged and skipped so
-// that remaining providers still contribute results.
-func (cfg *Config) gatherCandidates(ctx context.Context, providers []SearchProvider, query string, opts SearchOpts) []ImageCandidate {
+// that remaining providers still contribute results. Providers that st marks
+// as unhealthy are skipped. Engines are chosen per Config.EnginesByScript,
+// and result URLs are cleaned by sanitizeResults.
+func (cfg *Config) gatherCandidates(ctx context.Context, providers []SearchProvider, query string, opts SearchOpts, st *searchState) []ImageCandidate {
+	opts = cfg.withEngines(query, opts)
+	ctx = withBlockPolicy(ctx, cfg.BlockPolicy)
 	var mu sync.Mutex
 	var all []ImageCandidate
 	var wg sync.WaitGroup
 	for _, p := range providers {
+		if !st.health.allow(p.Name()) {
+			slog.Debug("imagefy: skipping unhealthy provider", "provider", p.Name())
+			continue
+		}
 		wg.Add(1)
 		go func(p SearchProvider) {
 			defer wg.Done()
 			results, err := p.Search(ctx, query, opts)
+			st.health.record(p.Name(), err)
 			if err != nil {
 				slog.Warn("imagefy: provider search failed", "provider", p.Name(), "error", err)
 				return
 			}
+			results = sanitizeResults(withProvenance(results, p.Name(), opts.PageNumber), p.Name())
 			mu.Lock()
 			all = append(all, results...)
 			mu.Unlock()
@@ -107,14 +199,40 @@ func (cfg *Config) gatherCandidates(ctx context.Context, providers []SearchProvi
 	return all
 }
 
+// withProvenance returns a copy of a provider's results recording on each
+// candidate which provider produced it, from which page, and at what rank, so
+// accepted candidates can be attributed. Values the provider already set are
+// kept. The provider's slice is not modified.
+func withProvenance(results []ImageCandidate, provider string, page int) []ImageCandidate {
+	if page < 1 {
+		page = 1
+	}
+	out := make([]ImageCandidate, len(results))
+	copy(out, results)
+	for i := range out {
+		c := &out[i]
+		if c.Provider == "" {
+			c.Provider = provider
+		}
+		if c.Page == 0 {
+			c.Page = page
+		}
+		if c.Rank == 0 {
+			c.Rank = i + 1
+		}
+	}
+	return out
+}
+
 // ValidateCandidates runs external image candidates through the full filter
 // pipeline: URL validation, license check, dedup, metadata assessment, and
 // LLM vision classification. Use this to validate images from sources outside
 // the built-in search providers (e.g. WP media library, user-supplied URLs).
 func (cfg *Config) ValidateCandidates(ctx context.Context, candidates []ImageCandidate, maxResults int) []ImageCandidate {
+	cfg = cfg.orZero()
 	if len(candidates) == 0 {
 		return nil
 	}
 	cfg.defaults()
-	return cfg.validateCandidates(ctx, candidates, maxResults)
+	return cfg.validateCandidates(ctx, candidates, maxResults, SearchOpts{}, newSearchState())
 }
@@ -18,6 +18,7 @@ func searxngResponse(results []map[string]string) []byte {
 		Thumbnail string `json:"thumbnail_src"`
 		URL       string `json:"url"`
 		Title     string `json:"title"`
+		Engine    string `json:"engine,omitempty"`
 	}
 	var items []resultItem
 	for _, r := range results {
@@ -26,6 +27,7 @@ func searxngResponse(results []map[string]string) []byte {
 			Thumbnail: r["thumbnail_src"],
 			URL:       r["url"],
 			Title:     r["title"],
+			Engine:    r["engine"],
 		})
 	}
 	body, _ := json.Marshal(map[string]any{"results": items})
@@ -317,7 +319,71 @@ func TestSearchImagesWithOptsZeroTimeout_UsesDefault(t *testing.T) {
 
 	results := cfg.SearchImagesWithOpts(context.Background(), "test", 5, SearchOpts{Timeout: 0})
 	if len(results) == 0 {
-		t.Error("expected at least 1 result with zero Timeout (should use default 15s)")
+		t.Error("expected at least 1 result with zero Timeout (should use default 30s)")
+	}
+}
+
+// slowProvider returns its candidates after delay.
+type slowProvider struct {
+	delay      time.Duration
+	candidates []ImageCandidate
+}
+
+func (p *slowProvider) Name() string { return "slow" }
+
+func (p *slowProvider) Search(ctx context.Context, _ string, _ SearchOpts) ([]ImageCandidate, error) {
+	select {
+	case <-time.After(p.delay):
+		return p.candidates, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestSearchImagesWithOpts_PhaseTimeouts(t *testing.T) {
+	t.Parallel()
+
+	// Every image request takes 100ms, so validation needs a few hundred.
+	img := makeJPEG(1000, 600)
+	imgSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(img)
+	}))
+	t.Cleanup(imgSrv.Close)
+
+	search := func(opts SearchOpts) []ImageCandidate {
+		cfg := &Config{
+			HTTPClient: imgSrv.Client(),
+			Providers: []SearchProvider{&slowProvider{delay: 150 * time.Millisecond, candidates: []ImageCandidate{
+				{ImgURL: imgSrv.URL + "/a.jpg", Source: imgSrv.URL + "/a", License: LicenseUnknown},
+			}}},
+		}
+		return cfg.SearchImagesWithOpts(context.Background(), "test", 1, opts)
+	}
+
+	tests := []struct {
+		name string
+		opts SearchOpts
+		want int
+	}{
+		{"shared deadline spent by providers", SearchOpts{Timeout: 200 * time.Millisecond}, 0},
+		{"separate validation budget", SearchOpts{SearchTimeout: 200 * time.Millisecond, ValidationTimeout: 5 * time.Second}, 1},
+		{"unset phase defaults to Timeout", SearchOpts{Timeout: 5 * time.Second, SearchTimeout: 200 * time.Millisecond}, 1},
+		{"validation budget too short", SearchOpts{SearchTimeout: time.Second, ValidationTimeout: 50 * time.Millisecond}, 0},
+		{"search budget too short", SearchOpts{SearchTimeout: 50 * time.Millisecond, ValidationTimeout: 5 * time.Second}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := search(tt.opts); len(got) != tt.want {
+				t.Errorf("results = %d, want %d", len(got), tt.want)
+			}
+		})
 	}
 }
 
@@ -408,3 +474,44 @@ func TestSearchImages_MetadataPassthrough(t *testing.T) {
 		t.Errorf("result ImgURL = %q, want %q", results[0].ImgURL, imgURL)
 	}
 }
+
+func TestSearchImages_Provenance(t *testing.T) {
+	t.Parallel()
+
+	imgSrv := newJPEGServer(t)
+	searxSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(searxngResponse([]map[string]string{
+			{"img_src": imgSrv.URL + "/photo.jpg", "url": imgSrv.URL + "/page", "engine": "bing"},
+		}))
+	}))
+	defer searxSrv.Close()
+
+	cfg := &Config{SearxngURL: searxSrv.URL, HTTPClient: searxSrv.Client()}
+	results := cfg.SearchImagesWithOpts(context.Background(), "forest", 5, SearchOpts{PageNumber: 2})
+	if len(results) != 1 {
+		t.Fatalf("results = %d, want 1", len(results))
+	}
+	if c := results[0]; c.Provider != "searxng" || c.Engine != "bing" || c.Page != 2 || c.Rank != 1 {
+		t.Errorf("provenance = {Provider:%q Engine:%q Page:%d Rank:%d}, want {searxng bing 2 1}", c.Provider, c.Engine, c.Page, c.Rank)
+	}
+}
+
+func TestWithProvenance(t *testing.T) {
+	t.Parallel()
+
+	in := []ImageCandidate{{ImgURL: "a"}, {ImgURL: "b", Provider: "inner", Rank: 7}}
+	got := withProvenance(in, "outer", 0)
+	want := []ImageCandidate{
+		{ImgURL: "a", Provider: "outer", Page: 1, Rank: 1},
+		{ImgURL: "b", Provider: "inner", Page: 1, Rank: 7},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if in[0].Provider != "" {
+		t.Error("withProvenance modified the provider's slice")
+	}
+}
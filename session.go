@@ -0,0 +1,368 @@
+package imagefy
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Session defaults.
+const (
+	defaultProviderFailureLimit = 3
+	defaultProviderCooldown     = 5 * time.Minute
+)
+
+// SessionOpts configures a Session. Zero values mean "use defaults".
+type SessionOpts struct {
+	// HostInterval is the minimum delay between two requests (probe or
+	// download) to the same image host. Zero disables per-host rate limiting.
+	HostInterval time.Duration
+
+	// ProviderFailureLimit is the number of consecutive failures after which a
+	// provider is skipped (default: 3).
+	ProviderFailureLimit int
+
+	// ProviderCooldown is how long a failing provider is skipped before it is
+	// tried again (default: 5m).
+	ProviderCooldown time.Duration
+
+	// VisionBudget caps the number of vision classifications (cache hits
+	// included) across the session. Once spent, unknown-license candidates are
+	// accepted unclassified, as when no Classifier is configured. Zero = unlimited.
+	VisionBudget int
+}
+
+// ProviderStatus is a snapshot of a provider's health within a Session.
+type ProviderStatus struct {
+	Name                string
+	Successes           int
+	Failures            int
+	ConsecutiveFailures int
+	LastError           string    // "" if the last call succeeded
+	DisabledUntil       time.Time // zero if the provider is not being skipped
+}
+
+// Session accumulates state across multiple searches that belong to one job
+// (e.g. generating a single article):
+//   - perceptual dedup hashes, so visual duplicates of earlier results are rejected;
+//   - used-image history, so the same URL is never returned twice;
+//   - per-host rate limiting of probe and download requests;
+//   - provider health, so a provider that keeps failing is skipped for a while.
+//
+// Create one with Config.NewSession and discard it when the job is done.
+// A Session is safe for concurrent use.
+type Session struct {
+	cfg   *Config
+	opts  SessionOpts
+	state *searchState
+}
+
+// NewSession returns a Session bound to cfg.
+func (cfg *Config) NewSession(opts SessionOpts) *Session {
+	cfg = cfg.orZero()
+	cfg.defaults()
+
+	limit := opts.ProviderFailureLimit
+	if limit <= 0 {
+		limit = defaultProviderFailureLimit
+	}
+	cooldown := opts.ProviderCooldown
+	if cooldown <= 0 {
+		cooldown = defaultProviderCooldown
+	}
+
+	st := newSearchState()
+	st.used = &usedImages{urls: make(map[string]string)}
+	st.health = &providerHealth{limit: limit, cooldown: cooldown, status: make(map[string]*ProviderStatus)}
+	if opts.HostInterval > 0 {
+		st.hosts = &hostLimiter{interval: opts.HostInterval, next: make(map[string]time.Time)}
+	}
+	if opts.VisionBudget > 0 {
+		st.vision = &visionBudget{}
+		st.vision.remaining.Store(int64(opts.VisionBudget))
+	}
+	return &Session{cfg: cfg, opts: opts, state: st}
+}
+
+// Retry returns a new Session with s's options for another attempt at the
+// same job: dedup hashes, used images, host limits, and provider health
+// start over, so images a failed attempt saw are not rejected as
+// duplicates, while the vision budget is shared with s.
+func (s *Session) Retry() *Session {
+	opts := s.opts
+	opts.VisionBudget = 0
+	r := s.cfg.NewSession(opts)
+	r.state.vision = s.state.vision
+	return r
+}
+
+// SearchImages is like Config.SearchImages, sharing the session's state.
+func (s *Session) SearchImages(ctx context.Context, query string, maxResults int) []ImageCandidate {
+	return s.SearchImagesWithOpts(ctx, query, maxResults, SearchOpts{})
+}
+
+// SearchImagesWithOpts is like Config.SearchImagesWithOpts, sharing the session's state.
+func (s *Session) SearchImagesWithOpts(ctx context.Context, query string, maxResults int, opts SearchOpts) []ImageCandidate {
+	return s.cfg.searchImages(ctx, query, maxResults, opts, s.state)
+}
+
+// FindImages is like Config.FindImages, sharing the session's state.
+func (s *Session) FindImages(ctx context.Context, opts FindOpts) []ImageCandidate {
+	return s.cfg.findImages(ctx, opts, s.state)
+}
+
+// ValidateCandidates is like Config.ValidateCandidates, sharing the session's state.
+func (s *Session) ValidateCandidates(ctx context.Context, candidates []ImageCandidate, maxResults int) []ImageCandidate {
+	if len(candidates) == 0 {
+		return nil
+	}
+	s.cfg.defaults()
+	return s.cfg.validateCandidates(ctx, candidates, maxResults, SearchOpts{}, s.state)
+}
+
+// MarkUsed records image URLs obtained elsewhere (e.g. already in the article)
+// so the session never returns them.
+func (s *Session) MarkUsed(imgURLs ...string) {
+	for _, u := range imgURLs {
+		s.state.used.add(u)
+	}
+}
+
+// Used returns the image URLs the session has returned or been told about, sorted.
+func (s *Session) Used() []string {
+	return s.state.used.list()
+}
+
+// ProviderHealth returns a snapshot of every provider the session has called, sorted by name.
+func (s *Session) ProviderHealth() []ProviderStatus {
+	return s.state.health.snapshot()
+}
+
+// VisionBudgetLeft returns the remaining vision budget, or -1 if unlimited.
+func (s *Session) VisionBudgetLeft() int {
+	if s.state.vision == nil {
+		return -1
+	}
+	return int(max(0, s.state.vision.remaining.Load()))
+}
+
+// searchState carries the mutable state of the validation pipeline. A fresh
+// state (dedup only) is created for every bare Config call; a Session keeps
+// one for its lifetime. Nil components are disabled; their methods are nil-safe.
+type searchState struct {
+	dedup  *dedupFilter
+	used   *usedImages
+	health *providerHealth
+	hosts  *hostLimiter
+	vision *visionBudget
+
+	features  *featureStore // visual features of validated images (SearchImagesDiverse)
+	relevance *scoreStore   // query relevance of validated images (Config.Embedder)
+	modified  *timeStore    // Last-Modified times of downloaded images
+	previews  *previewStore // downloaded bytes of validated images (SearchOpts.PickBest)
+	verdicts  *verdictStore // license and vision verdicts of validated images (SearchOpts.Ranker)
+}
+
+// newSearchState returns the per-call state used by Config methods.
+func newSearchState() *searchState {
+	return &searchState{dedup: &dedupFilter{}, relevance: &scoreStore{}, modified: &timeStore{}, verdicts: &verdictStore{}}
+}
+
+// usedImages is the used-image history, keyed by NormalizeURL so the same
+// image with different tracking parameters counts once. Values are the URLs
+// as first recorded.
+type usedImages struct {
+	mu   sync.Mutex
+	urls map[string]string
+}
+
+func (u *usedImages) contains(imgURL string) bool {
+	if u == nil {
+		return false
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	_, ok := u.urls[NormalizeURL(imgURL)]
+	return ok
+}
+
+func (u *usedImages) add(imgURL string) {
+	if u == nil || imgURL == "" {
+		return
+	}
+	key := NormalizeURL(imgURL)
+	u.mu.Lock()
+	if _, ok := u.urls[key]; !ok {
+		u.urls[key] = imgURL
+	}
+	u.mu.Unlock()
+}
+
+// claim adds imgURL and reports whether it was not already present.
+func (u *usedImages) claim(imgURL string) bool {
+	if u == nil || imgURL == "" {
+		return true
+	}
+	key := NormalizeURL(imgURL)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.urls[key]; ok {
+		return false
+	}
+	u.urls[key] = imgURL
+	return true
+}
+
+// release undoes a claim whose candidate was not returned after all.
+func (u *usedImages) release(imgURL string) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	delete(u.urls, NormalizeURL(imgURL))
+	u.mu.Unlock()
+}
+
+func (u *usedImages) list() []string {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	out := make([]string, 0, len(u.urls))
+	for _, v := range u.urls {
+		out = append(out, v)
+	}
+	u.mu.Unlock()
+	sort.Strings(out)
+	return out
+}
+
+// providerHealth tracks consecutive provider failures and skips a provider
+// for cooldown once it reaches limit.
+type providerHealth struct {
+	limit    int
+	cooldown time.Duration
+
+	mu     sync.Mutex
+	status map[string]*ProviderStatus
+}
+
+// allow reports whether the named provider should be called.
+func (h *providerHealth) allow(name string) bool {
+	if h == nil {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ps, ok := h.status[name]
+	return !ok || ps.DisabledUntil.IsZero() || time.Now().After(ps.DisabledUntil)
+}
+
+// record stores the outcome of one provider call.
+func (h *providerHealth) record(name string, err error) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ps, ok := h.status[name]
+	if !ok {
+		ps = &ProviderStatus{Name: name}
+		h.status[name] = ps
+	}
+	if err == nil {
+		ps.Successes++
+		ps.ConsecutiveFailures = 0
+		ps.LastError = ""
+		ps.DisabledUntil = time.Time{}
+		return
+	}
+	ps.Failures++
+	ps.ConsecutiveFailures++
+	ps.LastError = err.Error()
+	if ps.ConsecutiveFailures >= h.limit {
+		ps.DisabledUntil = time.Now().Add(h.cooldown)
+	}
+}
+
+func (h *providerHealth) snapshot() []ProviderStatus {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	out := make([]ProviderStatus, 0, len(h.status))
+	for _, ps := range h.status {
+		out = append(out, *ps)
+	}
+	h.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// visionBudget is a shared countdown of permitted vision classifications.
+type visionBudget struct {
+	remaining atomic.Int64
+}
+
+// take consumes one classification; false once the budget is spent.
+func (b *visionBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	return b.remaining.Add(-1) >= 0
+}
+
+// hostLimiter spaces out requests to the same host by at least interval.
+type hostLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time // earliest time the next request to host may start
+}
+
+// delay returns how long a request to host (an extractHost key) would wait
+// now, without reserving a slot.
+func (l *hostLimiter) delay(host string) time.Duration {
+	if l == nil || host == "" {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return max(time.Until(l.next[host]), 0)
+}
+
+// wait blocks until a request to rawURL's host may be made, or ctx is done.
+// Slots are reserved on entry, so concurrent callers queue in order.
+func (l *hostLimiter) wait(ctx context.Context, rawURL string) {
+	if l == nil {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	host := normalizeHost(u.Scheme, u.Host)
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,191 @@
+package imagefy
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProvider wraps mockProvider and counts Search calls.
+type countingProvider struct {
+	mockProvider
+	calls atomic.Int32
+}
+
+func (c *countingProvider) Search(ctx context.Context, query string, opts SearchOpts) ([]ImageCandidate, error) {
+	c.calls.Add(1)
+	return c.mockProvider.Search(ctx, query, opts)
+}
+
+func TestSession_UsedImagesNotReturnedTwice(t *testing.T) {
+	t.Parallel()
+
+	srv := newJPEGServer(t)
+	imgURL := srv.URL + "/photo.jpg"
+	cfg := &Config{
+		HTTPClient: srv.Client(),
+		Providers: []SearchProvider{&mockProvider{name: "mock", candidates: []ImageCandidate{
+			{ImgURL: imgURL, Source: srv.URL + "/page", License: LicenseUnknown},
+		}}},
+	}
+	s := cfg.NewSession(SessionOpts{})
+
+	if got := s.SearchImages(context.Background(), "query", 3); len(got) != 1 {
+		t.Fatalf("first search returned %d results, want 1", len(got))
+	}
+	if got := s.SearchImages(context.Background(), "query", 3); len(got) != 0 {
+		t.Errorf("second search returned %d results, want 0 (already used)", len(got))
+	}
+	if used := s.Used(); len(used) != 1 || used[0] != imgURL {
+		t.Errorf("Used() = %v, want [%s]", used, imgURL)
+	}
+
+	// A bare Config call keeps no history.
+	if got := cfg.SearchImages(context.Background(), "query", 3); len(got) != 1 {
+		t.Errorf("Config.SearchImages returned %d results, want 1", len(got))
+	}
+}
+
+func TestSession_MarkUsed(t *testing.T) {
+	t.Parallel()
+
+	srv := newJPEGServer(t)
+	cand := ImageCandidate{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown}
+	cfg := &Config{HTTPClient: srv.Client()}
+	s := cfg.NewSession(SessionOpts{})
+	s.MarkUsed(cand.ImgURL)
+
+	var rejected []CandidateEvent
+	cfg.OnCandidateRejected = func(e CandidateEvent) { rejected = append(rejected, e) }
+
+	if got := s.ValidateCandidates(context.Background(), []ImageCandidate{cand}, 3); len(got) != 0 {
+		t.Errorf("ValidateCandidates returned %d results, want 0", len(got))
+	}
+	if len(rejected) != 1 || rejected[0].Reason != ReasonAlreadyUsed {
+		t.Errorf("rejected = %+v, want one %q event", rejected, ReasonAlreadyUsed)
+	}
+}
+
+func TestSession_Retry(t *testing.T) {
+	t.Parallel()
+
+	srv := newJPEGServer(t)
+	cand := ImageCandidate{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown}
+	cfg := &Config{HTTPClient: srv.Client(), Classifier: &mockClassifier{response: "PHOTO 0.9"}}
+	s := cfg.NewSession(SessionOpts{VisionBudget: 2})
+	if got := s.ValidateCandidates(context.Background(), []ImageCandidate{cand}, 3); len(got) != 1 {
+		t.Fatalf("first attempt returned %d results, want 1", len(got))
+	}
+
+	r := s.Retry()
+	if used := r.Used(); len(used) != 0 {
+		t.Errorf("Retry().Used() = %v, want none", used)
+	}
+	if got := r.ValidateCandidates(context.Background(), []ImageCandidate{cand}, 3); len(got) != 1 {
+		t.Errorf("retry returned %d results, want the same image again", len(got))
+	}
+	if s.VisionBudgetLeft() != 0 || r.VisionBudgetLeft() != 0 {
+		t.Errorf("VisionBudgetLeft() = %d / %d, want the budget of 2 shared and spent", s.VisionBudgetLeft(), r.VisionBudgetLeft())
+	}
+}
+
+func TestSession_DedupAcrossSearches(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	cfg := &Config{HTTPClient: srv.Client()}
+	s := cfg.NewSession(SessionOpts{})
+
+	first := []ImageCandidate{{ImgURL: srv.URL + "/a.jpg", Source: srv.URL + "/page", License: LicenseUnknown}}
+	second := []ImageCandidate{{ImgURL: srv.URL + "/b.jpg", Source: srv.URL + "/page", License: LicenseUnknown}}
+
+	if got := s.ValidateCandidates(context.Background(), first, 3); len(got) != 1 {
+		t.Fatalf("first call returned %d results, want 1", len(got))
+	}
+	if got := s.ValidateCandidates(context.Background(), second, 3); len(got) != 0 {
+		t.Errorf("second call returned %d results, want 0 (perceptual duplicate)", len(got))
+	}
+}
+
+func TestSession_SkipsFailingProvider(t *testing.T) {
+	t.Parallel()
+
+	failing := &countingProvider{mockProvider: mockProvider{name: "broken", err: errProviderFailed}}
+	cfg := &Config{Providers: []SearchProvider{failing}}
+	s := cfg.NewSession(SessionOpts{ProviderFailureLimit: 2, ProviderCooldown: time.Hour})
+
+	for range 4 {
+		s.SearchImages(context.Background(), "query", 3)
+	}
+	if n := failing.calls.Load(); n != 2 {
+		t.Errorf("provider called %d times, want 2", n)
+	}
+
+	health := s.ProviderHealth()
+	if len(health) != 1 {
+		t.Fatalf("ProviderHealth() = %+v, want one entry", health)
+	}
+	ps := health[0]
+	if ps.Name != "broken" || ps.Failures != 2 || ps.ConsecutiveFailures != 2 || ps.LastError == "" || ps.DisabledUntil.IsZero() {
+		t.Errorf("ProviderStatus = %+v", ps)
+	}
+}
+
+func TestProviderHealth_RecoversAfterSuccess(t *testing.T) {
+	t.Parallel()
+
+	h := &providerHealth{limit: 1, cooldown: -time.Second, status: make(map[string]*ProviderStatus)}
+	h.record("p", errProviderFailed)
+	if !h.allow("p") {
+		t.Error("allow() = false after cooldown elapsed, want true")
+	}
+	h.record("p", nil)
+	ps := h.snapshot()[0]
+	if ps.ConsecutiveFailures != 0 || !ps.DisabledUntil.IsZero() || ps.Successes != 1 {
+		t.Errorf("ProviderStatus after success = %+v", ps)
+	}
+}
+
+func TestHostLimiter_SpacesSameHost(t *testing.T) {
+	t.Parallel()
+
+	const interval = 30 * time.Millisecond
+	l := &hostLimiter{interval: interval, next: make(map[string]time.Time)}
+	ctx := context.Background()
+
+	start := time.Now()
+	l.wait(ctx, "https://a.example/1.jpg")
+	l.wait(ctx, "https://b.example/1.jpg")
+	if elapsed := time.Since(start); elapsed >= interval {
+		t.Errorf("different hosts waited %v, want < %v", elapsed, interval)
+	}
+	l.wait(ctx, "https://a.example/2.jpg")
+	l.wait(ctx, "https://a.example/3.jpg")
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("three requests to one host took %v, want >= %v", elapsed, 2*interval)
+	}
+}
+
+func TestHostLimiter_NilAndCanceled(t *testing.T) {
+	t.Parallel()
+
+	var nilLimiter *hostLimiter
+	nilLimiter.wait(context.Background(), "https://a.example/x.jpg")
+
+	l := &hostLimiter{interval: time.Hour, next: make(map[string]time.Time)}
+	ctx, cancel := context.WithCancel(context.Background())
+	l.wait(ctx, "https://a.example/1.jpg")
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		l.wait(ctx, "https://a.example/2.jpg")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after context cancellation")
+	}
+}
@@ -0,0 +1,137 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Embedder turns an image into a vector embedding (CLIP, a hosted
+// multimodal embedding API, ...). When Config.Embedder is set, FindSimilar
+// ranks by cosine similarity of embeddings instead of perceptual features.
+type Embedder interface {
+	Embed(ctx context.Context, data []byte, mimeType string) ([]float32, error)
+}
+
+// ScoredCandidate is a FindSimilar result.
+type ScoredCandidate struct {
+	Candidate ImageCandidate
+	Score     float64 // 0–1 similarity to the reference; 1 = identical
+	Embedded  bool    // Score is an embedding cosine similarity, not a perceptual one
+}
+
+// FindSimilar scores candidates by visual similarity to the reference image
+// and returns them most similar first — e.g. to find a free-license
+// replacement in your own library for a stock photo an author pasted into a
+// draft. Candidates are scored as given; pass already validated ones (see
+// ValidateCandidates) to keep license guarantees.
+//
+// Each candidate's Thumbnail (or ImgURL if empty) is downloaded, up to 3 at
+// a time. The score combines perceptual-hash and color palette similarity,
+// or is the cosine similarity of Config.Embedder embeddings when both
+// embeddings succeed. Candidates that cannot be downloaded or decoded are
+// omitted. Returns nil if reference does not decode.
+func (cfg *Config) FindSimilar(ctx context.Context, reference []byte, candidates []ImageCandidate) []ScoredCandidate {
+	cfg = cfg.orZero()
+	cfg.defaults()
+
+	refImg, _, err := image.Decode(bytes.NewReader(reference))
+	if err != nil {
+		slog.Debug("imagefy: similar: reference does not decode", "error", err)
+		return nil
+	}
+	refFeat := extractFeatures(refImg)
+	refVec := cfg.embed(ctx, reference, http.DetectContentType(reference))
+
+	var (
+		mu  sync.Mutex
+		out []ScoredCandidate
+		wg  sync.WaitGroup
+	)
+	sem := make(chan struct{}, validationSemaphore)
+	for _, c := range candidates {
+		wg.Add(1)
+		go func(c ImageCandidate) {
+			defer wg.Done()
+			if !acquire(ctx, sem) {
+				return
+			}
+			defer func() { <-sem }()
+
+			sc, ok := cfg.scoreSimilar(ctx, c, refFeat, refVec)
+			if !ok {
+				return
+			}
+			mu.Lock()
+			out = append(out, sc)
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// scoreSimilar downloads c's preview and scores it against the reference.
+func (cfg *Config) scoreSimilar(ctx context.Context, c ImageCandidate, refFeat imageFeatures, refVec []float32) (ScoredCandidate, bool) {
+	imgURL := c.Thumbnail
+	if imgURL == "" {
+		imgURL = c.ImgURL
+	}
+	r, err := cfg.Download(ctx, imgURL, DownloadOpts{})
+	if err != nil || r == nil {
+		return ScoredCandidate{}, false
+	}
+
+	if refVec != nil {
+		if vec := cfg.embed(ctx, r.Data, r.MIMEType); vec != nil {
+			if sim, ok := cosineSimilarity(refVec, vec); ok {
+				return ScoredCandidate{Candidate: c, Score: sim, Embedded: true}, true
+			}
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(r.Data))
+	if err != nil {
+		return ScoredCandidate{}, false
+	}
+	return ScoredCandidate{Candidate: c, Score: 1 - featureDistance(refFeat, extractFeatures(img))}, true
+}
+
+// embed returns the Config.Embedder embedding of data, or nil when no
+// Embedder is set or it fails.
+func (cfg *Config) embed(ctx context.Context, data []byte, mimeType string) []float32 {
+	if cfg.Embedder == nil {
+		return nil
+	}
+	vec, err := cfg.Embedder.Embed(ctx, data, mimeType)
+	if err != nil {
+		slog.Debug("imagefy: embedding failed", "error", err)
+		return nil
+	}
+	return vec
+}
+
+// cosineSimilarity returns the cosine similarity of a and b clamped to 0–1,
+// or false if their lengths differ or either is a zero vector.
+func cosineSimilarity(a, b []float32) (float64, bool) {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0, false
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0, false
+	}
+	return max(0, min(1, dot/(math.Sqrt(na)*math.Sqrt(nb)))), true
+}
@@ -0,0 +1,102 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newMultiImageServer serves bodies[path] as JPEG.
+func newMultiImageServer(t *testing.T, bodies map[string][]byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFindSimilar_Perceptual(t *testing.T) {
+	t.Parallel()
+
+	reference := encodeJPEG(t, makeGradientImage(200, 100, 0))
+	srv := newMultiImageServer(t, map[string][]byte{
+		"/near.jpg":  encodeJPEG(t, makeGradientImage(200, 100, 8)),
+		"/check.jpg": encodeJPEG(t, makeCheckerImage(200, 100, 25)),
+		"/blue.jpg":  encodeJPEG(t, makeSolidImage(200, 100, color.RGBA{B: 220, A: 255})),
+	})
+	cfg := &Config{HTTPClient: srv.Client()}
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/blue.jpg"},
+		{ImgURL: srv.URL + "/full.jpg", Thumbnail: srv.URL + "/near.jpg"},
+		{ImgURL: srv.URL + "/check.jpg"},
+		{ImgURL: srv.URL + "/missing.jpg"},
+	}
+
+	got := cfg.FindSimilar(context.Background(), reference, cands)
+	if len(got) != 3 {
+		t.Fatalf("results = %d, want 3 (missing image omitted)", len(got))
+	}
+	if got[0].Candidate.ImgURL != srv.URL+"/full.jpg" || got[0].Embedded {
+		t.Errorf("best match = %+v, want the near-duplicate gradient (via its thumbnail)", got[0])
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Score > got[i-1].Score {
+			t.Errorf("results not sorted by score: %v", got)
+		}
+	}
+	if got := cfg.FindSimilar(context.Background(), []byte("not an image"), cands); got != nil {
+		t.Errorf("undecodable reference: got %v, want nil", got)
+	}
+}
+
+// lenEmbedder embeds an image as a 2-vector derived from its byte length.
+type lenEmbedder struct{ err error }
+
+func (e lenEmbedder) Embed(_ context.Context, data []byte, _ string) ([]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return []float32{1, float32(len(data) % 7)}, nil
+}
+
+func TestFindSimilar_Embedder(t *testing.T) {
+	t.Parallel()
+
+	body := encodeJPEG(t, makeGradientImage(200, 100, 0))
+	srv := newMultiImageServer(t, map[string][]byte{"/same.jpg": body})
+	cands := []ImageCandidate{{ImgURL: srv.URL + "/same.jpg"}}
+
+	got := (&Config{HTTPClient: srv.Client(), Embedder: lenEmbedder{}}).FindSimilar(context.Background(), body, cands)
+	if len(got) != 1 || !got[0].Embedded || got[0].Score < 0.999 {
+		t.Errorf("embedder result = %+v, want an embedded score of 1", got)
+	}
+
+	got = (&Config{HTTPClient: srv.Client(), Embedder: lenEmbedder{err: errors.New("down")}}).FindSimilar(context.Background(), body, cands)
+	if len(got) != 1 || got[0].Embedded {
+		t.Errorf("failing embedder result = %+v, want a perceptual fallback", got)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	t.Parallel()
+
+	if s, ok := cosineSimilarity([]float32{1, 0}, []float32{0, 1}); !ok || s != 0 {
+		t.Errorf("orthogonal = %v, %v; want 0, true", s, ok)
+	}
+	if _, ok := cosineSimilarity([]float32{1}, []float32{1, 2}); ok {
+		t.Error("length mismatch: ok = true")
+	}
+	if _, ok := cosineSimilarity([]float32{0, 0}, []float32{1, 2}); ok {
+		t.Error("zero vector: ok = true")
+	}
+}
@@ -0,0 +1,41 @@
+package imagefy
+
+// SearchStats summarizes the candidates of a SearchResult for dashboards,
+// so per-provider and per-stage counts need not be recomputed from Events.
+// Every map counts candidates with a CandidateEvent; maps are never nil.
+type SearchStats struct {
+	Candidates int // candidates the pipeline decided on
+	Accepted   int
+
+	ByProvider map[string]int // candidates per ImageCandidate.Provider ("" = external)
+	ByEngine   map[string]int // candidates per ImageCandidate.Engine ("" = not reported)
+	ByDomain   map[string]int // candidates per source host (the image host without a Source)
+
+	RejectedByStage  map[Stage]int        // rejections per deciding stage
+	RejectedByReason map[RejectReason]int // rejections per reason
+}
+
+// searchStats tallies events.
+func searchStats(events []CandidateEvent) SearchStats {
+	s := SearchStats{
+		Candidates:       len(events),
+		ByProvider:       map[string]int{},
+		ByEngine:         map[string]int{},
+		ByDomain:         map[string]int{},
+		RejectedByStage:  map[Stage]int{},
+		RejectedByReason: map[RejectReason]int{},
+	}
+	for _, e := range events {
+		c := e.Candidate
+		s.ByProvider[c.Provider]++
+		s.ByEngine[c.Engine]++
+		s.ByDomain[sourceKey(c)]++
+		if e.Reason == "" {
+			s.Accepted++
+			continue
+		}
+		s.RejectedByStage[e.Stage]++
+		s.RejectedByReason[e.Reason]++
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package imagefy
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ClientSelection chooses the HTTP client of a Download.
+type ClientSelection int
+
+const (
+	// ClientAuto routes the download by Config.StealthPolicy (the default).
+	ClientAuto ClientSelection = iota
+	// ClientStealth uses only Config.StealthClient; without one the
+	// download fails.
+	ClientStealth
+	// ClientDirect uses only Config.HTTPClient, never StealthClient.
+	ClientDirect
+)
+
+// StealthPolicy decides when ClientAuto downloads use Config.StealthClient.
+// Hosts in Config.StealthHosts always go straight to StealthClient, without
+// a direct attempt that is known to fail.
+type StealthPolicy int
+
+const (
+	// StealthFallback tries HTTPClient first and retries with StealthClient
+	// when that fails (the default).
+	StealthFallback StealthPolicy = iota
+	// StealthKnownHostsOnly uses StealthClient for StealthHosts only;
+	// downloads from other hosts never fall back to it.
+	StealthKnownHostsOnly
+)
+
+// downloadClients returns the clients Download tries for imageURL, in order.
+func (cfg *Config) downloadClients(imageURL string, sel ClientSelection) []*http.Client {
+	direct, stealth := cfg.directClient(), cfg.StealthClient
+	switch {
+	case sel == ClientDirect:
+		return []*http.Client{direct}
+	case sel == ClientStealth:
+		if stealth == nil {
+			return nil
+		}
+		return []*http.Client{stealth}
+	case stealth == nil:
+		return []*http.Client{direct}
+	case cfg.isStealthHost(imageURL):
+		return []*http.Client{stealth}
+	case cfg.StealthPolicy == StealthKnownHostsOnly:
+		return []*http.Client{direct}
+	}
+	return []*http.Client{direct, stealth}
+}
+
+// probeClient returns the client probeImageURL uses for rawURL:
+// StealthClient for StealthHosts when set, validationClient otherwise.
+func (cfg *Config) probeClient(rawURL string) *http.Client {
+	if cfg.StealthClient != nil && cfg.isStealthHost(rawURL) {
+		return cfg.StealthClient
+	}
+	return cfg.validationClient()
+}
+
+// isStealthHost reports whether the host of rawURL is one of
+// Config.StealthHosts or a subdomain of one, or has a UseStealth profile.
+func (cfg *Config) isStealthHost(rawURL string) bool {
+	if p, ok := cfg.hostProfile(rawURL); ok && p.UseStealth {
+		return true
+	}
+	if len(cfg.StealthHosts) == 0 {
+		return false
+	}
+	host := urlHostname(rawURL)
+	if host == "" {
+		return false
+	}
+	for _, s := range cfg.StealthHosts {
+		s = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")
+		if s != "" && (host == s || strings.HasSuffix(host, "."+s)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// recordingTransport records the name of every client that sends a request.
+type recordingTransport struct {
+	name string
+	mu   *sync.Mutex
+	log  *[]string
+	fail bool
+}
+
+func (rt recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	*rt.log = append(*rt.log, rt.name)
+	rt.mu.Unlock()
+	if rt.fail {
+		return &http.Response{StatusCode: http.StatusForbidden, Body: http.NoBody, Request: req}, nil
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestDownloadClientSelection(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(100, 100))
+
+	tests := []struct {
+		name         string
+		policy       StealthPolicy
+		stealthHosts []string
+		directFails  bool
+		sel          ClientSelection
+		want         []string
+		wantOK       bool
+	}{
+		{"direct succeeds", StealthFallback, nil, false, ClientAuto, []string{"direct"}, true},
+		{"fallback after direct failure", StealthFallback, nil, true, ClientAuto, []string{"direct", "stealth"}, true},
+		{"known host skips direct", StealthFallback, []string{"127.0.0.1"}, false, ClientAuto, []string{"stealth"}, true},
+		{"known hosts only: no fallback", StealthKnownHostsOnly, []string{"example.com"}, true, ClientAuto, []string{"direct"}, false},
+		{"known hosts only: known host", StealthKnownHostsOnly, []string{"127.0.0.1"}, true, ClientAuto, []string{"stealth"}, true},
+		{"forced stealth", StealthFallback, nil, false, ClientStealth, []string{"stealth"}, true},
+		{"forced direct", StealthFallback, []string{"127.0.0.1"}, true, ClientDirect, []string{"direct"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu  sync.Mutex
+				log []string
+			)
+			cfg := &Config{
+				HTTPClient:    &http.Client{Transport: recordingTransport{name: "direct", mu: &mu, log: &log, fail: tt.directFails}},
+				StealthClient: &http.Client{Transport: recordingTransport{name: "stealth", mu: &mu, log: &log}},
+				StealthHosts:  tt.stealthHosts,
+				StealthPolicy: tt.policy,
+			}
+			r, _ := cfg.Download(context.Background(), srv.URL+"/img.jpg", DownloadOpts{Client: tt.sel})
+			if (r != nil) != tt.wantOK {
+				t.Errorf("Download ok = %v, want %v", r != nil, tt.wantOK)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(log) != len(tt.want) {
+				t.Fatalf("clients used = %v, want %v", log, tt.want)
+			}
+			for i := range log {
+				if log[i] != tt.want[i] {
+					t.Errorf("clients used = %v, want %v", log, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestProbeUsesStealthForKnownHosts(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+	cfg := &Config{
+		HTTPClient:    &http.Client{Transport: recordingTransport{name: "direct", mu: &mu, log: &log, fail: true}},
+		StealthClient: &http.Client{Transport: recordingTransport{name: "stealth", mu: &mu, log: &log}},
+		StealthHosts:  []string{"127.0.0.1"},
+	}
+	if !cfg.ValidateImageURL(context.Background(), srv.URL+"/img.jpg") {
+		t.Errorf("ValidateImageURL = false, want the probe routed to StealthClient (clients used %v)", log)
+	}
+}
@@ -0,0 +1,64 @@
+package imagefy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// ErrNoProviders is returned by Validate (and reported under Config.Strict)
+// when neither Providers nor SearxngURL is set.
+var ErrNoProviders = errors.New("imagefy: no search providers configured")
+
+// Validate reports mis-wired configuration, for services to call at startup
+// instead of discovering it in production output:
+//   - ErrNoClassifier when Classifier is nil
+//   - ErrNoProviders when neither Providers nor SearxngURL is set
+//   - an unrecognized DegradationPolicy
+//   - a PreviewFormat that is unknown or lacks its PreviewEncoder
+//   - domain list entries that never take effect (see ValidateDomainLists)
+//   - an unhealthy classifier, if WarmupClassifier has run and failed
+//
+// All problems are joined into one error; use errors.Is to ignore a
+// dependency the service deliberately leaves out. Returns nil when the
+// configuration is complete.
+func (cfg *Config) Validate() error {
+	cfg = cfg.orZero()
+
+	var errs []error
+	if cfg.Classifier == nil {
+		errs = append(errs, ErrNoClassifier)
+	}
+	if len(cfg.resolveProviders()) == 0 {
+		errs = append(errs, ErrNoProviders)
+	}
+	switch cfg.DegradationPolicy {
+	case "", DegradeAccept, DegradeReject, DegradeMarkUnknown:
+	default:
+		errs = append(errs, fmt.Errorf("imagefy: unknown DegradationPolicy %q", cfg.DegradationPolicy))
+	}
+	if err := cfg.checkPreviewFormat(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := cfg.checkBlockPolicy(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateDomainLists(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	if h := cfg.ClassifierHealth(); !h.CheckedAt.IsZero() && !h.Healthy {
+		errs = append(errs, fmt.Errorf("imagefy: classifier unhealthy: %w", h.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// requireDependency logs err and returns it when cfg.Strict is set, for the
+// caller to fail with; otherwise it returns nil and the caller degrades
+// gracefully.
+func (cfg *Config) requireDependency(err error) error {
+	if !cfg.Strict {
+		return nil
+	}
+	slog.Error("imagefy: required dependency missing", "error", err)
+	return err
+}
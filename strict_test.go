@@ -0,0 +1,82 @@
+package imagefy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := (&Config{Classifier: &mockClassifier{}, SearxngURL: "http://searx.local"}).Validate(); err != nil {
+		t.Errorf("complete config: Validate() = %v, want nil", err)
+	}
+
+	err := (&Config{DegradationPolicy: "reject-on-error"}).Validate()
+	if !errors.Is(err, ErrNoClassifier) || !errors.Is(err, ErrNoProviders) {
+		t.Errorf("empty config: Validate() = %v, want ErrNoClassifier and ErrNoProviders", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "reject-on-error") {
+		t.Errorf("Validate() = %v, want the unknown DegradationPolicy reported", err)
+	}
+
+	var nilCfg *Config
+	if err := nilCfg.Validate(); !errors.Is(err, ErrNoClassifier) {
+		t.Errorf("nil config: Validate() = %v, want ErrNoClassifier", err)
+	}
+}
+
+func TestValidate_UnhealthyClassifier(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("connection refused")
+	cfg := &Config{Classifier: &mockClassifier{err: boom}, Providers: []SearchProvider{&mockProvider{name: "p"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() before warmup = %v, want nil", err)
+	}
+	_ = cfg.WarmupClassifier(context.Background())
+	if err := cfg.Validate(); !errors.Is(err, boom) {
+		t.Errorf("Validate() after failed warmup = %v, want the classifier error", err)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	for name, cfg := range map[string]*Config{"lenient": {}, "strict": {Strict: true}} {
+		// Neither mode panics, not even from the goroutines of a batch.
+		cfg.ClassifyImage(ctx, "https://example.com/a.jpg")
+		cfg.SearchImages(ctx, "query", 3)
+		cfg.FindImages(ctx, FindOpts{Query: "query", MaxResults: 3})
+		cfg.SearchImagesBatch(ctx, []QuerySpec{{Query: "a"}, {Query: "b"}})
+
+		var want error
+		if cfg.Strict {
+			want = ErrNoProviders
+		}
+		if res := cfg.SearchImagesResult(ctx, "query", 3, SearchOpts{}); !errors.Is(res.Err, want) {
+			t.Errorf("%s: SearchImagesResult Err = %v, want %v", name, res.Err, want)
+		}
+		if _, err := cfg.ClassifyImageErr(ctx, "https://example.com/a.jpg"); cfg.Strict != errors.Is(err, ErrNoClassifier) {
+			t.Errorf("%s: ClassifyImageErr error = %v", name, err)
+		}
+		// The default DegradationPolicy accepts, except for Strict.
+		if got := cfg.IsRealPhoto(ctx, "https://example.com/a.jpg"); got == cfg.Strict {
+			t.Errorf("%s: IsRealPhoto without a classifier = %v", name, got)
+		}
+	}
+
+	// Under Strict a search without providers returns no images, not even
+	// External candidates that would otherwise be validated.
+	srv := newJPEGServer(t)
+	external := []ImageCandidate{{ImgURL: srv.URL + "/photo.jpg", Source: srv.URL + "/page", License: LicenseUnknown}}
+	for strict, want := range map[bool]int{false: 1, true: 0} {
+		cfg := &Config{Strict: strict, HTTPClient: srv.Client()}
+		if got := cfg.FindImages(ctx, FindOpts{Query: "query", External: external}); len(got) != want {
+			t.Errorf("strict=%v: FindImages returned %d images, want %d", strict, len(got), want)
+		}
+	}
+}
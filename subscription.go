@@ -0,0 +1,247 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultSubscriptionInterval = 15 * time.Minute
+	subscriptionBodyLimit       = 4 * 1024 * 1024
+)
+
+// ErrUnverifiedSubscription is returned by ListSubscription.Refresh when neither
+// PublicKey nor ChecksumURL is set: remote lists are never applied unverified.
+var ErrUnverifiedSubscription = errors.New("imagefy: subscription needs PublicKey or ChecksumURL")
+
+// DomainLists is the document served by a list subscription:
+//
+//	{"version": "2026-10-01", "blocked": ["stockagency"], "safe": ["ourarchive.org"]}
+//
+// Entries use the same substring semantics as ExtraBlockedDomains and
+// ExtraSafeDomains; they are lower-cased and empty entries are dropped.
+type DomainLists struct {
+	Version string   `json:"version,omitempty"`
+	Blocked []string `json:"blocked"`
+	Safe    []string `json:"safe"`
+}
+
+// ListSubscription keeps a remotely hosted block/safe list up to date, so a
+// central team can push domain updates to every deployed service. Attach it
+// to Config.Subscription: its lists are applied in addition to
+// ExtraBlockedDomains and ExtraSafeDomains wherever those are used.
+//
+// Every new document must be verified before it is applied, either by an
+// Ed25519 signature (PublicKey) or a SHA-256 checksum (ChecksumURL). Polling
+// uses ETag / If-None-Match, so an unchanged list costs one 304 response.
+// On any failure the previously applied lists stay in effect.
+//
+// A ListSubscription is safe for concurrent use.
+type ListSubscription struct {
+	// URL serves the DomainLists JSON document.
+	URL string
+
+	// PublicKey verifies an Ed25519 signature of the document body. The
+	// base64-encoded signature is fetched from SignatureURL (default: URL + ".sig").
+	PublicKey    ed25519.PublicKey
+	SignatureURL string
+
+	// ChecksumURL serves the hex SHA-256 of the document body (sha256sum
+	// output is accepted). Used only when PublicKey is nil.
+	ChecksumURL string
+
+	Interval   time.Duration // polling interval for Run (default: 15m)
+	HTTPClient *http.Client  // nil = http.DefaultClient
+
+	OnUpdate func(DomainLists) // optional: called after a new document is applied
+	OnError  func(error)       // optional: called when a refresh in Run fails
+
+	current atomic.Pointer[DomainLists]
+
+	mu   sync.Mutex // serializes Refresh; guards etag
+	etag string
+}
+
+// Lists returns the currently applied lists (zero before the first successful refresh).
+func (s *ListSubscription) Lists() DomainLists {
+	if s == nil {
+		return DomainLists{}
+	}
+	if l := s.current.Load(); l != nil {
+		return *l
+	}
+	return DomainLists{}
+}
+
+// Refresh fetches the document once and applies it if it changed and
+// verifies. It reports whether new lists were applied.
+func (s *ListSubscription) Refresh(ctx context.Context) (bool, error) {
+	if s.PublicKey == nil && s.ChecksumURL == "" {
+		return false, ErrUnverifiedSubscription
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, etag, err := s.fetch(ctx, s.URL, s.etag)
+	if err != nil || body == nil {
+		return false, err // body == nil: 304 Not Modified
+	}
+	if err := s.verify(ctx, body); err != nil {
+		return false, err
+	}
+
+	var lists DomainLists
+	if err := json.Unmarshal(body, &lists); err != nil {
+		return false, fmt.Errorf("imagefy: decode subscription: %w", err)
+	}
+	lists.Blocked = normalizeDomains(lists.Blocked)
+	lists.Safe = normalizeDomains(lists.Safe)
+
+	s.current.Store(&lists)
+	s.etag = etag
+	slog.Debug("imagefy: subscription updated", "url", s.URL, "version", lists.Version,
+		"blocked", len(lists.Blocked), "safe", len(lists.Safe))
+	if s.OnUpdate != nil {
+		s.OnUpdate(lists)
+	}
+	return true, nil
+}
+
+// Run refreshes immediately and then every Interval until ctx is done.
+// Failures are reported to OnError and retried at the next tick.
+func (s *ListSubscription) Run(ctx context.Context) {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultSubscriptionInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+			slog.Debug("imagefy: subscription refresh failed", "url", s.URL, "error", err.Error())
+			if s.OnError != nil {
+				s.OnError(err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// verify checks body against the configured signature or checksum.
+func (s *ListSubscription) verify(ctx context.Context, body []byte) error {
+	if s.PublicKey != nil {
+		sigURL := s.SignatureURL
+		if sigURL == "" {
+			sigURL = s.URL + ".sig"
+		}
+		raw, _, err := s.fetch(ctx, sigURL, "")
+		if err != nil {
+			return err
+		}
+		sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw)))
+		if err != nil || !ed25519.Verify(s.PublicKey, body, sig) {
+			return errors.New("imagefy: subscription signature mismatch")
+		}
+		return nil
+	}
+
+	raw, _, err := s.fetch(ctx, s.ChecksumURL, "")
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(string(raw))
+	sum := sha256.Sum256(body)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+		return errors.New("imagefy: subscription checksum mismatch")
+	}
+	return nil
+}
+
+// fetch GETs rawURL. With a non-empty etag it sends If-None-Match and returns
+// a nil body on 304 Not Modified.
+func (s *ListSubscription) fetch(ctx context.Context, rawURL, etag string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: URL is operator-configured
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		if etag != "" {
+			return nil, etag, nil
+		}
+		fallthrough
+	default:
+		return nil, "", fmt.Errorf("imagefy: fetch %s: status %d", rawURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, subscriptionBodyLimit))
+	if err != nil {
+		return nil, "", err
+	}
+	return body, resp.Header.Get("ETag"), nil
+}
+
+// normalizeDomains lower-cases and trims entries, dropping empty ones.
+func normalizeDomains(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, d := range in {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// extraBlocked returns ExtraBlockedDomains plus the subscription's blocked list.
+func (cfg *Config) extraBlocked() []string {
+	remote := cfg.Subscription.Lists().Blocked
+	if len(remote) == 0 {
+		return cfg.ExtraBlockedDomains
+	}
+	return append(append(make([]string, 0, len(cfg.ExtraBlockedDomains)+len(remote)), cfg.ExtraBlockedDomains...), remote...)
+}
+
+// extraSafe returns ExtraSafeDomains plus the subscription's safe list.
+func (cfg *Config) extraSafe() []string {
+	remote := cfg.Subscription.Lists().Safe
+	if len(remote) == 0 {
+		return cfg.ExtraSafeDomains
+	}
+	return append(append(make([]string, 0, len(cfg.ExtraSafeDomains)+len(remote)), cfg.ExtraSafeDomains...), remote...)
+}
@@ -0,0 +1,197 @@
+package imagefy
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// listServer serves a DomainLists document at /lists.json with an ETag, its
+// Ed25519 signature at /lists.json.sig, and its SHA-256 at /lists.sha256.
+type listServer struct {
+	*httptest.Server
+	key ed25519.PrivateKey
+
+	mu       sync.Mutex
+	body     string
+	badSig   bool
+	requests atomic.Int32 // requests to /lists.json
+	notMod   atomic.Int32 // 304 responses
+}
+
+func newListServer(t *testing.T, body string) (*listServer, ed25519.PublicKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls := &listServer{key: priv, body: body}
+	ls.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ls.mu.Lock()
+		body, badSig := ls.body, ls.badSig
+		ls.mu.Unlock()
+		sum := sha256.Sum256([]byte(body))
+		etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+		switch r.URL.Path {
+		case "/lists.json":
+			ls.requests.Add(1)
+			if r.Header.Get("If-None-Match") == etag {
+				ls.notMod.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			_, _ = w.Write([]byte(body))
+		case "/lists.json.sig":
+			signed := body
+			if badSig {
+				signed = "tampered"
+			}
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(ls.key, []byte(signed)))))
+		case "/lists.sha256":
+			_, _ = w.Write([]byte(hex.EncodeToString(sum[:]) + "  lists.json\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ls.Close)
+	return ls, pub
+}
+
+func (ls *listServer) set(body string, badSig bool) {
+	ls.mu.Lock()
+	ls.body, ls.badSig = body, badSig
+	ls.mu.Unlock()
+}
+
+func TestListSubscription_SignedRefreshAndETag(t *testing.T) {
+	t.Parallel()
+
+	ls, pub := newListServer(t, `{"version":"v1","blocked":[" StockAgency ",""],"safe":["ourarchive.org"]}`)
+	var updates atomic.Int32
+	sub := &ListSubscription{URL: ls.URL + "/lists.json", PublicKey: pub, OnUpdate: func(DomainLists) { updates.Add(1) }}
+
+	changed, err := sub.Refresh(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Refresh = %v, %v; want true, nil", changed, err)
+	}
+	got := sub.Lists()
+	if got.Version != "v1" || len(got.Blocked) != 1 || got.Blocked[0] != "stockagency" || len(got.Safe) != 1 {
+		t.Errorf("Lists() = %+v", got)
+	}
+
+	changed, err = sub.Refresh(context.Background())
+	if err != nil || changed {
+		t.Errorf("second Refresh = %v, %v; want false, nil (not modified)", changed, err)
+	}
+	if ls.notMod.Load() != 1 || updates.Load() != 1 {
+		t.Errorf("304s = %d, updates = %d; want 1, 1", ls.notMod.Load(), updates.Load())
+	}
+}
+
+func TestListSubscription_BadSignatureKeepsPreviousLists(t *testing.T) {
+	t.Parallel()
+
+	ls, pub := newListServer(t, `{"blocked":["first"]}`)
+	sub := &ListSubscription{URL: ls.URL + "/lists.json", PublicKey: pub}
+	if _, err := sub.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ls.set(`{"blocked":["evil"]}`, true)
+	if _, err := sub.Refresh(context.Background()); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("Refresh error = %v, want signature mismatch", err)
+	}
+	if got := sub.Lists().Blocked; len(got) != 1 || got[0] != "first" {
+		t.Errorf("Blocked = %v, want previous [first]", got)
+	}
+}
+
+func TestListSubscription_Checksum(t *testing.T) {
+	t.Parallel()
+
+	ls, _ := newListServer(t, `{"blocked":["stockagency"]}`)
+	sub := &ListSubscription{URL: ls.URL + "/lists.json", ChecksumURL: ls.URL + "/lists.sha256"}
+	if _, err := sub.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	bad := &ListSubscription{URL: ls.URL + "/lists.json", ChecksumURL: ls.URL + "/lists.json.sig"}
+	if _, err := bad.Refresh(context.Background()); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Refresh error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestListSubscription_RequiresVerification(t *testing.T) {
+	t.Parallel()
+
+	sub := &ListSubscription{URL: "http://127.0.0.1:1/lists.json"}
+	if _, err := sub.Refresh(context.Background()); !errors.Is(err, ErrUnverifiedSubscription) {
+		t.Errorf("Refresh error = %v, want ErrUnverifiedSubscription", err)
+	}
+}
+
+func TestListSubscription_RunPolls(t *testing.T) {
+	t.Parallel()
+
+	ls, pub := newListServer(t, `{"blocked":["a"]}`)
+	sub := &ListSubscription{URL: ls.URL + "/lists.json", PublicKey: pub, Interval: 5 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sub.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for ls.requests.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+	if n := ls.requests.Load(); n < 3 {
+		t.Errorf("Run made %d requests, want >= 3", n)
+	}
+}
+
+func TestConfig_SubscriptionAppliesToLicenseChecks(t *testing.T) {
+	t.Parallel()
+
+	ls, pub := newListServer(t, `{"blocked":["stockagency"],"safe":["ourarchive"]}`)
+	sub := &ListSubscription{URL: ls.URL + "/lists.json", PublicKey: pub}
+	cfg := &Config{Subscription: sub}
+
+	blockedCand := ImageCandidate{ImgURL: "https://img.stockagency.example/a.jpg", License: LicenseUnknown}
+	if got := cfg.AssessLicense(blockedCand, nil).License; got != LicenseUnknown {
+		t.Fatalf("before refresh: License = %v, want unknown", got)
+	}
+	if _, err := sub.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cfg.AssessLicense(blockedCand, nil).License; got != LicenseBlocked {
+		t.Errorf("AssessLicense = %v, want blocked by remote list", got)
+	}
+	if !cfg.isBlockedByExtraDomains(blockedCand) {
+		t.Error("isBlockedByExtraDomains = false, want true")
+	}
+	safeCand := ImageCandidate{ImgURL: "https://ourarchive.org/a.jpg", License: LicenseUnknown}
+	if got := cfg.AssessLicense(safeCand, nil).License; got != LicenseSafe {
+		t.Errorf("AssessLicense = %v, want safe by remote list", got)
+	}
+	sigs := ExplainLicense(blockedCand.ImgURL, "", cfg)
+	if len(sigs) != 1 || !strings.Contains(sigs[0].Detail, "RemoteBlockedDomains") {
+		t.Errorf("ExplainLicense = %+v, want one RemoteBlockedDomains match", sigs)
+	}
+}
@@ -0,0 +1,126 @@
+package imagefy
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/anatolykoptev/go-imagefy/textutil"
+)
+
+// minTitleTokenRunes is the shortest word compared by TitleMatch.
+const minTitleTokenRunes = 2
+
+// minStemRunes and minStemShare decide when two words sharing a prefix are
+// inflections of one stem ("moskva" / "moskvy"): the common prefix must be
+// at least minStemRunes long and minStemShare of the shorter word.
+const (
+	minStemRunes = 4
+	minStemShare = 0.7
+)
+
+// textTokens splits s into words folded by textutil.Fold, dropping stop
+// words, numbers, and words shorter than minTitleTokenRunes.
+func textTokens(s string) []string {
+	words := textutil.Words(s)
+	tokens := make([]string, 0, len(words))
+	for _, w := range words {
+		if ruStopWords[w] || enStopWords[w] || isDigits(w) {
+			continue
+		}
+		if w = textutil.Fold(w); utf8.RuneCountInString(w) >= minTitleTokenRunes {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+func isDigits(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
+
+// urlSlug returns the words of the last path segment of rawURL (without
+// its extension), e.g. "red square moscow" for ".../red-square-moscow.jpg".
+func urlSlug(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	base := path.Base(u.Path)
+	if base == "/" || base == "." {
+		return ""
+	}
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// TitleMatch is a lexical relevance score: the fraction (0–1) of query
+// words found in the candidate's Title or in the URL slugs of its Source
+// page and image, after lower-casing, stripping diacritics, and
+// transliterating Cyrillic, with shared-stem inflections counted as
+// matches. It reports false when the candidate has no words to compare,
+// or the query none after stop words.
+func TitleMatch(query string, cand ImageCandidate) (float64, bool) {
+	want := textTokens(query)
+	have := textTokens(cand.Title + " " + urlSlug(cand.Source) + " " + urlSlug(cand.ImgURL))
+	if len(want) == 0 || len(have) == 0 {
+		return 0, false
+	}
+	matched := 0
+	for _, w := range want {
+		for _, h := range have {
+			if sameStem(w, h) {
+				matched++
+				break
+			}
+		}
+	}
+	return float64(matched) / float64(len(want)), true
+}
+
+// sameStem reports whether a and b are equal or share a long enough prefix
+// to be inflections of one word.
+func sameStem(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, rb := []rune(a), []rune(b)
+	n := 0
+	for n < len(ra) && n < len(rb) && ra[n] == rb[n] {
+		n++
+	}
+	shorter := min(len(ra), len(rb))
+	return n >= minStemRunes && float64(n) >= minStemShare*float64(shorter)
+}
+
+// withTitleMatch returns cfg itself when minMatch is zero, otherwise a
+// derived copy that rejects candidates scoring below it against query (see
+// SearchOpts.MinTitleMatch).
+func (cfg *Config) withTitleMatch(query string, minMatch float64) *Config {
+	if minMatch <= 0 || query == "" {
+		return cfg
+	}
+	c := cfg.derive()
+	c.titleQuery, c.minTitleMatch = query, minMatch
+	return c
+}
+
+// filterTitles drops candidates whose TitleMatch against the search query
+// is below SearchOpts.MinTitleMatch, before any network request. They are
+// reported as ReasonTitleMismatch at StageRelevance; candidates without
+// words to compare are kept.
+func (cfg *Config) filterTitles(candidates []ImageCandidate) []ImageCandidate {
+	if cfg.minTitleMatch <= 0 {
+		return candidates
+	}
+	out := candidates[:0:0]
+	for _, c := range candidates {
+		if score, ok := TitleMatch(cfg.titleQuery, c); ok && score < cfg.minTitleMatch {
+			cfg.emitCandidate(CandidateEvent{Candidate: c, Stage: StageRelevance, Reason: ReasonTitleMismatch})
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
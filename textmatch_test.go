@@ -0,0 +1,60 @@
+package imagefy
+
+import "testing"
+
+func TestTitleMatch(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		query string
+		cand  ImageCandidate
+		want  float64
+		ok    bool
+	}{
+		{"inflected title", "Красная площадь Москва", ImageCandidate{Title: "Красная площадь в Москве"}, 1, true},
+		{"transliterated slug", "Красная площадь Москва", ImageCandidate{ImgURL: "https://cdn.example.com/img/krasnaya-ploshchad_1200.jpg"}, 2.0 / 3, true},
+		{"source slug", "eiffel tower", ImageCandidate{Source: "https://travel.example.com/guides/eiffel-tower-at-night"}, 1, true},
+		{"diacritics", "cafe de flore", ImageCandidate{Title: "Le Café de Flore"}, 1, true},
+		{"unrelated", "eiffel tower", ImageCandidate{Title: "Best pizza in Naples"}, 0, true},
+		{"no words", "eiffel tower", ImageCandidate{ImgURL: "https://cdn.example.com/8472.jpg"}, 0, false},
+		{"stop-word query", "the best", ImageCandidate{Title: "Eiffel Tower"}, 0, false},
+	}
+	for _, tc := range cases {
+		got, ok := TitleMatch(tc.query, tc.cand)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("%s: TitleMatch = %v, %v; want %v, %v", tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestFilterTitles(t *testing.T) {
+	t.Parallel()
+
+	var events []CandidateEvent
+	cfg := (&Config{OnCandidateRejected: func(e CandidateEvent) { events = append(events, e) }}).
+		withTitleMatch("eiffel tower paris", 0.5)
+	in := []ImageCandidate{
+		{ImgURL: "https://cdn.example.com/eiffel-tower.jpg"},
+		{ImgURL: "https://cdn.example.com/pizza.jpg", Title: "Pizza in Naples"},
+		{ImgURL: "https://cdn.example.com/123.jpg"},
+		{ImgURL: "https://cdn.example.com/a.jpg", Title: "Tower of London"},
+	}
+
+	out := cfg.filterTitles(in)
+	if len(out) != 2 || out[0].ImgURL != in[0].ImgURL || out[1].ImgURL != in[2].ImgURL {
+		t.Fatalf("filterTitles = %+v", out)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	for _, e := range events {
+		if e.Stage != StageRelevance || e.Reason != ReasonTitleMismatch {
+			t.Errorf("event = %s/%s, want relevance/title_mismatch", e.Stage, e.Reason)
+		}
+	}
+
+	if got := (&Config{}).withTitleMatch("eiffel", 0).filterTitles(in); len(got) != len(in) {
+		t.Errorf("MinTitleMatch 0: kept %d of %d", len(got), len(in))
+	}
+}
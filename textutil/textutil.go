@@ -0,0 +1,147 @@
+// Package textutil exports the text normalization go-imagefy applies to
+// queries and titles: Cyrillic↔Latin transliteration, diacritic
+// stripping, word splitting, and slugs. Consumers that store queries or
+// compare titles themselves should normalize with it to agree with
+// imagefy.TitleMatch and the moderation blocklists.
+//
+// Comparisons fold both sides to Latin. ToCyrillic goes the other way, for
+// building Russian queries from romanized names; romanization loses
+// information (ь and ъ are dropped, ё and е both become e), so it is a
+// best guess rather than an exact inverse.
+//
+//	textutil.Fold("Café Пушкинъ")     // "cafe pushkin"
+//	textutil.Slugify("Щёлково, 2024") // "shchelkovo-2024"
+//	textutil.ToCyrillic("Shchelkovo") // "Щелково"
+package textutil
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// cyrillicLatin transliterates Russian, Ukrainian, and Belarusian letters
+// (lower case) to Latin, close to the BGN/PCGN and Yandex slug conventions.
+var cyrillicLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// Transliterate replaces Russian, Ukrainian, and Belarusian letters in s
+// with Latin ones, close to the BGN/PCGN and Yandex slug conventions
+// ("Щёлково" → "Shchelkovo"). Upper-case letters start an upper-case
+// transliteration; everything else, Latin included, is kept as is.
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFC.String(s) {
+		lat, ok := cyrillicLatin[unicode.ToLower(r)]
+		switch {
+		case !ok:
+			b.WriteRune(r)
+		case unicode.IsUpper(r) && lat != "":
+			first, size := utf8.DecodeRuneInString(lat)
+			b.WriteRune(unicode.ToUpper(first))
+			b.WriteString(lat[size:])
+		default:
+			b.WriteString(lat)
+		}
+	}
+	return b.String()
+}
+
+// latinCyrillic maps Latin letters and letter groups to Russian Cyrillic,
+// longest first, reversing the Russian part of cyrillicLatin; letters
+// cyrillicLatin never produces take their usual reading. "y" alone is
+// handled by ToCyrillic.
+var latinCyrillic = []struct{ lat, cyr string }{
+	{"shch", "щ"}, {"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"ya", "я"}, {"yo", "ё"}, {"ye", "е"},
+	{"a", "а"}, {"b", "б"}, {"v", "в"}, {"g", "г"}, {"d", "д"}, {"e", "е"}, {"z", "з"},
+	{"i", "и"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"},
+	{"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"}, {"f", "ф"},
+	{"c", "к"}, {"h", "х"}, {"j", "й"}, {"q", "к"}, {"w", "в"}, {"x", "кс"},
+}
+
+// ToCyrillic replaces Latin letters in s with Russian Cyrillic ones,
+// reversing Transliterate ("Shchelkovo" → "Щелково"). A lone "y" is й after
+// a vowel or at the start of a word ("Sergey" → "Сергей") and ы after a
+// consonant. An upper-case letter starts an upper-case Cyrillic letter;
+// everything else, Cyrillic included, is kept as is.
+func ToCyrillic(s string) string {
+	lower := []byte(s)
+	for i, c := range lower {
+		if 'A' <= c && c <= 'Z' {
+			lower[i] = c + 'a' - 'A'
+		}
+	}
+	var b strings.Builder
+	afterVowel := true // no letter yet: word start
+	for i := 0; i < len(s); {
+		if lower[i] < 'a' || lower[i] > 'z' {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			b.WriteRune(r)
+			afterVowel = !unicode.IsLetter(r) || strings.ContainsRune("аеёиоуыэюяaeiouy", unicode.ToLower(r))
+			i += size
+			continue
+		}
+		lat, cyr := "y", "ы"
+		if afterVowel {
+			cyr = "й"
+		}
+		for _, m := range latinCyrillic {
+			if strings.HasPrefix(string(lower[i:]), m.lat) {
+				lat, cyr = m.lat, m.cyr
+				break
+			}
+		}
+		if s[i] != lower[i] {
+			first, size := utf8.DecodeRuneInString(cyr)
+			b.WriteRune(unicode.ToUpper(first))
+			b.WriteString(cyr[size:])
+		} else {
+			b.WriteString(cyr)
+		}
+		afterVowel = strings.Contains("aeiouy", lat[len(lat)-1:])
+		i += len(lat)
+	}
+	return b.String()
+}
+
+// StripDiacritics removes combining marks from s after canonical
+// decomposition, so "Crème" becomes "Creme". It also strips the marks of
+// Cyrillic й and ї; call Transliterate first to keep them apart from и and і.
+func StripDiacritics(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Fold lower-cases s, transliterates Cyrillic to Latin, and strips
+// diacritics, so "Café Пушкинъ" and "cafe pushkin" compare equal. It is the
+// normalization imagefy.TitleMatch compares words with.
+func Fold(s string) string {
+	return StripDiacritics(Transliterate(strings.ToLower(s)))
+}
+
+// Words splits s into lower-cased words on anything that is not a letter
+// or digit, the way imagefy splits queries for moderation and matching.
+func Words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Slugify returns the folded words of s joined by hyphens, e.g.
+// "krasnaya-ploshchad" for "Красная площадь!".
+func Slugify(s string) string {
+	return strings.Join(Words(Fold(s)), "-")
+}
@@ -0,0 +1,89 @@
+package textutil
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"Café Crème": "cafe creme",
+		"Пушкинъ":    "pushkin",
+		"Щёлково Ярославль Йошкар": "shchelkovo yaroslavl yoshkar",
+		"Київ": "kiyiv",
+	}
+	for in, want := range cases {
+		if got := Fold(in); got != want {
+			t.Errorf("Fold(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"Щёлково":      "Shchelkovo",
+		"ЖУК":          "ZhUK",
+		"Объект, café": "Obekt, café",
+		"Київ":         "Kiyiv",
+	}
+	for in, want := range cases {
+		if got := Transliterate(in); got != want {
+			t.Errorf("Transliterate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestToCyrillic(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"Shchelkovo":          "Щелково",
+		"Krasnaya ploshchad":  "Красная площад",
+		"Sergey Dostoyevskiy": "Сергей Достоевский",
+		"ZhUK, Yekaterinburg": "ЖУК, Екатеринбург",
+		"Tsaritsyno Khimki":   "Царицыно Химки",
+		"yolka, Кремль 2024":  "ёлка, Кремль 2024",
+	}
+	for in, want := range cases {
+		if got := ToCyrillic(in); got != want {
+			t.Errorf("ToCyrillic(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for _, word := range []string{"Москва", "Чехов", "Жуковский", "Юрьевец"} {
+		if got := ToCyrillic(Transliterate(word)); got != strings.ReplaceAll(word, "ь", "") {
+			t.Errorf("ToCyrillic(Transliterate(%q)) = %q", word, got)
+		}
+	}
+}
+
+func TestStripDiacritics(t *testing.T) {
+	t.Parallel()
+
+	if got := StripDiacritics("Crème brûlée, São Paulo"); got != "Creme brulee, Sao Paulo" {
+		t.Errorf("StripDiacritics = %q", got)
+	}
+}
+
+func TestWordsAndSlugify(t *testing.T) {
+	t.Parallel()
+
+	if got := Words("Kazan, Kul-Sharif 2024!"); !slices.Equal(got, []string{"kazan", "kul", "sharif", "2024"}) {
+		t.Errorf("Words = %q", got)
+	}
+	cases := map[string]string{
+		"Красная площадь!":      "krasnaya-ploshchad",
+		"  Café Crème — Paris ": "cafe-creme-paris",
+		"Щёлково, 2024":         "shchelkovo-2024",
+		"!!!":                   "",
+	}
+	for in, want := range cases {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package imagefy
+
+import (
+	"slices"
+	"strings"
+)
+
+// withRequiredLabels returns cfg itself when labels is empty, otherwise a
+// derived copy whose classifications ask for topic labels and whose vision
+// stage enforces them (see SearchOpts.RequireLabels).
+func (cfg *Config) withRequiredLabels(labels []string) *Config {
+	var norm []string
+	for _, l := range labels {
+		if l = strings.ToUpper(strings.TrimSpace(l)); l != "" && !slices.Contains(norm, l) {
+			norm = append(norm, l)
+		}
+	}
+	if len(norm) == 0 {
+		return cfg
+	}
+	c := cfg.derive()
+	c.requireLabels = norm
+	return c
+}
+
+// withLabelHint appends to prompt the instruction to tag the image with the
+// required labels that apply.
+func withLabelHint(prompt string, labels []string) string {
+	if len(labels) == 0 {
+		return prompt
+	}
+	list := strings.Join(labels, ", ")
+	return prompt + "\n\nAlso tag the image with every one of these topic labels that applies: " + list +
+		". Write them after the class, comma-separated, before the confidence, e.g. " +
+		ClassPhoto + "," + labels[0] + " 0.92. Omit labels that do not apply."
+}
+
+// offTopic reports whether a classification fails the search's required
+// labels: it has a class but none of the labels. Unparsed results are not
+// judged.
+func (cfg *Config) offTopic(result ClassificationResult) bool {
+	if len(cfg.requireLabels) == 0 || result.Class == "" {
+		return false
+	}
+	for _, l := range result.Labels {
+		if slices.Contains(cfg.requireLabels, l) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package imagefy
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRequireLabels(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+
+	tests := []struct {
+		name       string
+		response   string
+		license    ImageLicense
+		wantReason RejectReason
+	}{
+		{"required label", "PHOTO,FOOD 0.9", LicenseUnknown, ""},
+		{"other label", "PHOTO,STREET 0.9", LicenseUnknown, ReasonOffTopic},
+		{"no labels", "PHOTO 0.9", LicenseUnknown, ReasonOffTopic},
+		{"safe license still classified", "PHOTO,STREET 0.9", LicenseSafe, ReasonOffTopic},
+		{"unparsed answer is not judged", "no idea", LicenseUnknown, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cl := &promptCapturingClassifier{response: tt.response}
+			var rejected []CandidateEvent
+			base := &Config{Classifier: cl, OnCandidateRejected: func(e CandidateEvent) { rejected = append(rejected, e) }}
+			cfg := base.withRequiredLabels([]string{"food", " interior ", "FOOD"})
+			if got := cfg.requireLabels; strings.Join(got, ",") != "FOOD,INTERIOR" {
+				t.Fatalf("requireLabels = %v", got)
+			}
+
+			cand := ImageCandidate{ImgURL: srv.URL + "/dish.jpg", Source: srv.URL + "/review", License: tt.license}
+			got := cfg.ValidateCandidates(context.Background(), []ImageCandidate{cand}, 1)
+			if tt.wantReason == "" {
+				if len(got) != 1 {
+					t.Fatalf("got %d results, rejected %+v; want accepted", len(got), rejected)
+				}
+			} else if len(rejected) != 1 || rejected[0].Reason != tt.wantReason {
+				t.Fatalf("rejected = %+v, want %s", rejected, tt.wantReason)
+			}
+			if !strings.Contains(cl.capturedPrompt, "topic labels that applies: FOOD, INTERIOR") {
+				t.Errorf("prompt lacks the label hint: %q", cl.capturedPrompt)
+			}
+		})
+	}
+}
+
+func TestRequireLabels_CacheKey(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Cache: &mockCache{store: map[string]any{}}}
+	plain := cfg.visionCacheKey("https://example.com/a.jpg")
+	labeled := cfg.withRequiredLabels([]string{"FOOD"}).visionCacheKey("https://example.com/a.jpg")
+	if plain == labeled {
+		t.Errorf("labeled classifications share the cache key %q", plain)
+	}
+}
@@ -0,0 +1,42 @@
+package imagefy
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Default transport tuning. http.DefaultTransport keeps only 2 idle
+// connections per host, so parallel probes and downloads against one CDN
+// keep re-dialing and re-handshaking.
+const (
+	defaultMaxIdleConns        = 256
+	defaultMaxIdleConnsPerHost = 16
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultTLSSessionCacheSize = 256
+)
+
+// defaultHTTPClient is the client used when Config.HTTPClient (or a
+// provider's HTTPClient) is nil. It is shared by every Config so idle
+// connections and TLS sessions are reused across searches.
+var defaultHTTPClient = &http.Client{Transport: NewTransport()}
+
+// NewTransport returns the tuned transport behind the default HTTP client:
+// http.DefaultTransport's proxy and dialer settings with larger idle pools
+// (256 total, 16 per host), a 90s idle timeout, TLS session resumption, and
+// HTTP/2 enabled. Use it as the base of a custom Config.HTTPClient to keep
+// the tuning, e.g. when adding a cookie jar or timeout.
+func NewTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // stdlib guarantees the type
+	t.MaxIdleConns = defaultMaxIdleConns
+	t.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	t.IdleConnTimeout = defaultIdleConnTimeout
+	t.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	t.ForceAttemptHTTP2 = true
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	t.TLSClientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(defaultTLSSessionCacheSize)
+	return t
+}
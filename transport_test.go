@@ -0,0 +1,67 @@
+package imagefy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewTransport_Tuning(t *testing.T) {
+	t.Parallel()
+
+	tr := NewTransport()
+	if tr.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost || tr.MaxIdleConns != defaultMaxIdleConns {
+		t.Errorf("idle pool = %d total / %d per host, want %d / %d",
+			tr.MaxIdleConns, tr.MaxIdleConnsPerHost, defaultMaxIdleConns, defaultMaxIdleConnsPerHost)
+	}
+	if tr.IdleConnTimeout != defaultIdleConnTimeout || !tr.ForceAttemptHTTP2 {
+		t.Errorf("IdleConnTimeout = %v, ForceAttemptHTTP2 = %v", tr.IdleConnTimeout, tr.ForceAttemptHTTP2)
+	}
+	if tr.TLSClientConfig == nil || tr.TLSClientConfig.ClientSessionCache == nil {
+		t.Error("TLS session cache not configured")
+	}
+	if tr.Proxy == nil {
+		t.Error("proxy-from-environment setting of http.DefaultTransport was dropped")
+	}
+	if NewTransport() == tr {
+		t.Error("NewTransport returned a shared transport")
+	}
+}
+
+func TestDefaults_UseTunedClient(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+	cfg.defaults()
+	if cfg.HTTPClient != defaultHTTPClient {
+		t.Fatal("defaults() did not install the tuned default client")
+	}
+	custom := &http.Client{}
+	cfg = &Config{HTTPClient: custom}
+	cfg.defaults()
+	if cfg.HTTPClient != custom {
+		t.Error("defaults() replaced a caller-supplied HTTPClient")
+	}
+}
+
+func TestNewTransport_NegotiatesHTTP2(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	tr := NewTransport()
+	tr.TLSClientConfig.RootCAs = srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	defer tr.CloseIdleConnections()
+
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("protocol = %s, want HTTP/2", resp.Proto)
+	}
+}
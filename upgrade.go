@@ -0,0 +1,92 @@
+package imagefy
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// thumbRendition matches the last path segment of a MediaWiki thumbnail,
+// e.g. "640px-Cat.jpg" under /thumb/a/ab/Cat.jpg/.
+var thumbRendition = regexp.MustCompile(`^\d+px-`)
+
+// upgradeParams are query parameters that ask a CDN to resize; without
+// them most image CDNs serve the stored original.
+var upgradeParams = map[string]bool{
+	"w": true, "h": true, "width": true, "height": true, "size": true, "resize": true,
+}
+
+// upgradedURL returns the URL of the original rendition of a size-variant
+// image URL, and false if rawURL matches none of the recognized patterns:
+// a WordPress "-300x200" file suffix, resize query parameters (?w=640),
+// or a /thumb/ path segment (MediaWiki and similar generators).
+func upgradedURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	p := u.Path
+
+	if before, after, ok := strings.Cut(p, "/thumb/"); ok {
+		if dir, file := path.Split(after); dir != "" && thumbRendition.MatchString(file) {
+			after = strings.TrimSuffix(dir, "/")
+		}
+		p = before + "/" + after
+	}
+
+	dir, file := path.Split(p)
+	ext := path.Ext(file)
+	if stem := strings.TrimSuffix(file, ext); ext != "" {
+		if s := dimensionSuffix.ReplaceAllString(stem, ""); s != "" {
+			p = dir + s + ext
+		}
+	}
+
+	query := u.RawQuery
+	if q := u.Query(); len(q) > 0 {
+		resized := false
+		for k := range q {
+			if upgradeParams[strings.ToLower(k)] {
+				q.Del(k)
+				resized = true
+			}
+		}
+		if resized {
+			query = q.Encode()
+		}
+	}
+
+	if p == u.Path && query == u.RawQuery {
+		return "", false
+	}
+	u.Path, u.RawPath, u.RawQuery = p, "", query
+	return u.String(), true
+}
+
+// upgradeCandidate swaps cand.ImgURL for its original rendition (see
+// upgradedURL) when Config.UpgradeSizeVariants is set and the original
+// passes the HTTP probe; the size variant is kept as Thumbnail if cand has
+// none. Otherwise cand is returned unchanged.
+func (cfg *Config) upgradeCandidate(ctx context.Context, cand ImageCandidate, st *searchState) ImageCandidate {
+	if !cfg.UpgradeSizeVariants {
+		return cand
+	}
+	orig, ok := upgradedURL(cand.ImgURL)
+	if !ok || st.used.contains(orig) {
+		return cand
+	}
+	st.hosts.wait(ctx, orig)
+	if reason := cfg.probeImageURL(ctx, orig, cand.referer); reason != "" {
+		slog.Debug("imagefy: size variant upgrade failed", "url", orig, "reason", reason)
+		return cand
+	}
+	slog.Debug("imagefy: upgraded size variant", "from", cand.ImgURL, "to", orig)
+	if cand.Thumbnail == "" {
+		cand.Thumbnail = cand.ImgURL
+	}
+	cand.ImgURL = orig
+	return cand
+}
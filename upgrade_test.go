@@ -0,0 +1,70 @@
+package imagefy
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUpgradedURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"https://example.com/wp-content/uploads/2024/05/cat-300x200.jpg", "https://example.com/wp-content/uploads/2024/05/cat.jpg"},
+		{"https://cdn.example.com/photo.jpg?w=640&id=7", "https://cdn.example.com/photo.jpg?id=7"},
+		{"https://cdn.example.com/photo.jpg?width=640&height=480", "https://cdn.example.com/photo.jpg"},
+		{"https://upload.wikimedia.org/wikipedia/commons/thumb/a/ab/Cat.jpg/640px-Cat.jpg", "https://upload.wikimedia.org/wikipedia/commons/a/ab/Cat.jpg"},
+		{"https://example.com/images/thumb/cat.jpg", "https://example.com/images/cat.jpg"},
+		{"https://example.com/uploads/cat-1024x768.png?w=300", "https://example.com/uploads/cat.png"},
+	}
+	for _, tt := range tests {
+		got, ok := upgradedURL(tt.in)
+		if !ok || got != tt.want {
+			t.Errorf("upgradedURL(%q) = %q, %v; want %q, true", tt.in, got, ok, tt.want)
+		}
+	}
+
+	for _, in := range []string{
+		"https://example.com/photos/cat.jpg",
+		"https://example.com/photos/cat.jpg?id=7",
+		"https://example.com/photos/1920x1080.jpg",
+		"not a url",
+	} {
+		if got, ok := upgradedURL(in); ok {
+			t.Errorf("upgradedURL(%q) = %q, want no upgrade", in, got)
+		}
+	}
+}
+
+func TestUpgradeSizeVariants(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultiImageServer(t, map[string][]byte{
+		"/uploads/cat-300x200.jpg": makeJPEG(300, 200),
+		"/uploads/cat.jpg":         makeJPEG(1200, 800),
+		"/uploads/dog-300x200.jpg": makeJPEG(300, 200),
+	})
+	variant := ImageCandidate{ImgURL: srv.URL + "/uploads/cat-300x200.jpg", Source: srv.URL + "/post"}
+	missing := ImageCandidate{ImgURL: srv.URL + "/uploads/dog-300x200.jpg", Source: srv.URL + "/post"}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		cfg := &Config{MinImageWidth: 1000}
+		if got := cfg.ValidateCandidates(context.Background(), []ImageCandidate{variant}, 1); len(got) != 0 {
+			t.Errorf("got %d results, want the 300px variant rejected", len(got))
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Parallel()
+		cfg := &Config{MinImageWidth: 1000, UpgradeSizeVariants: true}
+		got := cfg.ValidateCandidates(context.Background(), []ImageCandidate{variant, missing}, 2)
+		if len(got) != 1 {
+			t.Fatalf("got %d results, want 1", len(got))
+		}
+		if got[0].ImgURL != srv.URL+"/uploads/cat.jpg" || got[0].Thumbnail != variant.ImgURL {
+			t.Errorf("got ImgURL %q, Thumbnail %q; want the original with the variant as thumbnail", got[0].ImgURL, got[0].Thumbnail)
+		}
+	})
+}
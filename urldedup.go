@@ -0,0 +1,87 @@
+package imagefy
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// dimensionSuffix matches a "-1200x800" / "_640x480" size suffix.
+	dimensionSuffix = regexp.MustCompile(`[-_]\d{2,4}x\d{2,4}$`)
+	// widthSuffix matches a "_640" / "-1200w" / "_800px" size suffix.
+	widthSuffix = regexp.MustCompile(`[-_](\d{3,4})(?:w|px)?$`)
+)
+
+// commonWidths are the rendition widths recognized in widthSuffix, so that
+// sequence numbers like IMG_2034 are not mistaken for sizes.
+var commonWidths = map[string]bool{
+	"150": true, "160": true, "180": true, "200": true, "240": true, "250": true,
+	"300": true, "320": true, "360": true, "400": true, "480": true, "500": true,
+	"540": true, "600": true, "640": true, "720": true, "750": true, "768": true,
+	"800": true, "960": true, "1024": true, "1080": true, "1200": true, "1280": true,
+	"1440": true, "1536": true, "1600": true, "1920": true, "2048": true,
+}
+
+// sizeParams are query parameters that select a rendition of the same
+// asset, ignored by assetKey.
+var sizeParams = map[string]bool{
+	"w": true, "h": true, "width": true, "height": true, "size": true, "resize": true,
+	"fit": true, "crop": true, "quality": true, "q": true, "dpr": true, "auto": true,
+	"fm": true, "format": true,
+}
+
+// assetKey returns the key under which URLs of the same image asset
+// collide: the NormalizeURL form with size-variant suffixes (_640,
+// -1200x800) removed from the file name and rendition query parameters
+// (w, h, quality, ...) dropped.
+func assetKey(rawURL string) string {
+	u, err := url.Parse(NormalizeURL(rawURL))
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	dir, file := path.Split(u.Path)
+	ext := path.Ext(file)
+	stem := strings.TrimSuffix(file, ext)
+	if s := dimensionSuffix.ReplaceAllString(stem, ""); s != "" {
+		stem = s
+	}
+	if m := widthSuffix.FindStringSubmatchIndex(stem); m != nil && m[0] > 0 && commonWidths[stem[m[2]:m[3]]] {
+		stem = stem[:m[0]]
+	}
+	u.Path = dir + stem + strings.ToLower(ext)
+	u.RawPath = ""
+
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if sizeParams[strings.ToLower(k)] {
+				q.Del(k)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// dedupURLs drops candidates whose ImgURL has the same assetKey as an
+// earlier one — the same asset returned by several engines, or in several
+// sizes — before any network request. Dropped candidates are reported as
+// ReasonDuplicate at StageDedup. Earlier candidates win, so callers keep
+// their safe-first order.
+func (cfg *Config) dedupURLs(candidates []ImageCandidate) []ImageCandidate {
+	seen := make(map[string]bool, len(candidates))
+	out := candidates[:0:0]
+	for _, c := range candidates {
+		key := assetKey(c.ImgURL)
+		if seen[key] {
+			cfg.emitCandidate(CandidateEvent{Candidate: c, Stage: StageDedup, Reason: ReasonDuplicate})
+			continue
+		}
+		seen[key] = true
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package imagefy
+
+import "testing"
+
+func TestAssetKey(t *testing.T) {
+	t.Parallel()
+
+	same := [][]string{
+		{"https://cdn.example.com/photos/cat.jpg", "https://CDN.example.com/photos/cat.jpg?utm_source=x#top"},
+		{"https://cdn.example.com/photos/cat.jpg", "https://cdn.example.com/photos/cat-1200x800.jpg"},
+		{"https://cdn.example.com/photos/cat.jpg", "https://cdn.example.com/photos/cat_640.jpg"},
+		{"https://cdn.example.com/photos/cat.jpg", "https://cdn.example.com/photos/cat-1024w.JPG"},
+		{"https://cdn.example.com/photos/cat.jpg", "https://cdn.example.com/photos/cat.jpg?w=640&q=80"},
+		{"https://cdn.example.com/p.jpg?id=7&w=300", "https://cdn.example.com/p.jpg?w=1200&id=7"},
+	}
+	for _, p := range same {
+		if a, b := assetKey(p[0]), assetKey(p[1]); a != b {
+			t.Errorf("assetKey(%q) = %q, assetKey(%q) = %q; want equal", p[0], a, p[1], b)
+		}
+	}
+
+	distinct := [][]string{
+		{"https://cdn.example.com/photos/IMG_2034.jpg", "https://cdn.example.com/photos/IMG_2035.jpg"},
+		{"https://cdn.example.com/photos/cat.jpg", "https://cdn.example.com/photos/dog.jpg"},
+		{"https://a.example.com/cat.jpg", "https://b.example.com/cat.jpg"},
+		{"https://cdn.example.com/p.jpg?id=7", "https://cdn.example.com/p.jpg?id=8"},
+	}
+	for _, p := range distinct {
+		if a, b := assetKey(p[0]), assetKey(p[1]); a == b {
+			t.Errorf("assetKey(%q) == assetKey(%q) = %q; want distinct", p[0], p[1], a)
+		}
+	}
+
+	if got := assetKey("https://cdn.example.com/640.jpg"); got != "https://cdn.example.com/640.jpg" {
+		t.Errorf("bare size file name: got %q", got)
+	}
+}
+
+func TestDedupURLs(t *testing.T) {
+	t.Parallel()
+
+	var events []CandidateEvent
+	cfg := &Config{OnCandidateRejected: func(e CandidateEvent) { events = append(events, e) }}
+	in := []ImageCandidate{
+		{ImgURL: "https://cdn.example.com/cat.jpg", Source: "https://a.example.com/"},
+		{ImgURL: "https://cdn.example.com/cat-300x200.jpg", Source: "https://b.example.com/"},
+		{ImgURL: "https://cdn.example.com/dog.jpg"},
+		{ImgURL: "https://cdn.example.com/cat.jpg?utm_medium=feed"},
+	}
+
+	out := cfg.dedupURLs(in)
+	if len(out) != 2 || out[0].Source != "https://a.example.com/" || out[1].ImgURL != "https://cdn.example.com/dog.jpg" {
+		t.Fatalf("dedupURLs = %+v", out)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	for _, e := range events {
+		if e.Stage != StageDedup || e.Reason != ReasonDuplicate {
+			t.Errorf("event = %s/%s, want dedup/duplicate", e.Stage, e.Reason)
+		}
+	}
+	if in[0].ImgURL != "https://cdn.example.com/cat.jpg" || in[1].ImgURL != "https://cdn.example.com/cat-300x200.jpg" {
+		t.Error("input slice was modified")
+	}
+}
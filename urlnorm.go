@@ -0,0 +1,76 @@
+package imagefy
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// trackingParams are query parameters that identify a click or campaign,
+// not the resource, and are dropped by NormalizeURL.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"igshid": true, "mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true,
+	"ref_src": true, "spm": true,
+}
+
+// NormalizeURL returns the canonical form of rawURL used to compare image
+// URLs: lower-case scheme and host, IDN hosts in punycode, default ports
+// removed, percent-encoding in the path made canonical, tracking parameters
+// (utm_*, fbclid, gclid, ...) dropped, remaining parameters sorted, and the
+// fragment removed. Unparsable or relative URLs are returned unchanged.
+//
+// The pipeline uses it for the used-image history, classification cache and
+// feedback keys, and license host checks; candidates are still fetched by
+// their original URLs, which signed CDN links may depend on.
+func NormalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = normalizeHost(u.Scheme, u.Host)
+	u.User = nil
+	u.Fragment, u.RawFragment = "", ""
+	// Re-escape the path canonically, unless it encodes a slash that
+	// decoding would turn into a path separator.
+	if !strings.Contains(strings.ToLower(u.RawPath), "%2f") {
+		u.RawPath = ""
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	if u.RawQuery != "" {
+		q, err := url.ParseQuery(u.RawQuery)
+		if err == nil {
+			for k := range q {
+				if lk := strings.ToLower(k); trackingParams[lk] || strings.HasPrefix(lk, "utm_") {
+					q.Del(k)
+				}
+			}
+			u.RawQuery = q.Encode()
+		}
+	}
+	u.ForceQuery = false
+	return u.String()
+}
+
+// normalizeHost lower-cases host, converts an IDN to punycode, and drops
+// the scheme's default port.
+func normalizeHost(scheme, host string) string {
+	hostname, port := host, ""
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		hostname, port = host[:i], host[i+1:]
+	}
+	if ascii, err := idna.Lookup.ToASCII(hostname); err == nil {
+		hostname = ascii
+	}
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") || port == "" {
+		return hostname
+	}
+	return hostname + ":" + port
+}
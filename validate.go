@@ -18,12 +18,29 @@ import (
 // ValidateImageURL fetches image headers and checks:
 //   - HTTP 200 + image/* content type
 //   - Width >= cfg.MinImageWidth
+//   - Width/height within cfg.MinAspectRatio / MaxAspectRatio, if set
 //   - Not a logo/banner (URL pattern check)
 func (cfg *Config) ValidateImageURL(ctx context.Context, rawURL string) bool {
+	cfg = cfg.orZero()
 	cfg.defaults()
+	return cfg.probeImageURL(ctx, rawURL, "") == ""
+}
+
+// probeImageURL performs the ValidateImageURL checks, sending referer as
+// the Referer header if set, and returns the reason the URL failed, or ""
+// if it passed. Hotlink blocks (see isHotlinkResponse) and placeholder
+// pixels are reported as ReasonHotlinkBlocked.
+func (cfg *Config) probeImageURL(ctx context.Context, rawURL, referer string) RejectReason {
+	reason, _ := cfg.probeImage(ctx, rawURL, referer)
+	return reason
+}
 
+// probeImage is probeImageURL that also returns the body's prefix
+// fingerprint when Config.PrefixDedupBytes is set (see prefixFingerprint),
+// or "" when none could be taken.
+func (cfg *Config) probeImage(ctx context.Context, rawURL, referer string) (reason RejectReason, prefix string) {
 	if IsLogoOrBanner(strings.ToLower(rawURL)) {
-		return false
+		return ReasonLogoOrBanner, ""
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
@@ -31,46 +48,80 @@ func (cfg *Config) ValidateImageURL(ctx context.Context, rawURL string) bool {
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
-		return false
+		return ReasonProbeFailed, ""
+	}
+	req.Header = cfg.requestHeader(rawURL, "")
+	if referer != "" {
+		req.Header.Set("Referer", referer)
 	}
-	req.Header.Set("User-Agent", cfg.UserAgent)
 
-	client := cfg.validationClient()
+	client := cfg.downloadClient(cfg.probeClient(rawURL))
 	resp, err := client.Do(req) //nolint:gosec // G704: URL is caller-supplied by design — SSRF is caller's responsibility
 	if err != nil {
-		return false
+		return ReasonProbeFailed, ""
 	}
 	defer resp.Body.Close()
 
+	ct := resp.Header.Get("Content-Type")
+	if isHotlinkResponse(resp.StatusCode, ct) {
+		return ReasonHotlinkBlocked, ""
+	}
 	if resp.StatusCode != http.StatusOK {
-		return false
+		return ReasonProbeFailed, ""
 	}
-	ct := resp.Header.Get("Content-Type")
 	if !strings.HasPrefix(ct, "image/") {
-		return false
+		return ReasonNotImage, ""
 	}
 
-	const decodeLimit = 256 * 1024
-	imgCfg, _, err := image.DecodeConfig(io.LimitReader(resp.Body, decodeLimit))
+	body, ok := decodedBody(resp)
+	if !ok {
+		return ReasonProbeFailed, ""
+	}
+	src := io.LimitReader(body, probeDecodeLimit)
+	capture := cfg.capturePrefix(resp, &src)
+	imgCfg, _, err := image.DecodeConfig(src)
+	prefix = capture.fingerprint(resp.ContentLength)
 	if err != nil {
 		// Can't decode dimensions — accept (passed content-type check).
-		return true
+		return "", prefix
 	}
 
+	if isPlaceholderSize(imgCfg.Width, imgCfg.Height) {
+		slog.Debug("imagefy: placeholder pixel", "url", rawURL, "width", imgCfg.Width, "height", imgCfg.Height)
+		return ReasonHotlinkBlocked, ""
+	}
 	if imgCfg.Width < cfg.MinImageWidth {
 		slog.Debug("imagefy: too narrow", "url", rawURL, "width", imgCfg.Width, "min", cfg.MinImageWidth)
-		return false
+		return ReasonTooNarrow, ""
+	}
+	if reason := cfg.checkAspectRatio(imgCfg.Width, imgCfg.Height); reason != "" {
+		slog.Debug("imagefy: bad aspect ratio", "url", rawURL, "width", imgCfg.Width, "height", imgCfg.Height)
+		return reason, ""
 	}
 
-	return true
+	return "", prefix
+}
+
+// checkAspectRatio returns ReasonBadAspectRatio if width/height falls outside
+// Config.MinAspectRatio / MaxAspectRatio (zero bounds are not checked).
+func (cfg *Config) checkAspectRatio(width, height int) RejectReason {
+	if height <= 0 || (cfg.MinAspectRatio <= 0 && cfg.MaxAspectRatio <= 0) {
+		return ""
+	}
+	ratio := float64(width) / float64(height)
+	if (cfg.MinAspectRatio > 0 && ratio < cfg.MinAspectRatio) || (cfg.MaxAspectRatio > 0 && ratio > cfg.MaxAspectRatio) {
+		return ReasonBadAspectRatio
+	}
+	return ""
 }
 
 // validationClient returns an HTTP client for image URL validation.
 // Uses plain HTTPClient (fast, no proxy overhead). StealthClient is used
-// only by Download() as a fallback when HTTPClient gets blocked.
+// only by Download() as a fallback when HTTPClient gets blocked, and for
+// Config.StealthHosts (see probeClient).
 func (cfg *Config) validationClient() *http.Client {
 	return &http.Client{
-		Transport: cfg.HTTPClient.Transport,
+		Transport: cfg.directClient().Transport,
 		Timeout:   defaultTimeout,
 		Jar:       cfg.HTTPClient.Jar,
 		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
@@ -0,0 +1,103 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"log/slog"
+	"net/http"
+)
+
+// ErrEmptyImage is returned by ValidateImageBytes when data is empty.
+var ErrEmptyImage = errors.New("imagefy: empty image data")
+
+// ValidationReport is the outcome of ValidateImageBytes.
+type ValidationReport struct {
+	Valid    bool         // true if the image passed every check
+	Stage    Stage        // stage that accepted or rejected the image
+	Reason   RejectReason // "" when Valid
+	Format   string       // decoded format: "jpeg", "png", "gif", "webp"; "" if undecodable
+	MIMEType string       // sniffed content type
+	Width    int
+	Height   int
+
+	License        LicenseAssessment    // metadata-based license assessment
+	Classification ClassificationResult // zero unless the Classifier was consulted
+	Degraded       []Stage              // checks that failed to run (see Config.DegradationPolicy)
+}
+
+// ValidateImageBytes applies the validation policy of the search pipeline to
+// an image that is already in memory (e.g. a user upload):
+//   - the data must decode as JPEG, PNG, GIF, or WebP
+//   - Width >= cfg.MinImageWidth, and width/height within
+//     cfg.MinAspectRatio / MaxAspectRatio if set
+//   - embedded metadata must not name a stock agency
+//   - unless metadata marks it Creative Commons (and Config.VerifySafeDomains
+//     is unset), the vision classifier (if configured) must classify it as
+//     PHOTO, which also rejects logos and placeholders
+//
+// URL heuristics (logo/banner patterns, domain lists, reverse search) do not
+// apply. Policy rejections are reported in the ValidationReport; the error is
+// non-nil only for empty data or a done context.
+func (cfg *Config) ValidateImageBytes(ctx context.Context, data []byte) (ValidationReport, error) {
+	cfg = cfg.orZero()
+	cfg.defaults()
+
+	if len(data) == 0 {
+		return ValidationReport{}, ErrEmptyImage
+	}
+	if err := ctx.Err(); err != nil {
+		return ValidationReport{}, err
+	}
+
+	report := ValidationReport{Stage: StageProbe, MIMEType: http.DetectContentType(data)}
+	imgCfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		report.Reason = ReasonNotImage
+		return report, nil
+	}
+	report.Format, report.Width, report.Height = format, imgCfg.Width, imgCfg.Height
+	if imgCfg.Width < cfg.MinImageWidth {
+		slog.Debug("imagefy: too narrow", "width", imgCfg.Width, "min", cfg.MinImageWidth)
+		report.Reason = ReasonTooNarrow
+		return report, nil
+	}
+	if reason := cfg.checkAspectRatio(imgCfg.Width, imgCfg.Height); reason != "" {
+		report.Reason = reason
+		return report, nil
+	}
+
+	report.Stage = StageLicense
+	report.License = cfg.AssessLicense(ImageCandidate{}, cfg.imageMetadata(ctx, data))
+	switch report.License.License {
+	case LicenseBlocked:
+		report.Reason = report.License.rejectReason()
+		return report, nil
+	case LicenseSafe:
+		if !cfg.verifiesSafe() {
+			report.Valid = true
+			return report, nil
+		}
+	}
+
+	report.Stage = StageVision
+	if cfg.Classifier != nil {
+		var err error
+		report.Classification, err = cfg.classifyFromData(ctx, "", data, report.MIMEType)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ValidationReport{}, ctxErr
+		}
+		if err != nil {
+			if report.Reason = cfg.degrade(StageVision, ReasonCheckFailed, &report.Degraded); report.Reason != "" {
+				return report, nil
+			}
+		}
+		if c := report.Classification.Class; c != ClassPhoto && c != "" {
+			report.Reason = ReasonVisionReject
+			return report, nil
+		}
+	}
+	report.Valid = true
+	return report, nil
+}
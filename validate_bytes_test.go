@@ -0,0 +1,99 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// withXMPRights inserts an XMP APP1 segment carrying dc:rights into jpegData.
+// An empty EXIF segment goes first: the metadata decoder treats the first
+// APP1 segment as EXIF.
+func withXMPRights(jpegData []byte, rights string) []byte {
+	exif := "Exif\x00\x00II*\x00\x08\x00\x00\x00\x00\x00\x00\x00\x00\x00"
+	xmp := "http://ns.adobe.com/xap/1.0/\x00" +
+		`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+		`<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		`<dc:rights><rdf:Alt><rdf:li xml:lang="x-default">` + rights + `</rdf:li></rdf:Alt></dc:rights>` +
+		`</rdf:Description></rdf:RDF></x:xmpmeta>`
+
+	var buf bytes.Buffer
+	buf.Write(jpegData[:2]) // SOI
+	for _, payload := range []string{exif, xmp} {
+		buf.Write([]byte{0xFF, 0xE1})
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(payload)+2))
+		buf.WriteString(payload)
+	}
+	buf.Write(jpegData[2:])
+	return buf.Bytes()
+}
+
+func TestValidateImageBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		data       []byte
+		classifier Classifier
+		verifySafe bool
+		wantValid  bool
+		wantStage  Stage
+		wantReason RejectReason
+	}{
+		{"wide photo", makeJPEG(1000, 600), nil, false, true, StageVision, ""},
+		{"too narrow", makeJPEG(400, 300), nil, false, false, StageProbe, ReasonTooNarrow},
+		{"not an image", []byte("<html>hello</html>"), nil, false, false, StageProbe, ReasonNotImage},
+		{"stock metadata", withXMPRights(makeJPEG(1000, 600), "Getty Images"), nil, false, false, StageLicense, ReasonStockMetadata},
+		{"vision photo", makeJPEG(1000, 600), &mockClassifier{response: "PHOTO"}, false, true, StageVision, ""},
+		{"vision placeholder", makeJPEG(1000, 600), &mockClassifier{response: "PLACEHOLDER"}, false, false, StageVision, ReasonVisionReject},
+		{"cc metadata accepted unclassified", withXMPRights(makeJPEG(1000, 600), "https://creativecommons.org/licenses/by/4.0/"), &mockClassifier{response: "ILLUSTRATION"}, false, true, StageLicense, ""},
+		{"cc metadata verified", withXMPRights(makeJPEG(1000, 600), "https://creativecommons.org/licenses/by/4.0/"), &mockClassifier{response: "ILLUSTRATION"}, true, false, StageVision, ReasonVisionReject},
+		{"vision error accepts", makeJPEG(1000, 600), &mockClassifier{err: errors.New("boom")}, false, true, StageVision, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{Classifier: tt.classifier, VerifySafeDomains: tt.verifySafe}
+			report, err := cfg.ValidateImageBytes(context.Background(), tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Valid != tt.wantValid || report.Stage != tt.wantStage || report.Reason != tt.wantReason {
+				t.Errorf("report = {Valid:%v Stage:%q Reason:%q}, want {Valid:%v Stage:%q Reason:%q}",
+					report.Valid, report.Stage, report.Reason, tt.wantValid, tt.wantStage, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestValidateImageBytes_ReportFields(t *testing.T) {
+	t.Parallel()
+
+	report, err := (&Config{}).ValidateImageBytes(context.Background(), makeJPEG(1000, 600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != "jpeg" || report.MIMEType != "image/jpeg" || report.Width != 1000 || report.Height != 600 {
+		t.Errorf("report = %+v", report)
+	}
+	if report.License.License != LicenseUnknown || report.License.Signals == nil {
+		t.Errorf("License = %+v, want unknown with non-nil signals", report.License)
+	}
+}
+
+func TestValidateImageBytes_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (&Config{}).ValidateImageBytes(context.Background(), nil); !errors.Is(err, ErrEmptyImage) {
+		t.Errorf("empty data: err = %v, want ErrEmptyImage", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := (&Config{}).ValidateImageBytes(ctx, makeJPEG(1000, 600)); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled context: err = %v, want context.Canceled", err)
+	}
+}
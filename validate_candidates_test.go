@@ -2,7 +2,12 @@ package imagefy
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestValidateCandidates_EmptyInput(t *testing.T) {
@@ -74,3 +79,338 @@ func TestValidateCandidates_RespectsMaxResults(t *testing.T) {
 		t.Errorf("got %d results, want at most %d", len(results), maxResults)
 	}
 }
+
+func TestValidateCandidates_MaxPerDomain(t *testing.T) {
+	t.Parallel()
+
+	imgSrv := newJPEGServer(t)
+
+	var candidates []ImageCandidate
+	for i, src := range []string{"commons.wikimedia.org", "commons.wikimedia.org", "commons.wikimedia.org", "commons.wikimedia.org", "blog.example"} {
+		candidates = append(candidates, ImageCandidate{
+			ImgURL:  fmt.Sprintf("%s/photo%d.jpg", imgSrv.URL, i),
+			Source:  "https://" + src + "/page",
+			License: LicenseUnknown,
+		})
+	}
+
+	var mu sync.Mutex
+	capped := 0
+	cfg := &Config{
+		HTTPClient: imgSrv.Client(),
+		OnCandidateRejected: func(e CandidateEvent) {
+			if e.Reason == ReasonDomainCap {
+				mu.Lock()
+				capped++
+				mu.Unlock()
+			}
+		},
+	}
+
+	cfg.defaults()
+	results := cfg.validateCandidates(context.Background(), candidates, 5, SearchOpts{MaxPerDomain: 2}, newSearchState())
+
+	perHost := map[string]int{}
+	for _, r := range results {
+		perHost[sourceKey(r)]++
+	}
+	if perHost["commons.wikimedia.org"] != 2 || perHost["blog.example"] != 1 {
+		t.Errorf("per-host counts = %v, want 2 wikimedia and 1 blog", perHost)
+	}
+	if capped != 2 {
+		t.Errorf("domain_cap rejections = %d, want 2", capped)
+	}
+}
+
+func TestValidateCandidates_PerCandidateTimeout(t *testing.T) {
+	t.Parallel()
+
+	jpeg := makeJPEG(1000, 600)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	hits := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		n := hits[r.URL.Path]
+		mu.Unlock()
+		// /stall-probe.jpg stalls on the probe; /stall-download.jpg passes the
+		// probe and stalls on the download, which would otherwise degrade to accept.
+		if r.URL.Path == "/stall-probe.jpg" || (r.URL.Path == "/stall-download.jpg" && n > 1) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(jpeg)
+	}))
+	t.Cleanup(func() {
+		close(release)
+		srv.Close()
+	})
+
+	var rejected sync.Map
+	cfg := &Config{
+		HTTPClient:          srv.Client(),
+		OnCandidateRejected: func(e CandidateEvent) { rejected.Store(e.Candidate.ImgURL, e) },
+	}
+	cands := []ImageCandidate{
+		{ImgURL: srv.URL + "/stall-probe.jpg", Source: "https://a.example/page", License: LicenseUnknown},
+		{ImgURL: srv.URL + "/stall-download.jpg", Source: "https://b.example/page", License: LicenseUnknown},
+		{ImgURL: srv.URL + "/fast.jpg", Source: "https://c.example/page", License: LicenseUnknown},
+	}
+
+	cfg.defaults()
+	start := time.Now()
+	results := cfg.validateCandidates(context.Background(), cands, 5, SearchOpts{PerCandidateTimeout: 500 * time.Millisecond}, newSearchState())
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("validation took %v, want bounded by the per-candidate timeout", elapsed)
+	}
+	if len(results) != 1 || results[0].ImgURL != cands[2].ImgURL {
+		t.Fatalf("results = %+v, want only the fast candidate", results)
+	}
+
+	for url, wantStage := range map[string]Stage{cands[0].ImgURL: StageProbe, cands[1].ImgURL: StageDownload} {
+		v, ok := rejected.Load(url)
+		if !ok {
+			t.Errorf("%s: no rejection event", url)
+			continue
+		}
+		if e := v.(CandidateEvent); e.Reason != ReasonTimeout || e.Stage != wantStage {
+			t.Errorf("%s: event = {Stage:%q Reason:%q}, want {%q %q}", url, e.Stage, e.Reason, wantStage, ReasonTimeout)
+		}
+	}
+}
+
+// cancelingClassifier waits for the first acceptance, then cancels the
+// search, as a user navigating away once an image is shown.
+type cancelingClassifier struct {
+	accepted <-chan struct{}
+	cancel   context.CancelFunc
+}
+
+func (c cancelingClassifier) Classify(ctx context.Context, _ string, _ []ImageInput) (string, error) {
+	<-c.accepted
+	c.cancel()
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestSearchImagesResult_PartialOnCancel(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultiImageServer(t, map[string][]byte{
+		"/safe.jpg":    encodeJPEG(t, makeGradientImage(1000, 600, 0)),
+		"/unknown.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 25)),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accepted := make(chan struct{})
+	var once sync.Once
+	cfg := &Config{
+		HTTPClient:          srv.Client(),
+		Classifier:          cancelingClassifier{accepted: accepted, cancel: cancel},
+		OnCandidateAccepted: func(CandidateEvent) { once.Do(func() { close(accepted) }) },
+		Providers: []SearchProvider{&mockProvider{name: "p", candidates: []ImageCandidate{
+			{ImgURL: srv.URL + "/unknown.jpg", Source: "https://blog.example/post", License: LicenseUnknown},
+			{ImgURL: srv.URL + "/safe.jpg", Source: "https://commons.wikimedia.org/wiki/File:A.jpg", License: LicenseSafe},
+		}}},
+	}
+
+	res := cfg.SearchImagesResult(ctx, "kremlin", 5, SearchOpts{})
+	if !res.Partial {
+		t.Error("Partial = false after cancellation")
+	}
+	if len(res.Accepted) != 1 || res.Accepted[0].ImgURL != srv.URL+"/safe.jpg" {
+		t.Fatalf("accepted = %+v, want the safe image validated before cancellation", res.Accepted)
+	}
+	for _, e := range res.Events {
+		if e.Candidate.ImgURL == srv.URL+"/unknown.jpg" && (e.Reason != ReasonCanceled || e.Stage != StageVision) {
+			t.Errorf("in-flight candidate: {Stage:%q Reason:%q}, want {vision canceled}", e.Stage, e.Reason)
+		}
+	}
+}
+
+func TestValidateCandidates_CanceledBeforeStart(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var reasons []RejectReason
+	cfg := &Config{OnCandidateRejected: func(e CandidateEvent) { reasons = append(reasons, e.Reason) }}
+	cfg.defaults()
+
+	got := cfg.validateCandidates(ctx, []ImageCandidate{{ImgURL: "https://a.example/1.jpg"}, {ImgURL: "https://a.example/2.jpg"}}, 5, SearchOpts{}, newSearchState())
+	if len(got) != 0 || len(reasons) != 2 || reasons[0] != ReasonCanceled || reasons[1] != ReasonCanceled {
+		t.Errorf("results = %v, reasons = %v; want none accepted and both canceled", got, reasons)
+	}
+}
+
+func TestValidateCandidates_BoundedAdmissionInInputOrder(t *testing.T) {
+	t.Parallel()
+
+	imgs := map[string][]byte{
+		"/a.jpg": encodeJPEG(t, makeGradientImage(1000, 600, 0)),
+		"/b.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 25)),
+		"/c.jpg": encodeJPEG(t, makeCheckerImage(1000, 600, 90)),
+	}
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	// The first candidate is the slowest, so it finishes last.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		if r.URL.Path == "/a.jpg" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		body, ok := imgs[r.URL.Path]
+		if !ok {
+			body = imgs["/c.jpg"]
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &Config{HTTPClient: srv.Client(), MinImageWidth: 100}
+	var cands []ImageCandidate
+	for _, p := range []string{"/a.jpg", "/b.jpg", "/c.jpg"} {
+		cands = append(cands, ImageCandidate{ImgURL: srv.URL + p, Source: srv.URL + p + ".html", License: LicenseSafe})
+	}
+	got := cfg.ValidateCandidates(context.Background(), cands, 3)
+	if len(got) != 3 || got[0].ImgURL != cands[0].ImgURL || got[1].ImgURL != cands[1].ImgURL || got[2].ImgURL != cands[2].ImgURL {
+		t.Fatalf("got %v, want all three in input order", got)
+	}
+
+	// Once one candidate is accepted no further ones are admitted, so only a
+	// few of the 20 identical candidates are fetched, not all of them.
+	mu.Lock()
+	requests = 0
+	mu.Unlock()
+	var many []ImageCandidate
+	for i := range 20 {
+		many = append(many, ImageCandidate{ImgURL: fmt.Sprintf("%s/x%d.jpg", srv.URL, i), Source: fmt.Sprintf("%s/x%d", srv.URL, i), License: LicenseSafe})
+	}
+	if got := cfg.ValidateCandidates(context.Background(), many, 1); len(got) != 1 {
+		t.Fatalf("got %d results, want 1", len(got))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests >= 20 { // probe + download per candidate
+		t.Errorf("%d image requests, want admission to stop after the first accepted candidate", requests)
+	}
+}
+
+func TestValidateCandidates_SlotTimeout(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(makeJPEG(1000, 600))
+	}))
+	t.Cleanup(srv.Close)
+
+	var (
+		mu      sync.Mutex
+		reasons = map[string]RejectReason{}
+	)
+	cfg := &Config{
+		HTTPClient:          srv.Client(),
+		OnCandidateRejected: func(e CandidateEvent) { mu.Lock(); reasons[e.Candidate.ImgURL] = e.Reason; mu.Unlock() },
+	}
+	cfg.defaults()
+	var cands []ImageCandidate
+	for i := range validationSemaphore + 2 {
+		cands = append(cands, ImageCandidate{ImgURL: fmt.Sprintf("%s/%d.jpg", srv.URL, i), Source: fmt.Sprintf("%s/%d", srv.URL, i), License: LicenseSafe})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if got := cfg.validateCandidates(ctx, cands, 5, SearchOpts{}, newSearchState()); len(got) != 0 {
+		t.Fatalf("got %d results, want none", len(got))
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("validation took %v, want it to end at the deadline", elapsed)
+	}
+	for _, c := range cands[validationSemaphore:] {
+		if reasons[c.ImgURL] != ReasonSlotTimeout {
+			t.Errorf("queued %s rejected as %q, want %q", c.ImgURL, reasons[c.ImgURL], ReasonSlotTimeout)
+		}
+	}
+}
+
+func TestPartial(t *testing.T) {
+	t.Parallel()
+
+	done := []CandidateEvent{{}, {Reason: ReasonDownloadFailed}}
+	if partial(done) {
+		t.Error("partial(finished events) = true")
+	}
+	for _, reason := range []RejectReason{ReasonCanceled, ReasonSlotTimeout} {
+		if !partial(append(done, CandidateEvent{Reason: reason})) {
+			t.Errorf("partial with a %q event = false, want true", reason)
+		}
+	}
+}
+
+func TestValidateCandidates_VerifySafeDomains(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(1000, 600))
+	tests := []struct {
+		name       string
+		verify     bool
+		classifier *mockClassifier
+		wantReason RejectReason
+		wantCalls  int
+	}{
+		{"safe accepted unclassified", false, &mockClassifier{response: "ILLUSTRATION"}, "", 0},
+		{"illustration rejected", true, &mockClassifier{response: "ILLUSTRATION"}, ReasonVisionReject, 1},
+		{"photo accepted", true, &mockClassifier{response: "PHOTO 0.9"}, "", 1},
+		{"no classifier", true, nil, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rejected []CandidateEvent
+			var events []ClassificationEvent
+			cfg := &Config{
+				VerifySafeDomains:   tt.verify,
+				OnCandidateRejected: func(e CandidateEvent) { rejected = append(rejected, e) },
+				OnClassification:    func(e ClassificationEvent) { events = append(events, e) },
+			}
+			if tt.classifier != nil {
+				cfg.Classifier = tt.classifier
+			}
+			cand := ImageCandidate{ImgURL: srv.URL + "/meme.jpg", Source: "https://unsplash.com/photos/x", License: LicenseSafe}
+			got := cfg.ValidateCandidates(context.Background(), []ImageCandidate{cand}, 1)
+
+			if tt.wantReason == "" && len(got) != 1 {
+				t.Fatalf("got %d results, rejected %+v; want accepted", len(got), rejected)
+			}
+			if tt.wantReason != "" && (len(rejected) != 1 || rejected[0].Reason != tt.wantReason || rejected[0].Stage != StageVision) {
+				t.Fatalf("rejected = %+v, want %s at vision", rejected, tt.wantReason)
+			}
+			if tt.classifier != nil && tt.classifier.calls != tt.wantCalls {
+				t.Errorf("classifier calls = %d, want %d", tt.classifier.calls, tt.wantCalls)
+			}
+			for _, e := range events {
+				if tt.wantCalls > 0 && e.Source == "license_assessment" {
+					t.Errorf("verified safe candidate reported as a license_assessment PHOTO: %+v", e)
+				}
+			}
+		})
+	}
+}
@@ -2,156 +2,536 @@ package imagefy
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"sort"
 	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 const validationSemaphore = 3
 
-func (cfg *Config) validateCandidates(ctx context.Context, toValidate []ImageCandidate, maxResults int) []ImageCandidate {
+// validateCandidates drops URL-level duplicates (see dedupURLs) and title
+// mismatches (see filterTitles), validates the rest, and returns up to
+// maxResults accepted candidates. With opts.Ranker or opts.Overshoot a
+// larger pool is validated and re-ranked down to maxResults (see rerank).
+// Of opts, only MaxPerDomain, PerCandidateTimeout, Ranker, and Overshoot
+// apply.
+//
+// Validation is a bounded pipeline: candidates are admitted in order into
+// validationSemaphore slots (handed on before the vision stage, see
+// validateOne), sharded by image host (see hostQueue) so one slow host
+// cannot hold every slot, and admission stops once enough have been
+// accepted or ctx is done. The caps are applied atomically as candidates
+// finish, and the accepted ones are returned in input order rather than
+// completion order.
+func (cfg *Config) validateCandidates(ctx context.Context, toValidate []ImageCandidate, maxResults int, opts SearchOpts, st *searchState) []ImageCandidate {
+	toValidate = cfg.filterLanguage(cfg.filterTitles(cfg.dedupURLs(toValidate)))
+	rep := cfg.loadReputation(ctx, toValidate)
+	defer rep.flush(ctx)
+	toValidate = rep.order(toValidate)
+	poolSize := opts.poolSize(maxResults)
+	ranker := opts.Ranker
+	if ranker == nil && poolSize > maxResults {
+		ranker = rankByPipeline
+	}
+
+	col := &collector{limit: poolSize, maxPerDomain: opts.MaxPerDomain}
 	sem := make(chan struct{}, validationSemaphore)
-	var mu sync.Mutex
-	var validated []ImageCandidate
-	dedup := &dedupFilter{}
-
-	var wg sync.WaitGroup
-	for _, c := range toValidate {
-		mu.Lock()
-		enough := len(validated) >= maxResults
-		mu.Unlock()
-		if enough {
+	queue := newHostQueue(toValidate, st.hosts)
+	var g errgroup.Group
+	for {
+		if !acquire(ctx, sem) {
+			for _, i := range queue.drain() {
+				cfg.emitCandidate(CandidateEvent{Candidate: toValidate[i], Stage: StageProbe, Reason: unadmitted(ctx)})
+			}
+			break
+		}
+		i, ok := queue.next()
+		if !ok {
+			<-sem
 			break
 		}
+		c := toValidate[i]
+		if reason, stage := col.admit(c, cfg.meter, st); reason != "" {
+			queue.done(i)
+			<-sem
+			if reason == ReasonMaxResults {
+				for _, j := range append([]int{i}, queue.drain()...) {
+					cfg.emitCandidate(CandidateEvent{Candidate: toValidate[j], Stage: StageCollect, Reason: ReasonNotValidated})
+				}
+				break
+			}
+			cfg.emitCandidate(CandidateEvent{Candidate: c, Stage: stage, Reason: reason})
+			continue
+		}
+
+		g.Go(func() error {
+			var releaseOnce sync.Once
+			releaseSlot := func() { releaseOnce.Do(func() { <-sem; queue.done(i) }) }
+			defer releaseSlot()
+
+			e := cfg.validateEvent(ctx, c, opts, st, releaseSlot)
+			rep.record(e)
+			if e.Reason == "" {
+				if reason := col.add(i, e); reason != "" {
+					st.used.release(e.Candidate.ImgURL)
+					e.Stage, e.Reason = StageCollect, reason
+				} else if ranker != nil {
+					return nil // emitted by rerank
+				}
+			}
+			cfg.emitCandidate(e)
+			return nil
+		})
+	}
+	_ = g.Wait() // workers report through events, never errors
 
-		wg.Add(1)
-		go func(cand ImageCandidate) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
+	accepted := col.inOrder()
+	if ranker != nil {
+		return cfg.rerank(ranker, accepted, maxResults, st)
+	}
+	var out []ImageCandidate
+	for _, e := range accepted {
+		out = append(out, e.Candidate)
+	}
+	return out
+}
 
-			cfg.validateOne(ctx, cand, maxResults, &mu, &validated, dedup)
-		}(c)
+// unadmitted returns the reason for candidates still queued for a
+// validation slot when ctx ended: ReasonSlotTimeout past the deadline,
+// ReasonCanceled on cancellation.
+func unadmitted(ctx context.Context) RejectReason {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ReasonSlotTimeout
+	}
+	return ReasonCanceled
+}
+
+// acquire takes a slot of sem, or reports false once ctx is done, so no
+// goroutine stays queued past the deadline.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
 	}
-	wg.Wait()
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
 
-	return validated
+// validateEvent validates cand and returns the event reporting the verdict.
+// An accepted candidate carries its relevance and modification time and is
+// claimed in the used-image history, so parallel searches sharing st cannot
+// both return it.
+func (cfg *Config) validateEvent(ctx context.Context, cand ImageCandidate, opts SearchOpts, st *searchState, releaseSlot func()) CandidateEvent {
+	start := time.Now()
+	stage, reason, degraded := cfg.validateWithTimeout(ctx, &cand, opts.PerCandidateTimeout, st, releaseSlot)
+	if reason == "" {
+		cand.Relevance = st.relevance.get(cand.ImgURL)
+		cand.LastModified = st.modified.get(cand.ImgURL)
+		if len(degraded) > 0 && cfg.DegradationPolicy == DegradeMarkUnknown {
+			cand.License, cand.Degraded = LicenseUnknown, degraded[0]
+		}
+		if !st.used.claim(cand.ImgURL) {
+			stage, reason = StageDedup, ReasonAlreadyUsed
+		}
+	}
+	if reason != "" {
+		st.previews.drop(cand.ImgURL)
+	}
+	return CandidateEvent{Candidate: cand, Stage: stage, Reason: reason, Duration: time.Since(start), Degraded: degraded}
 }
 
-// validateOne validates a single candidate and appends it to validated if it passes all checks.
-// Recovers from panics to protect the goroutine pool.
+// validateWithTimeout runs validateCandidate bounded by timeout (if
+// positive), measured from when the candidate starts validating. A candidate whose
+// budget runs out is rejected with ReasonTimeout at the stage it reached,
+// even if a stage degraded gracefully and would have accepted it.
+func (cfg *Config) validateWithTimeout(ctx context.Context, cand *ImageCandidate, timeout time.Duration, st *searchState, releaseSlot func()) (Stage, RejectReason, []Stage) {
+	if timeout <= 0 {
+		return cfg.validateCandidate(ctx, cand, st, releaseSlot)
+	}
+	candCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stage, reason, degraded := cfg.validateCandidate(candCtx, cand, st, releaseSlot)
+	if ctx.Err() == nil && errors.Is(candCtx.Err(), context.DeadlineExceeded) {
+		slog.Debug("imagefy: candidate timed out", "url", cand.ImgURL, "stage", stage, "timeout", timeout)
+		return stage, ReasonTimeout, degraded
+	}
+	return stage, reason, degraded
+}
+
+// validateCandidate upgrades cand in place (see upgradeCandidate) and runs
+// validateOne on it. A hotlink-blocked candidate is retried once from its
+// source page (see hotlinkFallback), updating cand; if that fails too it
+// stays rejected with ReasonHotlinkBlocked.
+func (cfg *Config) validateCandidate(ctx context.Context, cand *ImageCandidate, st *searchState, releaseSlot func()) (Stage, RejectReason, []Stage) {
+	*cand = cfg.upgradeCandidate(ctx, *cand, st)
+	stage, reason, degraded := cfg.validateOne(ctx, *cand, st, releaseSlot)
+	if reason != ReasonHotlinkBlocked || ctx.Err() != nil {
+		return stage, reason, degraded
+	}
+	retry, ok := cfg.hotlinkFallback(ctx, *cand)
+	if !ok {
+		return stage, reason, degraded
+	}
+	*cand = retry
+	return cfg.validateOne(ctx, *cand, st, releaseSlot)
+}
+
+// validateOne runs a single candidate through the pipeline and returns the
+// deciding stage with the reason it was rejected, or "" if it should be accepted,
+// and the stages whose check failed and was handled by Config.DegradationPolicy.
+// Recovers from panics to protect the goroutine pool. If releaseSlot is
+// non-nil and Config.ClassifierConcurrency is set, it is called before the
+// vision stage to hand the validation slot to the next candidate.
 //
 // Pipeline stages:
-//  1. ValidateImageURL — HTTP probe (dimensions, content-type, logo/banner check)
+//  1. probeImage — HTTP probe (dimensions, content-type, logo/banner check),
+//     rejecting byte-identical mirrors by prefix fingerprint (PrefixDedupBytes)
 //  2. Extra domain pre-check — skip download for known-blocked domains
-//  3. downloadForValidation — single download for dedup + metadata + LLM
-//  4. Perceptual dedup — reject visual duplicates (dHash)
+//  3. downloadForValidation — single download for dedup + metadata + LLM;
+//     metadata and dHash are cached by content hash (Config.Cache)
+//  4. Perceptual dedup — reject visual duplicates (dHash), and semantic
+//     duplicates by image embedding when Config.Embedder is set
+//     4.5. Relevance — query-image embedding similarity (TextEmbedder only)
 //  5. ExtractImageMetadata + AssessLicense — domain + metadata signals
-//  5.5. ReverseCheck — reverse image search for laundered stock (opt-in)
+//     5.5. ReverseCheck — reverse image search for laundered stock (opt-in)
 //  6. LLM Vision classification — fallback for unknown license
-func (cfg *Config) validateOne(ctx context.Context, cand ImageCandidate, maxResults int, mu *sync.Mutex, validated *[]ImageCandidate, dedup *dedupFilter) {
+func (cfg *Config) validateOne(ctx context.Context, cand ImageCandidate, st *searchState, releaseSlot func()) (stage Stage, reason RejectReason, degraded []Stage) {
 	defer func() {
 		if r := recover(); r != nil {
 			if cfg.OnPanic != nil {
 				cfg.OnPanic("imageValidation", r)
 			}
+			reason = ReasonPanic
 		}
 	}()
 
-	if !cfg.ValidateImageURL(ctx, cand.ImgURL) {
-		return
+	stage = StageProbe
+	st.hosts.wait(ctx, cand.ImgURL)
+	reason, prefix := cfg.probeImage(ctx, cand.ImgURL, cand.referer)
+	if reason != "" {
+		if ctx.Err() != nil {
+			return stage, ReasonCanceled, nil
+		}
+		if cfg.meter.spent() {
+			return stage, ReasonByteBudget, nil
+		}
+		return stage, reason, nil
+	}
+	if st.dedup.seenPrefix(prefix) {
+		slog.Debug("imagefy: byte-identical duplicate", "url", cand.ImgURL)
+		return StageDedup, ReasonDuplicate, nil
 	}
 
+	stage = StageDomain
 	if cfg.isBlockedByExtraDomains(cand) {
-		return
+		return stage, ReasonBlockedDomain, nil
 	}
 
-	data, mimeType, img := cfg.downloadForValidation(ctx, cand.ImgURL)
+	stage = StageDownload
+	st.hosts.wait(ctx, cand.ImgURL)
+	data, mimeType, img, modified := cfg.downloadForValidation(ctx, cand.ImgURL, cand.Thumbnail, cand.referer)
+	if img != nil && (isPlaceholderSize(img.Bounds().Dx(), img.Bounds().Dy()) || cfg.isHotlinkPlaceholder(data)) {
+		slog.Debug("imagefy: hotlink placeholder downloaded", "url", cand.ImgURL)
+		return stage, ReasonHotlinkBlocked, degraded
+	}
+	if cfg.tooOld(modified) {
+		slog.Debug("imagefy: image too old", "url", cand.ImgURL, "last_modified", modified)
+		return stage, ReasonTooOld, degraded
+	}
+	st.modified.set(cand.ImgURL, modified)
+	st.previews.set(cand.ImgURL, data, mimeType)
+	if data == nil {
+		if ctx.Err() != nil {
+			return stage, ReasonCanceled, degraded // out of time, not a graceful miss
+		}
+		if cfg.meter.spent() {
+			return stage, ReasonByteBudget, degraded // never degrade to accept on a spent budget
+		}
+		if reason := cfg.degrade(stage, ReasonDownloadFailed, &degraded); reason != "" {
+			return stage, reason, degraded
+		}
+	}
 
-	if img != nil && dedup.isDuplicate(img) {
-		slog.Debug("imagefy: dedup rejected", "url", cand.ImgURL)
-		return
+	stage = StageDedup
+	if data != nil && img == nil {
+		if reason := cfg.degrade(stage, ReasonCheckFailed, &degraded); reason != "" {
+			return stage, reason, degraded
+		}
+	}
+	meta, hash := cfg.inspectAsset(ctx, data, img)
+	if hash != nil && cfg.isPlaceholderHash(hash) {
+		slog.Debug("imagefy: placeholder image", "url", cand.ImgURL)
+		return stage, ReasonPlaceholder, degraded
+	}
+	if hash != nil && st.dedup.isDuplicateHash(hash) {
+		return stage, ReasonDuplicate, degraded
 	}
+	var vec []float32
+	if data != nil {
+		vec = cfg.embed(ctx, data, mimeType)
+	}
+	if st.dedup.isSemanticDuplicate(vec, cfg.semanticDedupThreshold()) {
+		slog.Debug("imagefy: semantic duplicate", "url", cand.ImgURL)
+		return stage, ReasonDuplicate, degraded
+	}
+	st.dedup.addPrefix(prefix)
+	st.features.record(cand.ImgURL, img)
 
-	accepted, done := cfg.assessAndAccept(ctx, cand, data, maxResults, mu, validated)
-	if done {
-		return
+	stage = StageRelevance
+	if score, ok := cfg.relevance(vec); ok {
+		if score < cfg.MinRelevance {
+			slog.Debug("imagefy: irrelevant to query", "url", cand.ImgURL, "relevance", score)
+			return stage, ReasonIrrelevant, degraded
+		}
+		st.relevance.set(cand.ImgURL, score)
 	}
-	if accepted {
-		return
+
+	stage = StageLicense
+	license, reason := cfg.assessCandidate(cand, meta)
+	st.verdicts.update(cand.ImgURL, func(v *verdict) { *v = verdict{license: license} })
+	switch license {
+	case LicenseBlocked:
+		return stage, reason, degraded
+	case LicenseSafe:
+		if !cfg.verifiesSafe() && (len(cfg.requireLabels) == 0 || cfg.Classifier == nil) {
+			return stage, "", degraded
+		}
+		// Topic constraints still need the vision stage's labels, and
+		// VerifySafeDomains its verdict.
+	default:
+		// Step 5.5: Reverse image search — detect laundered stock photos.
+		stage = StageReverse
+		if reason := cfg.reverseStage(ctx, cand, &degraded); reason != "" {
+			return stage, reason, degraded
+		}
+	}
+
+	// Unknown license — classify using pre-downloaded data.
+	stage = StageVision
+	if cfg.ClassifierConcurrency > 0 && releaseSlot != nil {
+		releaseSlot() // vision is limited by the classifier slots instead
+	}
+	if cfg.Classifier != nil {
+		if f, ok := cfg.lookupFeedback(ctx, cand.ImgURL, img); ok {
+			result := cfg.feedbackResult(cand.ImgURL, f)
+			if result.Class != ClassPhoto {
+				return stage, ReasonVisionReject, degraded
+			}
+			st.verdicts.update(cand.ImgURL, func(v *verdict) { v.vision = result })
+			return stage, "", degraded
+		}
+	}
+	if cfg.Classifier != nil && (len(data) > 0 || cfg.classifiesByURL(cand.ImgURL)) && !st.vision.take() {
+		slog.Debug("imagefy: vision budget exhausted, accepting unclassified", "url", cand.ImgURL)
+		return stage, "", degraded
+	}
+	result, err := cfg.classifyPredownloaded(ctx, cand.ImgURL, data, mimeType)
+	if err != nil && ctx.Err() != nil {
+		return stage, ReasonCanceled, degraded // never accept unclassified because time ran out
+	}
+	if err != nil {
+		return stage, cfg.degrade(stage, ReasonCheckFailed, &degraded), degraded
 	}
+	if result.Class != ClassPhoto && result.Class != "" {
+		slog.Debug("imagefy: vision rejected", "url", cand.ImgURL, "class", result.Class)
+		return stage, ReasonVisionReject, degraded
+	}
+	if cfg.belowMinConfidence(result) {
+		slog.Debug("imagefy: vision confidence below variant threshold", "url", cand.ImgURL, "confidence", result.Confidence, "variant", cfg.variant.Label)
+		return stage, ReasonVisionReject, degraded
+	}
+	if cfg.offTopic(result) {
+		slog.Debug("imagefy: off topic", "url", cand.ImgURL, "labels", result.Labels, "required", cfg.requireLabels)
+		return stage, ReasonOffTopic, degraded
+	}
+	st.verdicts.update(cand.ImgURL, func(v *verdict) { v.vision = result })
+	return stage, "", degraded
+}
 
-	// Step 5.5: Reverse image search — detect laundered stock photos.
-	reverseResult := cfg.ReverseCheck(ctx, cand.ImgURL)
+// reverseStage runs the reverse image search check and returns the reason
+// to reject cand, or "" to continue.
+func (cfg *Config) reverseStage(ctx context.Context, cand ImageCandidate, degraded *[]Stage) RejectReason {
+	reverseResult, err := cfg.reverseCheck(ctx, cand.ImgURL)
+	if err != nil && ctx.Err() != nil {
+		return ReasonCanceled
+	}
+	if err != nil {
+		if reason := cfg.degrade(StageReverse, ReasonCheckFailed, degraded); reason != "" {
+			return reason
+		}
+	}
 	if reverseResult.IsStock {
 		slog.Debug("imagefy: blocked by reverse stock check",
 			"url", cand.ImgURL,
 			"stock_domains", reverseResult.StockDomains,
 		)
-		cfg.emitClassification(cand.ImgURL, ClassStock, 0, "reverse_stock")
-		return
+		cfg.emitEvent(ClassificationEvent{URL: cand.ImgURL, Class: ClassStock, Source: "reverse_stock", Reason: ReasonReverseStock})
+		return ReasonReverseStock
 	}
+	return ""
+}
 
-	// Unknown license — classify using pre-downloaded data.
-	result := cfg.classifyPredownloaded(ctx, cand.ImgURL, data, mimeType)
-	if result.Class != ClassPhoto && result.Class != "" {
-		slog.Debug("imagefy: vision rejected", "url", cand.ImgURL, "class", result.Class)
-		return
-	}
-	appendValidated(mu, validated, cand, maxResults)
+// verifiesSafe reports whether LicenseSafe candidates are classified
+// instead of accepted as photos (Config.VerifySafeDomains with a Classifier).
+func (cfg *Config) verifiesSafe() bool {
+	return cfg.VerifySafeDomains && cfg.Classifier != nil
 }
 
 // isBlockedByExtraDomains checks extra blocked domains before downloading.
+// Skipped with Config.TrustedCreators, which can override the domain once
+// the metadata is read.
 func (cfg *Config) isBlockedByExtraDomains(cand ImageCandidate) bool {
-	if len(cfg.ExtraBlockedDomains) == 0 {
+	extraBlocked := cfg.extraBlocked()
+	if len(extraBlocked) == 0 || len(cfg.TrustedCreators) > 0 {
 		return false
 	}
-	if CheckLicenseWith(cand.ImgURL, cand.Source, cfg.ExtraBlockedDomains, nil) != LicenseBlocked {
+	if checkLicense(cand.ImgURL, cand.Source, extraBlocked, nil, cfg.BlockPolicy) != LicenseBlocked {
 		return false
 	}
 	slog.Debug("imagefy: blocked by extra domain pre-check", "url", cand.ImgURL)
-	cfg.emitClassification(cand.ImgURL, ClassStock, 0, "license_assessment")
+	cfg.emitEvent(ClassificationEvent{URL: cand.ImgURL, Class: ClassStock, Source: "license_assessment", Reason: ReasonBlockedDomain})
 	return true
 }
 
-// assessAndAccept runs metadata extraction and license assessment.
-// Returns (accepted, done): accepted=true if candidate was added, done=true if pipeline should stop.
-func (cfg *Config) assessAndAccept(ctx context.Context, cand ImageCandidate, data []byte, maxResults int, mu *sync.Mutex, validated *[]ImageCandidate) (bool, bool) {
-	meta := ExtractImageMetadata(data)
+// assessCandidate runs license assessment on the extracted metadata.
+// Returns the assessed license and, for LicenseBlocked, the rejection reason.
+// LicenseUnknown means the pipeline should continue to the vision stage.
+func (cfg *Config) assessCandidate(cand ImageCandidate, meta *ImageMetadata) (ImageLicense, RejectReason) {
 	assessment := cfg.AssessLicense(cand, meta)
 
 	if assessment.License == LicenseBlocked {
+		reason := assessment.rejectReason()
 		slog.Debug("imagefy: blocked by license assessment", "url", cand.ImgURL, "signals", assessment.Signals)
-		cfg.emitClassification(cand.ImgURL, ClassStock, 0, "license_assessment")
-		return false, true
+		cfg.emitEvent(ClassificationEvent{URL: cand.ImgURL, Class: ClassStock, Source: "license_assessment", Reason: reason})
+		return LicenseBlocked, reason
 	}
 
 	if assessment.License == LicenseSafe {
 		slog.Debug("imagefy: safe by license assessment", "url", cand.ImgURL, "signals", assessment.Signals)
-		cfg.emitClassification(cand.ImgURL, ClassPhoto, 1.0, "license_assessment")
-		appendValidated(mu, validated, cand, maxResults)
-		return true, true
+		if !cfg.verifiesSafe() {
+			cfg.emitClassification(cand.ImgURL, ClassPhoto, 1.0, "license_assessment")
+		}
+		return LicenseSafe, ""
 	}
 
-	return false, false
+	return LicenseUnknown, ""
+}
+
+// emitCandidate logs the outcome of a candidate and fires the matching
+// OnCandidateAccepted / OnCandidateRejected callback if configured.
+func (cfg *Config) emitCandidate(e CandidateEvent) {
+	e.Variant = cfg.variant.Label
+	if e.Candidate.raw != "" {
+		e.Raw = json.RawMessage(e.Candidate.raw)
+	}
+	if e.Reason == "" {
+		if cfg.OnCandidateAccepted != nil {
+			cfg.OnCandidateAccepted(e)
+		}
+		return
+	}
+	slog.Debug("imagefy: candidate rejected", "url", e.Candidate.ImgURL, "stage", e.Stage, "reason", e.Reason)
+	if cfg.OnCandidateRejected != nil {
+		cfg.OnCandidateRejected(e)
+	}
 }
 
 // emitClassification fires the OnClassification callback if configured.
 func (cfg *Config) emitClassification(url, class string, confidence float64, source string) {
+	cfg.emitEvent(ClassificationEvent{
+		URL:        url,
+		Class:      class,
+		Confidence: confidence,
+		Source:     source,
+	})
+}
+
+// emitEvent fires the OnClassification callback with a fully-populated event.
+func (cfg *Config) emitEvent(e ClassificationEvent) {
+	e.Variant = cfg.variant.Label
 	if cfg.OnClassification != nil {
-		cfg.OnClassification(ClassificationEvent{
-			URL:        url,
-			Class:      class,
-			Confidence: confidence,
-			Source:     source,
-		})
+		cfg.OnClassification(e)
 	}
 }
 
-// appendValidated safely appends a candidate to the validated slice if capacity remains.
-func appendValidated(mu *sync.Mutex, validated *[]ImageCandidate, cand ImageCandidate, maxResults int) {
-	mu.Lock()
-	if len(*validated) < maxResults {
-		*validated = append(*validated, cand)
+// collector accumulates accepted events under the limit and per-domain
+// caps, keyed by input position. It is safe for concurrent use.
+type collector struct {
+	limit        int
+	maxPerDomain int // 0 = unlimited
+
+	mu        sync.Mutex
+	accepted  map[int]CandidateEvent
+	perDomain map[string]int
+}
+
+// admit reports why cand should not be validated, with the stage to report
+// it at: ReasonMaxResults once limit candidates were accepted (admission
+// ends and the rest are reported as ReasonNotValidated), ReasonByteBudget, ReasonAlreadyUsed, or ReasonDomainCap. "" admits
+// it.
+func (c *collector) admit(cand ImageCandidate, meter *byteMeter, st *searchState) (RejectReason, Stage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case len(c.accepted) >= c.limit:
+		return ReasonMaxResults, StageCollect
+	case meter.spent():
+		return ReasonByteBudget, StageProbe
+	case st.used.contains(cand.ImgURL):
+		return ReasonAlreadyUsed, StageDedup
+	case c.maxPerDomain > 0 && c.perDomain[sourceKey(cand)] >= c.maxPerDomain:
+		return ReasonDomainCap, StageCollect
+	}
+	return "", ""
+}
+
+// add records the accepted event e of the candidate at position pos if both
+// caps allow it. Returns ReasonMaxResults or ReasonDomainCap if a cap was
+// already reached, "" if e was added.
+func (c *collector) add(pos int, e CandidateEvent) RejectReason {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.accepted) >= c.limit {
+		return ReasonMaxResults
+	}
+	if c.maxPerDomain > 0 {
+		key := sourceKey(e.Candidate)
+		if c.perDomain[key] >= c.maxPerDomain {
+			return ReasonDomainCap
+		}
+		if c.perDomain == nil {
+			c.perDomain = make(map[string]int)
+		}
+		c.perDomain[key]++
+	}
+	if c.accepted == nil {
+		c.accepted = make(map[int]CandidateEvent)
+	}
+	c.accepted[pos] = e
+	return ""
+}
+
+// inOrder returns the accepted events in input order.
+func (c *collector) inOrder() []CandidateEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	positions := make([]int, 0, len(c.accepted))
+	for pos := range c.accepted {
+		positions = append(positions, pos)
+	}
+	sort.Ints(positions)
+	out := make([]CandidateEvent, len(positions))
+	for i, pos := range positions {
+		out[i] = c.accepted[pos]
 	}
-	mu.Unlock()
+	return out
 }
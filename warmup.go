@@ -0,0 +1,98 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const canarySize = 32 // canary image edge in pixels
+
+// ClassifierHealth is the outcome of the most recent canary classification.
+type ClassifierHealth struct {
+	Healthy   bool          // the canary call succeeded
+	Response  string        // raw model response of the canary
+	Latency   time.Duration // wall time of the canary call
+	Err       error         // non-nil when !Healthy
+	CheckedAt time.Time     // zero if no canary has run
+}
+
+// canaryJPEG is a tiny gray JPEG: cheap to send, but a real image, so a
+// local vision model loads its image encoder too.
+var canaryJPEG = sync.OnceValue(func() []byte {
+	img := image.NewGray(image.Rect(0, 0, canarySize, canarySize))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	img.SetGray(canarySize/2, canarySize/2, color.Gray{Y: 0xff})
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, img, nil) // writing to a bytes.Buffer cannot fail
+	return buf.Bytes()
+})
+
+// WarmupClassifier sends a tiny canary classification through the Classifier
+// so the first real image doesn't pay the cold-start latency of a local
+// vision model, and records the result for ClassifierHealth. The canary uses
+// the configured VisionPrompt, honors ClassifierConcurrency and rate-limit
+// retries, and bypasses the Cache and OnClassification.
+//
+// Returns ErrNoClassifier when no Classifier is set, or the Classifier's error.
+func (cfg *Config) WarmupClassifier(ctx context.Context) error {
+	cfg = cfg.orZero()
+	cfg.defaults()
+
+	if cfg.Classifier == nil {
+		return ErrNoClassifier
+	}
+
+	prompt := cfg.VisionPrompt
+	if prompt == "" {
+		prompt = DefaultVisionPrompt
+	}
+	data := canaryJPEG()
+	start := time.Now()
+	resp, err := cfg.callClassifier(ctx, prompt, []ImageInput{newImageInput(data, "image/jpeg")})
+
+	h := ClassifierHealth{Healthy: err == nil, Response: resp, Latency: time.Since(start), Err: err, CheckedAt: time.Now()}
+	l := cfg.limiter()
+	l.mu.Lock()
+	l.health = h
+	l.mu.Unlock()
+	if err != nil {
+		slog.Debug("imagefy: classifier warmup failed", "latency", h.Latency, "error", err.Error())
+		return err
+	}
+	slog.Debug("imagefy: classifier warm", "latency", h.Latency, "response", resp)
+	return nil
+}
+
+// KeepClassifierWarm runs WarmupClassifier immediately and then every
+// interval until ctx is done, keeping a local model loaded between bursts of
+// work. Failures are recorded in ClassifierHealth and retried at the next tick.
+func (cfg *Config) KeepClassifierWarm(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_ = cfg.WarmupClassifier(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ClassifierHealth returns the result of the most recent WarmupClassifier call
+// (zero CheckedAt if none has run).
+func (cfg *Config) ClassifierHealth() ClassifierHealth {
+	cfg = cfg.orZero()
+	l := cfg.limiter()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.health
+}
@@ -0,0 +1,88 @@
+package imagefy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/jpeg"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// canaryRecorder records the images it receives and answers PHOTO or err.
+type canaryRecorder struct {
+	err    error
+	calls  atomic.Int32
+	images []ImageInput
+}
+
+func (c *canaryRecorder) Classify(_ context.Context, _ string, images []ImageInput) (string, error) {
+	c.calls.Add(1)
+	c.images = images
+	return "PHOTO 0.5", c.err
+}
+
+func TestWarmupClassifier(t *testing.T) {
+	t.Parallel()
+
+	var events atomic.Int32
+	cls := &canaryRecorder{}
+	cfg := &Config{Classifier: cls, OnClassification: func(ClassificationEvent) { events.Add(1) }}
+
+	if h := cfg.ClassifierHealth(); !h.CheckedAt.IsZero() {
+		t.Fatalf("health before warmup = %+v, want zero", h)
+	}
+	if err := cfg.WarmupClassifier(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	h := cfg.ClassifierHealth()
+	if !h.Healthy || h.Response != "PHOTO 0.5" || h.CheckedAt.IsZero() {
+		t.Errorf("health = %+v", h)
+	}
+	if len(cls.images) != 1 {
+		t.Fatalf("canary images = %d, want 1", len(cls.images))
+	}
+	if _, err := jpeg.DecodeConfig(bytes.NewReader(cls.images[0].Data)); err != nil {
+		t.Errorf("canary is not a JPEG: %v", err)
+	}
+	if events.Load() != 0 {
+		t.Error("warmup emitted OnClassification events")
+	}
+}
+
+func TestWarmupClassifier_Unhealthy(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("model not loaded")
+	cfg := &Config{Classifier: &canaryRecorder{err: boom}}
+	if err := cfg.WarmupClassifier(context.Background()); !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+	if h := cfg.ClassifierHealth(); h.Healthy || !errors.Is(h.Err, boom) {
+		t.Errorf("health = %+v, want unhealthy with the classifier error", h)
+	}
+}
+
+func TestWarmupClassifier_NoClassifier(t *testing.T) {
+	t.Parallel()
+
+	if err := (&Config{}).WarmupClassifier(context.Background()); !errors.Is(err, ErrNoClassifier) {
+		t.Errorf("err = %v, want ErrNoClassifier", err)
+	}
+}
+
+func TestKeepClassifierWarm(t *testing.T) {
+	t.Parallel()
+
+	cls := &canaryRecorder{}
+	cfg := &Config{Classifier: cls}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cfg.KeepClassifierWarm(ctx, 20*time.Millisecond)
+
+	if n := cls.calls.Load(); n < 2 {
+		t.Errorf("canary calls = %d, want periodic warmups", n)
+	}
+}
@@ -0,0 +1,152 @@
+package imagefy
+
+import (
+	"context"
+	"image"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultWatermarkPrompt is the follow-up prompt Config.LocateWatermarks
+// sends for STOCK verdicts.
+const DefaultWatermarkPrompt = `This image carries a stock or agency watermark, or an overlaid third-party credit.
+Locate it.
+
+Answer with its bounding box as four numbers from 0.0 to 1.0 — left, top, right, bottom —
+as fractions of the image width and height. If the watermark repeats across the image or
+covers most of it, answer NONE.
+
+Example: 0.70 0.90 1.00 1.00
+Answer:`
+
+// WatermarkBox is the rough location of a watermark, as fractions (0–1) of
+// the image width and height.
+type WatermarkBox struct {
+	Left   float64 `json:"left"`
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+}
+
+// WatermarkLocator finds the watermark of an image classified STOCK, e.g. a
+// computer-vision detector. It returns nil when there is no single
+// watermark to locate.
+type WatermarkLocator interface {
+	LocateWatermark(ctx context.Context, img ImageInput) (*WatermarkBox, error)
+}
+
+// MarginCrop returns the part of a width×height image left after cutting
+// off the thinnest edge strip that contains b, and false if that strip
+// exceeds maxLoss (a fraction, e.g. 0.2) of the width or height — the
+// watermark is not confined to a croppable margin.
+func (b WatermarkBox) MarginCrop(width, height int, maxLoss float64) (image.Rectangle, bool) {
+	strips := []struct {
+		loss float64
+		rect func() image.Rectangle
+	}{
+		{b.Bottom, func() image.Rectangle { // top strip
+			return image.Rect(0, ceilPx(b.Bottom, height), width, height)
+		}},
+		{1 - b.Top, func() image.Rectangle { // bottom strip
+			return image.Rect(0, 0, width, floorPx(b.Top, height))
+		}},
+		{b.Right, func() image.Rectangle { // left strip
+			return image.Rect(ceilPx(b.Right, width), 0, width, height)
+		}},
+		{1 - b.Left, func() image.Rectangle { // right strip
+			return image.Rect(0, 0, floorPx(b.Left, width), height)
+		}},
+	}
+	best := -1
+	for i, s := range strips {
+		if s.loss <= maxLoss && (best < 0 || s.loss < strips[best].loss) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return image.Rectangle{}, false
+	}
+	r := strips[best].rect()
+	return r, !r.Empty()
+}
+
+func ceilPx(f float64, n int) int {
+	px := int(f * float64(n))
+	if float64(px) < f*float64(n) {
+		px++
+	}
+	return min(px, n)
+}
+
+func floorPx(f float64, n int) int { return max(int(f*float64(n)), 0) }
+
+// valid reports whether b is a non-empty box inside the image.
+func (b WatermarkBox) valid() bool {
+	return b.Left >= 0 && b.Top >= 0 && b.Right <= 1 && b.Bottom <= 1 && b.Left < b.Right && b.Top < b.Bottom
+}
+
+// locateWatermark fills result.Watermark for a STOCK verdict without one,
+// using Config.WatermarkLocator or, with LocateWatermarks, a follow-up
+// Classifier call. Failures leave it nil.
+func (cfg *Config) locateWatermark(ctx context.Context, imageURL string, input ImageInput, result *ClassificationResult) {
+	if result.Class != ClassStock || result.Watermark != nil {
+		return
+	}
+	var (
+		box *WatermarkBox
+		err error
+	)
+	switch {
+	case cfg.WatermarkLocator != nil:
+		box, err = cfg.WatermarkLocator.LocateWatermark(ctx, input)
+	case cfg.LocateWatermarks && cfg.Classifier != nil:
+		var resp string
+		if resp, err = cfg.callClassifier(ctx, DefaultWatermarkPrompt, []ImageInput{input}); err == nil {
+			box = parseWatermarkBox(resp)
+		}
+	default:
+		return
+	}
+	if err != nil {
+		slog.Debug("imagefy: watermark location failed", "url", imageURL, "error", err)
+		return
+	}
+	if box != nil && box.valid() {
+		result.Watermark = box
+	}
+}
+
+// boxEdges is the number of values in a bounding-box answer.
+const boxEdges = 4
+
+// watermarkNumberRe matches the decimal numbers of a bounding-box answer.
+var watermarkNumberRe = regexp.MustCompile(`\d*\.?\d+`)
+
+// parseWatermarkBox parses a "left top right bottom" answer (any
+// separators, JSON arrays included), or returns nil for NONE and
+// unparseable or out-of-range answers.
+func parseWatermarkBox(resp string) *WatermarkBox {
+	resp = stripReasoning(resp)
+	if strings.HasPrefix(asciiUpper(strings.TrimSpace(resp)), "NONE") {
+		return nil
+	}
+	nums := watermarkNumberRe.FindAllString(resp, boxEdges)
+	if len(nums) < boxEdges {
+		return nil
+	}
+	var v [boxEdges]float64
+	for i, s := range nums {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil
+		}
+		v[i] = f
+	}
+	box := &WatermarkBox{Left: v[0], Top: v[1], Right: v[2], Bottom: v[3]}
+	if !box.valid() {
+		return nil
+	}
+	return box
+}
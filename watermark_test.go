@@ -0,0 +1,129 @@
+package imagefy
+
+import (
+	"context"
+	"image"
+	"sync"
+	"testing"
+)
+
+// scriptedClassifier answers successive Classify calls from responses and
+// records the prompts.
+type scriptedClassifier struct {
+	mu        sync.Mutex
+	responses []string
+	prompts   []string
+}
+
+func (c *scriptedClassifier) Classify(_ context.Context, prompt string, _ []ImageInput) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prompts = append(c.prompts, prompt)
+	if len(c.responses) == 0 {
+		return "", nil
+	}
+	resp := c.responses[0]
+	c.responses = c.responses[1:]
+	return resp, nil
+}
+
+type fixedLocator struct{ box *WatermarkBox }
+
+func (l fixedLocator) LocateWatermark(context.Context, ImageInput) (*WatermarkBox, error) {
+	return l.box, nil
+}
+
+func TestParseWatermarkBox(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		resp string
+		want *WatermarkBox
+	}{
+		{"0.70 0.90 1.00 1.00", &WatermarkBox{0.7, 0.9, 1, 1}},
+		{"<think>bottom right</think>[0, 0.85, 0.4, 1]", &WatermarkBox{0, 0.85, 0.4, 1}},
+		{"NONE", nil},
+		{"0.5 0.5 0.4 0.6", nil}, // right < left
+		{"0.1 0.2 1.3 0.4", nil}, // out of range
+		{"0.1 0.2", nil},
+	}
+	for _, tt := range tests {
+		got := parseWatermarkBox(tt.resp)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("parseWatermarkBox(%q) = %+v, want %+v", tt.resp, got, tt.want)
+		}
+	}
+}
+
+func TestWatermarkBoxMarginCrop(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		box    WatermarkBox
+		want   image.Rectangle
+		wantOK bool
+	}{
+		{"bottom credit", WatermarkBox{0.6, 0.9, 1, 1}, image.Rect(0, 0, 1000, 540), true},
+		{"top-left logo", WatermarkBox{0, 0, 0.15, 0.1}, image.Rect(0, 60, 1000, 600), true},
+		{"right edge strip", WatermarkBox{0.92, 0.2, 1, 0.8}, image.Rect(0, 0, 920, 600), true},
+		{"center watermark", WatermarkBox{0.3, 0.3, 0.7, 0.7}, image.Rectangle{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.box.MarginCrop(1000, 600, 0.2)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("%s: MarginCrop = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestLocateWatermarks(t *testing.T) {
+	t.Parallel()
+
+	srv := newImageServer(t, "image/jpeg", makeJPEG(100, 100))
+
+	t.Run("classifier follow-up", func(t *testing.T) {
+		t.Parallel()
+		cl := &scriptedClassifier{responses: []string{"STOCK 0.9", "0.6 0.9 1 1"}}
+		var events []ClassificationEvent
+		cfg := &Config{Classifier: cl, LocateWatermarks: true, OnClassification: func(e ClassificationEvent) { events = append(events, e) }}
+		got := cfg.ClassifyImageFull(context.Background(), srv.URL+"/a.jpg")
+		if got.Watermark == nil || *got.Watermark != (WatermarkBox{0.6, 0.9, 1, 1}) {
+			t.Fatalf("Watermark = %+v", got.Watermark)
+		}
+		if len(cl.prompts) != 2 || cl.prompts[1] != DefaultWatermarkPrompt {
+			t.Errorf("prompts = %d, want the classification and DefaultWatermarkPrompt", len(cl.prompts))
+		}
+		if len(events) != 1 || events[0].Watermark == nil {
+			t.Errorf("events = %+v, want the watermark on the event", events)
+		}
+	})
+
+	t.Run("photo is not located", func(t *testing.T) {
+		t.Parallel()
+		cl := &scriptedClassifier{responses: []string{"PHOTO 0.9"}}
+		cfg := &Config{Classifier: cl, LocateWatermarks: true}
+		if got := cfg.ClassifyImageFull(context.Background(), srv.URL+"/b.jpg"); got.Watermark != nil || len(cl.prompts) != 1 {
+			t.Errorf("Watermark = %+v after %d calls, want nil after 1", got.Watermark, len(cl.prompts))
+		}
+	})
+
+	t.Run("json answer needs no follow-up", func(t *testing.T) {
+		t.Parallel()
+		cl := &scriptedClassifier{responses: []string{`{"class":"STOCK","confidence":0.8,"watermark":[0,0,0.2,0.1]}`}}
+		cfg := &Config{Classifier: cl, LocateWatermarks: true}
+		got := cfg.ClassifyImageFull(context.Background(), srv.URL+"/c.jpg")
+		if got.Watermark == nil || *got.Watermark != (WatermarkBox{0, 0, 0.2, 0.1}) || len(cl.prompts) != 1 {
+			t.Errorf("Watermark = %+v after %d calls", got.Watermark, len(cl.prompts))
+		}
+	})
+
+	t.Run("custom locator", func(t *testing.T) {
+		t.Parallel()
+		cl := &scriptedClassifier{responses: []string{"STOCK 0.9"}}
+		cfg := &Config{Classifier: cl, WatermarkLocator: fixedLocator{&WatermarkBox{0, 0.9, 1, 1}}}
+		if got := cfg.ClassifyImageFull(context.Background(), srv.URL+"/d.jpg"); got.Watermark == nil || got.Watermark.Top != 0.9 {
+			t.Errorf("Watermark = %+v", got.Watermark)
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package imagefy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	wikimediaDefaultURL   = "https://commons.wikimedia.org/w/api.php"
+	wikimediaBodyLimit    = 2 * 1024 * 1024 // 2MB: extmetadata is verbose
+	wikimediaDefaultLimit = 20
+	wikimediaDefaultWidth = 1280
+	wikimediaUserAgent    = "go-imagefy/1.0 (+https://github.com/anatolykoptev/go-imagefy)"
+)
+
+// wikimediaExtMetadata are the extmetadata fields requested per file.
+const wikimediaExtMetadata = "LicenseShortName|UsageTerms|ObjectName|NonFree"
+
+// htmlTagRe matches an HTML tag; extmetadata values may contain markup.
+var htmlTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// wikimediaPage is one file page of a Commons generator=search response
+// (formatversion=2).
+type wikimediaPage struct {
+	Title     string `json:"title"`
+	Index     int    `json:"index"`
+	ImageInfo []struct {
+		URL            string `json:"url"`
+		ThumbURL       string `json:"thumburl"`
+		ThumbWidth     int    `json:"thumbwidth"`
+		ThumbHeight    int    `json:"thumbheight"`
+		DescriptionURL string `json:"descriptionurl"`
+		MIME           string `json:"mime"`
+		ExtMetadata    map[string]struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"extmetadata"`
+	} `json:"imageinfo"`
+	Raw json.RawMessage `json:"-"` // the page as received, with SearchOpts.KeepRaw
+}
+
+// WikimediaProvider searches Wikimedia Commons through the MediaWiki API
+// (generator=search over the File namespace with prop=imageinfo). Each
+// candidate carries the file's license from its extmetadata — LicenseShortName,
+// or UsageTerms without one — as ImageCandidate.LicenseName, which
+// AssessLicense reports as a "provider_license" signal, and its License is
+// derived from that name (see licenseFromName) rather than from the domain.
+// ImgURL is a rendering at most Width pixels wide, since Commons originals
+// are often far larger than the download cap. Non-free (fair-use) files are
+// skipped. Engines from SearchOpts are ignored.
+type WikimediaProvider struct {
+	BaseURL    string       // default: "https://commons.wikimedia.org/w/api.php"
+	HTTPClient *http.Client // optional (nil = the default imagefy client)
+
+	// UserAgent identifies the client, as the Wikimedia User-Agent policy
+	// requires (default: "go-imagefy/1.0 (+https://github.com/anatolykoptev/go-imagefy)").
+	UserAgent string
+
+	// Width is the width of the rendering used as ImgURL (default: 1280).
+	// Files narrower than Width are returned at full size.
+	Width int
+}
+
+// Name returns the provider name.
+func (p *WikimediaProvider) Name() string { return "wikimedia" }
+
+// Search queries Commons for files matching query and returns their
+// candidates in search-rank order. Non-raster files (SVG, PDF, video) are
+// returned as their thumbnail, a rendering of the first page or frame, and
+// skipped without one; non-free files and logo/banner URLs are excluded.
+func (p *WikimediaProvider) Search(ctx context.Context, query string, opts SearchOpts) ([]ImageCandidate, error) {
+	pages, err := p.fetch(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	return wikimediaCandidates(pages), nil
+}
+
+func (p *WikimediaProvider) fetch(ctx context.Context, query string, opts SearchOpts) ([]wikimediaPage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.buildURL(query, opts), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	ua := p.UserAgent
+	if ua == "" {
+		ua = wikimediaUserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+
+	client := p.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	resp, err := client.Do(req) //nolint:gosec // G107: URL is cfg-supplied by design — SSRF is caller's responsibility
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("wikimedia: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, wikimediaBodyLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	var searchResp struct {
+		Query json.RawMessage `json:"query"`
+		Error *struct {
+			Code string `json:"code"`
+			Info string `json:"info"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &searchResp); err != nil {
+		return nil, err
+	}
+	if searchResp.Error != nil {
+		return nil, fmt.Errorf("wikimedia: %s: %s", searchResp.Error.Code, searchResp.Error.Info)
+	}
+	if len(searchResp.Query) == 0 {
+		return nil, nil // no matches: the API omits "query"
+	}
+	var q struct {
+		Pages []wikimediaPage `json:"pages"`
+	}
+	if err := json.Unmarshal(searchResp.Query, &q); err != nil {
+		return nil, err
+	}
+	if opts.KeepRaw {
+		if raws := rawElements(searchResp.Query, "pages"); len(raws) == len(q.Pages) {
+			for i := range q.Pages {
+				q.Pages[i].Raw = raws[i]
+			}
+		}
+	}
+	// Pages come keyed by page ID; index is the search rank.
+	sort.SliceStable(q.Pages, func(i, j int) bool { return q.Pages[i].Index < q.Pages[j].Index })
+	return q.Pages, nil
+}
+
+func (p *WikimediaProvider) buildURL(query string, opts SearchOpts) string {
+	base := p.BaseURL
+	if base == "" {
+		base = wikimediaDefaultURL
+	}
+	width := p.Width
+	if width <= 0 {
+		width = wikimediaDefaultWidth
+	}
+	page := opts.PageNumber
+	if page < 1 {
+		page = 1
+	}
+
+	v := url.Values{}
+	v.Set("action", "query")
+	v.Set("format", "json")
+	v.Set("formatversion", "2")
+	v.Set("generator", "search")
+	v.Set("gsrsearch", query)
+	v.Set("gsrnamespace", "6") // File:
+	v.Set("gsrlimit", strconv.Itoa(wikimediaDefaultLimit))
+	v.Set("gsroffset", strconv.Itoa((page-1)*wikimediaDefaultLimit))
+	v.Set("prop", "imageinfo")
+	v.Set("iiprop", "url|size|mime|extmetadata")
+	v.Set("iiurlwidth", strconv.Itoa(width))
+	v.Set("iiextmetadatafilter", wikimediaExtMetadata)
+	return base + "?" + v.Encode()
+}
+
+func wikimediaCandidates(pages []wikimediaPage) []ImageCandidate {
+	var candidates []ImageCandidate
+	for _, pg := range pages {
+		if len(pg.ImageInfo) == 0 {
+			continue
+		}
+		info := pg.ImageInfo[0]
+		meta := func(key string) string {
+			var s string
+			if json.Unmarshal(info.ExtMetadata[key].Value, &s) != nil {
+				return ""
+			}
+			return strings.TrimSpace(html.UnescapeString(htmlTagRe.ReplaceAllString(s, "")))
+		}
+
+		// Only raster originals are usable as is; PDFs and videos have a
+		// thumbnail of their first page or frame, or nothing usable.
+		imgURL := info.ThumbURL
+		if imgURL == "" && strings.HasPrefix(info.MIME, "image/") {
+			imgURL = info.URL
+		}
+		if imgURL == "" || IsLogoOrBanner(strings.ToLower(imgURL)) {
+			continue
+		}
+
+		name := meta("LicenseShortName")
+		if name == "" {
+			name = meta("UsageTerms")
+		}
+		license := licenseFromName(name)
+		if meta("NonFree") == "true" || license == LicenseBlocked {
+			continue // fair use on one wiki, not reusable elsewhere
+		}
+		title := meta("ObjectName")
+		if title == "" {
+			title = strings.TrimSuffix(strings.TrimPrefix(pg.Title, "File:"), path.Ext(pg.Title))
+		}
+
+		candidates = append(candidates, ImageCandidate{
+			ImgURL:      imgURL,
+			Source:      info.DescriptionURL,
+			Title:       title,
+			License:     license,
+			LicenseName: name,
+			Width:       info.ThumbWidth,
+			Height:      info.ThumbHeight,
+			raw:         string(pg.Raw),
+		})
+	}
+	return candidates
+}
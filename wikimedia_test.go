@@ -0,0 +1,173 @@
+package imagefy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// wikimediaFixture is a formatversion=2 generator=search response with pages
+// out of rank order, markup in extmetadata, and files to skip.
+const wikimediaFixture = `{"batchcomplete":true,"query":{"pages":[
+ {"pageid":2,"ns":6,"title":"File:Kazan Kremlin at night.jpg","index":2,"imageinfo":[{
+   "url":"https://upload.wikimedia.org/wikipedia/commons/b/bb/Kazan_Kremlin_at_night.jpg",
+   "thumburl":"https://upload.wikimedia.org/wikipedia/commons/thumb/b/bb/Kazan_Kremlin_at_night.jpg/1280px-Kazan_Kremlin_at_night.jpg",
+   "thumbwidth":1280,"thumbheight":853,"mime":"image/jpeg",
+   "descriptionurl":"https://commons.wikimedia.org/wiki/File:Kazan_Kremlin_at_night.jpg",
+   "extmetadata":{"UsageTerms":{"value":"Creative Commons Attribution 3.0"}}}]},
+ {"pageid":1,"ns":6,"title":"File:Kul Sharif.jpg","index":1,"imageinfo":[{
+   "url":"https://upload.wikimedia.org/wikipedia/commons/a/aa/Kul_Sharif.jpg",
+   "thumburl":"https://upload.wikimedia.org/wikipedia/commons/thumb/a/aa/Kul_Sharif.jpg/1280px-Kul_Sharif.jpg",
+   "thumbwidth":1280,"thumbheight":960,"mime":"image/jpeg",
+   "descriptionurl":"https://commons.wikimedia.org/wiki/File:Kul_Sharif.jpg",
+   "extmetadata":{"LicenseShortName":{"value":"CC BY-SA 4.0"},"ObjectName":{"value":"<span>Kul Sharif &amp; Kremlin</span>"}}}]},
+ {"pageid":3,"ns":6,"title":"File:Tour.webm","index":3,"imageinfo":[{
+   "url":"https://upload.wikimedia.org/wikipedia/commons/c/cc/Tour.webm","mime":"video/webm",
+   "descriptionurl":"https://commons.wikimedia.org/wiki/File:Tour.webm"}]},
+ {"pageid":5,"ns":6,"title":"File:Kremlin plan.pdf","index":5,"imageinfo":[{
+   "url":"https://upload.wikimedia.org/wikipedia/commons/e/ee/Kremlin_plan.pdf",
+   "thumburl":"https://upload.wikimedia.org/wikipedia/commons/thumb/e/ee/Kremlin_plan.pdf/page1-1280px-Kremlin_plan.pdf.jpg",
+   "thumbwidth":1280,"thumbheight":1811,"mime":"application/pdf",
+   "descriptionurl":"https://commons.wikimedia.org/wiki/File:Kremlin_plan.pdf",
+   "extmetadata":{"LicenseShortName":{"value":"Public domain"}}}]},
+ {"pageid":4,"ns":6,"title":"File:Poster.jpg","index":4,"imageinfo":[{
+   "url":"https://upload.wikimedia.org/wikipedia/en/d/dd/Poster.jpg","mime":"image/jpeg",
+   "descriptionurl":"https://en.wikipedia.org/wiki/File:Poster.jpg",
+   "extmetadata":{"LicenseShortName":{"value":"Fair use"},"NonFree":{"value":"true"}}}]}
+]}}`
+
+func newWikimediaServer(t *testing.T, body string, query *url.Values, ua *string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if query != nil {
+			*query = r.URL.Query()
+		}
+		if ua != nil {
+			*ua = r.Header.Get("User-Agent")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWikimediaProviderSearch_MapsLicenseMetadata(t *testing.T) {
+	t.Parallel()
+
+	srv := newWikimediaServer(t, wikimediaFixture, nil, nil)
+	p := &WikimediaProvider{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	candidates, err := p.Search(context.Background(), "Kazan Kremlin", SearchOpts{})
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(candidates) != 3 {
+		t.Fatalf("got %d candidates, want 3 (video without a thumbnail and non-free file skipped)", len(candidates))
+	}
+
+	first := candidates[0]
+	if first.Title != "Kul Sharif & Kremlin" || first.LicenseName != "CC BY-SA 4.0" || first.License != LicenseSafe {
+		t.Errorf("first = %q / %q / %v, want rank-1 file with its ObjectName and LicenseShortName, safe", first.Title, first.LicenseName, first.License)
+	}
+	if !strings.Contains(first.ImgURL, "/1280px-") || first.Width != 1280 || first.Height != 960 {
+		t.Errorf("first ImgURL = %s (%dx%d), want the 1280px rendering", first.ImgURL, first.Width, first.Height)
+	}
+	if first.Source != "https://commons.wikimedia.org/wiki/File:Kul_Sharif.jpg" {
+		t.Errorf("first Source = %q, want the file description page", first.Source)
+	}
+
+	second := candidates[1]
+	if second.LicenseName != "Creative Commons Attribution 3.0" || second.License != LicenseSafe || second.Title != "Kazan Kremlin at night" {
+		t.Errorf("second = %q / %v / %q, want UsageTerms fallback and the file name as title", second.LicenseName, second.License, second.Title)
+	}
+
+	if pdf := candidates[2]; !strings.HasSuffix(pdf.ImgURL, "/page1-1280px-Kremlin_plan.pdf.jpg") || pdf.Height != 1811 || pdf.License != LicenseSafe {
+		t.Errorf("PDF = %s (%dx%d, %v), want its first-page thumbnail, safe", pdf.ImgURL, pdf.Width, pdf.Height, pdf.License)
+	}
+	for _, c := range candidates {
+		if strings.Contains(c.ImgURL, "Poster.jpg") {
+			t.Errorf("non-free file returned: %s", c.ImgURL)
+		}
+	}
+}
+
+func TestWikimediaProviderSearch_Request(t *testing.T) {
+	t.Parallel()
+
+	var q url.Values
+	var ua string
+	srv := newWikimediaServer(t, `{"batchcomplete":true}`, &q, &ua)
+
+	p := &WikimediaProvider{BaseURL: srv.URL, HTTPClient: srv.Client(), Width: 800}
+	candidates, err := p.Search(context.Background(), "Kazan", SearchOpts{PageNumber: 3})
+	if err != nil || len(candidates) != 0 {
+		t.Fatalf("no-match response: %d candidates, %v", len(candidates), err)
+	}
+	for param, want := range map[string]string{
+		"generator": "search", "gsrsearch": "Kazan", "gsrnamespace": "6", "gsroffset": "40",
+		"prop": "imageinfo", "iiurlwidth": "800", "formatversion": "2",
+	} {
+		if got := q.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+	if !strings.Contains(q.Get("iiprop"), "extmetadata") || !strings.Contains(q.Get("iiextmetadatafilter"), "LicenseShortName") {
+		t.Errorf("iiprop = %q, iiextmetadatafilter = %q; want license extmetadata requested", q.Get("iiprop"), q.Get("iiextmetadatafilter"))
+	}
+	if !strings.HasPrefix(ua, "go-imagefy/") {
+		t.Errorf("User-Agent = %q, want the descriptive default", ua)
+	}
+}
+
+func TestWikimediaProviderSearch_APIError(t *testing.T) {
+	t.Parallel()
+
+	srv := newWikimediaServer(t, `{"error":{"code":"maxlag","info":"Waiting for a database server"}}`, nil, nil)
+	p := &WikimediaProvider{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	if _, err := p.Search(context.Background(), "Kazan", SearchOpts{}); err == nil || !strings.Contains(err.Error(), "maxlag") {
+		t.Errorf("Search error = %v, want the API error", err)
+	}
+}
+
+func TestLicenseFromName(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]ImageLicense{
+		"CC BY-SA 4.0":                     LicenseSafe,
+		"CC0":                              LicenseSafe,
+		"Public domain":                    LicenseSafe,
+		"PD-old-70":                        LicenseSafe,
+		"GFDL":                             LicenseSafe,
+		"Creative Commons Attribution 3.0": LicenseSafe,
+		"CC BY-NC-SA 2.0":                  LicenseUnknown,
+		"CC BY-ND 4.0":                     LicenseUnknown,
+		"Attribution-NonCommercial":        LicenseUnknown,
+		"Fair use":                         LicenseBlocked,
+		"Non-free media":                   LicenseBlocked,
+		"All rights reserved":              LicenseUnknown,
+		"":                                 LicenseUnknown,
+	} {
+		if got := licenseFromName(name); got != want {
+			t.Errorf("licenseFromName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestAssessLicense_ProviderLicense(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+	cand := ImageCandidate{ImgURL: "https://cdn.example/a.jpg", Source: "https://example.org/a", LicenseName: "CC BY 4.0"}
+	a := cfg.AssessLicense(cand, nil)
+	if a.License != LicenseSafe || len(a.Signals) != 1 || a.Signals[0].Source != "provider_license" {
+		t.Errorf("AssessLicense = %+v, want safe from a provider_license signal", a)
+	}
+
+	cand.LicenseName = "CC BY-NC 4.0"
+	if a := cfg.AssessLicense(cand, nil); a.License != LicenseUnknown || len(a.Signals) != 0 {
+		t.Errorf("NonCommercial: AssessLicense = %+v, want unknown without signals", a)
+	}
+}
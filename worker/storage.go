@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// defaultSignTTL is how long signed URLs stay valid without StorageSink.TTL.
+const defaultSignTTL = time.Hour
+
+// Storage writes the images of a Result to durable storage, e.g. an S3
+// bucket, and returns the object key of each of r.Candidates in order (""
+// = not stored). Implementations must be safe for concurrent use.
+type Storage interface {
+	Save(ctx context.Context, r Result) (keys []string, err error)
+}
+
+// StorageFunc adapts a function to Storage.
+type StorageFunc func(ctx context.Context, r Result) ([]string, error)
+
+// Save calls f(ctx, r).
+func (f StorageFunc) Save(ctx context.Context, r Result) ([]string, error) { return f(ctx, r) }
+
+// Signer returns a URL granting read access to the object stored under key
+// for ttl, e.g. an S3 pre-signed GET URL, and the time it expires. It only
+// sees keys, so one Signer fits any Storage that writes to its bucket.
+// Implementations must be safe for concurrent use.
+type Signer interface {
+	Sign(ctx context.Context, key string, ttl time.Duration) (signedURL string, expires time.Time, err error)
+}
+
+// SignerFunc adapts a function to Signer.
+type SignerFunc func(ctx context.Context, key string, ttl time.Duration) (string, time.Time, error)
+
+// Sign calls f(ctx, key, ttl).
+func (f SignerFunc) Sign(ctx context.Context, key string, ttl time.Duration) (string, time.Time, error) {
+	return f(ctx, key, ttl)
+}
+
+// StorageSink is a Sink that saves each Result to Storage, signs the stored
+// keys with Signer, and passes the Result on to Next with the signed URLs
+// set on its candidates (ImageCandidate.SignedURL and SignedURLExpires), so
+// a CMS can reference the stored images right away. A failed save or
+// signature fails Put, which leaves the job open to be delivered again.
+type StorageSink struct {
+	Storage Storage       // required
+	Signer  Signer        // optional: nil = candidates get no signed URL
+	TTL     time.Duration // lifetime of signed URLs (default: 1h)
+	Next    Sink          // optional: receives the Result with signed URLs
+}
+
+// Put implements Sink.
+func (s *StorageSink) Put(ctx context.Context, r Result) error {
+	keys, err := s.Storage.Save(ctx, r)
+	if err != nil {
+		return fmt.Errorf("worker: storage: %w", err)
+	}
+	if s.Signer != nil {
+		ttl := s.TTL
+		if ttl <= 0 {
+			ttl = defaultSignTTL
+		}
+		r.Candidates = slices.Clone(r.Candidates) // the caller's Result keeps its unsigned candidates
+		for i, key := range keys {
+			if key == "" || i >= len(r.Candidates) {
+				continue
+			}
+			signed, expires, err := s.Signer.Sign(ctx, key, ttl)
+			if err != nil {
+				return fmt.Errorf("worker: sign %s: %w", key, err)
+			}
+			r.Candidates[i].SignedURL, r.Candidates[i].SignedURLExpires = signed, expires
+		}
+	}
+	if s.Next == nil {
+		return nil
+	}
+	return s.Next.Put(ctx, r)
+}
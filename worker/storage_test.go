@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+func TestStorageSink_SignsStoredCandidates(t *testing.T) {
+	t.Parallel()
+
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	var gotTTL time.Duration
+	var delivered Result
+	sink := &StorageSink{
+		Storage: StorageFunc(func(_ context.Context, r Result) ([]string, error) {
+			return []string{"jobs/" + r.Job.ID + "/0.jpg", ""}, nil
+		}),
+		Signer: SignerFunc(func(_ context.Context, key string, ttl time.Duration) (string, time.Time, error) {
+			gotTTL = ttl
+			return "https://bucket.example/" + key + "?sig=x", expires, nil
+		}),
+		Next: SinkFunc(func(_ context.Context, r Result) error {
+			delivered = r
+			return nil
+		}),
+	}
+	res := Result{Job: Job{ID: "42"}, Candidates: []imagefy.ImageCandidate{{ImgURL: "https://a.example/1.jpg"}, {ImgURL: "https://a.example/2.jpg"}}}
+
+	if err := sink.Put(context.Background(), res); err != nil {
+		t.Fatalf("Put() = %v", err)
+	}
+	if c := delivered.Candidates[0]; c.SignedURL != "https://bucket.example/jobs/42/0.jpg?sig=x" || !c.SignedURLExpires.Equal(expires) {
+		t.Errorf("stored candidate = %+v, want signed", c)
+	}
+	if c := delivered.Candidates[1]; c.SignedURL != "" || !c.SignedURLExpires.IsZero() {
+		t.Errorf("unstored candidate = %+v, want no signed URL", c)
+	}
+	if gotTTL != defaultSignTTL {
+		t.Errorf("ttl = %v, want %v", gotTTL, defaultSignTTL)
+	}
+	if res.Candidates[0].SignedURL != "" {
+		t.Error("Put modified the caller's candidates")
+	}
+}
+
+func TestStorageSink_Errors(t *testing.T) {
+	t.Parallel()
+
+	errDown := errors.New("bucket down")
+	store := StorageFunc(func(context.Context, Result) ([]string, error) { return []string{"k"}, nil })
+	tests := []struct {
+		name string
+		sink *StorageSink
+	}{
+		{"storage", &StorageSink{Storage: StorageFunc(func(context.Context, Result) ([]string, error) { return nil, errDown })}},
+		{"signer", &StorageSink{Storage: store, Signer: SignerFunc(func(context.Context, string, time.Duration) (string, time.Time, error) {
+			return "", time.Time{}, errDown
+		})}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			next := false
+			tc.sink.Next = SinkFunc(func(context.Context, Result) error { next = true; return nil })
+			err := tc.sink.Put(context.Background(), Result{Candidates: []imagefy.ImageCandidate{{ImgURL: "https://a.example/1.jpg"}}})
+			if !errors.Is(err, errDown) || next {
+				t.Errorf("Put() = %v, Next called = %v; want %v and no delivery", err, next, errDown)
+			}
+		})
+	}
+}
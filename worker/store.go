@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+// Status is the lifecycle state of a job in a JobStore.
+type Status string
+
+// Job statuses.
+const (
+	StatusRunning Status = "running" // an attempt started; the job did not finish yet
+	StatusDone    Status = "done"    // images were found and delivered to the Sink
+	StatusFailed  Status = "failed"  // all attempts were used without finding images
+)
+
+// JobState is the persisted progress of a job.
+type JobState struct {
+	ID         string                   `json:"id"`
+	Status     Status                   `json:"status"`
+	Attempts   int                      `json:"attempts"`
+	Candidates []imagefy.ImageCandidate `json:"candidates,omitempty"`
+	LastError  string                   `json:"last_error,omitempty"`
+	UpdatedAt  time.Time                `json:"updated_at"`
+}
+
+// JobStore persists job progress so a restarted Worker resumes where it left
+// off: finished jobs are skipped (their stored Result is returned without a
+// pipeline run) and an interrupted job continues with its remaining attempts.
+// Implementations must be safe for concurrent use.
+type JobStore interface {
+	// Get returns the state of job id; ok is false if the job is unknown.
+	Get(ctx context.Context, id string) (state JobState, ok bool, err error)
+	// Put stores state, replacing any previous state of state.ID.
+	Put(ctx context.Context, state JobState) error
+}
+
+// MemoryStore is an in-process JobStore, useful for tests and for
+// deduplicating jobs within one process lifetime.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]JobState
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]JobState)}
+}
+
+// Get implements JobStore.
+func (m *MemoryStore) Get(_ context.Context, id string) (JobState, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.states[id]
+	return st, ok, nil
+}
+
+// Put implements JobStore.
+func (m *MemoryStore) Put(_ context.Context, state JobState) error {
+	m.mu.Lock()
+	m.states[state.ID] = state
+	m.mu.Unlock()
+	return nil
+}
+
+// FileStore is a JobStore keeping one JSON file per job under Dir. Writes go
+// through a temporary file, so a crash never leaves a partial state behind.
+type FileStore struct {
+	Dir string
+}
+
+// Get implements JobStore.
+func (f *FileStore) Get(_ context.Context, id string) (JobState, bool, error) {
+	data, err := os.ReadFile(f.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return JobState{}, false, nil
+	}
+	if err != nil {
+		return JobState{}, false, err
+	}
+	var st JobState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return JobState{}, false, err
+	}
+	return st, true, nil
+}
+
+// Put implements JobStore.
+func (f *FileStore) Put(_ context.Context, state JobState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil { //nolint:mnd // standard directory permissions
+		return err
+	}
+	tmp, err := os.CreateTemp(f.Dir, "job-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(state.ID))
+}
+
+// path maps a job ID to a file name. IDs are hashed because they are
+// caller-defined and may contain path separators.
+func (f *FileStore) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(f.Dir, hex.EncodeToString(sum[:16])+".json") //nolint:mnd // 128-bit prefix is plenty
+}
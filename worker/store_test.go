@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+	"github.com/anatolykoptev/go-imagefy/imagefytest"
+)
+
+func TestWorker_StoreSkipsFinishedJobs(t *testing.T) {
+	t.Parallel()
+
+	srv := imagefytest.NewImageServer(t)
+	srv.Add("/x.jpg", imagefytest.Image{})
+	prov := &flakyProvider{url: srv.URL}
+	store := NewMemoryStore()
+	w := &Worker{
+		Config: &imagefy.Config{HTTPClient: srv.Client(), Providers: []imagefy.SearchProvider{prov}},
+		Store:  store,
+	}
+	job := Job{ID: "x", Query: "x"}
+
+	first := w.Process(context.Background(), job)
+	if first.Err != nil || first.Resumed {
+		t.Fatalf("first Process() = %+v", first)
+	}
+	st, ok, _ := store.Get(context.Background(), "x")
+	if !ok || st.Status != StatusDone || len(st.Candidates) != 1 || st.UpdatedAt.IsZero() {
+		t.Fatalf("stored state = %+v, %v", st, ok)
+	}
+
+	second := w.Process(context.Background(), job)
+	if !second.Resumed || len(second.Candidates) != 1 || second.Candidates[0].ImgURL != first.Candidates[0].ImgURL {
+		t.Errorf("second Process() = %+v, want resumed copy of first", second)
+	}
+	if n := prov.calls.Load(); n != 1 {
+		t.Errorf("provider calls = %d, want 1", n)
+	}
+}
+
+func TestWorker_StoreResumesRemainingAttempts(t *testing.T) {
+	t.Parallel()
+
+	prov := &flakyProvider{fails: 100}
+	store := NewMemoryStore()
+	_ = store.Put(context.Background(), JobState{ID: "y", Status: StatusRunning, Attempts: 2})
+	w := &Worker{Config: newTestConfig(t, prov), Store: store, MaxAttempts: 3, RetryDelay: time.Millisecond}
+
+	r := w.Process(context.Background(), Job{ID: "y", Query: "y"})
+	if !errors.Is(r.Err, ErrNoImages) || r.Attempts != 3 {
+		t.Errorf("Process() = %+v, want ErrNoImages after 3 attempts", r)
+	}
+	if n := prov.calls.Load(); n != 1 {
+		t.Errorf("provider calls = %d, want 1 (only the remaining attempt)", n)
+	}
+	st, _, _ := store.Get(context.Background(), "y")
+	if st.Status != StatusFailed || st.LastError != ErrNoImages.Error() {
+		t.Errorf("stored state = %+v, want failed", st)
+	}
+
+	// A failed job is not retried on the next run.
+	again := w.Process(context.Background(), Job{ID: "y", Query: "y"})
+	if !again.Resumed || !errors.Is(again.Err, ErrNoImages) || prov.calls.Load() != 1 {
+		t.Errorf("Process() on failed job = %+v, provider calls = %d", again, prov.calls.Load())
+	}
+}
+
+func TestWorker_RunKeepsJobOpenWhenSinkFails(t *testing.T) {
+	t.Parallel()
+
+	srv := imagefytest.NewImageServer(t)
+	srv.Add("/z.jpg", imagefytest.Image{})
+	store := NewMemoryStore()
+	q := make(ChanQueue, 1)
+	q <- Job{ID: "z", Query: "z"}
+	close(q)
+
+	w := &Worker{
+		Config: &imagefy.Config{HTTPClient: srv.Client(), Providers: []imagefy.SearchProvider{&flakyProvider{url: srv.URL}}},
+		Queue:  q,
+		Store:  store,
+		Sink:   SinkFunc(func(context.Context, Result) error { return errors.New("db down") }),
+	}
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	st, _, _ := store.Get(context.Background(), "z")
+	if st.Status != StatusRunning {
+		t.Errorf("status after failed sink = %q, want %q", st.Status, StatusRunning)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+
+	fs := &FileStore{Dir: t.TempDir()}
+	ctx := context.Background()
+
+	if _, ok, err := fs.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("Get(missing) = %v, %v; want false, nil", ok, err)
+	}
+
+	want := JobState{
+		ID:         "places/42",
+		Status:     StatusDone,
+		Attempts:   2,
+		Candidates: []imagefy.ImageCandidate{{ImgURL: "https://example.org/a.jpg", License: imagefy.LicenseSafe}},
+		UpdatedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := fs.Put(ctx, want); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, ok, err := fs.Get(ctx, want.ID)
+	if err != nil || !ok {
+		t.Fatalf("Get = %v, %v", ok, err)
+	}
+	if got.ID != want.ID || got.Status != want.Status || got.Attempts != want.Attempts ||
+		len(got.Candidates) != 1 || got.Candidates[0] != want.Candidates[0] || !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("Get = %+v, want %+v", got, want)
+	}
+}
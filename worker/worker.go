@@ -0,0 +1,352 @@
+// Package worker runs imagefy image sourcing as a background service: a pool
+// of goroutines consumes "find an image for entity X" jobs from a Queue, runs
+// the full pipeline with retries and a per-job vision budget, and hands each
+// Result to a Sink.
+//
+//	q := make(worker.ChanQueue, 100)
+//	w := &worker.Worker{
+//		Config: cfg,
+//		Queue:  q,
+//		Sink: worker.SinkFunc(func(ctx context.Context, r worker.Result) error {
+//			return db.SaveImages(ctx, r.Job.ID, r.Candidates)
+//		}),
+//	}
+//	go w.Run(ctx)
+//	q <- worker.Job{ID: "place-42", Query: "Hermitage Museum"}
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+)
+
+// Worker defaults.
+const (
+	defaultConcurrency = 4
+	defaultMaxAttempts = 3
+	defaultRetryDelay  = time.Second
+	defaultJobTimeout  = time.Minute
+	defaultMaxResults  = 3
+)
+
+// ErrQueueClosed is returned by Queue.Next when no more jobs will arrive.
+var ErrQueueClosed = errors.New("worker: queue closed")
+
+// ErrNoImages is set as Result.Err when every attempt returned no images.
+var ErrNoImages = errors.New("worker: no images found")
+
+// Job asks for images for one entity.
+type Job struct {
+	ID         string                   // caller's entity ID, passed through to the Result
+	Query      string                   // search query
+	PageURL    string                   // optional page to extract content images from
+	External   []imagefy.ImageCandidate // optional caller-supplied candidates
+	MaxResults int                      // default: 3
+	Opts       imagefy.SearchOpts       // search options
+}
+
+// Result is the outcome of a Job.
+type Result struct {
+	Job        Job
+	Candidates []imagefy.ImageCandidate // accepted images, best first
+	Attempts   int                      // pipeline runs performed
+	Duration   time.Duration            // wall time including retry delays
+	Err        error                    // nil on success; ErrNoImages, a context error, or a recovered panic
+	Resumed    bool                     // true if the job had already finished and the Result was loaded from Store
+}
+
+// Queue supplies jobs. Next blocks until a job is available and returns
+// ErrQueueClosed once the queue is drained and closed.
+// Implementations must be safe for concurrent use.
+type Queue interface {
+	Next(ctx context.Context) (Job, error)
+}
+
+// ChanQueue is a Queue backed by a channel. Close the channel to stop the Worker
+// once queued jobs are processed.
+type ChanQueue chan Job
+
+// Next returns the next job from the channel.
+func (q ChanQueue) Next(ctx context.Context) (Job, error) {
+	select {
+	case job, ok := <-q:
+		if !ok {
+			return Job{}, ErrQueueClosed
+		}
+		return job, nil
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
+
+// Sink receives results. Errors are logged and do not stop the Worker.
+// Implementations must be safe for concurrent use.
+type Sink interface {
+	Put(ctx context.Context, r Result) error
+}
+
+// SinkFunc adapts a function to Sink.
+type SinkFunc func(ctx context.Context, r Result) error
+
+// Put calls f(ctx, r).
+func (f SinkFunc) Put(ctx context.Context, r Result) error { return f(ctx, r) }
+
+// Worker processes jobs from Queue with Concurrency goroutines.
+type Worker struct {
+	Config *imagefy.Config // pipeline configuration (required)
+	Queue  Queue           // job source (required)
+	Sink   Sink            // result destination (nil = results are dropped)
+	Store  JobStore        // optional: persists job progress for resumability
+
+	Concurrency int           // parallel jobs (default: 4)
+	MaxAttempts int           // pipeline runs per job before giving up (default: 3)
+	RetryDelay  time.Duration // delay before the first retry, doubled for each further retry (default: 1s)
+	JobTimeout  time.Duration // timeout of a single attempt (default: 1m)
+
+	// VisionBudget caps vision classifications per job across all of its
+	// attempts (0 = unlimited). See imagefy.SessionOpts.VisionBudget.
+	VisionBudget int
+
+	initOnce sync.Once
+}
+
+// init fills Config defaults once, before the first job of Run or Process,
+// rather than racing on them from every job's session.
+func (w *Worker) init() {
+	w.initOnce.Do(w.Config.ApplyDefaults)
+}
+
+// Run processes jobs until the queue is closed (returns nil) or ctx is done
+// (returns ctx.Err()). Jobs in flight are finished before Run returns; when
+// ctx is done they end early with a context error.
+func (w *Worker) Run(ctx context.Context) error {
+	if w.Config == nil || w.Queue == nil {
+		return errors.New("worker: Config and Queue are required")
+	}
+
+	n := w.Concurrency
+	if n <= 0 {
+		n = defaultConcurrency
+	}
+
+	w.init()
+
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil && !errors.Is(err, ErrQueueClosed) {
+			return err
+		}
+	}
+	return nil
+}
+
+// loop pulls and processes jobs until Next fails. The final job state is
+// persisted only after the Sink accepted the result, so a crash in between
+// re-delivers the job instead of losing it.
+func (w *Worker) loop(ctx context.Context) error {
+	for {
+		job, err := w.Queue.Next(ctx)
+		if err != nil {
+			return err
+		}
+		res := w.run(ctx, job)
+		if res.Resumed {
+			continue
+		}
+		if w.Sink != nil {
+			if err := w.Sink.Put(ctx, res); err != nil {
+				slog.Warn("imagefy: worker sink failed", "job", job.ID, "error", err)
+				continue
+			}
+		}
+		w.finish(ctx, res)
+	}
+}
+
+// Process runs a single job with retries, persists its final state to Store,
+// and returns its Result. It may be called directly to process a job
+// synchronously; Run uses the same logic.
+func (w *Worker) Process(ctx context.Context, job Job) Result {
+	w.init()
+	res := w.run(ctx, job)
+	w.finish(ctx, res)
+	return res
+}
+
+// run executes job, resuming from Store state if present.
+func (w *Worker) run(ctx context.Context, job Job) Result {
+	start := time.Now()
+	res := Result{Job: job}
+
+	if st, ok := w.load(ctx, job.ID); ok {
+		switch st.Status {
+		case StatusDone:
+			res.Candidates, res.Attempts, res.Resumed = st.Candidates, st.Attempts, true
+			return res
+		case StatusFailed:
+			res.Attempts, res.Resumed = st.Attempts, true
+			res.Err = ErrNoImages
+			if st.LastError != ErrNoImages.Error() {
+				res.Err = errors.New(st.LastError)
+			}
+			return res
+		default:
+			res.Attempts = st.Attempts
+		}
+	}
+
+	session := w.Config.NewSession(imagefy.SessionOpts{VisionBudget: w.VisionBudget})
+	attempts := w.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultMaxAttempts
+	}
+	delay := w.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	for res.Attempts < attempts {
+		if res.Attempts > 0 {
+			if err := sleep(ctx, delay); err != nil {
+				res.Err = err
+				break
+			}
+			delay *= 2
+		}
+		res.Attempts++
+		w.save(ctx, JobState{ID: job.ID, Status: StatusRunning, Attempts: res.Attempts})
+
+		if res.Attempts > 1 {
+			session = session.Retry() // a failed attempt's images are not duplicates
+		}
+		cands, err := w.attempt(ctx, session, job)
+		if err != nil {
+			res.Err = err
+			break
+		}
+		if len(cands) > 0 {
+			res.Candidates = cands
+			res.Err = nil
+			break
+		}
+		res.Err = ErrNoImages
+		if ctx.Err() != nil {
+			res.Err = ctx.Err()
+			break
+		}
+		slog.Debug("imagefy: worker attempt found no images", "job", job.ID, "attempt", res.Attempts)
+	}
+	if res.Err == nil && len(res.Candidates) == 0 {
+		res.Err = ErrNoImages // resumed with no attempts left
+	}
+
+	res.Duration = time.Since(start)
+	return res
+}
+
+// finish persists the final state of res. Interrupted jobs (context errors)
+// stay StatusRunning so they are resumed later.
+func (w *Worker) finish(ctx context.Context, res Result) {
+	if res.Resumed {
+		return
+	}
+	st := JobState{ID: res.Job.ID, Attempts: res.Attempts, Candidates: res.Candidates}
+	switch {
+	case res.Err == nil:
+		st.Status = StatusDone
+	case errors.Is(res.Err, context.Canceled), errors.Is(res.Err, context.DeadlineExceeded):
+		st.Status = StatusRunning
+		st.LastError = res.Err.Error()
+	default:
+		st.Status = StatusFailed
+		st.LastError = res.Err.Error()
+	}
+	// Persist even if ctx was canceled: recording progress is what makes the
+	// job resumable.
+	w.save(context.WithoutCancel(ctx), st)
+}
+
+// load reads the stored state of id. Store errors are logged and treated as
+// "no state" so a broken store degrades to re-running the job.
+func (w *Worker) load(ctx context.Context, id string) (JobState, bool) {
+	if w.Store == nil {
+		return JobState{}, false
+	}
+	st, ok, err := w.Store.Get(ctx, id)
+	if err != nil {
+		slog.Warn("imagefy: worker store get failed", "job", id, "error", err)
+		return JobState{}, false
+	}
+	return st, ok
+}
+
+// save writes st to Store, logging failures.
+func (w *Worker) save(ctx context.Context, st JobState) {
+	if w.Store == nil {
+		return
+	}
+	st.UpdatedAt = time.Now()
+	if err := w.Store.Put(ctx, st); err != nil {
+		slog.Warn("imagefy: worker store put failed", "job", st.ID, "error", err)
+	}
+}
+
+// attempt runs the pipeline once, converting a panic into an error.
+func (w *Worker) attempt(ctx context.Context, session *imagefy.Session, job Job) (cands []imagefy.ImageCandidate, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if w.Config.OnPanic != nil {
+				w.Config.OnPanic("worker", r)
+			}
+			err = fmt.Errorf("worker: panic: %v", r)
+		}
+	}()
+
+	timeout := w.JobTimeout
+	if timeout <= 0 {
+		timeout = defaultJobTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	maxResults := job.MaxResults
+	if maxResults <= 0 {
+		maxResults = defaultMaxResults
+	}
+	return session.FindImages(ctx, imagefy.FindOpts{
+		Query:      job.Query,
+		PageURL:    job.PageURL,
+		External:   job.External,
+		MaxResults: maxResults,
+		SearchOpts: job.Opts,
+	}), nil
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
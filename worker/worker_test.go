@@ -0,0 +1,208 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	imagefy "github.com/anatolykoptev/go-imagefy"
+	"github.com/anatolykoptev/go-imagefy/imagefytest"
+)
+
+func newTestConfig(t *testing.T, prov imagefy.SearchProvider) *imagefy.Config {
+	t.Helper()
+	srv := imagefytest.NewImageServer(t)
+	return &imagefy.Config{HTTPClient: srv.Client(), Providers: []imagefy.SearchProvider{prov}}
+}
+
+// flakyProvider returns no results for the first fails calls.
+type flakyProvider struct {
+	url   string
+	fails int32
+	calls atomic.Int32
+}
+
+func (p *flakyProvider) Name() string { return "flaky" }
+
+func (p *flakyProvider) Search(_ context.Context, query string, _ imagefy.SearchOpts) ([]imagefy.ImageCandidate, error) {
+	if p.calls.Add(1) <= p.fails {
+		return nil, errors.New("temporarily unavailable")
+	}
+	return []imagefy.ImageCandidate{{ImgURL: p.url + "/" + query + ".jpg", Source: "https://example.org/page", License: imagefy.LicenseUnknown}}, nil
+}
+
+func TestWorker_RunProcessesAllJobs(t *testing.T) {
+	t.Parallel()
+
+	srv := imagefytest.NewImageServer(t)
+	for _, q := range []string{"a", "b", "c"} {
+		srv.Add("/"+q+".jpg", imagefytest.Image{})
+	}
+	prov := &flakyProvider{url: srv.URL}
+	cfg := &imagefy.Config{HTTPClient: srv.Client(), Providers: []imagefy.SearchProvider{prov}}
+
+	var mu sync.Mutex
+	results := map[string]Result{}
+	q := make(ChanQueue, 3)
+	w := &Worker{
+		Config:      cfg,
+		Queue:       q,
+		Concurrency: 2,
+		Sink: SinkFunc(func(_ context.Context, r Result) error {
+			mu.Lock()
+			results[r.Job.ID] = r
+			mu.Unlock()
+			return nil
+		}),
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		q <- Job{ID: id, Query: id}
+	}
+	close(q)
+
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run() = %v, want nil", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for id, r := range results {
+		if r.Err != nil || len(r.Candidates) != 1 || r.Attempts != 1 {
+			t.Errorf("result %s = %+v", id, r)
+		}
+	}
+}
+
+func TestWorker_ProcessRetries(t *testing.T) {
+	t.Parallel()
+
+	srv := imagefytest.NewImageServer(t)
+	srv.Add("/x.jpg", imagefytest.Image{})
+	prov := &flakyProvider{url: srv.URL, fails: 2}
+	w := &Worker{
+		Config:     &imagefy.Config{HTTPClient: srv.Client(), Providers: []imagefy.SearchProvider{prov}},
+		RetryDelay: time.Millisecond,
+	}
+
+	r := w.Process(context.Background(), Job{ID: "x", Query: "x"})
+	if r.Err != nil || len(r.Candidates) != 1 {
+		t.Fatalf("Process() = %+v, want one candidate", r)
+	}
+	if r.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", r.Attempts)
+	}
+}
+
+// flakyClassifier fails its first fails calls and answers PHOTO after.
+type flakyClassifier struct {
+	fails int32
+	calls atomic.Int32
+}
+
+func (c *flakyClassifier) Classify(context.Context, string, []imagefy.ImageInput) (string, error) {
+	if c.calls.Add(1) <= c.fails {
+		return "", errors.New("temporarily unavailable")
+	}
+	return "PHOTO 0.9", nil
+}
+
+func TestWorker_ProcessRetriesSameImages(t *testing.T) {
+	t.Parallel()
+
+	srv := imagefytest.NewImageServer(t)
+	srv.Add("/x.jpg", imagefytest.Image{})
+	classifier := &flakyClassifier{fails: 1}
+	w := &Worker{
+		Config: &imagefy.Config{
+			HTTPClient:        srv.Client(),
+			Providers:         []imagefy.SearchProvider{&flakyProvider{url: srv.URL}},
+			Classifier:        classifier,
+			DegradationPolicy: imagefy.DegradeReject,
+		},
+		RetryDelay:   time.Millisecond,
+		VisionBudget: 5,
+	}
+
+	r := w.Process(context.Background(), Job{ID: "x", Query: "x"})
+	if r.Err != nil || len(r.Candidates) != 1 || r.Attempts != 2 {
+		t.Fatalf("Process() = %+v, want the image accepted on the second attempt", r)
+	}
+	if n := classifier.calls.Load(); n != 2 {
+		t.Errorf("classifier calls = %d, want 2 (the retry must not reject the image as a duplicate)", n)
+	}
+}
+
+// TestWorker_ProcessConcurrent calls Process from several goroutines on a
+// Config whose defaults are still unset; run with -race.
+func TestWorker_ProcessConcurrent(t *testing.T) {
+	t.Parallel()
+
+	srv := imagefytest.NewImageServer(t)
+	jobs := []string{"a", "b", "c", "d"}
+	for _, q := range jobs {
+		srv.Add("/"+q+".jpg", imagefytest.Image{})
+	}
+	prov := &flakyProvider{url: srv.URL}
+	w := &Worker{Config: &imagefy.Config{HTTPClient: srv.Client(), Providers: []imagefy.SearchProvider{prov}}}
+
+	var wg sync.WaitGroup
+	results := make([]Result, len(jobs))
+	for i, id := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = w.Process(context.Background(), Job{ID: id, Query: id})
+		}()
+	}
+	wg.Wait()
+	for _, r := range results {
+		if r.Err != nil || len(r.Candidates) != 1 {
+			t.Errorf("Process(%s) = %+v, want one candidate", r.Job.ID, r)
+		}
+	}
+}
+
+func TestWorker_ProcessGivesUp(t *testing.T) {
+	t.Parallel()
+
+	prov := &flakyProvider{fails: 100}
+	w := &Worker{Config: newTestConfig(t, prov), MaxAttempts: 2, RetryDelay: time.Millisecond}
+
+	r := w.Process(context.Background(), Job{ID: "y", Query: "y"})
+	if !errors.Is(r.Err, ErrNoImages) {
+		t.Errorf("Err = %v, want ErrNoImages", r.Err)
+	}
+	if r.Attempts != 2 || prov.calls.Load() != 2 {
+		t.Errorf("Attempts = %d, provider calls = %d; want 2, 2", r.Attempts, prov.calls.Load())
+	}
+}
+
+func TestWorker_RunStopsOnContextCancel(t *testing.T) {
+	t.Parallel()
+
+	w := &Worker{Config: newTestConfig(t, &flakyProvider{}), Queue: make(ChanQueue)}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run() = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestWorker_RunRequiresConfig(t *testing.T) {
+	t.Parallel()
+
+	if err := (&Worker{}).Run(context.Background()); err == nil {
+		t.Error("Run() without Config = nil, want error")
+	}
+}